@@ -0,0 +1,85 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"net/mail"
+	"os"
+	"strings"
+
+	"github.com/RomanKovalev007/mai_news/internal/config"
+	"github.com/RomanKovalev007/mai_news/internal/lib/password"
+	"github.com/RomanKovalev007/mai_news/internal/models"
+	"github.com/RomanKovalev007/mai_news/internal/storage/pgstore"
+	"github.com/RomanKovalev007/mai_news/internal/storage/sqlstore"
+)
+
+const usage = `usage: adduser -name NAME -email EMAIL [-role ROLE] < password
+
+creates a user, by default the first administrator, who can then sign in
+with POST /auth/login; the password is read from the first line of stdin
+and the role is one of reader, author, editor and admin`
+
+type userSaver interface{
+	SaveUser(ctx context.Context, input models.InputUser) (models.User, error)
+	Close() error
+}
+
+func main(){
+	name := flag.String("name", "", "name of the user")
+	email := flag.String("email", "", "email the user signs in with")
+	role := flag.String("role", string(models.RoleAdmin), "role of the user")
+	flag.Usage = func() { fmt.Fprintln(os.Stderr, usage) }
+	flag.Parse()
+
+	addr, err := mail.ParseAddress(*email)
+	if strings.TrimSpace(*name) == "" || err != nil || addr.Address != *email || !models.Role(*role).Valid() {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	plain, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && plain == ""{
+		log.Fatal("failed to read password: ", err)
+	}
+	plain = strings.TrimRight(plain, "\r\n")
+	if err := password.Check(plain); err != nil{
+		log.Fatal("password must be 8 to 128 characters long")
+	}
+	hash, err := password.Hash(plain)
+	if err != nil{
+		log.Fatal("failed to hash password: ", err)
+	}
+
+	cfg := config.MustLoad()
+
+	var store userSaver
+	switch cfg.StorageDriver {
+	case "sqlite", "":
+		store, err = sqlstore.New(cfg.StoragePath)
+	case "postgres":
+		store, err = pgstore.New(cfg.StoragePath)
+	case "memory":
+		log.Fatal("the memory driver keeps no users between runs")
+	default:
+		log.Fatalf("unknown storage driver %q", cfg.StorageDriver)
+	}
+	if err != nil{
+		log.Fatal("failed to open storage: ", err)
+	}
+	defer store.Close()
+
+	user, err := store.SaveUser(context.Background(), models.InputUser{
+		Name: strings.TrimSpace(*name),
+		Email: strings.ToLower(addr.Address),
+		PasswordHash: hash,
+		Role: models.Role(*role),
+	})
+	if err != nil{
+		log.Fatal("failed to create user: ", err)
+	}
+	fmt.Printf("created %s %d <%s>\n", user.Role, user.ID, user.Email)
+}
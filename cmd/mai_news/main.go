@@ -1,18 +1,51 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
 	"log/slog"
+	"net"
 	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
+	// embeds the time zone database for systems without one
+	_ "time/tzdata"
 
+	"github.com/RomanKovalev007/mai_news/internal/auth"
+	"github.com/RomanKovalev007/mai_news/internal/authz"
 	"github.com/RomanKovalev007/mai_news/internal/config"
 	"github.com/RomanKovalev007/mai_news/internal/handlers"
+	"github.com/RomanKovalev007/mai_news/internal/lib/jwt"
+	"github.com/RomanKovalev007/mai_news/internal/lib/retention"
+	"github.com/RomanKovalev007/mai_news/internal/lib/views"
+	"github.com/RomanKovalev007/mai_news/internal/middleware"
+	"github.com/RomanKovalev007/mai_news/internal/models"
+	"github.com/RomanKovalev007/mai_news/internal/storage/memstore"
+	"github.com/RomanKovalev007/mai_news/internal/storage/pgstore"
 	"github.com/RomanKovalev007/mai_news/internal/storage/sqlstore"
 )
 const (
 	envLocal = "local"
 	envDev = "dev"
 	envProd = "prod"
+
+	driverSQLite = "sqlite"
+	driverPostgres = "postgres"
+	driverMemory = "memory"
+
+	algHS256 = "HS256"
+	algEdDSA = "EdDSA"
+
+	memoryPath = ":memory:"
+
+	trashPurgeInterval = time.Hour
+	// defaultViewFlushInterval applies when the config sets no
+	// view_flush_interval
+	defaultViewFlushInterval = 10 * time.Second
 )
 func setupLogger(env string) *slog.Logger{
 	var log *slog.Logger
@@ -28,15 +61,188 @@ func setupLogger(env string) *slog.Logger{
 
 }
 
+// storageBackend is implemented by every backend selectable in the config.
+type storageBackend interface{
+	handlers.Poster
+	handlers.Trasher
+	handlers.Reviser
+	handlers.Tagger
+	handlers.Sectioner
+	handlers.Registrar
+	handlers.KeyManager
+	handlers.Commenter
+	handlers.Reactor
+	handlers.ViewStats
+	views.Store
+	authz.Posts
+	auth.Revoker
+	auth.Keys
+	retention.TrashPurger
+	io.Closer
+}
+
+func setupStorage(cfg *config.Config) (storageBackend, error){
+	switch cfg.StorageDriver{
+	case driverMemory:
+		return memstore.New(), nil
+	case driverSQLite, "":
+		// a pooled in-memory SQLite database is not shared between connections,
+		// so ":memory:" selects the in-process store instead
+		if cfg.StoragePath == memoryPath{
+			return memstore.New(), nil
+		}
+		return sqlstore.New(cfg.StoragePath)
+	case driverPostgres:
+		return pgstore.New(cfg.StoragePath)
+	}
+	return nil, fmt.Errorf("unknown storage driver %q", cfg.StorageDriver)
+}
+
+// setupAuth returns the service issuing and checking the tokens of users.
+func setupAuth(cfg *config.Config, storage storageBackend) (*auth.Service, error){
+	var method jwt.Method
+	var err error
+	switch cfg.Auth.Algorithm{
+	case algHS256, "":
+		method, err = jwt.HS256([]byte(cfg.Auth.Secret))
+	case algEdDSA:
+		data, err := os.ReadFile(cfg.Auth.PrivateKeyFile)
+		if err != nil{
+			return nil, fmt.Errorf("read private key: %w", err)
+		}
+		key, err := jwt.ParseEd25519Key(data)
+		if err != nil{
+			return nil, err
+		}
+		if method, err = jwt.EdDSA(key); err != nil{
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unknown token algorithm %q", cfg.Auth.Algorithm)
+	}
+	if err != nil{
+		return nil, err
+	}
+	return auth.New(method, storage, storage, cfg.Auth.AccessTokenTTL, cfg.Auth.RefreshTokenTTL), nil
+}
+
+// newRouter registers the HTTP API. Fixed segments such as search and trash
+// are anchored with {$}: as prefixes they would overlap the {id} routes
+// below them, which ServeMux refuses to register. Routes that change data
+// or expose accounts require a signed-in user whose role allows the action
+// of the route; a request made with an API key also needs the scope of the
+// route.
+func newRouter(cfg *config.Config, storage storageBackend, authService *auth.Service, viewCounter handlers.ViewRecorder, log *slog.Logger) http.Handler{
+	r := http.NewServeMux()
+	authorizer := authz.New(storage, storage)
+	read := middleware.RequireScope(models.ScopePostsRead)
+	write := func(action authz.Action, next http.Handler) http.Handler{
+		return signedIn(models.ScopePostsWrite, authorizer, action, log)(next)
+	}
+	remove := func(action authz.Action, next http.Handler) http.Handler{
+		return signedIn(models.ScopePostsDelete, authorizer, action, log)(next)
+	}
+	admin := signedIn(models.ScopeAdmin, authorizer, authz.ManageUsers, log)
+
+	r.Handle("GET /posts/", read(handlers.GetAllPostsHandler(storage, cfg.MaxPageSize, log)))
+	r.Handle("GET /posts/search/{$}", read(handlers.SearchPostsHandler(storage, log)))
+	r.Handle("POST /posts/", write(authz.CreatePost, handlers.CreatePostHandler(storage, authorizer, log)))
+	r.Handle("GET /posts/{id}/", read(handlers.GetPostHandler(storage, viewCounter, log)))
+	r.Handle("PATCH /posts/{id}/", write(authz.EditPost, handlers.PatchPostHandler(storage, authorizer, !cfg.IfMatchOptional, log)))
+	r.Handle("DELETE /posts/{id}/", remove(authz.DeletePost, handlers.DeletePostHandler(storage, authorizer, !cfg.IfMatchOptional, log)))
+
+	r.Handle("GET /posts/trash/{$}", signedIn(models.ScopePostsRead, authorizer, authz.ViewTrash, log)(handlers.GetTrashHandler(storage, authorizer, log)))
+	r.Handle("POST /posts/{id}/restore/", write(authz.RestorePost, handlers.RestorePostHandler(storage, authorizer, log)))
+	r.Handle("DELETE /posts/{id}/purge/", remove(authz.PurgePost, handlers.PurgePostHandler(storage, authorizer, log)))
+
+	r.Handle("GET /posts/{id}/revisions/", read(handlers.GetRevisionsHandler(storage, log)))
+	r.Handle("GET /posts/{id}/revisions/{rev}/", read(handlers.GetRevisionHandler(storage, log)))
+	r.Handle("GET /posts/{id}/revisions/{rev}/diff/", read(handlers.DiffRevisionHandler(storage, storage, log)))
+	r.Handle("POST /posts/{id}/revisions/{rev}/revert/", write(authz.EditPost, handlers.RevertPostHandler(storage, authorizer, log)))
+
+	r.Handle("GET /posts/{id}/stats/", read(handlers.GetPostStatsHandler(storage, log)))
+
+	r.Handle("GET /posts/{id}/comments/{$}", read(handlers.GetCommentsHandler(storage, log)))
+	r.Handle("POST /posts/{id}/comments/{$}", write(authz.CreateComment, handlers.CreateCommentHandler(storage, authorizer, log)))
+	r.Handle("PATCH /posts/{id}/comments/{comment}/", write(authz.EditComment, handlers.PatchCommentHandler(storage, authorizer, log)))
+	r.Handle("DELETE /posts/{id}/comments/{comment}/", remove(authz.DeleteComment, handlers.DeleteCommentHandler(storage, authorizer, log)))
+	r.Handle("GET /comments/queue/{$}", write(authz.ModerateComments, handlers.GetCommentQueueHandler(storage, authorizer, log)))
+	r.Handle("POST /comments/{id}/moderate/", write(authz.ModerateComments, handlers.ModerateCommentHandler(storage, authorizer, log)))
+
+	// readers react without signing in, see handlers.SetReactionHandler;
+	// API keys need to be allowed to write
+	react := middleware.RequireScope(models.ScopePostsWrite)
+	r.Handle("PUT /posts/{id}/reactions/{kind}/", react(handlers.SetReactionHandler(storage, log)))
+	r.Handle("DELETE /posts/{id}/reactions/{kind}/", react(handlers.DeleteReactionHandler(storage, log)))
+
+	r.Handle("GET /tags/", read(handlers.GetTagsHandler(storage, log)))
+	r.Handle("PATCH /tags/{name}/", write(authz.ManageTags, handlers.RenameTagHandler(storage, log)))
+	r.Handle("POST /tags/{name}/merge/", write(authz.ManageTags, handlers.MergeTagHandler(storage, log)))
+
+	r.Handle("GET /sections/", read(handlers.GetSectionsHandler(storage, log)))
+	r.Handle("POST /sections/", write(authz.CreateSection, handlers.CreateSectionHandler(storage, log)))
+	r.Handle("GET /sections/{slug}/", read(handlers.GetSectionHandler(storage, log)))
+	r.Handle("PATCH /sections/{slug}/", write(authz.EditSection, handlers.PatchSectionHandler(storage, authorizer, log)))
+	r.Handle("GET /sections/{slug}/posts/", read(handlers.GetSectionPostsHandler(storage, storage, cfg.MaxPageSize, log)))
+
+	r.HandleFunc("POST /auth/login", handlers.LoginHandler(authService, log))
+	r.HandleFunc("POST /auth/refresh", handlers.RefreshHandler(authService, log))
+	r.HandleFunc("POST /auth/revoke", handlers.RevokeHandler(authService, log))
+
+	r.Handle("GET /users/{id}/posts/", read(handlers.GetUserPostsHandler(storage, storage, cfg.MaxPageSize, log)))
+	r.Handle("GET /admin/users/", admin(handlers.GetUsersHandler(storage, log)))
+	r.Handle("POST /admin/users/", admin(handlers.CreateUserHandler(storage, log)))
+	r.Handle("GET /admin/users/{id}/", admin(handlers.GetUserHandler(storage, log)))
+	r.Handle("PATCH /admin/users/{id}/", admin(handlers.PatchUserHandler(storage, log)))
+	r.Handle("DELETE /admin/users/{id}/", admin(handlers.DeleteUserHandler(storage, log)))
+
+	r.Handle("GET /admin/api-keys/", admin(handlers.GetAPIKeysHandler(storage, log)))
+	r.Handle("POST /admin/api-keys/", admin(handlers.CreateAPIKeyHandler(storage, log)))
+	r.Handle("GET /admin/api-keys/{id}/", admin(handlers.GetAPIKeyHandler(storage, log)))
+	r.Handle("POST /admin/api-keys/{id}/rotate/", admin(handlers.RotateAPIKeyHandler(storage, log)))
+	r.Handle("DELETE /admin/api-keys/{id}/", admin(handlers.DeleteAPIKeyHandler(storage, log)))
+
+	// /posts/by-slug/{slug}/ and /posts/{id}/revisions/ both match
+	// /posts/by-slug/revisions/ and neither is more specific, so by-slug
+	// lives in a mux in front of the others
+	root := http.NewServeMux()
+	root.Handle("GET /posts/by-slug/{slug}/{$}", read(handlers.GetPostBySlugHandler(storage, viewCounter, log)))
+	root.Handle("/", r)
+
+	keyring := auth.NewKeyring(storage, storage, log)
+	return middleware.Authenticate(authService, log)(middleware.APIKey(keyring, log)(root))
+}
+
+// signedIn returns a middleware requiring a user allowed to do action and,
+// of requests made with an API key, the scope.
+func signedIn(scope string, authorizer middleware.RouteAuthorizer, action authz.Action, log *slog.Logger) func(http.Handler) http.Handler{
+	requireScope := middleware.RequireScope(scope)
+	permit := middleware.Permit(authorizer, action, log)
+	return func(next http.Handler) http.Handler{
+		return middleware.RequireUser(requireScope(permit(next)))
+	}
+}
 
 func main(){
 	cfg := config.MustLoad()
 
 	log := setupLogger(cfg.Env)
 
-	storage, err := sqlstore.New(cfg.StoragePath)
+	timeZone, err := time.LoadLocation(cfg.TimeZone)
+	if err != nil{
+		log.Error("invalid time zone", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+
+	storage, err := setupStorage(cfg)
+	if err != nil{
+		log.Error("failed to start storage", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+
+	authService, err := setupAuth(cfg, storage)
 	if err != nil{
-		log.Error("failed to start storage", err)
+		log.Error("failed to set up authentication", slog.String("error", err.Error()))
 		os.Exit(1)
 	}
 
@@ -44,25 +250,68 @@ func main(){
 	log.Info("starting mai_news", slog.String("env", cfg.Env))
 	log.Debug("debug messages are enabled")
 
-	r := http.NewServeMux()
+	viewCounter := views.New(storage, log)
+	r := newRouter(cfg, storage, authService, viewCounter, log)
 
-	r.HandleFunc("GET /posts/", handlers.GetAllPostsHandler(storage, log))
-	r.HandleFunc("POST /posts/", handlers.CreatePostHandler(storage, log))
-	r.HandleFunc("GET /posts/{id}/",handlers.GetPostHandler(storage, log))
-	r.HandleFunc("PATCH /posts/{id}/",handlers.PatchPostHandler(storage, log))
-	r.HandleFunc("DELETE /posts/{id}/",handlers.DeletePostHandler(storage, log))
+	// requests derive from baseCtx so that in-flight storage calls can be
+	// aborted if they outlive the shutdown grace period
+	baseCtx, cancelBase := context.WithCancelCause(context.Background())
 
 	srv := &http.Server{
 		Addr:         cfg.Address,
-		Handler:      r,
+		Handler:      middleware.Timeout(cfg.HTTPServer.Timeout)(middleware.TimeZone(timeZone)(r)),
 		ReadTimeout:  cfg.HTTPServer.Timeout,
 		WriteTimeout: cfg.HTTPServer.Timeout,
 		IdleTimeout:  cfg.HTTPServer.IdleTimeout,
+		BaseContext:  func(net.Listener) context.Context { return baseCtx },
+	}
+
+	if cfg.TrashRetentionDays > 0{
+		retentionPeriod := time.Duration(cfg.TrashRetentionDays) * 24 * time.Hour
+		go retention.PurgeTrash(baseCtx, log, storage, retentionPeriod, trashPurgeInterval)
+	}
+
+	viewFlushInterval := cfg.ViewFlushInterval
+	if viewFlushInterval <= 0{
+		viewFlushInterval = defaultViewFlushInterval
+	}
+	// views keep being flushed until the server has stopped serving posts,
+	// and once more then
+	viewsCtx, stopViews := context.WithCancel(context.Background())
+	viewsDone := make(chan struct{})
+	go func(){
+		viewCounter.Run(viewsCtx, viewFlushInterval)
+		close(viewsDone)
+	}()
+
+	go func(){
+		log.Info("server started", slog.String("address", cfg.Address))
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed){
+			log.Error("failed to start server", slog.String("error", err.Error()))
+			os.Exit(1)
+		}
+	}()
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, syscall.SIGINT, syscall.SIGTERM)
+	<-stop
+
+	log.Info("stopping server")
+
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.HTTPServer.Timeout)
+	defer cancel()
+
+	if err := srv.Shutdown(ctx); err != nil{
+		log.Error("failed to stop server gracefully", slog.String("error", err.Error()))
 	}
+	cancelBase(handlers.ErrShuttingDown)
 
-	log.Info("server started: http/localhost:8000/" )
-	if err := srv.ListenAndServe(); err != nil{
-		log.Error("failed to start server", err)
+	stopViews()
+	<-viewsDone
+
+	if err := storage.Close(); err != nil{
+		log.Error("failed to close storage", slog.String("error", err.Error()))
 	}
-	
-}
\ No newline at end of file
+
+	log.Info("server stopped")
+}
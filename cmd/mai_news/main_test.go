@@ -0,0 +1,339 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/RomanKovalev007/mai_news/internal/config"
+	"github.com/RomanKovalev007/mai_news/internal/lib/logger/slogdiscard"
+	"github.com/RomanKovalev007/mai_news/internal/lib/password"
+	"github.com/RomanKovalev007/mai_news/internal/lib/views"
+	"github.com/RomanKovalev007/mai_news/internal/models"
+	"github.com/RomanKovalev007/mai_news/internal/storage/memstore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestRouter returns the router over an empty memstore with one admin and
+// an access token of the admin.
+func newTestRouter(t *testing.T) (http.Handler, string) {
+	t.Helper()
+	r, token, _ := newTestRouterViews(t)
+	return r, token
+}
+
+// newTestRouterViews is newTestRouter that also returns the view counter of
+// the router, which is never flushed unless the test does so.
+func newTestRouterViews(t *testing.T) (http.Handler, string, *views.Aggregator) {
+	t.Helper()
+	cfg := &config.Config{MaxPageSize: 100, Auth: config.Auth{Secret: strings.Repeat("s", 32)}}
+	storage := memstore.New()
+	authService, err := setupAuth(cfg, storage)
+	require.NoError(t, err)
+	viewCounter := views.New(storage, slogdiscard.NewDiscardLogger())
+	r := newRouter(cfg, storage, authService, viewCounter, slogdiscard.NewDiscardLogger())
+
+	hash, err := password.Hash("correct horse")
+	require.NoError(t, err)
+	_, err = storage.SaveUser(context.Background(), models.InputUser{Name: "Анна", Email: "anna@mai.ru", PasswordHash: hash, Role: models.RoleAdmin})
+	require.NoError(t, err)
+
+	return r, login(t, r, "anna@mai.ru", "correct horse"), viewCounter
+}
+
+// login returns an access token of the user.
+func login(t *testing.T, r http.Handler, email, password string) string {
+	t.Helper()
+	body, err := json.Marshal(map[string]string{"email": email, "password": password})
+	require.NoError(t, err)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("POST", "/auth/login", bytes.NewReader(body)))
+	require.Equal(t, http.StatusOK, w.Code)
+	var tokens models.Tokens
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&tokens))
+	return tokens.AccessToken
+}
+
+func TestRouter(t *testing.T) {
+	r, accessToken := newTestRouter(t)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("POST", "/posts/", bytes.NewBufferString(`{"title":"Title","content":"Content"}`)))
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	post := func(target, body string) int {
+		req := httptest.NewRequest("POST", target, bytes.NewBufferString(body))
+		req.Header.Set("Authorization", "Bearer "+accessToken)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+	assert.Equal(t, http.StatusCreated, post("/posts/", `{"title":"Title","content":"Content","tags":["News"]}`))
+	assert.Equal(t, http.StatusCreated, post("/sections/", `{"slug":"it","title":"Институт №8"}`))
+	assert.Equal(t, http.StatusCreated, post("/posts/", `{"title":"Schedule","content":"Content","section":"it"}`))
+
+	tests := []struct {
+		target         string
+		expectedStatus int
+		expectedPrefix string
+	}{
+		{"/posts/", http.StatusOK, `{"posts":[{"id":2,`},
+		{"/posts/1/", http.StatusOK, `{"id":1,`},
+		{"/posts/3/", http.StatusNotFound, "Post not found"},
+		{"/posts/trash/", http.StatusUnauthorized, "Authentication required"},
+		{"/posts/1/revisions/", http.StatusOK, "[]"},
+		{"/posts/search/?q=title", http.StatusOK, `[{"id":1,`},
+		{"/posts/by-slug/title/", http.StatusOK, `{"id":1,`},
+		{"/posts/by-slug/revisions/", http.StatusNotFound, "Post not found"},
+		{"/posts/?tag=news", http.StatusOK, `{"posts":[{"id":1,`},
+		{"/tags/", http.StatusOK, `[{"name":"news","count":1}]`},
+		{"/sections/", http.StatusOK, `[{"id":2,"slug":"it",`},
+		{"/sections/it/", http.StatusOK, `{"id":2,"slug":"it",`},
+		{"/sections/it/posts/", http.StatusOK, `{"posts":[{"id":2,`},
+		{"/sections/general/posts/", http.StatusOK, `{"posts":[{"id":1,`},
+		{"/sections/missing/posts/", http.StatusNotFound, "Section not found"},
+		{"/admin/users/", http.StatusUnauthorized, "Authentication required"},
+		{"/users/1/posts/", http.StatusOK, `{"posts":[{"id":2,`},
+		{"/users/2/posts/", http.StatusNotFound, "User not found"},
+	}
+
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", tt.target, nil))
+		assert.Equal(t, tt.expectedStatus, w.Code, tt.target)
+		assert.True(t, strings.HasPrefix(w.Body.String(), tt.expectedPrefix), "%s: %s", tt.target, w.Body.String())
+	}
+}
+
+func TestRouterAPIKeys(t *testing.T) {
+	r, accessToken := newTestRouter(t)
+
+	do := func(method, target, authorization, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, bytes.NewBufferString(body))
+		req.Header.Set("Authorization", authorization)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := do("POST", "/admin/api-keys/", "Bearer "+accessToken, `{"name":"bot","scopes":["posts:read","posts:write"]}`)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var issued models.IssuedAPIKey
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&issued))
+	key := "ApiKey " + issued.Key
+
+	assert.Equal(t, http.StatusCreated, do("POST", "/posts/", key, `{"title":"Title","content":"Content"}`).Code)
+	w = do("GET", "/posts/1/", key, "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"author":{"id":1,"name":"Анна"}`)
+	assert.Equal(t, http.StatusForbidden, do("DELETE", "/posts/1/", key, "").Code)
+	assert.Equal(t, http.StatusForbidden, do("GET", "/admin/users/", key, "").Code)
+	assert.Equal(t, http.StatusUnauthorized, do("GET", "/posts/", "ApiKey mai_unknown", "").Code)
+
+	w = do("GET", "/admin/api-keys/1/", "Bearer "+accessToken, "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"last_used_at":`)
+
+	w = do("POST", "/admin/api-keys/1/rotate/", "Bearer "+accessToken, "")
+	require.Equal(t, http.StatusOK, w.Code)
+	var rotated models.IssuedAPIKey
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&rotated))
+	assert.Equal(t, http.StatusUnauthorized, do("GET", "/posts/", key, "").Code)
+	assert.Equal(t, http.StatusOK, do("GET", "/posts/", "ApiKey "+rotated.Key, "").Code)
+
+	assert.Equal(t, http.StatusOK, do("DELETE", "/admin/api-keys/1/", "Bearer "+accessToken, "").Code)
+	assert.Equal(t, http.StatusUnauthorized, do("GET", "/posts/", "ApiKey "+rotated.Key, "").Code)
+}
+
+func TestRouterRoles(t *testing.T) {
+	r, adminToken := newTestRouter(t)
+
+	do := func(method, target, token, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, bytes.NewBufferString(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("If-Match", "*")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	require.Equal(t, http.StatusCreated, do("POST", "/admin/users/", adminToken, `{"name":"Борис","email":"boris@mai.ru","password":"correct horse","role":"author"}`).Code)
+	require.Equal(t, http.StatusCreated, do("POST", "/admin/users/", adminToken, `{"name":"Вера","email":"vera@mai.ru","password":"correct horse"}`).Code)
+	author := login(t, r, "boris@mai.ru", "correct horse")
+	reader := login(t, r, "vera@mai.ru", "correct horse")
+
+	require.Equal(t, http.StatusCreated, do("POST", "/posts/", adminToken, `{"title":"By admin","content":"Content"}`).Code)
+	require.Equal(t, http.StatusCreated, do("POST", "/posts/", author, `{"title":"By author","content":"Content"}`).Code)
+
+	w := do("POST", "/posts/", reader, `{"title":"By reader","content":"Content"}`)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Equal(t, `{"action":"posts.create","reason":"role","message":"Role \"reader\" may not do this"}`+"\n", w.Body.String())
+
+	w = do("PATCH", "/posts/1/", author, `{"title":"Mine"}`)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Equal(t, `{"action":"posts.edit","reason":"not_author","message":"Only the author may do this to the post"}`+"\n", w.Body.String())
+	assert.Equal(t, http.StatusNotFound, do("PATCH", "/posts/3/", author, `{"title":"Mine"}`).Code)
+	assert.Equal(t, http.StatusOK, do("PATCH", "/posts/2/", author, `{"title":"Mine"}`).Code)
+	assert.Equal(t, http.StatusOK, do("PATCH", "/posts/1/", adminToken, `{"title":"Also mine"}`).Code)
+
+	assert.Equal(t, http.StatusOK, do("DELETE", "/posts/2/", author, "").Code)
+	assert.Equal(t, http.StatusOK, do("POST", "/posts/2/restore/", author, "").Code)
+	assert.Equal(t, http.StatusForbidden, do("DELETE", "/posts/2/purge/", author, "").Code)
+	assert.Equal(t, http.StatusForbidden, do("GET", "/posts/trash/", author, "").Code)
+	assert.Equal(t, http.StatusOK, do("GET", "/posts/trash/", adminToken, "").Code)
+
+	// editors publish in the default section and the sections they edit
+	require.Equal(t, http.StatusCreated, do("POST", "/admin/users/", adminToken, `{"name":"Глеб","email":"gleb@mai.ru","password":"correct horse","role":"editor"}`).Code)
+	editor := login(t, r, "gleb@mai.ru", "correct horse")
+	require.Equal(t, http.StatusCreated, do("POST", "/sections/", adminToken, `{"slug":"it","title":"Институт №8","editors":["gleb@mai.ru"]}`).Code)
+	require.Equal(t, http.StatusCreated, do("POST", "/sections/", adminToken, `{"slug":"sport","title":"Спорт"}`).Code)
+	assert.Equal(t, http.StatusForbidden, do("POST", "/posts/", author, `{"title":"Schedule","content":"Content","section":"it"}`).Code)
+	require.Equal(t, http.StatusCreated, do("POST", "/posts/", editor, `{"title":"Schedule","content":"Content","section":"it"}`).Code)
+	w = do("PATCH", "/posts/3/", editor, `{"section":"sport"}`)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Equal(t, `{"action":"posts.edit","reason":"not_section_editor","message":"Only editors of section \"sport\" may do this"}`+"\n", w.Body.String())
+	assert.Equal(t, http.StatusForbidden, do("PATCH", "/posts/2/", author, `{"section":"it"}`).Code)
+	assert.Equal(t, http.StatusOK, do("PATCH", "/posts/3/", adminToken, `{"section":"sport"}`).Code)
+
+	// editors change their sections but not who edits them
+	assert.Equal(t, http.StatusOK, do("PATCH", "/sections/it/", editor, `{"description":"Компьютерные науки"}`).Code)
+	w = do("PATCH", "/sections/it/", editor, `{"editors":["gleb@mai.ru","boris@mai.ru"]}`)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Equal(t, `{"action":"sections.editors","reason":"role","message":"Role \"editor\" may not do this"}`+"\n", w.Body.String())
+	assert.Equal(t, http.StatusOK, do("PATCH", "/sections/it/", adminToken, `{"editors":["gleb@mai.ru","boris@mai.ru"]}`).Code)
+	assert.Equal(t, http.StatusForbidden, do("GET", "/admin/users/", author, "").Code)
+}
+
+func TestRouterComments(t *testing.T) {
+	r, adminToken := newTestRouter(t)
+
+	do := func(method, target, token, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, bytes.NewBufferString(body))
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	require.Equal(t, http.StatusCreated, do("POST", "/admin/users/", adminToken, `{"name":"Вера","email":"vera@mai.ru","password":"correct horse"}`).Code)
+	reader := login(t, r, "vera@mai.ru", "correct horse")
+	require.Equal(t, http.StatusCreated, do("POST", "/posts/", adminToken, `{"title":"Title","content":"Content"}`).Code)
+
+	assert.Equal(t, http.StatusUnauthorized, do("POST", "/posts/1/comments/", "", `{"content":"Hi"}`).Code)
+	w := do("POST", "/posts/1/comments/", reader, `{"content":"Hi"}`)
+	require.Equal(t, http.StatusCreated, w.Code)
+	assert.Contains(t, w.Body.String(), `"status":"pending"`)
+	assert.Equal(t, "[]\n", do("GET", "/posts/1/comments/", "", "").Body.String())
+
+	assert.Equal(t, http.StatusForbidden, do("GET", "/comments/queue/", reader, "").Code)
+	w = do("GET", "/comments/queue/", adminToken, "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.True(t, strings.HasPrefix(w.Body.String(), `[{"id":1,`), w.Body.String())
+	// editors moderate the sections they edit only
+	require.Equal(t, http.StatusCreated, do("POST", "/admin/users/", adminToken, `{"name":"Глеб","email":"gleb@mai.ru","password":"correct horse","role":"editor"}`).Code)
+	editor := login(t, r, "gleb@mai.ru", "correct horse")
+	assert.Equal(t, "[]\n", do("GET", "/comments/queue/", editor, "").Body.String())
+	assert.Equal(t, http.StatusForbidden, do("POST", "/comments/1/moderate/", editor, `{"status":"rejected"}`).Code)
+	assert.Equal(t, http.StatusOK, do("POST", "/comments/1/moderate/", adminToken, `{"status":"approved"}`).Code)
+
+	// comments of moderators need no approval
+	require.Equal(t, http.StatusCreated, do("POST", "/posts/1/comments/", adminToken, `{"content":"Welcome","parent_id":1}`).Code)
+	w = do("GET", "/posts/1/comments/", "", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"replies":[{"id":2,"post_id":1,"parent_id":1,`)
+	assert.Contains(t, do("GET", "/posts/1/", "", "").Body.String(), `"comment_count":2`)
+
+	assert.Equal(t, http.StatusForbidden, do("PATCH", "/posts/1/comments/2/", reader, `{"content":"Mine"}`).Code)
+	assert.Equal(t, http.StatusOK, do("PATCH", "/posts/1/comments/1/", reader, `{"content":"Hi again"}`).Code)
+	// the edited comment awaits moderation again, and the reply is hidden
+	// with it
+	assert.Contains(t, do("GET", "/posts/1/", "", "").Body.String(), `"title":"Title","content":"Content","slug":"title","section":"general","author":{"id":1,"name":"Анна"},"created_at"`)
+	assert.Equal(t, http.StatusOK, do("DELETE", "/posts/1/comments/1/", reader, "").Code)
+	assert.Equal(t, http.StatusNotFound, do("DELETE", "/posts/1/comments/2/", adminToken, "").Code)
+}
+
+func TestRouterReactions(t *testing.T) {
+	r, adminToken := newTestRouter(t)
+
+	do := func(method, target, token, fingerprint string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		if fingerprint != "" {
+			req.Header.Set("X-Client-Fingerprint", fingerprint)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	post := httptest.NewRequest("POST", "/posts/", bytes.NewBufferString(`{"title":"Title","content":"Content"}`))
+	post.Header.Set("Authorization", "Bearer "+adminToken)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, post)
+	require.Equal(t, http.StatusCreated, w.Code)
+
+	assert.Equal(t, http.StatusBadRequest, do("PUT", "/posts/1/reactions/like/", "", "").Code)
+	w = do("PUT", "/posts/1/reactions/like/", adminToken, "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `{"post_id":1,"kind":"like","reactions":{"like":1}}`+"\n", w.Body.String())
+	w = do("PUT", "/posts/1/reactions/heart/", "", "0123456789abcdef")
+	assert.Equal(t, `{"post_id":1,"kind":"heart","reactions":{"heart":1,"like":1}}`+"\n", w.Body.String())
+	assert.Contains(t, do("GET", "/posts/", "", "").Body.String(), `"reactions":{"heart":1,"like":1}`)
+
+	assert.Equal(t, http.StatusNotFound, do("DELETE", "/posts/1/reactions/like/", "", "0123456789abcdef").Code)
+	assert.Equal(t, http.StatusOK, do("DELETE", "/posts/1/reactions/heart/", "", "0123456789abcdef").Code)
+	assert.Contains(t, do("GET", "/posts/1/", "", "").Body.String(), `"reactions":{"like":1}`)
+
+	// API keys react only with the write scope
+	for scope, status := range map[string]int{"posts:read": http.StatusForbidden, "posts:write": http.StatusOK} {
+		create := httptest.NewRequest("POST", "/admin/api-keys/", bytes.NewBufferString(`{"name":"bot","scopes":["`+scope+`"]}`))
+		create.Header.Set("Authorization", "Bearer "+adminToken)
+		w = httptest.NewRecorder()
+		r.ServeHTTP(w, create)
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+		var issued models.IssuedAPIKey
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&issued))
+
+		for _, method := range []string{"PUT", "DELETE"} {
+			req := httptest.NewRequest(method, "/posts/1/reactions/fire/", nil)
+			req.Header.Set("Authorization", "ApiKey "+issued.Key)
+			w = httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			assert.Equal(t, status, w.Code, "%s %s", method, scope)
+		}
+	}
+}
+
+func TestRouterViews(t *testing.T) {
+	r, adminToken, viewCounter := newTestRouterViews(t)
+
+	do := func(method, target, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, bytes.NewBufferString(body))
+		req.Header.Set("Authorization", "Bearer "+adminToken)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	require.Equal(t, http.StatusCreated, do("POST", "/posts/", `{"title":"Title","content":"Content"}`).Code)
+	assert.Equal(t, `{"post_id":1,"views":0,"days":[]}`+"\n", do("GET", "/posts/1/stats/", "").Body.String())
+
+	require.Equal(t, http.StatusOK, do("GET", "/posts/1/", "").Code)
+	require.Equal(t, http.StatusOK, do("GET", "/posts/by-slug/title/", "").Code)
+	// the views are buffered until the next flush
+	assert.Contains(t, do("GET", "/posts/1/stats/", "").Body.String(), `"views":0`)
+	require.NoError(t, viewCounter.Flush(context.Background()))
+	day := time.Now().UTC().Format(time.DateOnly)
+	assert.Equal(t, `{"post_id":1,"views":2,"days":[{"day":"`+day+`","views":2}]}`+"\n", do("GET", "/posts/1/stats/", "").Body.String())
+	assert.Equal(t, http.StatusNotFound, do("GET", "/posts/2/stats/", "").Code)
+}
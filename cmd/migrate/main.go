@@ -0,0 +1,100 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+
+	"github.com/RomanKovalev007/mai_news/internal/config"
+	"github.com/RomanKovalev007/mai_news/internal/storage/migrate"
+	"github.com/RomanKovalev007/mai_news/internal/storage/pgstore"
+	"github.com/RomanKovalev007/mai_news/internal/storage/sqlstore"
+	_ "github.com/lib/pq"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+const usage = `usage: migrate <command>
+
+commands:
+  up          apply all pending migrations
+  down [N]    roll back the last N migrations (default 1)
+  status      list migrations and whether they are applied
+  unlock      release a lock left by a crashed migration`
+
+func main(){
+	if len(os.Args) < 2 {
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	cfg := config.MustLoad()
+
+	var db *sql.DB
+	var migrator *migrate.Migrator
+	var err error
+	switch cfg.StorageDriver {
+	case "sqlite", "":
+		db, err = sql.Open("sqlite3", cfg.StoragePath)
+		if err == nil {
+			migrator, err = sqlstore.NewMigrator(db)
+		}
+	case "postgres":
+		db, err = sql.Open("postgres", cfg.StoragePath)
+		if err == nil {
+			migrator, err = pgstore.NewMigrator(db)
+		}
+	case "memory":
+		log.Fatal("the memory driver keeps no schema to migrate")
+	default:
+		log.Fatalf("unknown storage driver %q", cfg.StorageDriver)
+	}
+	if err != nil{
+		log.Fatal("failed to load migrations: ", err)
+	}
+	defer db.Close()
+
+	switch os.Args[1] {
+	case "up":
+		n, err := migrator.Up()
+		if err != nil{
+			log.Fatal(err)
+		}
+		fmt.Printf("applied %d migration(s)\n", n)
+	case "down":
+		steps := 1
+		if len(os.Args) > 2 {
+			steps, err = strconv.Atoi(os.Args[2])
+			if err != nil || steps < 1 {
+				log.Fatal("invalid number of steps: ", os.Args[2])
+			}
+		}
+		n, err := migrator.Down(steps)
+		if err != nil && !errors.Is(err, migrate.ErrNoMigrations){
+			log.Fatal(err)
+		}
+		fmt.Printf("rolled back %d migration(s)\n", n)
+	case "status":
+		statuses, err := migrator.Status()
+		if err != nil{
+			log.Fatal(err)
+		}
+		for _, s := range statuses {
+			state := "pending"
+			if s.Applied {
+				state = "applied " + s.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%04d  %-30s %s\n", s.Version, s.Name, state)
+		}
+	case "unlock":
+		if err := migrator.ForceUnlock(); err != nil{
+			log.Fatal(err)
+		}
+		fmt.Println("lock released")
+	default:
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
+	}
+}
@@ -0,0 +1,67 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/RomanKovalev007/mai_news/internal/lib/apikey"
+	"github.com/RomanKovalev007/mai_news/internal/models"
+	"github.com/RomanKovalev007/mai_news/internal/storage"
+)
+
+// lastUsedPrecision is how stale last_used_at of a key may get, so that a
+// busy client does not write on each request.
+const lastUsedPrecision = time.Minute
+
+// ErrInvalidKey covers unknown, rotated and revoked API keys.
+var ErrInvalidKey = errors.New("invalid api key")
+
+// Keys looks up API keys and records their use.
+type Keys interface {
+	GetAPIKeyByHash(ctx context.Context, hash string) (models.APIKey, error)
+	TouchAPIKey(ctx context.Context, id int, usedAt time.Time) error
+}
+
+// Keyring checks the API keys of machine clients.
+type Keyring struct {
+	keys  Keys
+	users Users
+	log   *slog.Logger
+	now   func() time.Time
+}
+
+func NewKeyring(keys Keys, users Users, log *slog.Logger) *Keyring {
+	return &Keyring{keys: keys, users: users, log: log, now: time.Now}
+}
+
+// Authenticate returns the user an API key acts for and the scopes of the
+// key. Failing to record the use of the key is only logged.
+func (k *Keyring) Authenticate(ctx context.Context, key string) (models.User, []string, error) {
+	found, err := k.keys.GetAPIKeyByHash(ctx, apikey.Hash(key))
+	if err != nil {
+		if errors.Is(err, storage.ErrAPIKeyNotFound) {
+			return models.User{}, nil, ErrInvalidKey
+		}
+		return models.User{}, nil, fmt.Errorf("get api key: %w", err)
+	}
+
+	user, err := k.users.GetUser(ctx, found.UserID)
+	if err != nil {
+		if errors.Is(err, storage.ErrUserNotFound) {
+			return models.User{}, nil, ErrInvalidKey
+		}
+		return models.User{}, nil, fmt.Errorf("get user: %w", err)
+	}
+
+	now := k.now()
+	if found.LastUsedAt == nil || now.Sub(*found.LastUsedAt) >= lastUsedPrecision {
+		if err := k.keys.TouchAPIKey(ctx, found.ID, now); err != nil {
+			k.log.Error("failed to touch api key", slog.Int("id", found.ID), slog.String("error", err.Error()))
+		}
+	}
+
+	return user, found.Scopes, nil
+}
@@ -0,0 +1,92 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/RomanKovalev007/mai_news/internal/lib/apikey"
+	"github.com/RomanKovalev007/mai_news/internal/lib/logger/slogdiscard"
+	"github.com/RomanKovalev007/mai_news/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyring(t *testing.T) {
+	ctx := context.Background()
+	_, store, user := newTestService(t)
+	k := NewKeyring(store, store, slogdiscard.NewDiscardLogger())
+
+	key, prefix, hash := apikey.Generate()
+	saved, err := store.SaveAPIKey(ctx, models.InputAPIKey{
+		Name:   "bot",
+		Prefix: prefix,
+		Hash:   hash,
+		Scopes: []string{models.ScopePostsRead},
+		UserID: user.ID,
+	})
+	require.NoError(t, err)
+
+	got, scopes, err := k.Authenticate(ctx, key)
+	require.NoError(t, err)
+	assert.Equal(t, user.ID, got.ID)
+	assert.Equal(t, []string{models.ScopePostsRead}, scopes)
+
+	saved, err = store.GetAPIKey(ctx, saved.ID)
+	require.NoError(t, err)
+	require.NotNil(t, saved.LastUsedAt)
+	firstUse := *saved.LastUsedAt
+
+	// uses within lastUsedPrecision are not recorded
+	k.now = func() time.Time { return firstUse.Add(lastUsedPrecision / 2) }
+	_, _, err = k.Authenticate(ctx, key)
+	require.NoError(t, err)
+	saved, err = store.GetAPIKey(ctx, saved.ID)
+	require.NoError(t, err)
+	assert.True(t, firstUse.Equal(*saved.LastUsedAt))
+
+	k.now = func() time.Time { return firstUse.Add(lastUsedPrecision) }
+	_, _, err = k.Authenticate(ctx, key)
+	require.NoError(t, err)
+	saved, err = store.GetAPIKey(ctx, saved.ID)
+	require.NoError(t, err)
+	assert.True(t, firstUse.Add(lastUsedPrecision).Equal(*saved.LastUsedAt))
+
+	_, _, err = k.Authenticate(ctx, key+"x")
+	assert.ErrorIs(t, err, ErrInvalidKey)
+
+	require.NoError(t, store.DeleteAPIKey(ctx, saved.ID))
+	_, _, err = k.Authenticate(ctx, key)
+	assert.ErrorIs(t, err, ErrInvalidKey)
+}
+
+// untouchable fails to record the use of keys.
+type untouchable struct {
+	Keys
+}
+
+func (untouchable) TouchAPIKey(ctx context.Context, id int, usedAt time.Time) error {
+	return errors.New("disk full")
+}
+
+func TestKeyringTouchFails(t *testing.T) {
+	ctx := context.Background()
+	_, store, user := newTestService(t)
+	k := NewKeyring(untouchable{store}, store, slogdiscard.NewDiscardLogger())
+
+	key, prefix, hash := apikey.Generate()
+	_, err := store.SaveAPIKey(ctx, models.InputAPIKey{
+		Name:   "bot",
+		Prefix: prefix,
+		Hash:   hash,
+		Scopes: []string{models.ScopePostsRead},
+		UserID: user.ID,
+	})
+	require.NoError(t, err)
+
+	got, scopes, err := k.Authenticate(ctx, key)
+	require.NoError(t, err)
+	assert.Equal(t, user.ID, got.ID)
+	assert.Equal(t, []string{models.ScopePostsRead}, scopes)
+}
@@ -0,0 +1,206 @@
+// Package auth issues and checks the JWTs users authenticate with. A login
+// yields a short-lived access token and a long-lived refresh token; a
+// refresh token can be used once, for a new pair. Revoked tokens are kept
+// in a denylist until they expire.
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/RomanKovalev007/mai_news/internal/lib/jwt"
+	"github.com/RomanKovalev007/mai_news/internal/lib/password"
+	"github.com/RomanKovalev007/mai_news/internal/models"
+	"github.com/RomanKovalev007/mai_news/internal/storage"
+)
+
+const (
+	DefaultAccessTTL  = 15 * time.Minute
+	DefaultRefreshTTL = 30 * 24 * time.Hour
+
+	typeAccess  = "access"
+	typeRefresh = "refresh"
+)
+
+var (
+	ErrInvalidCredentials = errors.New("invalid email or password")
+	// ErrInvalidToken covers malformed, forged, expired and revoked tokens
+	// as well as tokens of deleted users.
+	ErrInvalidToken = errors.New("invalid token")
+)
+
+// Users looks up the accounts tokens are issued to.
+type Users interface {
+	GetUser(ctx context.Context, id int) (models.User, error)
+	GetUserByEmail(ctx context.Context, email string) (models.User, error)
+}
+
+// Revoker keeps the ids of revoked tokens.
+type Revoker interface {
+	// RevokeToken fails with storage.ErrTokenRevoked if the token was
+	// revoked before.
+	RevokeToken(ctx context.Context, id string, expiresAt time.Time) error
+	TokenRevoked(ctx context.Context, id string) (bool, error)
+}
+
+type Service struct {
+	method     jwt.Method
+	users      Users
+	revoker    Revoker
+	accessTTL  time.Duration
+	refreshTTL time.Duration
+	now        func() time.Time
+}
+
+// New returns a Service signing tokens with method. Zero TTLs select
+// DefaultAccessTTL and DefaultRefreshTTL.
+func New(method jwt.Method, users Users, revoker Revoker, accessTTL, refreshTTL time.Duration) *Service {
+	if accessTTL <= 0 {
+		accessTTL = DefaultAccessTTL
+	}
+	if refreshTTL <= 0 {
+		refreshTTL = DefaultRefreshTTL
+	}
+	return &Service{
+		method:     method,
+		users:      users,
+		revoker:    revoker,
+		accessTTL:  accessTTL,
+		refreshTTL: refreshTTL,
+		now:        time.Now,
+	}
+}
+
+// dummyHash is verified against when there is no user with the email, so
+// that a login takes as long whether the email is known or not.
+var dummyHash = sync.OnceValue(func() string {
+	hash, _ := password.Hash("")
+	return hash
+})
+
+// Login checks the password of the user with the lowercased email and
+// issues a pair of tokens.
+func (s *Service) Login(ctx context.Context, email, plain string) (models.Tokens, error) {
+	user, err := s.users.GetUserByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, storage.ErrUserNotFound) {
+			password.Verify(dummyHash(), plain)
+			return models.Tokens{}, ErrInvalidCredentials
+		}
+		return models.Tokens{}, fmt.Errorf("get user: %w", err)
+	}
+	if !password.Verify(user.PasswordHash, plain) {
+		return models.Tokens{}, ErrInvalidCredentials
+	}
+
+	return s.issue(user.ID)
+}
+
+// Refresh exchanges a refresh token for a new pair. The refresh token is
+// revoked, so of two refreshes with the same token only one succeeds.
+func (s *Service) Refresh(ctx context.Context, refreshToken string) (models.Tokens, error) {
+	claims, user, err := s.check(ctx, refreshToken, typeRefresh)
+	if err != nil {
+		return models.Tokens{}, err
+	}
+
+	err = s.revoker.RevokeToken(ctx, claims.ID, time.Unix(claims.ExpiresAt, 0))
+	if err != nil {
+		if errors.Is(err, storage.ErrTokenRevoked) {
+			return models.Tokens{}, ErrInvalidToken
+		}
+		return models.Tokens{}, fmt.Errorf("revoke refresh token: %w", err)
+	}
+
+	return s.issue(user.ID)
+}
+
+// Revoke revokes an access or refresh token. Tokens that are invalid
+// already are left alone.
+func (s *Service) Revoke(ctx context.Context, token string) error {
+	claims, err := jwt.Parse(s.method, token, s.now())
+	if err != nil {
+		return nil
+	}
+
+	err = s.revoker.RevokeToken(ctx, claims.ID, time.Unix(claims.ExpiresAt, 0))
+	if err != nil && !errors.Is(err, storage.ErrTokenRevoked) {
+		return fmt.Errorf("revoke token: %w", err)
+	}
+	return nil
+}
+
+// Authenticate returns the user an access token was issued to.
+func (s *Service) Authenticate(ctx context.Context, accessToken string) (models.User, error) {
+	_, user, err := s.check(ctx, accessToken, typeAccess)
+	return user, err
+}
+
+// check verifies a token of the given type and that neither it was revoked
+// nor its user deleted.
+func (s *Service) check(ctx context.Context, token, typ string) (jwt.Claims, models.User, error) {
+	claims, err := jwt.Parse(s.method, token, s.now())
+	if err != nil || claims.Type != typ {
+		return jwt.Claims{}, models.User{}, ErrInvalidToken
+	}
+	userID, err := strconv.Atoi(claims.Subject)
+	if err != nil {
+		return jwt.Claims{}, models.User{}, ErrInvalidToken
+	}
+
+	revoked, err := s.revoker.TokenRevoked(ctx, claims.ID)
+	if err != nil {
+		return jwt.Claims{}, models.User{}, fmt.Errorf("check revocation: %w", err)
+	}
+	if revoked {
+		return jwt.Claims{}, models.User{}, ErrInvalidToken
+	}
+
+	user, err := s.users.GetUser(ctx, userID)
+	if err != nil {
+		if errors.Is(err, storage.ErrUserNotFound) {
+			return jwt.Claims{}, models.User{}, ErrInvalidToken
+		}
+		return jwt.Claims{}, models.User{}, fmt.Errorf("get user: %w", err)
+	}
+
+	return claims, user, nil
+}
+
+func (s *Service) issue(userID int) (models.Tokens, error) {
+	now := s.now()
+	access, err := s.sign(userID, typeAccess, now, s.accessTTL)
+	if err != nil {
+		return models.Tokens{}, err
+	}
+	refresh, err := s.sign(userID, typeRefresh, now, s.refreshTTL)
+	if err != nil {
+		return models.Tokens{}, err
+	}
+
+	return models.Tokens{
+		AccessToken:  access,
+		RefreshToken: refresh,
+		TokenType:    "Bearer",
+		ExpiresIn:    int(s.accessTTL / time.Second),
+	}, nil
+}
+
+func (s *Service) sign(userID int, typ string, now time.Time, ttl time.Duration) (string, error) {
+	token, err := jwt.Sign(s.method, jwt.Claims{
+		Subject:   strconv.Itoa(userID),
+		ID:        rand.Text(),
+		Type:      typ,
+		IssuedAt:  now.Unix(),
+		ExpiresAt: now.Add(ttl).Unix(),
+	})
+	if err != nil {
+		return "", fmt.Errorf("sign %s token: %w", typ, err)
+	}
+	return token, nil
+}
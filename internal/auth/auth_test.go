@@ -0,0 +1,112 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/RomanKovalev007/mai_news/internal/lib/jwt"
+	"github.com/RomanKovalev007/mai_news/internal/lib/password"
+	"github.com/RomanKovalev007/mai_news/internal/models"
+	"github.com/RomanKovalev007/mai_news/internal/storage/memstore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestService(t *testing.T) (*Service, *memstore.Storage, models.User) {
+	t.Helper()
+	method, err := jwt.HS256([]byte("0123456789abcdef0123456789abcdef"))
+	require.NoError(t, err)
+	store := memstore.New()
+	hash, err := password.Hash("correct horse")
+	require.NoError(t, err)
+	user, err := store.SaveUser(context.Background(), models.InputUser{Name: "Анна", Email: "anna@mai.ru", PasswordHash: hash})
+	require.NoError(t, err)
+	return New(method, store, store, 0, 0), store, user
+}
+
+func TestLogin(t *testing.T) {
+	ctx := context.Background()
+	s, _, user := newTestService(t)
+
+	tokens, err := s.Login(ctx, "anna@mai.ru", "correct horse")
+	require.NoError(t, err)
+	assert.Equal(t, "Bearer", tokens.TokenType)
+	assert.Equal(t, int(DefaultAccessTTL/time.Second), tokens.ExpiresIn)
+
+	got, err := s.Authenticate(ctx, tokens.AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, user.ID, got.ID)
+
+	// a refresh token does not authenticate requests
+	_, err = s.Authenticate(ctx, tokens.RefreshToken)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+
+	_, err = s.Login(ctx, "anna@mai.ru", "wrong horse")
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+	_, err = s.Login(ctx, "boris@mai.ru", "correct horse")
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+}
+
+func TestExpiry(t *testing.T) {
+	ctx := context.Background()
+	s, _, _ := newTestService(t)
+
+	tokens, err := s.Login(ctx, "anna@mai.ru", "correct horse")
+	require.NoError(t, err)
+
+	s.now = func() time.Time { return time.Now().Add(DefaultAccessTTL) }
+	_, err = s.Authenticate(ctx, tokens.AccessToken)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+
+	// the refresh token outlives the access token
+	_, err = s.Refresh(ctx, tokens.RefreshToken)
+	assert.NoError(t, err)
+}
+
+func TestRefresh(t *testing.T) {
+	ctx := context.Background()
+	s, _, user := newTestService(t)
+
+	tokens, err := s.Login(ctx, "anna@mai.ru", "correct horse")
+	require.NoError(t, err)
+
+	refreshed, err := s.Refresh(ctx, tokens.RefreshToken)
+	require.NoError(t, err)
+	got, err := s.Authenticate(ctx, refreshed.AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, user.ID, got.ID)
+
+	// a refresh token is good for one refresh
+	_, err = s.Refresh(ctx, tokens.RefreshToken)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+	// and access tokens are not refresh tokens
+	_, err = s.Refresh(ctx, refreshed.AccessToken)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+}
+
+func TestRevoke(t *testing.T) {
+	ctx := context.Background()
+	s, store, user := newTestService(t)
+
+	tokens, err := s.Login(ctx, "anna@mai.ru", "correct horse")
+	require.NoError(t, err)
+
+	require.NoError(t, s.Revoke(ctx, tokens.AccessToken))
+	_, err = s.Authenticate(ctx, tokens.AccessToken)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+	// revoking twice or revoking garbage is not an error
+	assert.NoError(t, s.Revoke(ctx, tokens.AccessToken))
+	assert.NoError(t, s.Revoke(ctx, "garbage"))
+
+	require.NoError(t, s.Revoke(ctx, tokens.RefreshToken))
+	_, err = s.Refresh(ctx, tokens.RefreshToken)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+
+	// tokens of deleted users are rejected
+	tokens, err = s.Login(ctx, "anna@mai.ru", "correct horse")
+	require.NoError(t, err)
+	require.NoError(t, store.DeleteUser(ctx, user.ID))
+	_, err = s.Authenticate(ctx, tokens.AccessToken)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+}
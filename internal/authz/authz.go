@@ -0,0 +1,303 @@
+// Package authz decides what users may do. Each role is granted actions
+// with a reach: an author may change their own posts, an editor also the
+// posts of the sections they edit, an admin everything. A route is open to
+// the roles granted its action at all; the post or section it acts on is
+// then checked against the reach.
+package authz
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+
+	"github.com/RomanKovalev007/mai_news/internal/models"
+	"github.com/RomanKovalev007/mai_news/internal/storage"
+)
+
+// Action is something a user asks to do.
+type Action string
+
+const (
+	CreatePost           Action = "posts.create"
+	EditPost             Action = "posts.edit"
+	DeletePost           Action = "posts.delete"
+	RestorePost          Action = "posts.restore"
+	PurgePost            Action = "posts.purge"
+	ViewTrash            Action = "posts.trash"
+	ManageTags           Action = "tags.manage"
+	CreateSection        Action = "sections.create"
+	EditSection          Action = "sections.edit"
+	ManageSectionEditors Action = "sections.editors"
+	ManageUsers          Action = "users.manage"
+	// ModerateComments is checked against the post of the comment.
+	CreateComment    Action = "comments.create"
+	EditComment      Action = "comments.edit"
+	DeleteComment    Action = "comments.delete"
+	ModerateComments Action = "comments.moderate"
+)
+
+// Reasons of a Denied.
+const (
+	ReasonRole             = "role"
+	ReasonNotAuthor        = "not_author"
+	ReasonNotSectionEditor = "not_section_editor"
+)
+
+// reach is how far a granted action extends.
+type reach int
+
+const (
+	reachNone reach = iota
+	// own posts only
+	reachOwn
+	// own posts and everything in the sections the user edits
+	reachSection
+	// everything in the sections the user edits, own posts elsewhere aside
+	reachEdited
+	reachAll
+)
+
+// matrix holds the actions each role is granted. Readers may only comment.
+var matrix = map[models.Role]map[Action]reach{
+	models.RoleReader: {
+		CreateComment: reachAll,
+		EditComment:   reachOwn,
+		DeleteComment: reachOwn,
+	},
+	models.RoleAuthor: {
+		CreatePost:    reachSection,
+		EditPost:      reachOwn,
+		DeletePost:    reachOwn,
+		RestorePost:   reachOwn,
+		CreateComment: reachAll,
+		EditComment:   reachOwn,
+		DeleteComment: reachOwn,
+	},
+	models.RoleEditor: {
+		CreatePost:       reachSection,
+		EditPost:         reachSection,
+		DeletePost:       reachSection,
+		RestorePost:      reachSection,
+		PurgePost:        reachSection,
+		ViewTrash:        reachEdited,
+		ManageTags:       reachAll,
+		EditSection:      reachSection,
+		CreateComment:    reachAll,
+		EditComment:      reachOwn,
+		DeleteComment:    reachOwn,
+		ModerateComments: reachEdited,
+	},
+	models.RoleAdmin: {
+		CreatePost:           reachAll,
+		EditPost:             reachAll,
+		DeletePost:           reachAll,
+		RestorePost:          reachAll,
+		PurgePost:            reachAll,
+		ViewTrash:            reachAll,
+		ManageTags:           reachAll,
+		CreateSection:        reachAll,
+		EditSection:          reachAll,
+		ManageSectionEditors: reachAll,
+		ManageUsers:          reachAll,
+		CreateComment:        reachAll,
+		EditComment:          reachAll,
+		DeleteComment:        reachAll,
+		ModerateComments:     reachAll,
+	},
+}
+
+// Denied is the error of a refused action. It is sent to clients as is, as
+// the body of a 403.
+type Denied struct {
+	Action  Action `json:"action"`
+	Reason  string `json:"reason"`
+	Message string `json:"message"`
+}
+
+func (d *Denied) Error() string {
+	return fmt.Sprintf("%s denied: %s", d.Action, d.Message)
+}
+
+// Write answers 403 with denied as JSON.
+func Write(w http.ResponseWriter, denied *Denied) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusForbidden)
+	json.NewEncoder(w).Encode(denied)
+}
+
+// Posts looks up the posts actions are asked on.
+type Posts interface {
+	GetPost(ctx context.Context, id int) (models.OutputPost, error)
+	GetTrashedPost(ctx context.Context, id int) (models.OutputPost, error)
+}
+
+// Sections looks up the editors of sections.
+type Sections interface {
+	GetSection(ctx context.Context, slug string) (models.Section, error)
+	GetSections(ctx context.Context) ([]models.Section, error)
+}
+
+type Authorizer struct {
+	posts    Posts
+	sections Sections
+}
+
+func New(posts Posts, sections Sections) *Authorizer {
+	return &Authorizer{posts: posts, sections: sections}
+}
+
+// Authorize checks that the role of user is granted action at all. It
+// fails with a *Denied otherwise.
+func (a *Authorizer) Authorize(user models.User, action Action) error {
+	if matrix[user.Role][action] == reachNone {
+		return &Denied{Action: action, Reason: ReasonRole, Message: fmt.Sprintf("Role %q may not do this", user.Role)}
+	}
+	return nil
+}
+
+// AuthorizePost checks that user may do action to the post with the id,
+// which RestorePost and PurgePost look for in the trash. It fails with a
+// *Denied, or with storage.ErrPostNotFound if there is no such post.
+func (a *Authorizer) AuthorizePost(ctx context.Context, user models.User, action Action, id int) error {
+	if err := a.Authorize(user, action); err != nil {
+		return err
+	}
+	if matrix[user.Role][action] == reachAll {
+		return nil
+	}
+
+	var post models.OutputPost
+	var err error
+	if action == RestorePost || action == PurgePost {
+		post, err = a.posts.GetTrashedPost(ctx, id)
+	} else {
+		post, err = a.posts.GetPost(ctx, id)
+	}
+	if err != nil {
+		if errors.Is(err, storage.ErrPostNotFound) {
+			return err
+		}
+		return fmt.Errorf("get post: %w", err)
+	}
+
+	return a.authorizePost(ctx, user, action, post)
+}
+
+// authorizePost checks the reach of action, which the role of user is
+// granted, against post.
+func (a *Authorizer) authorizePost(ctx context.Context, user models.User, action Action, post models.OutputPost) error {
+	r := matrix[user.Role][action]
+	if r == reachAll || r != reachEdited && post.Author != nil && post.Author.ID == user.ID {
+		return nil
+	}
+	if r == reachOwn {
+		return &Denied{Action: action, Reason: ReasonNotAuthor, Message: "Only the author may do this to the post"}
+	}
+	return a.authorizeSection(ctx, user, action, post.Section)
+}
+
+// AuthorizePublish checks that user may put a new post in the section with
+// the slug, the default section if it is empty. Anyone granted CreatePost
+// publishes in the default section; beyond it, the reach of CreatePost is
+// the sections the user edits. It fails with a *Denied otherwise.
+func (a *Authorizer) AuthorizePublish(ctx context.Context, user models.User, slug string) error {
+	return a.authorizePublish(ctx, user, CreatePost, slug)
+}
+
+// AuthorizeMove checks that user may move the post with the id to the
+// section with the slug, which is publishing there unless the post is in it
+// already. It fails like AuthorizePost.
+func (a *Authorizer) AuthorizeMove(ctx context.Context, user models.User, id int, slug string) error {
+	post, err := a.posts.GetPost(ctx, id)
+	if err != nil {
+		if errors.Is(err, storage.ErrPostNotFound) {
+			return err
+		}
+		return fmt.Errorf("get post: %w", err)
+	}
+	if post.Section == slug {
+		return nil
+	}
+	return a.authorizePublish(ctx, user, EditPost, slug)
+}
+
+// authorizePublish checks CreatePost for the section, refusing as action.
+func (a *Authorizer) authorizePublish(ctx context.Context, user models.User, action Action, slug string) error {
+	r := matrix[user.Role][CreatePost]
+	if r == reachNone {
+		return &Denied{Action: action, Reason: ReasonRole, Message: fmt.Sprintf("Role %q may not do this", user.Role)}
+	}
+	if r == reachAll || slug == "" || slug == models.DefaultSection {
+		return nil
+	}
+	return a.authorizeSection(ctx, user, action, slug)
+}
+
+// AuthorizeComment checks that user may do action to comment. It fails with
+// a *Denied otherwise.
+func (a *Authorizer) AuthorizeComment(user models.User, action Action, comment models.Comment) error {
+	if err := a.Authorize(user, action); err != nil {
+		return err
+	}
+	if matrix[user.Role][action] == reachAll || comment.Author != nil && comment.Author.ID == user.ID {
+		return nil
+	}
+	return &Denied{Action: action, Reason: ReasonNotAuthor, Message: "Only the author may do this to the comment"}
+}
+
+// AuthorizeSection checks that user may do action to the section with the
+// given slug. It fails with a *Denied otherwise.
+func (a *Authorizer) AuthorizeSection(ctx context.Context, user models.User, action Action, slug string) error {
+	if err := a.Authorize(user, action); err != nil {
+		return err
+	}
+	if matrix[user.Role][action] == reachAll {
+		return nil
+	}
+	return a.authorizeSection(ctx, user, action, slug)
+}
+
+// ReachedSections tells in which sections user may do action, which is
+// meant for lists spanning sections: in all of them if all is true,
+// otherwise in those with the returned slugs, the ones the user edits. An
+// action reaching only the user's own posts reaches no section, and the own
+// posts of the user elsewhere are not counted in. It fails
+// with a *Denied if the role of user is not granted action.
+func (a *Authorizer) ReachedSections(ctx context.Context, user models.User, action Action) (slugs []string, all bool, err error) {
+	if err := a.Authorize(user, action); err != nil {
+		return nil, false, err
+	}
+	switch matrix[user.Role][action] {
+	case reachAll:
+		return nil, true, nil
+	case reachOwn:
+		return nil, false, nil
+	}
+
+	sections, err := a.sections.GetSections(ctx)
+	if err != nil {
+		return nil, false, fmt.Errorf("get sections: %w", err)
+	}
+	for _, section := range sections {
+		if slices.Contains(section.Editors, user.Email) {
+			slugs = append(slugs, section.Slug)
+		}
+	}
+	return slugs, false, nil
+}
+
+// authorizeSection checks that user edits the section. A section that is
+// gone has no editors.
+func (a *Authorizer) authorizeSection(ctx context.Context, user models.User, action Action, slug string) error {
+	section, err := a.sections.GetSection(ctx, slug)
+	if err != nil && !errors.Is(err, storage.ErrSectionNotFound) {
+		return fmt.Errorf("get section: %w", err)
+	}
+	if err == nil && slices.Contains(section.Editors, user.Email) {
+		return nil
+	}
+	return &Denied{Action: action, Reason: ReasonNotSectionEditor, Message: fmt.Sprintf("Only editors of section %q may do this", slug)}
+}
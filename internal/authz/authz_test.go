@@ -0,0 +1,292 @@
+package authz
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/RomanKovalev007/mai_news/internal/models"
+	"github.com/RomanKovalev007/mai_news/internal/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+// testStore has live posts 10 to 13, post 14 in the trash and the
+// sections "it", edited by editor@mai.ru, and "general", edited by nobody.
+type testStore struct{}
+
+var testPosts = map[int]models.OutputPost{
+	10: {ID: 10, Section: "general", Author: &models.Author{ID: 1}},
+	11: {ID: 11, Section: "it", Author: &models.Author{ID: 4}},
+	12: {ID: 12, Section: "general"},
+	13: {ID: 13, Section: "broken"},
+}
+
+func (testStore) GetPost(ctx context.Context, id int) (models.OutputPost, error) {
+	post, ok := testPosts[id]
+	if !ok {
+		return models.OutputPost{}, storage.ErrPostNotFound
+	}
+	return post, nil
+}
+
+func (testStore) GetTrashedPost(ctx context.Context, id int) (models.OutputPost, error) {
+	if id != 14 {
+		return models.OutputPost{}, storage.ErrPostNotFound
+	}
+	return models.OutputPost{ID: 14, Section: "general", Author: &models.Author{ID: 1}}, nil
+}
+
+func (testStore) GetSection(ctx context.Context, slug string) (models.Section, error) {
+	switch slug {
+	case "it":
+		return models.Section{Slug: "it", Editors: []string{"editor@mai.ru"}}, nil
+	case "general":
+		return models.Section{Slug: "general", Editors: []string{}}, nil
+	case "broken":
+		return models.Section{}, errors.New("db error")
+	}
+	return models.Section{}, storage.ErrSectionNotFound
+}
+
+func (testStore) GetSections(ctx context.Context) ([]models.Section, error) {
+	return []models.Section{
+		{Slug: "general", Editors: []string{}},
+		{Slug: "it", Editors: []string{"editor@mai.ru"}},
+	}, nil
+}
+
+func newTestAuthorizer() *Authorizer {
+	return New(testStore{}, testStore{})
+}
+
+func TestAuthorize(t *testing.T) {
+	a := newTestAuthorizer()
+
+	tests := []struct {
+		role    models.Role
+		action  Action
+		allowed bool
+	}{
+		{models.RoleReader, CreatePost, false},
+		{models.RoleReader, CreateComment, true},
+		{models.RoleAuthor, ModerateComments, false},
+		{models.RoleEditor, ModerateComments, true},
+		{models.RoleAuthor, CreatePost, true},
+		{models.RoleAuthor, PurgePost, false},
+		{models.RoleAuthor, ManageTags, false},
+		{models.RoleEditor, PurgePost, true},
+		{models.RoleAuthor, ViewTrash, false},
+		{models.RoleEditor, ViewTrash, true},
+		{models.RoleEditor, CreateSection, false},
+		{models.RoleEditor, ManageSectionEditors, false},
+		{models.RoleAdmin, ManageSectionEditors, true},
+		{models.RoleEditor, ManageUsers, false},
+		{models.RoleAdmin, ManageUsers, true},
+		{"", EditPost, false},
+	}
+
+	for _, tt := range tests {
+		err := a.Authorize(models.User{ID: 1, Role: tt.role}, tt.action)
+		if tt.allowed {
+			assert.NoError(t, err, "%s %s", tt.role, tt.action)
+			continue
+		}
+		var denied *Denied
+		if assert.ErrorAs(t, err, &denied, "%s %s", tt.role, tt.action) {
+			assert.Equal(t, ReasonRole, denied.Reason)
+			assert.Equal(t, tt.action, denied.Action)
+		}
+	}
+}
+
+func TestAuthorizePost(t *testing.T) {
+	ctx := context.Background()
+	a := newTestAuthorizer()
+
+	author := models.User{ID: 1, Email: "author@mai.ru", Role: models.RoleAuthor}
+	editor := models.User{ID: 2, Email: "editor@mai.ru", Role: models.RoleEditor}
+	admin := models.User{ID: 3, Email: "admin@mai.ru", Role: models.RoleAdmin}
+
+	tests := []struct {
+		name   string
+		user   models.User
+		action Action
+		postID int
+		reason string
+	}{
+		{"author edits own post", author, EditPost, 10, ""},
+		{"author edits post of another", author, EditPost, 11, ReasonNotAuthor},
+		{"author edits post without author", author, EditPost, 12, ReasonNotAuthor},
+		{"author purges own post", author, PurgePost, 14, ReasonRole},
+		{"author restores own post", author, RestorePost, 14, ""},
+		{"editor edits post of their section", editor, EditPost, 11, ""},
+		{"editor deletes post of their section", editor, DeletePost, 11, ""},
+		{"editor edits post of another section", editor, EditPost, 12, ReasonNotSectionEditor},
+		{"editor purges post of another section", editor, PurgePost, 14, ReasonNotSectionEditor},
+		{"admin edits any post", admin, EditPost, 12, ""},
+		{"admin purges any post", admin, PurgePost, 14, ""},
+		{"editor moderates comments of their section", editor, ModerateComments, 11, ""},
+		{"editor moderates comments of another section", editor, ModerateComments, 12, ReasonNotSectionEditor},
+		{"editor moderates comments of own post in another section", models.User{ID: 1, Email: "editor@mai.ru", Role: models.RoleEditor}, ModerateComments, 10, ReasonNotSectionEditor},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := a.AuthorizePost(ctx, tt.user, tt.action, tt.postID)
+			if tt.reason == "" {
+				assert.NoError(t, err)
+				return
+			}
+			var denied *Denied
+			if assert.ErrorAs(t, err, &denied) {
+				assert.Equal(t, tt.reason, denied.Reason)
+			}
+		})
+	}
+
+	// a post in the trash is not there for editing, and the other way round
+	assert.ErrorIs(t, a.AuthorizePost(ctx, author, EditPost, 14), storage.ErrPostNotFound)
+	assert.ErrorIs(t, a.AuthorizePost(ctx, author, RestorePost, 10), storage.ErrPostNotFound)
+
+	// failing to look up editors is not a refusal
+	err := a.AuthorizePost(ctx, editor, EditPost, 13)
+	var denied *Denied
+	assert.Error(t, err)
+	assert.False(t, errors.As(err, &denied))
+}
+
+func TestAuthorizeComment(t *testing.T) {
+	a := newTestAuthorizer()
+
+	reader := models.User{ID: 1, Role: models.RoleReader}
+	comment := models.Comment{ID: 5, Author: &models.Author{ID: 1}}
+	orphan := models.Comment{ID: 6}
+	assert.NoError(t, a.AuthorizeComment(reader, EditComment, comment))
+	assert.NoError(t, a.AuthorizeComment(reader, DeleteComment, comment))
+	assert.NoError(t, a.AuthorizeComment(models.User{ID: 3, Role: models.RoleAdmin}, DeleteComment, orphan))
+
+	var denied *Denied
+	assert.ErrorAs(t, a.AuthorizeComment(models.User{ID: 2, Role: models.RoleEditor}, EditComment, comment), &denied)
+	assert.Equal(t, ReasonNotAuthor, denied.Reason)
+	assert.ErrorAs(t, a.AuthorizeComment(reader, DeleteComment, orphan), &denied)
+	assert.Equal(t, ReasonNotAuthor, denied.Reason)
+	assert.ErrorAs(t, a.AuthorizeComment(models.User{ID: 1}, EditComment, comment), &denied)
+	assert.Equal(t, ReasonRole, denied.Reason)
+}
+
+func TestAuthorizeSection(t *testing.T) {
+	ctx := context.Background()
+	a := newTestAuthorizer()
+
+	editor := models.User{ID: 2, Email: "editor@mai.ru", Role: models.RoleEditor}
+	assert.NoError(t, a.AuthorizeSection(ctx, editor, EditSection, "it"))
+	assert.NoError(t, a.AuthorizeSection(ctx, models.User{Role: models.RoleAdmin}, EditSection, "general"))
+
+	var denied *Denied
+	assert.ErrorAs(t, a.AuthorizeSection(ctx, editor, EditSection, "general"), &denied)
+	assert.Equal(t, ReasonNotSectionEditor, denied.Reason)
+	assert.ErrorAs(t, a.AuthorizeSection(ctx, models.User{Role: models.RoleAuthor}, EditSection, "it"), &denied)
+	assert.Equal(t, ReasonRole, denied.Reason)
+}
+
+func TestAuthorizePublish(t *testing.T) {
+	ctx := context.Background()
+	a := newTestAuthorizer()
+
+	author := models.User{ID: 1, Email: "author@mai.ru", Role: models.RoleAuthor}
+	editor := models.User{ID: 2, Email: "editor@mai.ru", Role: models.RoleEditor}
+	admin := models.User{ID: 3, Email: "admin@mai.ru", Role: models.RoleAdmin}
+
+	assert.NoError(t, a.AuthorizePublish(ctx, author, ""))
+	assert.NoError(t, a.AuthorizePublish(ctx, author, "general"))
+	assert.NoError(t, a.AuthorizePublish(ctx, editor, "it"))
+	assert.NoError(t, a.AuthorizePublish(ctx, admin, "it"))
+	assert.NoError(t, a.AuthorizePublish(ctx, admin, "missing"))
+
+	var denied *Denied
+	assert.ErrorAs(t, a.AuthorizePublish(ctx, author, "it"), &denied)
+	assert.Equal(t, CreatePost, denied.Action)
+	assert.Equal(t, ReasonNotSectionEditor, denied.Reason)
+	assert.ErrorAs(t, a.AuthorizePublish(ctx, editor, "missing"), &denied)
+	assert.Equal(t, ReasonNotSectionEditor, denied.Reason)
+	assert.ErrorAs(t, a.AuthorizePublish(ctx, models.User{ID: 5, Role: models.RoleReader}, ""), &denied)
+	assert.Equal(t, ReasonRole, denied.Reason)
+}
+
+func TestAuthorizeMove(t *testing.T) {
+	ctx := context.Background()
+	a := newTestAuthorizer()
+
+	author := models.User{ID: 1, Email: "author@mai.ru", Role: models.RoleAuthor}
+	editor := models.User{ID: 2, Email: "editor@mai.ru", Role: models.RoleEditor}
+	admin := models.User{ID: 3, Email: "admin@mai.ru", Role: models.RoleAdmin}
+
+	tests := []struct {
+		name   string
+		user   models.User
+		postID int
+		slug   string
+		reason string
+	}{
+		{"author keeps the section", author, 10, "general", ""},
+		{"author moves into a section of editors", author, 10, "it", ReasonNotSectionEditor},
+		{"editor keeps the section", editor, 11, "it", ""},
+		{"editor moves into their section", editor, 12, "it", ""},
+		{"editor moves out of their section", editor, 11, "sport", ReasonNotSectionEditor},
+		{"editor moves into the default section", editor, 11, "general", ""},
+		{"admin moves anywhere", admin, 11, "sport", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := a.AuthorizeMove(ctx, tt.user, tt.postID, tt.slug)
+			if tt.reason == "" {
+				assert.NoError(t, err)
+				return
+			}
+			var denied *Denied
+			if assert.ErrorAs(t, err, &denied) {
+				assert.Equal(t, EditPost, denied.Action)
+				assert.Equal(t, tt.reason, denied.Reason)
+			}
+		})
+	}
+
+	assert.ErrorIs(t, a.AuthorizeMove(ctx, admin, 14, "it"), storage.ErrPostNotFound)
+}
+
+func TestReachedSections(t *testing.T) {
+	ctx := context.Background()
+	a := newTestAuthorizer()
+
+	editor := models.User{ID: 2, Email: "editor@mai.ru", Role: models.RoleEditor}
+	slugs, all, err := a.ReachedSections(ctx, editor, ViewTrash)
+	assert.NoError(t, err)
+	assert.False(t, all)
+	assert.Equal(t, []string{"it"}, slugs)
+
+	slugs, all, err = a.ReachedSections(ctx, models.User{ID: 5, Email: "other@mai.ru", Role: models.RoleEditor}, ModerateComments)
+	assert.NoError(t, err)
+	assert.False(t, all)
+	assert.Empty(t, slugs)
+
+	_, all, err = a.ReachedSections(ctx, models.User{ID: 3, Role: models.RoleAdmin}, ViewTrash)
+	assert.NoError(t, err)
+	assert.True(t, all)
+
+	var denied *Denied
+	_, _, err = a.ReachedSections(ctx, models.User{ID: 1, Role: models.RoleAuthor}, ViewTrash)
+	assert.ErrorAs(t, err, &denied)
+	assert.Equal(t, ReasonRole, denied.Reason)
+}
+
+func TestWrite(t *testing.T) {
+	w := httptest.NewRecorder()
+	Write(w, &Denied{Action: EditPost, Reason: ReasonNotAuthor, Message: "Only the author may do this to the post"})
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	assert.Equal(t, `{"action":"posts.edit","reason":"not_author","message":"Only the author may do this to the post"}`+"\n", w.Body.String())
+}
@@ -10,8 +10,15 @@ import (
 
 type Config struct{
 	Env string `yaml:"env" env-required:"true"`
+	StorageDriver string `yaml:"storage_driver" env-default:"sqlite"` // sqlite, postgres, memory
 	StoragePath string `yaml:"storage_path" env-required:"true"`
+	TrashRetentionDays int `yaml:"trash_retention_days" env-default:"0"` // 0 keeps trashed posts forever
+	MaxPageSize int `yaml:"max_page_size" env-default:"100"` // upper bound of ?limit= on lists
+	IfMatchOptional bool `yaml:"if_match_optional" env-default:"false"` // allow PATCH and DELETE without If-Match
+	TimeZone string `yaml:"time_zone" env-default:"UTC"` // IANA zone times are shown in unless ?tz= asks otherwise
+	ViewFlushInterval time.Duration `yaml:"view_flush_interval" env-default:"10s"` // how often counted post views are written to the storage
 	HTTPServer `yaml:"http_server"`
+	Auth `yaml:"auth"`
 }
 
 type HTTPServer struct{
@@ -21,6 +28,14 @@ type HTTPServer struct{
 
 }
 
+type Auth struct{
+	Algorithm string `yaml:"algorithm" env-default:"HS256"` // HS256 or EdDSA
+	Secret string `yaml:"secret"` // HS256 key, at least 32 bytes
+	PrivateKeyFile string `yaml:"private_key_file"` // PEM PKCS #8 Ed25519 key for EdDSA
+	AccessTokenTTL time.Duration `yaml:"access_token_ttl" env-default:"15m"`
+	RefreshTokenTTL time.Duration `yaml:"refresh_token_ttl" env-default:"720h"`
+}
+
 func MustLoad()*Config{
 	configPath := os.Getenv("CONFIG_PATH")
 
@@ -0,0 +1,225 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/RomanKovalev007/mai_news/internal/lib/apikey"
+	"github.com/RomanKovalev007/mai_news/internal/middleware"
+	"github.com/RomanKovalev007/mai_news/internal/models"
+	"github.com/RomanKovalev007/mai_news/internal/storage"
+)
+
+var (
+	errMissingScopes = errors.New("At least one scope is required")
+	errInvalidScope = errors.New("Invalid scope")
+)
+
+// knownScopes are the scopes an API key can be granted.
+var knownScopes = []string{models.ScopePostsRead, models.ScopePostsWrite, models.ScopePostsDelete, models.ScopeAdmin}
+
+// KeyManager manages API keys. GetAPIKey, RotateAPIKey and DeleteAPIKey
+// fail with storage.ErrAPIKeyNotFound for unknown ids.
+type KeyManager interface{
+	GetAPIKeys(ctx context.Context) ([]models.APIKey, error)
+	GetAPIKey(ctx context.Context, id int) (models.APIKey, error)
+	// SaveAPIKey fails with storage.ErrUserNotFound if the owner is gone.
+	SaveAPIKey(ctx context.Context, key models.InputAPIKey) (models.APIKey, error)
+	RotateAPIKey(ctx context.Context, id int, prefix, hash string) (models.APIKey, error)
+	DeleteAPIKey(ctx context.Context, id int) error
+}
+
+// apiKeyRequest is the body of POST /admin/api-keys/.
+type apiKeyRequest struct{
+	Name string `json:"name"`
+	Scopes []string `json:"scopes"`
+}
+
+// GetAPIKeysHandler serves GET /admin/api-keys/ with every API key, without
+// the keys themselves.
+func GetAPIKeysHandler(keys KeyManager, log *slog.Logger) http.HandlerFunc {
+	return func (w http.ResponseWriter, r *http.Request){
+		result, err := keys.GetAPIKeys(r.Context())
+		if err != nil {
+			if writeContextError(w, r, log, err){
+				return
+			}
+			http.Error(w, "failed to get api keys", http.StatusInternalServerError)
+			log.Error("failed to get api keys", slog.String("error", err.Error()))
+			return
+		}
+		if result == nil {
+			result = []models.APIKey{}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(result)
+	}
+}
+
+func GetAPIKeyHandler(keys KeyManager, log *slog.Logger) http.HandlerFunc {
+	return func (w http.ResponseWriter, r *http.Request){
+		id, err := strconv.Atoi(r.PathValue("id"))
+		if err != nil {
+			http.Error(w, "Invalid API key ID", http.StatusBadRequest)
+			return
+		}
+
+		key, err := keys.GetAPIKey(r.Context(), id)
+		if err != nil {
+			if writeContextError(w, r, log, err){
+				return
+			}
+			writeAPIKeyError(w, err, log)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(key)
+	}
+}
+
+// CreateAPIKeyHandler serves POST /admin/api-keys/ with a name and scopes.
+// The key acts for the user creating it and is in the response only; a
+// request made with an API key cannot grant scopes that key lacks.
+func CreateAPIKeyHandler(keys KeyManager, log *slog.Logger) http.HandlerFunc {
+	return func (w http.ResponseWriter, r *http.Request){
+		user, ok := middleware.User(r.Context())
+		if !ok {
+			http.Error(w, "Authentication required", http.StatusUnauthorized)
+			return
+		}
+
+		var req apiKeyRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request payload", http.StatusBadRequest)
+			return
+		}
+		name := strings.TrimSpace(req.Name)
+		if name == "" {
+			http.Error(w, errMissingName.Error(), http.StatusBadRequest)
+			return
+		}
+		scopes, err := normalizeScopes(req.Scopes)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if granted, ok := middleware.Scopes(r.Context()); ok {
+			for _, scope := range scopes {
+				if !slices.Contains(granted, scope) {
+					http.Error(w, "API key lacks scope "+scope, http.StatusForbidden)
+					return
+				}
+			}
+		}
+
+		secret, prefix, hash := apikey.Generate()
+		key, err := keys.SaveAPIKey(r.Context(), models.InputAPIKey{
+			Name: name,
+			Prefix: prefix,
+			Hash: hash,
+			Scopes: scopes,
+			UserID: user.ID,
+		})
+		if err != nil {
+			if writeContextError(w, r, log, err){
+				return
+			}
+			writeAPIKeyError(w, err, log)
+			return
+		}
+
+		writeIssuedAPIKey(w, http.StatusCreated, models.IssuedAPIKey{APIKey: key, Key: secret})
+	}
+}
+
+// RotateAPIKeyHandler serves POST /admin/api-keys/{id}/rotate/: the API key
+// gets a new key, in the response only, and the old one stops working.
+func RotateAPIKeyHandler(keys KeyManager, log *slog.Logger) http.HandlerFunc {
+	return func (w http.ResponseWriter, r *http.Request){
+		id, err := strconv.Atoi(r.PathValue("id"))
+		if err != nil {
+			http.Error(w, "Invalid API key ID", http.StatusBadRequest)
+			return
+		}
+
+		secret, prefix, hash := apikey.Generate()
+		key, err := keys.RotateAPIKey(r.Context(), id, prefix, hash)
+		if err != nil {
+			if writeContextError(w, r, log, err){
+				return
+			}
+			writeAPIKeyError(w, err, log)
+			return
+		}
+
+		writeIssuedAPIKey(w, http.StatusOK, models.IssuedAPIKey{APIKey: key, Key: secret})
+	}
+}
+
+// DeleteAPIKeyHandler serves DELETE /admin/api-keys/{id}/, revoking the key.
+func DeleteAPIKeyHandler(keys KeyManager, log *slog.Logger) http.HandlerFunc {
+	return func (w http.ResponseWriter, r *http.Request){
+		id, err := strconv.Atoi(r.PathValue("id"))
+		if err != nil {
+			http.Error(w, "Invalid API key ID", http.StatusBadRequest)
+			return
+		}
+
+		if err = keys.DeleteAPIKey(r.Context(), id); err != nil {
+			if writeContextError(w, r, log, err){
+				return
+			}
+			writeAPIKeyError(w, err, log)
+			return
+		}
+	}
+}
+
+// normalizeScopes checks that scopes is a non-empty list of known scopes and
+// drops duplicates, keeping the order of knownScopes.
+func normalizeScopes(scopes []string) ([]string, error){
+	if len(scopes) == 0{
+		return nil, errMissingScopes
+	}
+	for _, scope := range scopes{
+		if !slices.Contains(knownScopes, scope){
+			return nil, errInvalidScope
+		}
+	}
+	var result []string
+	for _, scope := range knownScopes{
+		if slices.Contains(scopes, scope){
+			result = append(result, scope)
+		}
+	}
+	return result, nil
+}
+
+// writeIssuedAPIKey answers with a key that is never shown again, so it
+// must not be cached.
+func writeIssuedAPIKey(w http.ResponseWriter, status int, key models.IssuedAPIKey){
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(key)
+}
+
+func writeAPIKeyError(w http.ResponseWriter, err error, log *slog.Logger){
+	switch {
+	case errors.Is(err, storage.ErrAPIKeyNotFound):
+		http.Error(w, "API key not found", http.StatusNotFound)
+	case errors.Is(err, storage.ErrUserNotFound):
+		http.Error(w, "Unknown user", http.StatusUnauthorized)
+	default:
+		http.Error(w, "failed to access api key", http.StatusInternalServerError)
+		log.Error("failed to access api key", slog.String("error", err.Error()))
+	}
+}
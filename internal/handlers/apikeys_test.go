@@ -0,0 +1,271 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/RomanKovalev007/mai_news/internal/lib/apikey"
+	"github.com/RomanKovalev007/mai_news/internal/middleware"
+	"github.com/RomanKovalev007/mai_news/internal/models"
+	"github.com/RomanKovalev007/mai_news/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+var testAPIKey = models.APIKey{
+	ID:        4,
+	Name:      "telegram",
+	Prefix:    "mai_abcdefgh",
+	Hash:      "hash",
+	Scopes:    []string{models.ScopePostsRead, models.ScopePostsWrite},
+	UserID:    3,
+	CreatedAt: time.Date(2025, 9, 1, 10, 0, 0, 0, time.UTC),
+}
+
+const testAPIKeyJSON = `{"id":4,"name":"telegram","prefix":"mai_abcdefgh","scopes":["posts:read","posts:write"],"user_id":3,"created_at":"2025-09-01T10:00:00Z"}`
+
+func TestGetAPIKeysHandler(t *testing.T) {
+	tests := []struct {
+		name           string
+		mockSetup      func(*MockKeyManager)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name: "success",
+			mockSetup: func(mk *MockKeyManager) {
+				mk.On("GetAPIKeys", mock.Anything).Return([]models.APIKey{testAPIKey}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   "[" + testAPIKeyJSON + "]\n",
+		},
+		{
+			name: "no keys",
+			mockSetup: func(mk *MockKeyManager) {
+				mk.On("GetAPIKeys", mock.Anything).Return(nil, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   "[]\n",
+		},
+		{
+			name: "error",
+			mockSetup: func(mk *MockKeyManager) {
+				mk.On("GetAPIKeys", mock.Anything).Return(nil, errors.New("db error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   "failed to get api keys\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockKeys := NewMockKeyManager(t)
+			tt.mockSetup(mockKeys)
+
+			handler := GetAPIKeysHandler(mockKeys, slog.Default())
+			req := httptest.NewRequest("GET", "/admin/api-keys/", nil)
+			w := httptest.NewRecorder()
+
+			handler(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Equal(t, tt.expectedBody, w.Body.String())
+			mockKeys.AssertExpectations(t)
+		})
+	}
+}
+
+func TestCreateAPIKeyHandler(t *testing.T) {
+	owner := models.User{ID: 3, Name: "Анна"}
+	asOwner := func(ctx context.Context) context.Context { return middleware.WithUser(ctx, owner) }
+
+	tests := []struct {
+		name           string
+		requestBody    string
+		ctx            func(context.Context) context.Context
+		mockSetup      func(*MockKeyManager)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:        "success",
+			requestBody: `{"name":" telegram ","scopes":["posts:write","posts:read","posts:write"]}`,
+			ctx:         asOwner,
+			mockSetup: func(mk *MockKeyManager) {
+				mk.On("SaveAPIKey", mock.Anything, mock.MatchedBy(func(input models.InputAPIKey) bool {
+					return input.Name == "telegram" && input.UserID == 3 &&
+						assert.ObjectsAreEqual([]string{models.ScopePostsRead, models.ScopePostsWrite}, input.Scopes) &&
+						strings.HasPrefix(input.Prefix, "mai_") && len(input.Hash) == 64
+				})).Return(testAPIKey, nil)
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "anonymous",
+			requestBody:    `{"name":"telegram","scopes":["posts:read"]}`,
+			ctx:            func(ctx context.Context) context.Context { return ctx },
+			mockSetup:      func(mk *MockKeyManager) {},
+			expectedStatus: http.StatusUnauthorized,
+			expectedBody:   "Authentication required\n",
+		},
+		{
+			name:           "missing name",
+			requestBody:    `{"name":" ","scopes":["posts:read"]}`,
+			ctx:            asOwner,
+			mockSetup:      func(mk *MockKeyManager) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Name is required\n",
+		},
+		{
+			name:           "no scopes",
+			requestBody:    `{"name":"telegram","scopes":[]}`,
+			ctx:            asOwner,
+			mockSetup:      func(mk *MockKeyManager) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "At least one scope is required\n",
+		},
+		{
+			name:           "unknown scope",
+			requestBody:    `{"name":"telegram","scopes":["posts:read","everything"]}`,
+			ctx:            asOwner,
+			mockSetup:      func(mk *MockKeyManager) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Invalid scope\n",
+		},
+		{
+			name:           "invalid json",
+			requestBody:    `invalid json`,
+			ctx:            asOwner,
+			mockSetup:      func(mk *MockKeyManager) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Invalid request payload\n",
+		},
+		{
+			name:        "wider than the key of the request",
+			requestBody: `{"name":"import","scopes":["admin","posts:delete"]}`,
+			ctx: func(ctx context.Context) context.Context {
+				return middleware.WithScopes(asOwner(ctx), []string{models.ScopeAdmin})
+			},
+			mockSetup:      func(mk *MockKeyManager) {},
+			expectedStatus: http.StatusForbidden,
+			expectedBody:   "API key lacks scope posts:delete\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockKeys := NewMockKeyManager(t)
+			tt.mockSetup(mockKeys)
+
+			handler := CreateAPIKeyHandler(mockKeys, slog.Default())
+			req := httptest.NewRequest("POST", "/admin/api-keys/", strings.NewReader(tt.requestBody))
+			req = req.WithContext(tt.ctx(req.Context()))
+			w := httptest.NewRecorder()
+
+			handler(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusCreated {
+				var issued models.IssuedAPIKey
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&issued))
+				assert.Equal(t, testAPIKey.ID, issued.ID)
+				assert.True(t, strings.HasPrefix(issued.Key, "mai_"), issued.Key)
+				assert.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+				saved := mockKeys.Calls[0].Arguments.Get(1).(models.InputAPIKey)
+				assert.Equal(t, apikey.Hash(issued.Key), saved.Hash)
+			} else {
+				assert.Equal(t, tt.expectedBody, w.Body.String())
+			}
+			mockKeys.AssertExpectations(t)
+		})
+	}
+}
+
+func TestRotateAPIKeyHandler(t *testing.T) {
+	tests := []struct {
+		name           string
+		id             string
+		mockSetup      func(*MockKeyManager)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name: "success",
+			id:   "4",
+			mockSetup: func(mk *MockKeyManager) {
+				mk.On("RotateAPIKey", mock.Anything, 4, mock.AnythingOfType("string"), mock.AnythingOfType("string")).Return(testAPIKey, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "not found",
+			id:   "5",
+			mockSetup: func(mk *MockKeyManager) {
+				mk.On("RotateAPIKey", mock.Anything, 5, mock.AnythingOfType("string"), mock.AnythingOfType("string")).
+					Return(models.APIKey{}, storage.ErrAPIKeyNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   "API key not found\n",
+		},
+		{
+			name:           "invalid id",
+			id:             "abc",
+			mockSetup:      func(mk *MockKeyManager) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Invalid API key ID\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockKeys := NewMockKeyManager(t)
+			tt.mockSetup(mockKeys)
+
+			handler := RotateAPIKeyHandler(mockKeys, slog.Default())
+			req := httptest.NewRequest("POST", "/admin/api-keys/"+tt.id+"/rotate/", nil)
+			req.SetPathValue("id", tt.id)
+			w := httptest.NewRecorder()
+
+			handler(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusOK {
+				var issued models.IssuedAPIKey
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&issued))
+				assert.True(t, strings.HasPrefix(issued.Key, "mai_"), issued.Key)
+			} else {
+				assert.Equal(t, tt.expectedBody, w.Body.String())
+			}
+			mockKeys.AssertExpectations(t)
+		})
+	}
+}
+
+func TestDeleteAPIKeyHandler(t *testing.T) {
+	mockKeys := NewMockKeyManager(t)
+	mockKeys.On("DeleteAPIKey", mock.Anything, 4).Return(nil)
+	mockKeys.On("DeleteAPIKey", mock.Anything, 5).Return(storage.ErrAPIKeyNotFound)
+	handler := DeleteAPIKeyHandler(mockKeys, slog.Default())
+
+	req := httptest.NewRequest("DELETE", "/admin/api-keys/4/", nil)
+	req.SetPathValue("id", "4")
+	w := httptest.NewRecorder()
+	handler(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	req = httptest.NewRequest("DELETE", "/admin/api-keys/5/", nil)
+	req.SetPathValue("id", "5")
+	w = httptest.NewRecorder()
+	handler(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, "API key not found\n", w.Body.String())
+	mockKeys.AssertExpectations(t)
+}
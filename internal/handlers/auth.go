@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/RomanKovalev007/mai_news/internal/auth"
+	"github.com/RomanKovalev007/mai_news/internal/models"
+)
+
+// TokenIssuer issues the tokens users authenticate with; auth.Service
+// implements it.
+type TokenIssuer interface{
+	// Login fails with auth.ErrInvalidCredentials for an unknown email or a
+	// wrong password.
+	Login(ctx context.Context, email, password string) (models.Tokens, error)
+	// Refresh fails with auth.ErrInvalidToken unless refreshToken is a
+	// valid refresh token that was not used before.
+	Refresh(ctx context.Context, refreshToken string) (models.Tokens, error)
+	Revoke(ctx context.Context, token string) error
+}
+
+// LoginHandler serves POST /auth/login with {"email", "password"} and
+// answers with a pair of tokens.
+func LoginHandler(issuer TokenIssuer, log *slog.Logger) http.HandlerFunc {
+	return func (w http.ResponseWriter, r *http.Request){
+		var req struct{
+			Email string `json:"email"`
+			Password string `json:"password"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request payload", http.StatusBadRequest)
+			return
+		}
+		// a malformed email cannot belong to anyone
+		email, _ := normalizeEmail(req.Email)
+
+		tokens, err := issuer.Login(r.Context(), email, req.Password)
+		if err != nil {
+			if writeContextError(w, r, log, err){
+				return
+			}
+			writeAuthError(w, err, log)
+			return
+		}
+
+		writeTokens(w, tokens)
+	}
+}
+
+// RefreshHandler serves POST /auth/refresh with {"refresh_token"}. The
+// refresh token is used up and a new pair is returned.
+func RefreshHandler(issuer TokenIssuer, log *slog.Logger) http.HandlerFunc {
+	return func (w http.ResponseWriter, r *http.Request){
+		var req struct{
+			RefreshToken string `json:"refresh_token"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request payload", http.StatusBadRequest)
+			return
+		}
+
+		tokens, err := issuer.Refresh(r.Context(), req.RefreshToken)
+		if err != nil {
+			if writeContextError(w, r, log, err){
+				return
+			}
+			writeAuthError(w, err, log)
+			return
+		}
+
+		writeTokens(w, tokens)
+	}
+}
+
+// RevokeHandler serves POST /auth/revoke with {"token"}, an access or a
+// refresh token. Like RFC 7009 it answers 200 for tokens that are invalid
+// already, so a client can always log out.
+func RevokeHandler(issuer TokenIssuer, log *slog.Logger) http.HandlerFunc {
+	return func (w http.ResponseWriter, r *http.Request){
+		var req struct{
+			Token string `json:"token"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request payload", http.StatusBadRequest)
+			return
+		}
+
+		if err := issuer.Revoke(r.Context(), req.Token); err != nil {
+			if writeContextError(w, r, log, err){
+				return
+			}
+			writeAuthError(w, err, log)
+			return
+		}
+	}
+}
+
+func writeTokens(w http.ResponseWriter, tokens models.Tokens){
+	// tokens must not end up in shared caches
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(tokens)
+}
+
+func writeAuthError(w http.ResponseWriter, err error, log *slog.Logger){
+	switch {
+	case errors.Is(err, auth.ErrInvalidCredentials):
+		http.Error(w, "Invalid email or password", http.StatusUnauthorized)
+	case errors.Is(err, auth.ErrInvalidToken):
+		http.Error(w, "Invalid token", http.StatusUnauthorized)
+	default:
+		http.Error(w, "failed to authenticate", http.StatusInternalServerError)
+		log.Error("failed to authenticate", slog.String("error", err.Error()))
+	}
+}
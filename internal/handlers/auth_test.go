@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/RomanKovalev007/mai_news/internal/auth"
+	"github.com/RomanKovalev007/mai_news/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var testTokens = models.Tokens{AccessToken: "access", RefreshToken: "refresh", TokenType: "Bearer", ExpiresIn: 900}
+
+const testTokensJSON = `{"access_token":"access","refresh_token":"refresh","token_type":"Bearer","expires_in":900}`
+
+func TestLoginHandler(t *testing.T) {
+	tests := []struct {
+		name           string
+		requestBody    string
+		mockSetup      func(*MockTokenIssuer)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:        "success",
+			requestBody: `{"email":"Anna@MAI.ru","password":"correct horse"}`,
+			mockSetup: func(mi *MockTokenIssuer) {
+				mi.On("Login", mock.Anything, "anna@mai.ru", "correct horse").Return(testTokens, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   testTokensJSON + "\n",
+		},
+		{
+			name:        "wrong password",
+			requestBody: `{"email":"anna@mai.ru","password":"wrong horse"}`,
+			mockSetup: func(mi *MockTokenIssuer) {
+				mi.On("Login", mock.Anything, "anna@mai.ru", "wrong horse").Return(models.Tokens{}, auth.ErrInvalidCredentials)
+			},
+			expectedStatus: http.StatusUnauthorized,
+			expectedBody:   "Invalid email or password\n",
+		},
+		{
+			name:        "malformed email",
+			requestBody: `{"email":"anna","password":"correct horse"}`,
+			mockSetup: func(mi *MockTokenIssuer) {
+				mi.On("Login", mock.Anything, "", "correct horse").Return(models.Tokens{}, auth.ErrInvalidCredentials)
+			},
+			expectedStatus: http.StatusUnauthorized,
+			expectedBody:   "Invalid email or password\n",
+		},
+		{
+			name:           "invalid json",
+			requestBody:    `invalid json`,
+			mockSetup:      func(mi *MockTokenIssuer) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Invalid request payload\n",
+		},
+		{
+			name:        "error",
+			requestBody: `{"email":"anna@mai.ru","password":"correct horse"}`,
+			mockSetup: func(mi *MockTokenIssuer) {
+				mi.On("Login", mock.Anything, "anna@mai.ru", "correct horse").Return(models.Tokens{}, errors.New("db error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   "failed to authenticate\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockIssuer := NewMockTokenIssuer(t)
+			tt.mockSetup(mockIssuer)
+
+			handler := LoginHandler(mockIssuer, slog.Default())
+			req := httptest.NewRequest("POST", "/auth/login", strings.NewReader(tt.requestBody))
+			w := httptest.NewRecorder()
+
+			handler(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Equal(t, tt.expectedBody, w.Body.String())
+			if tt.expectedStatus == http.StatusOK {
+				assert.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+			}
+			mockIssuer.AssertExpectations(t)
+		})
+	}
+}
+
+func TestRefreshHandler(t *testing.T) {
+	tests := []struct {
+		name           string
+		requestBody    string
+		mockSetup      func(*MockTokenIssuer)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:        "success",
+			requestBody: `{"refresh_token":"refresh"}`,
+			mockSetup: func(mi *MockTokenIssuer) {
+				mi.On("Refresh", mock.Anything, "refresh").Return(testTokens, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   testTokensJSON + "\n",
+		},
+		{
+			name:        "used token",
+			requestBody: `{"refresh_token":"used"}`,
+			mockSetup: func(mi *MockTokenIssuer) {
+				mi.On("Refresh", mock.Anything, "used").Return(models.Tokens{}, auth.ErrInvalidToken)
+			},
+			expectedStatus: http.StatusUnauthorized,
+			expectedBody:   "Invalid token\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockIssuer := NewMockTokenIssuer(t)
+			tt.mockSetup(mockIssuer)
+
+			handler := RefreshHandler(mockIssuer, slog.Default())
+			req := httptest.NewRequest("POST", "/auth/refresh", strings.NewReader(tt.requestBody))
+			w := httptest.NewRecorder()
+
+			handler(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Equal(t, tt.expectedBody, w.Body.String())
+			mockIssuer.AssertExpectations(t)
+		})
+	}
+}
+
+func TestRevokeHandler(t *testing.T) {
+	mockIssuer := NewMockTokenIssuer(t)
+	mockIssuer.On("Revoke", mock.Anything, "refresh").Return(nil)
+
+	handler := RevokeHandler(mockIssuer, slog.Default())
+	w := httptest.NewRecorder()
+	handler(w, httptest.NewRequest("POST", "/auth/revoke", strings.NewReader(`{"token":"refresh"}`)))
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	handler(w, httptest.NewRequest("POST", "/auth/revoke", strings.NewReader(`invalid json`)))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockIssuer.AssertExpectations(t)
+}
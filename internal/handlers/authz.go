@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/RomanKovalev007/mai_news/internal/authz"
+	"github.com/RomanKovalev007/mai_news/internal/middleware"
+	"github.com/RomanKovalev007/mai_news/internal/models"
+	"github.com/RomanKovalev007/mai_news/internal/storage"
+)
+
+// Authorizer decides whether a user may act on a particular post or
+// section; that the role of the user allows the action at all is checked in
+// front of the handlers, see middleware.Permit.
+type Authorizer interface{
+	// AuthorizePost fails with a *authz.Denied, or with
+	// storage.ErrPostNotFound if there is no such post.
+	AuthorizePost(ctx context.Context, user models.User, action authz.Action, id int) error
+	// AuthorizeSection and AuthorizeComment fail with a *authz.Denied.
+	AuthorizeSection(ctx context.Context, user models.User, action authz.Action, slug string) error
+	AuthorizeComment(user models.User, action authz.Action, comment models.Comment) error
+	// AuthorizePublish checks putting a new post in a section, AuthorizeMove
+	// moving a post to one. They fail like AuthorizePost.
+	AuthorizePublish(ctx context.Context, user models.User, slug string) error
+	AuthorizeMove(ctx context.Context, user models.User, id int, slug string) error
+	// ReachedSections tells in which sections user may do action: in all
+	// of them if all is true, otherwise in those with the slugs. It fails
+	// with a *authz.Denied.
+	ReachedSections(ctx context.Context, user models.User, action authz.Action) (slugs []string, all bool, err error)
+}
+
+// authorizePost checks that the user of the request may do action to the
+// post. If not, it answers 403 with the reason, or 404 with notFound for a
+// missing post, and returns false.
+func authorizePost(w http.ResponseWriter, r *http.Request, authorizer Authorizer, action authz.Action, id int, notFound string, log *slog.Logger) bool{
+	user, _ := middleware.User(r.Context())
+	err := authorizer.AuthorizePost(r.Context(), user, action, id)
+	if err != nil && errors.Is(err, storage.ErrPostNotFound){
+		http.Error(w, notFound, http.StatusNotFound)
+		return false
+	}
+	return authorized(w, r, err, log)
+}
+
+// authorizeSection checks that the user of the request may do action to the
+// section like authorizePost.
+func authorizeSection(w http.ResponseWriter, r *http.Request, authorizer Authorizer, action authz.Action, slug string, log *slog.Logger) bool{
+	user, _ := middleware.User(r.Context())
+	return authorized(w, r, authorizer.AuthorizeSection(r.Context(), user, action, slug), log)
+}
+
+// authorizePublish checks that the user of the request may put a new post in
+// the section like authorizeSection.
+func authorizePublish(w http.ResponseWriter, r *http.Request, authorizer Authorizer, slug string, log *slog.Logger) bool{
+	user, _ := middleware.User(r.Context())
+	return authorized(w, r, authorizer.AuthorizePublish(r.Context(), user, slug), log)
+}
+
+// authorizeMove checks that the user of the request may move the post to the
+// section like authorizePost.
+func authorizeMove(w http.ResponseWriter, r *http.Request, authorizer Authorizer, id int, slug string, log *slog.Logger) bool{
+	user, _ := middleware.User(r.Context())
+	err := authorizer.AuthorizeMove(r.Context(), user, id, slug)
+	if err != nil && errors.Is(err, storage.ErrPostNotFound){
+		http.Error(w, "Post not found", http.StatusNotFound)
+		return false
+	}
+	return authorized(w, r, err, log)
+}
+
+// reachedSections tells in which sections the user of the request may do
+// action, for lists spanning sections. If the check fails it answers like
+// authorizePost and ok is false.
+func reachedSections(w http.ResponseWriter, r *http.Request, authorizer Authorizer, action authz.Action, log *slog.Logger) (slugs []string, all bool, ok bool){
+	user, _ := middleware.User(r.Context())
+	slugs, all, err := authorizer.ReachedSections(r.Context(), user, action)
+	return slugs, all, authorized(w, r, err, log)
+}
+
+// authorized answers a failed check and reports whether the request may go
+// on.
+func authorized(w http.ResponseWriter, r *http.Request, err error, log *slog.Logger) bool{
+	if err == nil{
+		return true
+	}
+	if writeContextError(w, r, log, err){
+		return false
+	}
+	var denied *authz.Denied
+	if errors.As(err, &denied){
+		authz.Write(w, denied)
+		return false
+	}
+	http.Error(w, "failed to authorize", http.StatusInternalServerError)
+	log.Error("failed to authorize", slog.String("error", err.Error()))
+	return false
+}
@@ -0,0 +1,251 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/RomanKovalev007/mai_news/internal/authz"
+	"github.com/RomanKovalev007/mai_news/internal/middleware"
+	"github.com/RomanKovalev007/mai_news/internal/models"
+	"github.com/RomanKovalev007/mai_news/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// allowAll lets everyone do anything, for tests of handlers beyond their
+// authorization.
+type allowAll struct{}
+
+func (allowAll) AuthorizePost(context.Context, models.User, authz.Action, int) error {
+	return nil
+}
+
+func (allowAll) AuthorizeSection(context.Context, models.User, authz.Action, string) error {
+	return nil
+}
+
+func (allowAll) AuthorizeComment(models.User, authz.Action, models.Comment) error {
+	return nil
+}
+
+func (allowAll) AuthorizePublish(context.Context, models.User, string) error {
+	return nil
+}
+
+func (allowAll) AuthorizeMove(context.Context, models.User, int, string) error {
+	return nil
+}
+
+func (allowAll) ReachedSections(context.Context, models.User, authz.Action) ([]string, bool, error) {
+	return nil, true, nil
+}
+
+func TestAuthorizePost(t *testing.T) {
+	user := models.User{ID: 2, Name: "Борис", Role: models.RoleAuthor}
+	tests := []struct {
+		name           string
+		mockSetup      func(*MockAuthorizer, *MockPoster)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name: "allowed",
+			mockSetup: func(ma *MockAuthorizer, mp *MockPoster) {
+				ma.On("AuthorizePost", mock.Anything, user, authz.DeletePost, 1).Return(nil)
+				mp.On("DeletePost", mock.Anything, 1, 0).Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   "",
+		},
+		{
+			name: "denied",
+			mockSetup: func(ma *MockAuthorizer, mp *MockPoster) {
+				ma.On("AuthorizePost", mock.Anything, user, authz.DeletePost, 1).Return(&authz.Denied{
+					Action: authz.DeletePost, Reason: authz.ReasonNotAuthor, Message: "Only the author may do this to the post",
+				})
+			},
+			expectedStatus: http.StatusForbidden,
+			expectedBody:   `{"action":"posts.delete","reason":"not_author","message":"Only the author may do this to the post"}` + "\n",
+		},
+		{
+			name: "not found",
+			mockSetup: func(ma *MockAuthorizer, mp *MockPoster) {
+				ma.On("AuthorizePost", mock.Anything, user, authz.DeletePost, 1).Return(storage.ErrPostNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   "Post not found\n",
+		},
+		{
+			name: "storage error",
+			mockSetup: func(ma *MockAuthorizer, mp *MockPoster) {
+				ma.On("AuthorizePost", mock.Anything, user, authz.DeletePost, 1).Return(errors.New("db is down"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   "failed to authorize\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockAuthorizer := NewMockAuthorizer(t)
+			mockPoster := NewMockPoster(t)
+			tt.mockSetup(mockAuthorizer, mockPoster)
+
+			handler := DeletePostHandler(mockPoster, mockAuthorizer, false, slog.Default())
+			req := httptest.NewRequest("DELETE", "/posts/1/", nil)
+			req.SetPathValue("id", "1")
+			req = req.WithContext(middleware.WithUser(req.Context(), user))
+			w := httptest.NewRecorder()
+
+			handler(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Equal(t, tt.expectedBody, w.Body.String())
+		})
+	}
+}
+
+func TestAuthorizeSection(t *testing.T) {
+	user := models.User{ID: 2, Email: "boris@mai.ru", Role: models.RoleEditor}
+	mockAuthorizer := NewMockAuthorizer(t)
+	mockAuthorizer.On("AuthorizeSection", mock.Anything, user, authz.EditSection, "it").Return(&authz.Denied{
+		Action: authz.EditSection, Reason: authz.ReasonNotSectionEditor, Message: `Only editors of section "it" may do this`,
+	})
+
+	handler := PatchSectionHandler(NewMockSectioner(t), mockAuthorizer, slog.Default())
+	req := httptest.NewRequest("PATCH", "/sections/it/", bytes.NewBufferString(`{"title":"ИТ"}`))
+	req.SetPathValue("slug", "it")
+	req = req.WithContext(middleware.WithUser(req.Context(), user))
+	w := httptest.NewRecorder()
+
+	handler(w, req)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Equal(t, `{"action":"sections.edit","reason":"not_section_editor","message":"Only editors of section \"it\" may do this"}`+"\n", w.Body.String())
+}
+
+func TestAuthorizeSectionEditors(t *testing.T) {
+	user := models.User{ID: 2, Email: "boris@mai.ru", Role: models.RoleEditor}
+	mockAuthorizer := NewMockAuthorizer(t)
+	mockAuthorizer.On("AuthorizeSection", mock.Anything, user, authz.EditSection, "it").Return(nil)
+	mockAuthorizer.On("AuthorizeSection", mock.Anything, user, authz.ManageSectionEditors, "it").Return(&authz.Denied{
+		Action: authz.ManageSectionEditors, Reason: authz.ReasonRole, Message: `Role "editor" may not do this`,
+	})
+
+	handler := PatchSectionHandler(NewMockSectioner(t), mockAuthorizer, slog.Default())
+	req := httptest.NewRequest("PATCH", "/sections/it/", bytes.NewBufferString(`{"editors":["boris@mai.ru","gleb@mai.ru"]}`))
+	req.SetPathValue("slug", "it")
+	req = req.WithContext(middleware.WithUser(req.Context(), user))
+	w := httptest.NewRecorder()
+
+	handler(w, req)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Equal(t, `{"action":"sections.editors","reason":"role","message":"Role \"editor\" may not do this"}`+"\n", w.Body.String())
+}
+
+func TestAuthorizePublish(t *testing.T) {
+	user := models.User{ID: 2, Email: "boris@mai.ru", Role: models.RoleAuthor}
+	mockAuthorizer := NewMockAuthorizer(t)
+	mockAuthorizer.On("AuthorizePublish", mock.Anything, user, "it").Return(&authz.Denied{
+		Action: authz.CreatePost, Reason: authz.ReasonNotSectionEditor, Message: `Only editors of section "it" may do this`,
+	})
+
+	handler := CreatePostHandler(NewMockPoster(t), mockAuthorizer, slog.Default())
+	req := httptest.NewRequest("POST", "/posts/", bytes.NewBufferString(`{"title":"Schedule","section":"it"}`))
+	req = req.WithContext(middleware.WithUser(req.Context(), user))
+	w := httptest.NewRecorder()
+
+	handler(w, req)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Equal(t, `{"action":"posts.create","reason":"not_section_editor","message":"Only editors of section \"it\" may do this"}`+"\n", w.Body.String())
+}
+
+func TestAuthorizeMove(t *testing.T) {
+	user := models.User{ID: 2, Email: "boris@mai.ru", Role: models.RoleEditor}
+	tests := []struct {
+		name           string
+		body           string
+		contentType    string
+		mockSetup      func(*MockAuthorizer, *MockPoster)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name: "out of their section",
+			body: `{"section":"sport"}`,
+			mockSetup: func(ma *MockAuthorizer, mp *MockPoster) {
+				ma.On("AuthorizePost", mock.Anything, user, authz.EditPost, 1).Return(nil)
+				ma.On("AuthorizeMove", mock.Anything, user, 1, "sport").Return(&authz.Denied{
+					Action: authz.EditPost, Reason: authz.ReasonNotSectionEditor, Message: `Only editors of section "sport" may do this`,
+				})
+			},
+			expectedStatus: http.StatusForbidden,
+			expectedBody:   `{"action":"posts.edit","reason":"not_section_editor","message":"Only editors of section \"sport\" may do this"}` + "\n",
+		},
+		{
+			name: "within their sections",
+			body: `{"section":"it"}`,
+			mockSetup: func(ma *MockAuthorizer, mp *MockPoster) {
+				section := "it"
+				ma.On("AuthorizePost", mock.Anything, user, authz.EditPost, 1).Return(nil)
+				ma.On("AuthorizeMove", mock.Anything, user, 1, "it").Return(nil)
+				mp.On("PatchPost", mock.Anything, 1, 0, models.PostPatch{Section: &section, EditorID: 2}).Return(models.OutputPost{ID: 1, Section: "it"}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"id":1,"title":"","content":"","section":"it","created_at":"0001-01-01T00:00:00Z"}` + "\n",
+		},
+		{
+			name:        "json patch out of their section",
+			body:        `[{"op":"replace","path":"/section","value":"sport"}]`,
+			contentType: "application/json-patch+json",
+			mockSetup: func(ma *MockAuthorizer, mp *MockPoster) {
+				ma.On("AuthorizePost", mock.Anything, user, authz.EditPost, 1).Return(nil)
+				ma.On("AuthorizeMove", mock.Anything, user, 1, "sport").Return(&authz.Denied{
+					Action: authz.EditPost, Reason: authz.ReasonNotSectionEditor, Message: `Only editors of section "sport" may do this`,
+				})
+			},
+			expectedStatus: http.StatusForbidden,
+			expectedBody:   `{"action":"posts.edit","reason":"not_section_editor","message":"Only editors of section \"sport\" may do this"}` + "\n",
+		},
+		{
+			name: "section left alone",
+			body: `{"title":"Schedule"}`,
+			mockSetup: func(ma *MockAuthorizer, mp *MockPoster) {
+				title := "Schedule"
+				ma.On("AuthorizePost", mock.Anything, user, authz.EditPost, 1).Return(nil)
+				mp.On("PatchPost", mock.Anything, 1, 0, models.PostPatch{Title: &title, EditorID: 2}).Return(models.OutputPost{ID: 1, Title: "Schedule"}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"id":1,"title":"Schedule","content":"","created_at":"0001-01-01T00:00:00Z"}` + "\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockAuthorizer := NewMockAuthorizer(t)
+			mockPoster := NewMockPoster(t)
+			tt.mockSetup(mockAuthorizer, mockPoster)
+
+			handler := PatchPostHandler(mockPoster, mockAuthorizer, false, slog.Default())
+			req := httptest.NewRequest("PATCH", "/posts/1/", bytes.NewBufferString(tt.body))
+			req.SetPathValue("id", "1")
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+			req = req.WithContext(middleware.WithUser(req.Context(), user))
+			w := httptest.NewRecorder()
+
+			handler(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Equal(t, tt.expectedBody, w.Body.String())
+		})
+	}
+}
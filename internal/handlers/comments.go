@@ -0,0 +1,375 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/RomanKovalev007/mai_news/internal/authz"
+	"github.com/RomanKovalev007/mai_news/internal/middleware"
+	"github.com/RomanKovalev007/mai_news/internal/models"
+	"github.com/RomanKovalev007/mai_news/internal/storage"
+)
+
+const maxCommentLength = 5000
+
+var errInvalidComment = errors.New("Comment must be 1 to 5000 characters long")
+
+// Commenter stores the comments on posts. GetComment, PatchComment and
+// DeleteComment fail with storage.ErrCommentNotFound for unknown ids.
+type Commenter interface{
+	// GetComments returns the approved comments of the post whose parents
+	// are shown too, oldest first; it fails with storage.ErrPostNotFound
+	// for unknown posts and posts in the trash.
+	GetComments(ctx context.Context, postID int) ([]models.Comment, error)
+	// GetCommentQueue returns the pending comments, oldest first, of posts
+	// in the sections with the slugs, or in all sections if it is nil.
+	GetCommentQueue(ctx context.Context, sections []string) ([]models.Comment, error)
+	GetComment(ctx context.Context, id int) (models.Comment, error)
+	// SaveComment fails with storage.ErrPostNotFound like GetComments, with
+	// storage.ErrCommentNotFound if the parent is not shown under the post
+	// and with storage.ErrUserNotFound if the author does not exist.
+	SaveComment(ctx context.Context, comment models.InputComment) (models.Comment, error)
+	PatchComment(ctx context.Context, id int, patch models.CommentPatch) (models.Comment, error)
+	// DeleteComment deletes the replies to the comment as well.
+	DeleteComment(ctx context.Context, id int) error
+}
+
+// commentRequest is the body of POST /posts/{id}/comments/ and, without
+// parent_id, of PATCH /posts/{id}/comments/{comment}/.
+type commentRequest struct{
+	Content string `json:"content"`
+	ParentID int `json:"parent_id"`
+}
+
+// GetCommentsHandler serves GET /posts/{id}/comments/ with the approved
+// comments of the post as threads: each comment carries the replies to it.
+func GetCommentsHandler(commenter Commenter, log *slog.Logger) http.HandlerFunc {
+	return func (w http.ResponseWriter, r *http.Request){
+		id, err := strconv.Atoi(r.PathValue("id"))
+		if err != nil {
+			http.Error(w, "Invalid post ID", http.StatusBadRequest)
+			return
+		}
+
+		comments, err := commenter.GetComments(r.Context(), id)
+		if err != nil {
+			if writeContextError(w, r, log, err){
+				return
+			}
+			writeCommentError(w, err, log)
+			return
+		}
+
+		for i := range comments{
+			comments[i] = commentInZone(r, comments[i])
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(threadComments(comments))
+	}
+}
+
+// CreateCommentHandler serves POST /posts/{id}/comments/ with the content
+// and, for a reply, the parent_id of the comment. Comments await moderation
+// unless the user may moderate the comments of the post.
+func CreateCommentHandler(commenter Commenter, authorizer Authorizer, log *slog.Logger) http.HandlerFunc {
+	return func (w http.ResponseWriter, r *http.Request){
+		id, err := strconv.Atoi(r.PathValue("id"))
+		if err != nil {
+			http.Error(w, "Invalid post ID", http.StatusBadRequest)
+			return
+		}
+
+		var req commentRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request payload", http.StatusBadRequest)
+			return
+		}
+		content, ok := normalizeComment(req.Content)
+		if !ok {
+			http.Error(w, errInvalidComment.Error(), http.StatusBadRequest)
+			return
+		}
+
+		user, _ := middleware.User(r.Context())
+		status, err := newCommentStatus(r.Context(), authorizer, user, id)
+		if err != nil {
+			authorized(w, r, err, log)
+			return
+		}
+
+		comment, err := commenter.SaveComment(r.Context(), models.InputComment{
+			PostID: id,
+			ParentID: req.ParentID,
+			AuthorID: user.ID,
+			Content: content,
+			Status: status,
+		})
+		if err != nil {
+			if writeContextError(w, r, log, err){
+				return
+			}
+			if errors.Is(err, storage.ErrCommentNotFound){
+				http.Error(w, "Unknown parent comment", http.StatusBadRequest)
+				return
+			}
+			if errors.Is(err, storage.ErrUserNotFound){
+				// the account was deleted after the request was authenticated
+				http.Error(w, "Unknown author", http.StatusUnauthorized)
+				return
+			}
+			writeCommentError(w, err, log)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(commentInZone(r, comment))
+	}
+}
+
+// PatchCommentHandler serves PATCH /posts/{id}/comments/{comment}/ with a
+// new content for a comment of the user. An edited comment awaits
+// moderation again like a new one.
+func PatchCommentHandler(commenter Commenter, authorizer Authorizer, log *slog.Logger) http.HandlerFunc {
+	return func (w http.ResponseWriter, r *http.Request){
+		var req commentRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request payload", http.StatusBadRequest)
+			return
+		}
+		content, ok := normalizeComment(req.Content)
+		if !ok {
+			http.Error(w, errInvalidComment.Error(), http.StatusBadRequest)
+			return
+		}
+
+		comment, ok := findComment(w, r, commenter, authorizer, authz.EditComment, log)
+		if !ok {
+			return
+		}
+
+		user, _ := middleware.User(r.Context())
+		status, err := newCommentStatus(r.Context(), authorizer, user, comment.PostID)
+		if err != nil {
+			authorized(w, r, err, log)
+			return
+		}
+		patch := models.CommentPatch{Content: &content}
+		if comment.Status == models.CommentApproved{
+			patch.Status = &status
+		}
+
+		comment, err = commenter.PatchComment(r.Context(), comment.ID, patch)
+		if err != nil {
+			if writeContextError(w, r, log, err){
+				return
+			}
+			writeCommentError(w, err, log)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(commentInZone(r, comment))
+	}
+}
+
+// DeleteCommentHandler serves DELETE /posts/{id}/comments/{comment}/. The
+// replies to the comment are deleted with it.
+func DeleteCommentHandler(commenter Commenter, authorizer Authorizer, log *slog.Logger) http.HandlerFunc {
+	return func (w http.ResponseWriter, r *http.Request){
+		comment, ok := findComment(w, r, commenter, authorizer, authz.DeleteComment, log)
+		if !ok {
+			return
+		}
+
+		if err := commenter.DeleteComment(r.Context(), comment.ID); err != nil {
+			if writeContextError(w, r, log, err){
+				return
+			}
+			writeCommentError(w, err, log)
+			return
+		}
+	}
+}
+
+// GetCommentQueueHandler serves GET /comments/queue/ with the comments
+// awaiting moderation, oldest first, on posts of the sections the user
+// edits, or of all sections for an admin.
+func GetCommentQueueHandler(commenter Commenter, authorizer Authorizer, log *slog.Logger) http.HandlerFunc {
+	return func (w http.ResponseWriter, r *http.Request){
+		sections, all, ok := reachedSections(w, r, authorizer, authz.ModerateComments, log)
+		if !ok{
+			return
+		}
+		if all{
+			sections = nil
+		} else if sections == nil{
+			sections = []string{}
+		}
+
+		comments, err := commenter.GetCommentQueue(r.Context(), sections)
+		if err != nil {
+			if writeContextError(w, r, log, err){
+				return
+			}
+			writeCommentError(w, err, log)
+			return
+		}
+		if comments == nil {
+			comments = []models.Comment{}
+		}
+
+		for i := range comments{
+			comments[i] = commentInZone(r, comments[i])
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(comments)
+	}
+}
+
+// ModerateCommentHandler serves POST /comments/{id}/moderate/ with the new
+// status of the comment. The user must be allowed to moderate the comments
+// of its post, which like the queue takes editing its section.
+func ModerateCommentHandler(commenter Commenter, authorizer Authorizer, log *slog.Logger) http.HandlerFunc {
+	return func (w http.ResponseWriter, r *http.Request){
+		id, err := strconv.Atoi(r.PathValue("id"))
+		if err != nil {
+			http.Error(w, "Invalid comment ID", http.StatusBadRequest)
+			return
+		}
+
+		var req struct{
+			Status models.CommentStatus `json:"status"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request payload", http.StatusBadRequest)
+			return
+		}
+		if !req.Status.Valid() {
+			http.Error(w, "Status must be one of pending, approved and rejected", http.StatusBadRequest)
+			return
+		}
+
+		comment, err := commenter.GetComment(r.Context(), id)
+		if err != nil {
+			if writeContextError(w, r, log, err){
+				return
+			}
+			writeCommentError(w, err, log)
+			return
+		}
+		if !authorizePost(w, r, authorizer, authz.ModerateComments, comment.PostID, "Post not found", log){
+			return
+		}
+
+		comment, err = commenter.PatchComment(r.Context(), id, models.CommentPatch{Status: &req.Status})
+		if err != nil {
+			if writeContextError(w, r, log, err){
+				return
+			}
+			writeCommentError(w, err, log)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(commentInZone(r, comment))
+	}
+}
+
+// findComment returns the comment of the post in the path that the user of
+// the request may do action to, or answers the request and returns false.
+func findComment(w http.ResponseWriter, r *http.Request, commenter Commenter, authorizer Authorizer, action authz.Action, log *slog.Logger) (models.Comment, bool){
+	postID, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "Invalid post ID", http.StatusBadRequest)
+		return models.Comment{}, false
+	}
+	id, err := strconv.Atoi(r.PathValue("comment"))
+	if err != nil {
+		http.Error(w, "Invalid comment ID", http.StatusBadRequest)
+		return models.Comment{}, false
+	}
+
+	comment, err := commenter.GetComment(r.Context(), id)
+	if err == nil && comment.PostID != postID{
+		err = storage.ErrCommentNotFound
+	}
+	if err != nil {
+		if writeContextError(w, r, log, err){
+			return models.Comment{}, false
+		}
+		writeCommentError(w, err, log)
+		return models.Comment{}, false
+	}
+
+	user, _ := middleware.User(r.Context())
+	if !authorized(w, r, authorizer.AuthorizeComment(user, action, comment), log){
+		return models.Comment{}, false
+	}
+	return comment, true
+}
+
+// newCommentStatus returns the status of a comment the user writes on the
+// post: approved if the user may moderate its comments, pending otherwise.
+func newCommentStatus(ctx context.Context, authorizer Authorizer, user models.User, postID int) (models.CommentStatus, error){
+	err := authorizer.AuthorizePost(ctx, user, authz.ModerateComments, postID)
+	var denied *authz.Denied
+	switch {
+	case err == nil:
+		return models.CommentApproved, nil
+	case errors.As(err, &denied), errors.Is(err, storage.ErrPostNotFound):
+		// a missing post is reported by the store
+		return models.CommentPending, nil
+	}
+	return "", err
+}
+
+// normalizeComment trims content and checks its length.
+func normalizeComment(content string) (string, bool){
+	content = strings.TrimSpace(content)
+	n := utf8.RuneCountInString(content)
+	return content, n > 0 && n <= maxCommentLength
+}
+
+// threadComments nests comments into the Replies of their parents and
+// returns the top-level ones, keeping the order of comments. Every parent
+// must be among comments.
+func threadComments(comments []models.Comment) []models.Comment{
+	replies := make(map[int][]models.Comment)
+	for _, comment := range comments{
+		replies[comment.ParentID] = append(replies[comment.ParentID], comment)
+	}
+
+	var thread func(parentID int) []models.Comment
+	thread = func(parentID int) []models.Comment{
+		result := replies[parentID]
+		for i := range result{
+			result[i].Replies = thread(result[i].ID)
+		}
+		return result
+	}
+
+	result := thread(0)
+	if result == nil {
+		result = []models.Comment{}
+	}
+	return result
+}
+
+func writeCommentError(w http.ResponseWriter, err error, log *slog.Logger){
+	switch {
+	case errors.Is(err, storage.ErrCommentNotFound):
+		http.Error(w, "Comment not found", http.StatusNotFound)
+	case errors.Is(err, storage.ErrPostNotFound):
+		http.Error(w, "Post not found", http.StatusNotFound)
+	default:
+		http.Error(w, "failed to access comment", http.StatusInternalServerError)
+		log.Error("failed to access comment", slog.String("error", err.Error()))
+	}
+}
@@ -0,0 +1,489 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/RomanKovalev007/mai_news/internal/authz"
+	"github.com/RomanKovalev007/mai_news/internal/middleware"
+	"github.com/RomanKovalev007/mai_news/internal/models"
+	"github.com/RomanKovalev007/mai_news/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var commentTime = time.Date(2025, 9, 1, 10, 0, 0, 0, time.UTC)
+
+func testComment(id, parentID int, status models.CommentStatus) models.Comment {
+	return models.Comment{
+		ID:        id,
+		PostID:    1,
+		ParentID:  parentID,
+		Author:    &models.Author{ID: 3, Name: "Анна"},
+		Content:   "Content",
+		Status:    status,
+		CreatedAt: commentTime,
+		UpdatedAt: commentTime,
+	}
+}
+
+func commentJSON(id int, status string) string {
+	return `{"id":` + strconv.Itoa(id) + `,"post_id":1,"author":{"id":3,"name":"Анна"},"content":"Content","status":"` + status +
+		`","created_at":"2025-09-01T10:00:00Z","updated_at":"2025-09-01T10:00:00Z"}`
+}
+
+func TestGetCommentsHandler(t *testing.T) {
+	tests := []struct {
+		name           string
+		postID         string
+		mockSetup      func(*MockCommenter)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:   "threads",
+			postID: "1",
+			mockSetup: func(mc *MockCommenter) {
+				mc.On("GetComments", mock.Anything, 1).Return([]models.Comment{
+					testComment(1, 0, models.CommentApproved),
+					testComment(2, 1, models.CommentApproved),
+					testComment(3, 0, models.CommentApproved),
+					testComment(4, 2, models.CommentApproved),
+				}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: `[{"id":1,"post_id":1,"author":{"id":3,"name":"Анна"},"content":"Content","status":"approved","created_at":"2025-09-01T10:00:00Z","updated_at":"2025-09-01T10:00:00Z","replies":[` +
+				`{"id":2,"post_id":1,"parent_id":1,"author":{"id":3,"name":"Анна"},"content":"Content","status":"approved","created_at":"2025-09-01T10:00:00Z","updated_at":"2025-09-01T10:00:00Z","replies":[` +
+				`{"id":4,"post_id":1,"parent_id":2,"author":{"id":3,"name":"Анна"},"content":"Content","status":"approved","created_at":"2025-09-01T10:00:00Z","updated_at":"2025-09-01T10:00:00Z"}]}]},` +
+				commentJSON(3, "approved") + "]\n",
+		},
+		{
+			name:   "no comments",
+			postID: "1",
+			mockSetup: func(mc *MockCommenter) {
+				mc.On("GetComments", mock.Anything, 1).Return(nil, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   "[]\n",
+		},
+		{
+			name:           "invalid id",
+			postID:         "invalid",
+			mockSetup:      func(mc *MockCommenter) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Invalid post ID\n",
+		},
+		{
+			name:   "post not found",
+			postID: "2",
+			mockSetup: func(mc *MockCommenter) {
+				mc.On("GetComments", mock.Anything, 2).Return(nil, storage.ErrPostNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   "Post not found\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockCommenter := NewMockCommenter(t)
+			tt.mockSetup(mockCommenter)
+
+			handler := GetCommentsHandler(mockCommenter, slog.Default())
+			req := httptest.NewRequest("GET", "/posts/"+tt.postID+"/comments/", nil)
+			req.SetPathValue("id", tt.postID)
+			w := httptest.NewRecorder()
+
+			handler(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Equal(t, tt.expectedBody, w.Body.String())
+		})
+	}
+}
+
+func TestCreateCommentHandler(t *testing.T) {
+	user := models.User{ID: 3, Name: "Анна", Role: models.RoleReader}
+	denied := &authz.Denied{Action: authz.ModerateComments, Reason: authz.ReasonRole}
+
+	tests := []struct {
+		name           string
+		requestBody    string
+		mockSetup      func(*MockCommenter, *MockAuthorizer)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:        "awaits moderation",
+			requestBody: `{"content":" Content "}`,
+			mockSetup: func(mc *MockCommenter, ma *MockAuthorizer) {
+				ma.On("AuthorizePost", mock.Anything, user, authz.ModerateComments, 1).Return(denied)
+				mc.On("SaveComment", mock.Anything, models.InputComment{PostID: 1, AuthorID: 3, Content: "Content", Status: models.CommentPending}).
+					Return(testComment(1, 0, models.CommentPending), nil)
+			},
+			expectedStatus: http.StatusCreated,
+			expectedBody:   commentJSON(1, "pending") + "\n",
+		},
+		{
+			name:        "by moderator",
+			requestBody: `{"content":"Content","parent_id":2}`,
+			mockSetup: func(mc *MockCommenter, ma *MockAuthorizer) {
+				ma.On("AuthorizePost", mock.Anything, user, authz.ModerateComments, 1).Return(nil)
+				mc.On("SaveComment", mock.Anything, models.InputComment{PostID: 1, ParentID: 2, AuthorID: 3, Content: "Content", Status: models.CommentApproved}).
+					Return(testComment(1, 0, models.CommentApproved), nil)
+			},
+			expectedStatus: http.StatusCreated,
+			expectedBody:   commentJSON(1, "approved") + "\n",
+		},
+		{
+			name:           "empty",
+			requestBody:    `{"content":"  "}`,
+			mockSetup:      func(mc *MockCommenter, ma *MockAuthorizer) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Comment must be 1 to 5000 characters long\n",
+		},
+		{
+			name:           "invalid json",
+			requestBody:    `invalid json`,
+			mockSetup:      func(mc *MockCommenter, ma *MockAuthorizer) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Invalid request payload\n",
+		},
+		{
+			name:        "unknown parent",
+			requestBody: `{"content":"Content","parent_id":9}`,
+			mockSetup: func(mc *MockCommenter, ma *MockAuthorizer) {
+				ma.On("AuthorizePost", mock.Anything, user, authz.ModerateComments, 1).Return(denied)
+				mc.On("SaveComment", mock.Anything, mock.Anything).Return(models.Comment{}, storage.ErrCommentNotFound)
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Unknown parent comment\n",
+		},
+		{
+			name:        "post not found",
+			requestBody: `{"content":"Content"}`,
+			mockSetup: func(mc *MockCommenter, ma *MockAuthorizer) {
+				ma.On("AuthorizePost", mock.Anything, user, authz.ModerateComments, 1).Return(storage.ErrPostNotFound)
+				mc.On("SaveComment", mock.Anything, mock.Anything).Return(models.Comment{}, storage.ErrPostNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   "Post not found\n",
+		},
+		{
+			name:        "authorizer error",
+			requestBody: `{"content":"Content"}`,
+			mockSetup: func(mc *MockCommenter, ma *MockAuthorizer) {
+				ma.On("AuthorizePost", mock.Anything, user, authz.ModerateComments, 1).Return(errors.New("db is down"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   "failed to authorize\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockCommenter := NewMockCommenter(t)
+			mockAuthorizer := NewMockAuthorizer(t)
+			tt.mockSetup(mockCommenter, mockAuthorizer)
+
+			handler := CreateCommentHandler(mockCommenter, mockAuthorizer, slog.Default())
+			req := httptest.NewRequest("POST", "/posts/1/comments/", strings.NewReader(tt.requestBody))
+			req.SetPathValue("id", "1")
+			req = req.WithContext(middleware.WithUser(req.Context(), user))
+			w := httptest.NewRecorder()
+
+			handler(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Equal(t, tt.expectedBody, w.Body.String())
+		})
+	}
+}
+
+func TestPatchCommentHandler(t *testing.T) {
+	user := models.User{ID: 3, Name: "Анна", Role: models.RoleReader}
+	content := "New content"
+	pending := models.CommentPending
+
+	tests := []struct {
+		name           string
+		commentID      string
+		mockSetup      func(*MockCommenter, *MockAuthorizer)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:      "approved comment awaits moderation again",
+			commentID: "1",
+			mockSetup: func(mc *MockCommenter, ma *MockAuthorizer) {
+				comment := testComment(1, 0, models.CommentApproved)
+				mc.On("GetComment", mock.Anything, 1).Return(comment, nil)
+				ma.On("AuthorizeComment", user, authz.EditComment, comment).Return(nil)
+				ma.On("AuthorizePost", mock.Anything, user, authz.ModerateComments, 1).Return(&authz.Denied{})
+				mc.On("PatchComment", mock.Anything, 1, models.CommentPatch{Content: &content, Status: &pending}).
+					Return(testComment(1, 0, models.CommentPending), nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   commentJSON(1, "pending") + "\n",
+		},
+		{
+			name:      "rejected comment stays rejected",
+			commentID: "1",
+			mockSetup: func(mc *MockCommenter, ma *MockAuthorizer) {
+				comment := testComment(1, 0, models.CommentRejected)
+				mc.On("GetComment", mock.Anything, 1).Return(comment, nil)
+				ma.On("AuthorizeComment", user, authz.EditComment, comment).Return(nil)
+				ma.On("AuthorizePost", mock.Anything, user, authz.ModerateComments, 1).Return(&authz.Denied{})
+				mc.On("PatchComment", mock.Anything, 1, models.CommentPatch{Content: &content}).
+					Return(testComment(1, 0, models.CommentRejected), nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   commentJSON(1, "rejected") + "\n",
+		},
+		{
+			name:      "not the author",
+			commentID: "1",
+			mockSetup: func(mc *MockCommenter, ma *MockAuthorizer) {
+				comment := testComment(1, 0, models.CommentApproved)
+				mc.On("GetComment", mock.Anything, 1).Return(comment, nil)
+				ma.On("AuthorizeComment", user, authz.EditComment, comment).Return(&authz.Denied{
+					Action: authz.EditComment, Reason: authz.ReasonNotAuthor, Message: "Only the author may do this to the comment",
+				})
+			},
+			expectedStatus: http.StatusForbidden,
+			expectedBody:   `{"action":"comments.edit","reason":"not_author","message":"Only the author may do this to the comment"}` + "\n",
+		},
+		{
+			name:      "comment of another post",
+			commentID: "2",
+			mockSetup: func(mc *MockCommenter, ma *MockAuthorizer) {
+				comment := testComment(2, 0, models.CommentApproved)
+				comment.PostID = 5
+				mc.On("GetComment", mock.Anything, 2).Return(comment, nil)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   "Comment not found\n",
+		},
+		{
+			name:           "invalid id",
+			commentID:      "invalid",
+			mockSetup:      func(mc *MockCommenter, ma *MockAuthorizer) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Invalid comment ID\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockCommenter := NewMockCommenter(t)
+			mockAuthorizer := NewMockAuthorizer(t)
+			tt.mockSetup(mockCommenter, mockAuthorizer)
+
+			handler := PatchCommentHandler(mockCommenter, mockAuthorizer, slog.Default())
+			req := httptest.NewRequest("PATCH", "/posts/1/comments/"+tt.commentID+"/", strings.NewReader(`{"content":"New content"}`))
+			req.SetPathValue("id", "1")
+			req.SetPathValue("comment", tt.commentID)
+			req = req.WithContext(middleware.WithUser(req.Context(), user))
+			w := httptest.NewRecorder()
+
+			handler(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Equal(t, tt.expectedBody, w.Body.String())
+		})
+	}
+}
+
+func TestDeleteCommentHandler(t *testing.T) {
+	tests := []struct {
+		name           string
+		commentID      string
+		mockSetup      func(*MockCommenter)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:      "success",
+			commentID: "1",
+			mockSetup: func(mc *MockCommenter) {
+				mc.On("GetComment", mock.Anything, 1).Return(testComment(1, 0, models.CommentApproved), nil)
+				mc.On("DeleteComment", mock.Anything, 1).Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   "",
+		},
+		{
+			name:      "not found",
+			commentID: "2",
+			mockSetup: func(mc *MockCommenter) {
+				mc.On("GetComment", mock.Anything, 2).Return(models.Comment{}, storage.ErrCommentNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   "Comment not found\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockCommenter := NewMockCommenter(t)
+			tt.mockSetup(mockCommenter)
+
+			handler := DeleteCommentHandler(mockCommenter, allowAll{}, slog.Default())
+			req := httptest.NewRequest("DELETE", "/posts/1/comments/"+tt.commentID+"/", nil)
+			req.SetPathValue("id", "1")
+			req.SetPathValue("comment", tt.commentID)
+			w := httptest.NewRecorder()
+
+			handler(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Equal(t, tt.expectedBody, w.Body.String())
+		})
+	}
+}
+
+func TestGetCommentQueueHandler(t *testing.T) {
+	user := models.User{ID: 2, Email: "editor@mai.ru", Role: models.RoleEditor}
+	queue := []models.Comment{testComment(1, 0, models.CommentPending)}
+
+	tests := []struct {
+		name           string
+		mockSetup      func(*MockCommenter, *MockAuthorizer)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name: "all sections",
+			mockSetup: func(mc *MockCommenter, ma *MockAuthorizer) {
+				ma.On("ReachedSections", mock.Anything, user, authz.ModerateComments).Return(nil, true, nil)
+				mc.On("GetCommentQueue", mock.Anything, []string(nil)).Return(queue, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   "[" + commentJSON(1, "pending") + "]\n",
+		},
+		{
+			name: "sections edited",
+			mockSetup: func(mc *MockCommenter, ma *MockAuthorizer) {
+				ma.On("ReachedSections", mock.Anything, user, authz.ModerateComments).Return([]string{"it"}, false, nil)
+				mc.On("GetCommentQueue", mock.Anything, []string{"it"}).Return(queue, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   "[" + commentJSON(1, "pending") + "]\n",
+		},
+		{
+			name: "no sections edited",
+			mockSetup: func(mc *MockCommenter, ma *MockAuthorizer) {
+				ma.On("ReachedSections", mock.Anything, user, authz.ModerateComments).Return(nil, false, nil)
+				mc.On("GetCommentQueue", mock.Anything, []string{}).Return(nil, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   "[]\n",
+		},
+		{
+			name: "denied",
+			mockSetup: func(mc *MockCommenter, ma *MockAuthorizer) {
+				ma.On("ReachedSections", mock.Anything, user, authz.ModerateComments).Return(nil, false, &authz.Denied{
+					Action: authz.ModerateComments, Reason: authz.ReasonRole, Message: `Role "editor" may not do this`,
+				})
+			},
+			expectedStatus: http.StatusForbidden,
+			expectedBody:   `{"action":"comments.moderate","reason":"role","message":"Role \"editor\" may not do this"}` + "\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockCommenter := NewMockCommenter(t)
+			mockAuthorizer := NewMockAuthorizer(t)
+			tt.mockSetup(mockCommenter, mockAuthorizer)
+
+			handler := GetCommentQueueHandler(mockCommenter, mockAuthorizer, slog.Default())
+			req := httptest.NewRequest("GET", "/comments/queue/", nil)
+			req = req.WithContext(middleware.WithUser(req.Context(), user))
+			w := httptest.NewRecorder()
+
+			handler(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Equal(t, tt.expectedBody, w.Body.String())
+		})
+	}
+}
+
+func TestModerateCommentHandler(t *testing.T) {
+	user := models.User{ID: 2, Email: "editor@mai.ru", Role: models.RoleEditor}
+	approved := models.CommentApproved
+
+	tests := []struct {
+		name           string
+		requestBody    string
+		mockSetup      func(*MockCommenter, *MockAuthorizer)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:        "approve",
+			requestBody: `{"status":"approved"}`,
+			mockSetup: func(mc *MockCommenter, ma *MockAuthorizer) {
+				mc.On("GetComment", mock.Anything, 1).Return(testComment(1, 0, models.CommentPending), nil)
+				ma.On("AuthorizePost", mock.Anything, user, authz.ModerateComments, 1).Return(nil)
+				mc.On("PatchComment", mock.Anything, 1, models.CommentPatch{Status: &approved}).Return(testComment(1, 0, models.CommentApproved), nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   commentJSON(1, "approved") + "\n",
+		},
+		{
+			name:        "not an editor of the section",
+			requestBody: `{"status":"approved"}`,
+			mockSetup: func(mc *MockCommenter, ma *MockAuthorizer) {
+				mc.On("GetComment", mock.Anything, 1).Return(testComment(1, 0, models.CommentPending), nil)
+				ma.On("AuthorizePost", mock.Anything, user, authz.ModerateComments, 1).Return(&authz.Denied{
+					Action: authz.ModerateComments, Reason: authz.ReasonNotSectionEditor, Message: `Only editors of section "it" may do this`,
+				})
+			},
+			expectedStatus: http.StatusForbidden,
+			expectedBody:   `{"action":"comments.moderate","reason":"not_section_editor","message":"Only editors of section \"it\" may do this"}` + "\n",
+		},
+		{
+			name:           "invalid status",
+			requestBody:    `{"status":"hidden"}`,
+			mockSetup:      func(mc *MockCommenter, ma *MockAuthorizer) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Status must be one of pending, approved and rejected\n",
+		},
+		{
+			name:        "not found",
+			requestBody: `{"status":"rejected"}`,
+			mockSetup: func(mc *MockCommenter, ma *MockAuthorizer) {
+				mc.On("GetComment", mock.Anything, 1).Return(models.Comment{}, storage.ErrCommentNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   "Comment not found\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockCommenter := NewMockCommenter(t)
+			mockAuthorizer := NewMockAuthorizer(t)
+			tt.mockSetup(mockCommenter, mockAuthorizer)
+
+			handler := ModerateCommentHandler(mockCommenter, mockAuthorizer, slog.Default())
+			req := httptest.NewRequest("POST", "/comments/1/moderate/", strings.NewReader(tt.requestBody))
+			req.SetPathValue("id", "1")
+			req = req.WithContext(middleware.WithUser(req.Context(), user))
+			w := httptest.NewRecorder()
+
+			handler(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Equal(t, tt.expectedBody, w.Body.String())
+		})
+	}
+}
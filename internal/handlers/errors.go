@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+)
+
+// StatusClientClosedRequest is the non-standard status (introduced by nginx)
+// for requests the client abandoned before the response was ready.
+const StatusClientClosedRequest = 499
+
+// ErrShuttingDown is the cancellation cause set on request contexts when the
+// server stops before the request finished.
+var ErrShuttingDown = errors.New("server is shutting down")
+
+// writeContextError answers requests whose storage call failed because the
+// request context ended and reports whether it did so.
+func writeContextError(w http.ResponseWriter, r *http.Request, log *slog.Logger, err error) bool{
+	ctx := r.Context()
+	if ctx.Err() == nil && !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded){
+		return false
+	}
+
+	log.Warn("request aborted", slog.String("error", err.Error()))
+	switch {
+	case errors.Is(context.Cause(ctx), ErrShuttingDown):
+		http.Error(w, "Server is shutting down", http.StatusServiceUnavailable)
+	case errors.Is(ctx.Err(), context.DeadlineExceeded), errors.Is(err, context.DeadlineExceeded):
+		http.Error(w, "Request timed out", http.StatusGatewayTimeout)
+	default:
+		http.Error(w, "Client closed request", StatusClientClosedRequest)
+	}
+	return true
+}
@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/RomanKovalev007/mai_news/internal/lib/tags"
+	"github.com/RomanKovalev007/mai_news/internal/middleware"
+	"github.com/RomanKovalev007/mai_news/internal/models"
+)
+
+var (
+	errInvalidFrom = errors.New("Invalid from")
+	errInvalidTo = errors.New("Invalid to")
+	errInvalidRange = errors.New("Invalid date range")
+	errInvalidSort = errors.New("Invalid sort")
+	errInvalidOrder = errors.New("Invalid order")
+	errInvalidTag = errors.New("Invalid tag")
+	errInvalidTagMode = errors.New("Invalid tag_mode")
+)
+
+// parseFilter reads the filtering and sorting parameters of the posts list:
+//
+//	from, to      created_at range as RFC 3339 times or dates in the display
+//	              time zone; from is inclusive, to is exclusive, a date in to
+//	              includes that day
+//	title_prefix  title starts with the value, case-sensitively
+//	tag           has the tag; repeat it for several tags
+//	tag_mode      any (default) of the tags or all of them
+//	sort          created_at (default) or title
+//	order         asc or desc; newest first and A to Z by default
+func parseFilter(r *http.Request) (models.PostFilter, error){
+	q := r.URL.Query()
+	loc := middleware.Location(r.Context())
+	var filter models.PostFilter
+
+	if v := q.Get("from"); v != ""{
+		from, _, err := parseTime(v, loc)
+		if err != nil{
+			return models.PostFilter{}, errInvalidFrom
+		}
+		filter.From = from.UTC()
+	}
+	if v := q.Get("to"); v != ""{
+		to, isDate, err := parseTime(v, loc)
+		if err != nil{
+			return models.PostFilter{}, errInvalidTo
+		}
+		if isDate{
+			to = to.AddDate(0, 0, 1)
+		}
+		filter.To = to.UTC()
+	}
+	if !filter.From.IsZero() && !filter.To.IsZero() && !filter.From.Before(filter.To){
+		return models.PostFilter{}, errInvalidRange
+	}
+
+	filter.TitlePrefix = q.Get("title_prefix")
+
+	names, err := tags.NormalizeAll(q["tag"])
+	if err != nil{
+		return models.PostFilter{}, errInvalidTag
+	}
+	filter.Tags = names
+	switch q.Get("tag_mode"){
+	case "", "any":
+	case "all":
+		filter.AllTags = true
+	default:
+		return models.PostFilter{}, errInvalidTagMode
+	}
+
+	switch models.SortField(q.Get("sort")){
+	case "", models.SortByCreatedAt:
+		filter.SortBy = models.SortByCreatedAt
+		filter.Desc = true
+	case models.SortByTitle:
+		filter.SortBy = models.SortByTitle
+	default:
+		return models.PostFilter{}, errInvalidSort
+	}
+
+	switch q.Get("order"){
+	case "":
+	case "asc":
+		filter.Desc = false
+	case "desc":
+		filter.Desc = true
+	default:
+		return models.PostFilter{}, errInvalidOrder
+	}
+
+	return filter, nil
+}
+
+// parseTime accepts an RFC 3339 time or a date, which is read as midnight
+// in loc.
+func parseTime(v string, loc *time.Location) (t time.Time, isDate bool, err error){
+	if t, err = time.ParseInLocation(time.DateOnly, v, loc); err == nil{
+		return t, true, nil
+	}
+	t, err = time.Parse(time.RFC3339Nano, v)
+	return t, false, err
+}
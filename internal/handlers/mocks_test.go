@@ -5,17 +5,3197 @@
 package handlers
 
 import (
+	"context"
+	"github.com/RomanKovalev007/mai_news/internal/authz"
+	"github.com/RomanKovalev007/mai_news/internal/lib/jsonpatch"
 	"github.com/RomanKovalev007/mai_news/internal/models"
 	mock "github.com/stretchr/testify/mock"
 )
 
+// NewMockAuthorizer creates a new instance of MockAuthorizer. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockAuthorizer(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockAuthorizer {
+	mock := &MockAuthorizer{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// MockAuthorizer is an autogenerated mock type for the Authorizer type
+type MockAuthorizer struct {
+	mock.Mock
+}
+
+type MockAuthorizer_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockAuthorizer) EXPECT() *MockAuthorizer_Expecter {
+	return &MockAuthorizer_Expecter{mock: &_m.Mock}
+}
+
+// AuthorizeComment provides a mock function for the type MockAuthorizer
+func (_mock *MockAuthorizer) AuthorizeComment(user models.User, action authz.Action, comment models.Comment) error {
+	ret := _mock.Called(user, action, comment)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AuthorizeComment")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(models.User, authz.Action, models.Comment) error); ok {
+		r0 = returnFunc(user, action, comment)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockAuthorizer_AuthorizeComment_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'AuthorizeComment'
+type MockAuthorizer_AuthorizeComment_Call struct {
+	*mock.Call
+}
+
+// AuthorizeComment is a helper method to define mock.On call
+//   - user models.User
+//   - action authz.Action
+//   - comment models.Comment
+func (_e *MockAuthorizer_Expecter) AuthorizeComment(user interface{}, action interface{}, comment interface{}) *MockAuthorizer_AuthorizeComment_Call {
+	return &MockAuthorizer_AuthorizeComment_Call{Call: _e.mock.On("AuthorizeComment", user, action, comment)}
+}
+
+func (_c *MockAuthorizer_AuthorizeComment_Call) Run(run func(user models.User, action authz.Action, comment models.Comment)) *MockAuthorizer_AuthorizeComment_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 models.User
+		if args[0] != nil {
+			arg0 = args[0].(models.User)
+		}
+		var arg1 authz.Action
+		if args[1] != nil {
+			arg1 = args[1].(authz.Action)
+		}
+		var arg2 models.Comment
+		if args[2] != nil {
+			arg2 = args[2].(models.Comment)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockAuthorizer_AuthorizeComment_Call) Return(err error) *MockAuthorizer_AuthorizeComment_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockAuthorizer_AuthorizeComment_Call) RunAndReturn(run func(user models.User, action authz.Action, comment models.Comment) error) *MockAuthorizer_AuthorizeComment_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// AuthorizeMove provides a mock function for the type MockAuthorizer
+func (_mock *MockAuthorizer) AuthorizeMove(ctx context.Context, user models.User, id int, slug string) error {
+	ret := _mock.Called(ctx, user, id, slug)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AuthorizeMove")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, models.User, int, string) error); ok {
+		r0 = returnFunc(ctx, user, id, slug)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockAuthorizer_AuthorizeMove_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'AuthorizeMove'
+type MockAuthorizer_AuthorizeMove_Call struct {
+	*mock.Call
+}
+
+// AuthorizeMove is a helper method to define mock.On call
+//   - ctx context.Context
+//   - user models.User
+//   - id int
+//   - slug string
+func (_e *MockAuthorizer_Expecter) AuthorizeMove(ctx interface{}, user interface{}, id interface{}, slug interface{}) *MockAuthorizer_AuthorizeMove_Call {
+	return &MockAuthorizer_AuthorizeMove_Call{Call: _e.mock.On("AuthorizeMove", ctx, user, id, slug)}
+}
+
+func (_c *MockAuthorizer_AuthorizeMove_Call) Run(run func(ctx context.Context, user models.User, id int, slug string)) *MockAuthorizer_AuthorizeMove_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 models.User
+		if args[1] != nil {
+			arg1 = args[1].(models.User)
+		}
+		var arg2 int
+		if args[2] != nil {
+			arg2 = args[2].(int)
+		}
+		var arg3 string
+		if args[3] != nil {
+			arg3 = args[3].(string)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+			arg3,
+		)
+	})
+	return _c
+}
+
+func (_c *MockAuthorizer_AuthorizeMove_Call) Return(err error) *MockAuthorizer_AuthorizeMove_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockAuthorizer_AuthorizeMove_Call) RunAndReturn(run func(ctx context.Context, user models.User, id int, slug string) error) *MockAuthorizer_AuthorizeMove_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// AuthorizePost provides a mock function for the type MockAuthorizer
+func (_mock *MockAuthorizer) AuthorizePost(ctx context.Context, user models.User, action authz.Action, id int) error {
+	ret := _mock.Called(ctx, user, action, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AuthorizePost")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, models.User, authz.Action, int) error); ok {
+		r0 = returnFunc(ctx, user, action, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockAuthorizer_AuthorizePost_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'AuthorizePost'
+type MockAuthorizer_AuthorizePost_Call struct {
+	*mock.Call
+}
+
+// AuthorizePost is a helper method to define mock.On call
+//   - ctx context.Context
+//   - user models.User
+//   - action authz.Action
+//   - id int
+func (_e *MockAuthorizer_Expecter) AuthorizePost(ctx interface{}, user interface{}, action interface{}, id interface{}) *MockAuthorizer_AuthorizePost_Call {
+	return &MockAuthorizer_AuthorizePost_Call{Call: _e.mock.On("AuthorizePost", ctx, user, action, id)}
+}
+
+func (_c *MockAuthorizer_AuthorizePost_Call) Run(run func(ctx context.Context, user models.User, action authz.Action, id int)) *MockAuthorizer_AuthorizePost_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 models.User
+		if args[1] != nil {
+			arg1 = args[1].(models.User)
+		}
+		var arg2 authz.Action
+		if args[2] != nil {
+			arg2 = args[2].(authz.Action)
+		}
+		var arg3 int
+		if args[3] != nil {
+			arg3 = args[3].(int)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+			arg3,
+		)
+	})
+	return _c
+}
+
+func (_c *MockAuthorizer_AuthorizePost_Call) Return(err error) *MockAuthorizer_AuthorizePost_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockAuthorizer_AuthorizePost_Call) RunAndReturn(run func(ctx context.Context, user models.User, action authz.Action, id int) error) *MockAuthorizer_AuthorizePost_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// AuthorizePublish provides a mock function for the type MockAuthorizer
+func (_mock *MockAuthorizer) AuthorizePublish(ctx context.Context, user models.User, slug string) error {
+	ret := _mock.Called(ctx, user, slug)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AuthorizePublish")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, models.User, string) error); ok {
+		r0 = returnFunc(ctx, user, slug)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockAuthorizer_AuthorizePublish_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'AuthorizePublish'
+type MockAuthorizer_AuthorizePublish_Call struct {
+	*mock.Call
+}
+
+// AuthorizePublish is a helper method to define mock.On call
+//   - ctx context.Context
+//   - user models.User
+//   - slug string
+func (_e *MockAuthorizer_Expecter) AuthorizePublish(ctx interface{}, user interface{}, slug interface{}) *MockAuthorizer_AuthorizePublish_Call {
+	return &MockAuthorizer_AuthorizePublish_Call{Call: _e.mock.On("AuthorizePublish", ctx, user, slug)}
+}
+
+func (_c *MockAuthorizer_AuthorizePublish_Call) Run(run func(ctx context.Context, user models.User, slug string)) *MockAuthorizer_AuthorizePublish_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 models.User
+		if args[1] != nil {
+			arg1 = args[1].(models.User)
+		}
+		var arg2 string
+		if args[2] != nil {
+			arg2 = args[2].(string)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockAuthorizer_AuthorizePublish_Call) Return(err error) *MockAuthorizer_AuthorizePublish_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockAuthorizer_AuthorizePublish_Call) RunAndReturn(run func(ctx context.Context, user models.User, slug string) error) *MockAuthorizer_AuthorizePublish_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// AuthorizeSection provides a mock function for the type MockAuthorizer
+func (_mock *MockAuthorizer) AuthorizeSection(ctx context.Context, user models.User, action authz.Action, slug string) error {
+	ret := _mock.Called(ctx, user, action, slug)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AuthorizeSection")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, models.User, authz.Action, string) error); ok {
+		r0 = returnFunc(ctx, user, action, slug)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockAuthorizer_AuthorizeSection_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'AuthorizeSection'
+type MockAuthorizer_AuthorizeSection_Call struct {
+	*mock.Call
+}
+
+// AuthorizeSection is a helper method to define mock.On call
+//   - ctx context.Context
+//   - user models.User
+//   - action authz.Action
+//   - slug string
+func (_e *MockAuthorizer_Expecter) AuthorizeSection(ctx interface{}, user interface{}, action interface{}, slug interface{}) *MockAuthorizer_AuthorizeSection_Call {
+	return &MockAuthorizer_AuthorizeSection_Call{Call: _e.mock.On("AuthorizeSection", ctx, user, action, slug)}
+}
+
+func (_c *MockAuthorizer_AuthorizeSection_Call) Run(run func(ctx context.Context, user models.User, action authz.Action, slug string)) *MockAuthorizer_AuthorizeSection_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 models.User
+		if args[1] != nil {
+			arg1 = args[1].(models.User)
+		}
+		var arg2 authz.Action
+		if args[2] != nil {
+			arg2 = args[2].(authz.Action)
+		}
+		var arg3 string
+		if args[3] != nil {
+			arg3 = args[3].(string)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+			arg3,
+		)
+	})
+	return _c
+}
+
+func (_c *MockAuthorizer_AuthorizeSection_Call) Return(err error) *MockAuthorizer_AuthorizeSection_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockAuthorizer_AuthorizeSection_Call) RunAndReturn(run func(ctx context.Context, user models.User, action authz.Action, slug string) error) *MockAuthorizer_AuthorizeSection_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ReachedSections provides a mock function for the type MockAuthorizer
+func (_mock *MockAuthorizer) ReachedSections(ctx context.Context, user models.User, action authz.Action) ([]string, bool, error) {
+	ret := _mock.Called(ctx, user, action)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ReachedSections")
+	}
+
+	var r0 []string
+	var r1 bool
+	var r2 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, models.User, authz.Action) ([]string, bool, error)); ok {
+		return returnFunc(ctx, user, action)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, models.User, authz.Action) []string); ok {
+		r0 = returnFunc(ctx, user, action)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, models.User, authz.Action) bool); ok {
+		r1 = returnFunc(ctx, user, action)
+	} else {
+		r1 = ret.Get(1).(bool)
+	}
+	if returnFunc, ok := ret.Get(2).(func(context.Context, models.User, authz.Action) error); ok {
+		r2 = returnFunc(ctx, user, action)
+	} else {
+		r2 = ret.Error(2)
+	}
+	return r0, r1, r2
+}
+
+// MockAuthorizer_ReachedSections_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ReachedSections'
+type MockAuthorizer_ReachedSections_Call struct {
+	*mock.Call
+}
+
+// ReachedSections is a helper method to define mock.On call
+//   - ctx context.Context
+//   - user models.User
+//   - action authz.Action
+func (_e *MockAuthorizer_Expecter) ReachedSections(ctx interface{}, user interface{}, action interface{}) *MockAuthorizer_ReachedSections_Call {
+	return &MockAuthorizer_ReachedSections_Call{Call: _e.mock.On("ReachedSections", ctx, user, action)}
+}
+
+func (_c *MockAuthorizer_ReachedSections_Call) Run(run func(ctx context.Context, user models.User, action authz.Action)) *MockAuthorizer_ReachedSections_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 models.User
+		if args[1] != nil {
+			arg1 = args[1].(models.User)
+		}
+		var arg2 authz.Action
+		if args[2] != nil {
+			arg2 = args[2].(authz.Action)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockAuthorizer_ReachedSections_Call) Return(slugs []string, all bool, err error) *MockAuthorizer_ReachedSections_Call {
+	_c.Call.Return(slugs, all, err)
+	return _c
+}
+
+func (_c *MockAuthorizer_ReachedSections_Call) RunAndReturn(run func(ctx context.Context, user models.User, action authz.Action) ([]string, bool, error)) *MockAuthorizer_ReachedSections_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockCommenter creates a new instance of MockCommenter. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockCommenter(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockCommenter {
+	mock := &MockCommenter{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// MockCommenter is an autogenerated mock type for the Commenter type
+type MockCommenter struct {
+	mock.Mock
+}
+
+type MockCommenter_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockCommenter) EXPECT() *MockCommenter_Expecter {
+	return &MockCommenter_Expecter{mock: &_m.Mock}
+}
+
+// DeleteComment provides a mock function for the type MockCommenter
+func (_mock *MockCommenter) DeleteComment(ctx context.Context, id int) error {
+	ret := _mock.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteComment")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int) error); ok {
+		r0 = returnFunc(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockCommenter_DeleteComment_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteComment'
+type MockCommenter_DeleteComment_Call struct {
+	*mock.Call
+}
+
+// DeleteComment is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id int
+func (_e *MockCommenter_Expecter) DeleteComment(ctx interface{}, id interface{}) *MockCommenter_DeleteComment_Call {
+	return &MockCommenter_DeleteComment_Call{Call: _e.mock.On("DeleteComment", ctx, id)}
+}
+
+func (_c *MockCommenter_DeleteComment_Call) Run(run func(ctx context.Context, id int)) *MockCommenter_DeleteComment_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 int
+		if args[1] != nil {
+			arg1 = args[1].(int)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockCommenter_DeleteComment_Call) Return(err error) *MockCommenter_DeleteComment_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockCommenter_DeleteComment_Call) RunAndReturn(run func(ctx context.Context, id int) error) *MockCommenter_DeleteComment_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetComment provides a mock function for the type MockCommenter
+func (_mock *MockCommenter) GetComment(ctx context.Context, id int) (models.Comment, error) {
+	ret := _mock.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetComment")
+	}
+
+	var r0 models.Comment
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int) (models.Comment, error)); ok {
+		return returnFunc(ctx, id)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int) models.Comment); ok {
+		r0 = returnFunc(ctx, id)
+	} else {
+		r0 = ret.Get(0).(models.Comment)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = returnFunc(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockCommenter_GetComment_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetComment'
+type MockCommenter_GetComment_Call struct {
+	*mock.Call
+}
+
+// GetComment is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id int
+func (_e *MockCommenter_Expecter) GetComment(ctx interface{}, id interface{}) *MockCommenter_GetComment_Call {
+	return &MockCommenter_GetComment_Call{Call: _e.mock.On("GetComment", ctx, id)}
+}
+
+func (_c *MockCommenter_GetComment_Call) Run(run func(ctx context.Context, id int)) *MockCommenter_GetComment_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 int
+		if args[1] != nil {
+			arg1 = args[1].(int)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockCommenter_GetComment_Call) Return(comment models.Comment, err error) *MockCommenter_GetComment_Call {
+	_c.Call.Return(comment, err)
+	return _c
+}
+
+func (_c *MockCommenter_GetComment_Call) RunAndReturn(run func(ctx context.Context, id int) (models.Comment, error)) *MockCommenter_GetComment_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetCommentQueue provides a mock function for the type MockCommenter
+func (_mock *MockCommenter) GetCommentQueue(ctx context.Context, sections []string) ([]models.Comment, error) {
+	ret := _mock.Called(ctx, sections)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetCommentQueue")
+	}
+
+	var r0 []models.Comment
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, []string) ([]models.Comment, error)); ok {
+		return returnFunc(ctx, sections)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, []string) []models.Comment); ok {
+		r0 = returnFunc(ctx, sections)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.Comment)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, []string) error); ok {
+		r1 = returnFunc(ctx, sections)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockCommenter_GetCommentQueue_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetCommentQueue'
+type MockCommenter_GetCommentQueue_Call struct {
+	*mock.Call
+}
+
+// GetCommentQueue is a helper method to define mock.On call
+//   - ctx context.Context
+//   - sections []string
+func (_e *MockCommenter_Expecter) GetCommentQueue(ctx interface{}, sections interface{}) *MockCommenter_GetCommentQueue_Call {
+	return &MockCommenter_GetCommentQueue_Call{Call: _e.mock.On("GetCommentQueue", ctx, sections)}
+}
+
+func (_c *MockCommenter_GetCommentQueue_Call) Run(run func(ctx context.Context, sections []string)) *MockCommenter_GetCommentQueue_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 []string
+		if args[1] != nil {
+			arg1 = args[1].([]string)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockCommenter_GetCommentQueue_Call) Return(comments []models.Comment, err error) *MockCommenter_GetCommentQueue_Call {
+	_c.Call.Return(comments, err)
+	return _c
+}
+
+func (_c *MockCommenter_GetCommentQueue_Call) RunAndReturn(run func(ctx context.Context, sections []string) ([]models.Comment, error)) *MockCommenter_GetCommentQueue_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetComments provides a mock function for the type MockCommenter
+func (_mock *MockCommenter) GetComments(ctx context.Context, postID int) ([]models.Comment, error) {
+	ret := _mock.Called(ctx, postID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetComments")
+	}
+
+	var r0 []models.Comment
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int) ([]models.Comment, error)); ok {
+		return returnFunc(ctx, postID)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int) []models.Comment); ok {
+		r0 = returnFunc(ctx, postID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.Comment)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = returnFunc(ctx, postID)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockCommenter_GetComments_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetComments'
+type MockCommenter_GetComments_Call struct {
+	*mock.Call
+}
+
+// GetComments is a helper method to define mock.On call
+//   - ctx context.Context
+//   - postID int
+func (_e *MockCommenter_Expecter) GetComments(ctx interface{}, postID interface{}) *MockCommenter_GetComments_Call {
+	return &MockCommenter_GetComments_Call{Call: _e.mock.On("GetComments", ctx, postID)}
+}
+
+func (_c *MockCommenter_GetComments_Call) Run(run func(ctx context.Context, postID int)) *MockCommenter_GetComments_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 int
+		if args[1] != nil {
+			arg1 = args[1].(int)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockCommenter_GetComments_Call) Return(comments []models.Comment, err error) *MockCommenter_GetComments_Call {
+	_c.Call.Return(comments, err)
+	return _c
+}
+
+func (_c *MockCommenter_GetComments_Call) RunAndReturn(run func(ctx context.Context, postID int) ([]models.Comment, error)) *MockCommenter_GetComments_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// PatchComment provides a mock function for the type MockCommenter
+func (_mock *MockCommenter) PatchComment(ctx context.Context, id int, patch models.CommentPatch) (models.Comment, error) {
+	ret := _mock.Called(ctx, id, patch)
+
+	if len(ret) == 0 {
+		panic("no return value specified for PatchComment")
+	}
+
+	var r0 models.Comment
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int, models.CommentPatch) (models.Comment, error)); ok {
+		return returnFunc(ctx, id, patch)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int, models.CommentPatch) models.Comment); ok {
+		r0 = returnFunc(ctx, id, patch)
+	} else {
+		r0 = ret.Get(0).(models.Comment)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, int, models.CommentPatch) error); ok {
+		r1 = returnFunc(ctx, id, patch)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockCommenter_PatchComment_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'PatchComment'
+type MockCommenter_PatchComment_Call struct {
+	*mock.Call
+}
+
+// PatchComment is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id int
+//   - patch models.CommentPatch
+func (_e *MockCommenter_Expecter) PatchComment(ctx interface{}, id interface{}, patch interface{}) *MockCommenter_PatchComment_Call {
+	return &MockCommenter_PatchComment_Call{Call: _e.mock.On("PatchComment", ctx, id, patch)}
+}
+
+func (_c *MockCommenter_PatchComment_Call) Run(run func(ctx context.Context, id int, patch models.CommentPatch)) *MockCommenter_PatchComment_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 int
+		if args[1] != nil {
+			arg1 = args[1].(int)
+		}
+		var arg2 models.CommentPatch
+		if args[2] != nil {
+			arg2 = args[2].(models.CommentPatch)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockCommenter_PatchComment_Call) Return(comment models.Comment, err error) *MockCommenter_PatchComment_Call {
+	_c.Call.Return(comment, err)
+	return _c
+}
+
+func (_c *MockCommenter_PatchComment_Call) RunAndReturn(run func(ctx context.Context, id int, patch models.CommentPatch) (models.Comment, error)) *MockCommenter_PatchComment_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SaveComment provides a mock function for the type MockCommenter
+func (_mock *MockCommenter) SaveComment(ctx context.Context, comment models.InputComment) (models.Comment, error) {
+	ret := _mock.Called(ctx, comment)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveComment")
+	}
+
+	var r0 models.Comment
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, models.InputComment) (models.Comment, error)); ok {
+		return returnFunc(ctx, comment)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, models.InputComment) models.Comment); ok {
+		r0 = returnFunc(ctx, comment)
+	} else {
+		r0 = ret.Get(0).(models.Comment)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, models.InputComment) error); ok {
+		r1 = returnFunc(ctx, comment)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockCommenter_SaveComment_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SaveComment'
+type MockCommenter_SaveComment_Call struct {
+	*mock.Call
+}
+
+// SaveComment is a helper method to define mock.On call
+//   - ctx context.Context
+//   - comment models.InputComment
+func (_e *MockCommenter_Expecter) SaveComment(ctx interface{}, comment interface{}) *MockCommenter_SaveComment_Call {
+	return &MockCommenter_SaveComment_Call{Call: _e.mock.On("SaveComment", ctx, comment)}
+}
+
+func (_c *MockCommenter_SaveComment_Call) Run(run func(ctx context.Context, comment models.InputComment)) *MockCommenter_SaveComment_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 models.InputComment
+		if args[1] != nil {
+			arg1 = args[1].(models.InputComment)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockCommenter_SaveComment_Call) Return(comment models.Comment, err error) *MockCommenter_SaveComment_Call {
+	_c.Call.Return(comment, err)
+	return _c
+}
+
+func (_c *MockCommenter_SaveComment_Call) RunAndReturn(run func(ctx context.Context, comment models.InputComment) (models.Comment, error)) *MockCommenter_SaveComment_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockKeyManager creates a new instance of MockKeyManager. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockKeyManager(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockKeyManager {
+	mock := &MockKeyManager{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// MockKeyManager is an autogenerated mock type for the KeyManager type
+type MockKeyManager struct {
+	mock.Mock
+}
+
+type MockKeyManager_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockKeyManager) EXPECT() *MockKeyManager_Expecter {
+	return &MockKeyManager_Expecter{mock: &_m.Mock}
+}
+
+// DeleteAPIKey provides a mock function for the type MockKeyManager
+func (_mock *MockKeyManager) DeleteAPIKey(ctx context.Context, id int) error {
+	ret := _mock.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteAPIKey")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int) error); ok {
+		r0 = returnFunc(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockKeyManager_DeleteAPIKey_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteAPIKey'
+type MockKeyManager_DeleteAPIKey_Call struct {
+	*mock.Call
+}
+
+// DeleteAPIKey is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id int
+func (_e *MockKeyManager_Expecter) DeleteAPIKey(ctx interface{}, id interface{}) *MockKeyManager_DeleteAPIKey_Call {
+	return &MockKeyManager_DeleteAPIKey_Call{Call: _e.mock.On("DeleteAPIKey", ctx, id)}
+}
+
+func (_c *MockKeyManager_DeleteAPIKey_Call) Run(run func(ctx context.Context, id int)) *MockKeyManager_DeleteAPIKey_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 int
+		if args[1] != nil {
+			arg1 = args[1].(int)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockKeyManager_DeleteAPIKey_Call) Return(err error) *MockKeyManager_DeleteAPIKey_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockKeyManager_DeleteAPIKey_Call) RunAndReturn(run func(ctx context.Context, id int) error) *MockKeyManager_DeleteAPIKey_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetAPIKey provides a mock function for the type MockKeyManager
+func (_mock *MockKeyManager) GetAPIKey(ctx context.Context, id int) (models.APIKey, error) {
+	ret := _mock.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAPIKey")
+	}
+
+	var r0 models.APIKey
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int) (models.APIKey, error)); ok {
+		return returnFunc(ctx, id)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int) models.APIKey); ok {
+		r0 = returnFunc(ctx, id)
+	} else {
+		r0 = ret.Get(0).(models.APIKey)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = returnFunc(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockKeyManager_GetAPIKey_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetAPIKey'
+type MockKeyManager_GetAPIKey_Call struct {
+	*mock.Call
+}
+
+// GetAPIKey is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id int
+func (_e *MockKeyManager_Expecter) GetAPIKey(ctx interface{}, id interface{}) *MockKeyManager_GetAPIKey_Call {
+	return &MockKeyManager_GetAPIKey_Call{Call: _e.mock.On("GetAPIKey", ctx, id)}
+}
+
+func (_c *MockKeyManager_GetAPIKey_Call) Run(run func(ctx context.Context, id int)) *MockKeyManager_GetAPIKey_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 int
+		if args[1] != nil {
+			arg1 = args[1].(int)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockKeyManager_GetAPIKey_Call) Return(apiKey models.APIKey, err error) *MockKeyManager_GetAPIKey_Call {
+	_c.Call.Return(apiKey, err)
+	return _c
+}
+
+func (_c *MockKeyManager_GetAPIKey_Call) RunAndReturn(run func(ctx context.Context, id int) (models.APIKey, error)) *MockKeyManager_GetAPIKey_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetAPIKeys provides a mock function for the type MockKeyManager
+func (_mock *MockKeyManager) GetAPIKeys(ctx context.Context) ([]models.APIKey, error) {
+	ret := _mock.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAPIKeys")
+	}
+
+	var r0 []models.APIKey
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context) ([]models.APIKey, error)); ok {
+		return returnFunc(ctx)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context) []models.APIKey); ok {
+		r0 = returnFunc(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.APIKey)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = returnFunc(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockKeyManager_GetAPIKeys_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetAPIKeys'
+type MockKeyManager_GetAPIKeys_Call struct {
+	*mock.Call
+}
+
+// GetAPIKeys is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *MockKeyManager_Expecter) GetAPIKeys(ctx interface{}) *MockKeyManager_GetAPIKeys_Call {
+	return &MockKeyManager_GetAPIKeys_Call{Call: _e.mock.On("GetAPIKeys", ctx)}
+}
+
+func (_c *MockKeyManager_GetAPIKeys_Call) Run(run func(ctx context.Context)) *MockKeyManager_GetAPIKeys_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		run(
+			arg0,
+		)
+	})
+	return _c
+}
+
+func (_c *MockKeyManager_GetAPIKeys_Call) Return(apiKeys []models.APIKey, err error) *MockKeyManager_GetAPIKeys_Call {
+	_c.Call.Return(apiKeys, err)
+	return _c
+}
+
+func (_c *MockKeyManager_GetAPIKeys_Call) RunAndReturn(run func(ctx context.Context) ([]models.APIKey, error)) *MockKeyManager_GetAPIKeys_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RotateAPIKey provides a mock function for the type MockKeyManager
+func (_mock *MockKeyManager) RotateAPIKey(ctx context.Context, id int, prefix string, hash string) (models.APIKey, error) {
+	ret := _mock.Called(ctx, id, prefix, hash)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RotateAPIKey")
+	}
+
+	var r0 models.APIKey
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int, string, string) (models.APIKey, error)); ok {
+		return returnFunc(ctx, id, prefix, hash)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int, string, string) models.APIKey); ok {
+		r0 = returnFunc(ctx, id, prefix, hash)
+	} else {
+		r0 = ret.Get(0).(models.APIKey)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, int, string, string) error); ok {
+		r1 = returnFunc(ctx, id, prefix, hash)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockKeyManager_RotateAPIKey_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RotateAPIKey'
+type MockKeyManager_RotateAPIKey_Call struct {
+	*mock.Call
+}
+
+// RotateAPIKey is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id int
+//   - prefix string
+//   - hash string
+func (_e *MockKeyManager_Expecter) RotateAPIKey(ctx interface{}, id interface{}, prefix interface{}, hash interface{}) *MockKeyManager_RotateAPIKey_Call {
+	return &MockKeyManager_RotateAPIKey_Call{Call: _e.mock.On("RotateAPIKey", ctx, id, prefix, hash)}
+}
+
+func (_c *MockKeyManager_RotateAPIKey_Call) Run(run func(ctx context.Context, id int, prefix string, hash string)) *MockKeyManager_RotateAPIKey_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 int
+		if args[1] != nil {
+			arg1 = args[1].(int)
+		}
+		var arg2 string
+		if args[2] != nil {
+			arg2 = args[2].(string)
+		}
+		var arg3 string
+		if args[3] != nil {
+			arg3 = args[3].(string)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+			arg3,
+		)
+	})
+	return _c
+}
+
+func (_c *MockKeyManager_RotateAPIKey_Call) Return(apiKey models.APIKey, err error) *MockKeyManager_RotateAPIKey_Call {
+	_c.Call.Return(apiKey, err)
+	return _c
+}
+
+func (_c *MockKeyManager_RotateAPIKey_Call) RunAndReturn(run func(ctx context.Context, id int, prefix string, hash string) (models.APIKey, error)) *MockKeyManager_RotateAPIKey_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SaveAPIKey provides a mock function for the type MockKeyManager
+func (_mock *MockKeyManager) SaveAPIKey(ctx context.Context, key models.InputAPIKey) (models.APIKey, error) {
+	ret := _mock.Called(ctx, key)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveAPIKey")
+	}
+
+	var r0 models.APIKey
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, models.InputAPIKey) (models.APIKey, error)); ok {
+		return returnFunc(ctx, key)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, models.InputAPIKey) models.APIKey); ok {
+		r0 = returnFunc(ctx, key)
+	} else {
+		r0 = ret.Get(0).(models.APIKey)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, models.InputAPIKey) error); ok {
+		r1 = returnFunc(ctx, key)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockKeyManager_SaveAPIKey_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SaveAPIKey'
+type MockKeyManager_SaveAPIKey_Call struct {
+	*mock.Call
+}
+
+// SaveAPIKey is a helper method to define mock.On call
+//   - ctx context.Context
+//   - key models.InputAPIKey
+func (_e *MockKeyManager_Expecter) SaveAPIKey(ctx interface{}, key interface{}) *MockKeyManager_SaveAPIKey_Call {
+	return &MockKeyManager_SaveAPIKey_Call{Call: _e.mock.On("SaveAPIKey", ctx, key)}
+}
+
+func (_c *MockKeyManager_SaveAPIKey_Call) Run(run func(ctx context.Context, key models.InputAPIKey)) *MockKeyManager_SaveAPIKey_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 models.InputAPIKey
+		if args[1] != nil {
+			arg1 = args[1].(models.InputAPIKey)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockKeyManager_SaveAPIKey_Call) Return(apiKey models.APIKey, err error) *MockKeyManager_SaveAPIKey_Call {
+	_c.Call.Return(apiKey, err)
+	return _c
+}
+
+func (_c *MockKeyManager_SaveAPIKey_Call) RunAndReturn(run func(ctx context.Context, key models.InputAPIKey) (models.APIKey, error)) *MockKeyManager_SaveAPIKey_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // NewMockPoster creates a new instance of MockPoster. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
-func NewMockPoster(t interface {
+func NewMockPoster(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockPoster {
+	mock := &MockPoster{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// MockPoster is an autogenerated mock type for the Poster type
+type MockPoster struct {
+	mock.Mock
+}
+
+type MockPoster_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockPoster) EXPECT() *MockPoster_Expecter {
+	return &MockPoster_Expecter{mock: &_m.Mock}
+}
+
+// ApplyPatch provides a mock function for the type MockPoster
+func (_mock *MockPoster) ApplyPatch(ctx context.Context, id int, version int, editorID int, ops []jsonpatch.Operation) (models.OutputPost, error) {
+	ret := _mock.Called(ctx, id, version, editorID, ops)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ApplyPatch")
+	}
+
+	var r0 models.OutputPost
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int, int, int, []jsonpatch.Operation) (models.OutputPost, error)); ok {
+		return returnFunc(ctx, id, version, editorID, ops)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int, int, int, []jsonpatch.Operation) models.OutputPost); ok {
+		r0 = returnFunc(ctx, id, version, editorID, ops)
+	} else {
+		r0 = ret.Get(0).(models.OutputPost)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, int, int, int, []jsonpatch.Operation) error); ok {
+		r1 = returnFunc(ctx, id, version, editorID, ops)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockPoster_ApplyPatch_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ApplyPatch'
+type MockPoster_ApplyPatch_Call struct {
+	*mock.Call
+}
+
+// ApplyPatch is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id int
+//   - version int
+//   - editorID int
+//   - ops []jsonpatch.Operation
+func (_e *MockPoster_Expecter) ApplyPatch(ctx interface{}, id interface{}, version interface{}, editorID interface{}, ops interface{}) *MockPoster_ApplyPatch_Call {
+	return &MockPoster_ApplyPatch_Call{Call: _e.mock.On("ApplyPatch", ctx, id, version, editorID, ops)}
+}
+
+func (_c *MockPoster_ApplyPatch_Call) Run(run func(ctx context.Context, id int, version int, editorID int, ops []jsonpatch.Operation)) *MockPoster_ApplyPatch_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 int
+		if args[1] != nil {
+			arg1 = args[1].(int)
+		}
+		var arg2 int
+		if args[2] != nil {
+			arg2 = args[2].(int)
+		}
+		var arg3 int
+		if args[3] != nil {
+			arg3 = args[3].(int)
+		}
+		var arg4 []jsonpatch.Operation
+		if args[4] != nil {
+			arg4 = args[4].([]jsonpatch.Operation)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+			arg3,
+			arg4,
+		)
+	})
+	return _c
+}
+
+func (_c *MockPoster_ApplyPatch_Call) Return(outputPost models.OutputPost, err error) *MockPoster_ApplyPatch_Call {
+	_c.Call.Return(outputPost, err)
+	return _c
+}
+
+func (_c *MockPoster_ApplyPatch_Call) RunAndReturn(run func(ctx context.Context, id int, version int, editorID int, ops []jsonpatch.Operation) (models.OutputPost, error)) *MockPoster_ApplyPatch_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeletePost provides a mock function for the type MockPoster
+func (_mock *MockPoster) DeletePost(ctx context.Context, id int, version int) error {
+	ret := _mock.Called(ctx, id, version)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeletePost")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int, int) error); ok {
+		r0 = returnFunc(ctx, id, version)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockPoster_DeletePost_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeletePost'
+type MockPoster_DeletePost_Call struct {
+	*mock.Call
+}
+
+// DeletePost is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id int
+//   - version int
+func (_e *MockPoster_Expecter) DeletePost(ctx interface{}, id interface{}, version interface{}) *MockPoster_DeletePost_Call {
+	return &MockPoster_DeletePost_Call{Call: _e.mock.On("DeletePost", ctx, id, version)}
+}
+
+func (_c *MockPoster_DeletePost_Call) Run(run func(ctx context.Context, id int, version int)) *MockPoster_DeletePost_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 int
+		if args[1] != nil {
+			arg1 = args[1].(int)
+		}
+		var arg2 int
+		if args[2] != nil {
+			arg2 = args[2].(int)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockPoster_DeletePost_Call) Return(err error) *MockPoster_DeletePost_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockPoster_DeletePost_Call) RunAndReturn(run func(ctx context.Context, id int, version int) error) *MockPoster_DeletePost_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetAllPosts provides a mock function for the type MockPoster
+func (_mock *MockPoster) GetAllPosts(ctx context.Context, filter models.PostFilter, page models.Page) (models.PostsPage, error) {
+	ret := _mock.Called(ctx, filter, page)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAllPosts")
+	}
+
+	var r0 models.PostsPage
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, models.PostFilter, models.Page) (models.PostsPage, error)); ok {
+		return returnFunc(ctx, filter, page)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, models.PostFilter, models.Page) models.PostsPage); ok {
+		r0 = returnFunc(ctx, filter, page)
+	} else {
+		r0 = ret.Get(0).(models.PostsPage)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, models.PostFilter, models.Page) error); ok {
+		r1 = returnFunc(ctx, filter, page)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockPoster_GetAllPosts_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetAllPosts'
+type MockPoster_GetAllPosts_Call struct {
+	*mock.Call
+}
+
+// GetAllPosts is a helper method to define mock.On call
+//   - ctx context.Context
+//   - filter models.PostFilter
+//   - page models.Page
+func (_e *MockPoster_Expecter) GetAllPosts(ctx interface{}, filter interface{}, page interface{}) *MockPoster_GetAllPosts_Call {
+	return &MockPoster_GetAllPosts_Call{Call: _e.mock.On("GetAllPosts", ctx, filter, page)}
+}
+
+func (_c *MockPoster_GetAllPosts_Call) Run(run func(ctx context.Context, filter models.PostFilter, page models.Page)) *MockPoster_GetAllPosts_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 models.PostFilter
+		if args[1] != nil {
+			arg1 = args[1].(models.PostFilter)
+		}
+		var arg2 models.Page
+		if args[2] != nil {
+			arg2 = args[2].(models.Page)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockPoster_GetAllPosts_Call) Return(postsPage models.PostsPage, err error) *MockPoster_GetAllPosts_Call {
+	_c.Call.Return(postsPage, err)
+	return _c
+}
+
+func (_c *MockPoster_GetAllPosts_Call) RunAndReturn(run func(ctx context.Context, filter models.PostFilter, page models.Page) (models.PostsPage, error)) *MockPoster_GetAllPosts_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetPost provides a mock function for the type MockPoster
+func (_mock *MockPoster) GetPost(ctx context.Context, id int) (models.OutputPost, error) {
+	ret := _mock.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetPost")
+	}
+
+	var r0 models.OutputPost
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int) (models.OutputPost, error)); ok {
+		return returnFunc(ctx, id)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int) models.OutputPost); ok {
+		r0 = returnFunc(ctx, id)
+	} else {
+		r0 = ret.Get(0).(models.OutputPost)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = returnFunc(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockPoster_GetPost_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetPost'
+type MockPoster_GetPost_Call struct {
+	*mock.Call
+}
+
+// GetPost is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id int
+func (_e *MockPoster_Expecter) GetPost(ctx interface{}, id interface{}) *MockPoster_GetPost_Call {
+	return &MockPoster_GetPost_Call{Call: _e.mock.On("GetPost", ctx, id)}
+}
+
+func (_c *MockPoster_GetPost_Call) Run(run func(ctx context.Context, id int)) *MockPoster_GetPost_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 int
+		if args[1] != nil {
+			arg1 = args[1].(int)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockPoster_GetPost_Call) Return(outputPost models.OutputPost, err error) *MockPoster_GetPost_Call {
+	_c.Call.Return(outputPost, err)
+	return _c
+}
+
+func (_c *MockPoster_GetPost_Call) RunAndReturn(run func(ctx context.Context, id int) (models.OutputPost, error)) *MockPoster_GetPost_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetPostBySlug provides a mock function for the type MockPoster
+func (_mock *MockPoster) GetPostBySlug(ctx context.Context, slug string) (models.OutputPost, error) {
+	ret := _mock.Called(ctx, slug)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetPostBySlug")
+	}
+
+	var r0 models.OutputPost
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) (models.OutputPost, error)); ok {
+		return returnFunc(ctx, slug)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) models.OutputPost); ok {
+		r0 = returnFunc(ctx, slug)
+	} else {
+		r0 = ret.Get(0).(models.OutputPost)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = returnFunc(ctx, slug)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockPoster_GetPostBySlug_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetPostBySlug'
+type MockPoster_GetPostBySlug_Call struct {
+	*mock.Call
+}
+
+// GetPostBySlug is a helper method to define mock.On call
+//   - ctx context.Context
+//   - slug string
+func (_e *MockPoster_Expecter) GetPostBySlug(ctx interface{}, slug interface{}) *MockPoster_GetPostBySlug_Call {
+	return &MockPoster_GetPostBySlug_Call{Call: _e.mock.On("GetPostBySlug", ctx, slug)}
+}
+
+func (_c *MockPoster_GetPostBySlug_Call) Run(run func(ctx context.Context, slug string)) *MockPoster_GetPostBySlug_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockPoster_GetPostBySlug_Call) Return(outputPost models.OutputPost, err error) *MockPoster_GetPostBySlug_Call {
+	_c.Call.Return(outputPost, err)
+	return _c
+}
+
+func (_c *MockPoster_GetPostBySlug_Call) RunAndReturn(run func(ctx context.Context, slug string) (models.OutputPost, error)) *MockPoster_GetPostBySlug_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// PatchPost provides a mock function for the type MockPoster
+func (_mock *MockPoster) PatchPost(ctx context.Context, id int, version int, patch models.PostPatch) (models.OutputPost, error) {
+	ret := _mock.Called(ctx, id, version, patch)
+
+	if len(ret) == 0 {
+		panic("no return value specified for PatchPost")
+	}
+
+	var r0 models.OutputPost
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int, int, models.PostPatch) (models.OutputPost, error)); ok {
+		return returnFunc(ctx, id, version, patch)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int, int, models.PostPatch) models.OutputPost); ok {
+		r0 = returnFunc(ctx, id, version, patch)
+	} else {
+		r0 = ret.Get(0).(models.OutputPost)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, int, int, models.PostPatch) error); ok {
+		r1 = returnFunc(ctx, id, version, patch)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockPoster_PatchPost_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'PatchPost'
+type MockPoster_PatchPost_Call struct {
+	*mock.Call
+}
+
+// PatchPost is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id int
+//   - version int
+//   - patch models.PostPatch
+func (_e *MockPoster_Expecter) PatchPost(ctx interface{}, id interface{}, version interface{}, patch interface{}) *MockPoster_PatchPost_Call {
+	return &MockPoster_PatchPost_Call{Call: _e.mock.On("PatchPost", ctx, id, version, patch)}
+}
+
+func (_c *MockPoster_PatchPost_Call) Run(run func(ctx context.Context, id int, version int, patch models.PostPatch)) *MockPoster_PatchPost_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 int
+		if args[1] != nil {
+			arg1 = args[1].(int)
+		}
+		var arg2 int
+		if args[2] != nil {
+			arg2 = args[2].(int)
+		}
+		var arg3 models.PostPatch
+		if args[3] != nil {
+			arg3 = args[3].(models.PostPatch)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+			arg3,
+		)
+	})
+	return _c
+}
+
+func (_c *MockPoster_PatchPost_Call) Return(outputPost models.OutputPost, err error) *MockPoster_PatchPost_Call {
+	_c.Call.Return(outputPost, err)
+	return _c
+}
+
+func (_c *MockPoster_PatchPost_Call) RunAndReturn(run func(ctx context.Context, id int, version int, patch models.PostPatch) (models.OutputPost, error)) *MockPoster_PatchPost_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SavePost provides a mock function for the type MockPoster
+func (_mock *MockPoster) SavePost(ctx context.Context, post models.InputPost) (models.OutputPost, error) {
+	ret := _mock.Called(ctx, post)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SavePost")
+	}
+
+	var r0 models.OutputPost
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, models.InputPost) (models.OutputPost, error)); ok {
+		return returnFunc(ctx, post)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, models.InputPost) models.OutputPost); ok {
+		r0 = returnFunc(ctx, post)
+	} else {
+		r0 = ret.Get(0).(models.OutputPost)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, models.InputPost) error); ok {
+		r1 = returnFunc(ctx, post)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockPoster_SavePost_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SavePost'
+type MockPoster_SavePost_Call struct {
+	*mock.Call
+}
+
+// SavePost is a helper method to define mock.On call
+//   - ctx context.Context
+//   - post models.InputPost
+func (_e *MockPoster_Expecter) SavePost(ctx interface{}, post interface{}) *MockPoster_SavePost_Call {
+	return &MockPoster_SavePost_Call{Call: _e.mock.On("SavePost", ctx, post)}
+}
+
+func (_c *MockPoster_SavePost_Call) Run(run func(ctx context.Context, post models.InputPost)) *MockPoster_SavePost_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 models.InputPost
+		if args[1] != nil {
+			arg1 = args[1].(models.InputPost)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockPoster_SavePost_Call) Return(outputPost models.OutputPost, err error) *MockPoster_SavePost_Call {
+	_c.Call.Return(outputPost, err)
+	return _c
+}
+
+func (_c *MockPoster_SavePost_Call) RunAndReturn(run func(ctx context.Context, post models.InputPost) (models.OutputPost, error)) *MockPoster_SavePost_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SearchPosts provides a mock function for the type MockPoster
+func (_mock *MockPoster) SearchPosts(ctx context.Context, query string, limit int) ([]models.SearchResult, error) {
+	ret := _mock.Called(ctx, query, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SearchPosts")
+	}
+
+	var r0 []models.SearchResult
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, int) ([]models.SearchResult, error)); ok {
+		return returnFunc(ctx, query, limit)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, int) []models.SearchResult); ok {
+		r0 = returnFunc(ctx, query, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.SearchResult)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string, int) error); ok {
+		r1 = returnFunc(ctx, query, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockPoster_SearchPosts_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SearchPosts'
+type MockPoster_SearchPosts_Call struct {
+	*mock.Call
+}
+
+// SearchPosts is a helper method to define mock.On call
+//   - ctx context.Context
+//   - query string
+//   - limit int
+func (_e *MockPoster_Expecter) SearchPosts(ctx interface{}, query interface{}, limit interface{}) *MockPoster_SearchPosts_Call {
+	return &MockPoster_SearchPosts_Call{Call: _e.mock.On("SearchPosts", ctx, query, limit)}
+}
+
+func (_c *MockPoster_SearchPosts_Call) Run(run func(ctx context.Context, query string, limit int)) *MockPoster_SearchPosts_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		var arg2 int
+		if args[2] != nil {
+			arg2 = args[2].(int)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockPoster_SearchPosts_Call) Return(searchResults []models.SearchResult, err error) *MockPoster_SearchPosts_Call {
+	_c.Call.Return(searchResults, err)
+	return _c
+}
+
+func (_c *MockPoster_SearchPosts_Call) RunAndReturn(run func(ctx context.Context, query string, limit int) ([]models.SearchResult, error)) *MockPoster_SearchPosts_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockReactor creates a new instance of MockReactor. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockReactor(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockReactor {
+	mock := &MockReactor{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// MockReactor is an autogenerated mock type for the Reactor type
+type MockReactor struct {
+	mock.Mock
+}
+
+type MockReactor_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockReactor) EXPECT() *MockReactor_Expecter {
+	return &MockReactor_Expecter{mock: &_m.Mock}
+}
+
+// DeleteReaction provides a mock function for the type MockReactor
+func (_mock *MockReactor) DeleteReaction(ctx context.Context, postID int, reactor models.ReactorID, kind models.ReactionKind) error {
+	ret := _mock.Called(ctx, postID, reactor, kind)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteReaction")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int, models.ReactorID, models.ReactionKind) error); ok {
+		r0 = returnFunc(ctx, postID, reactor, kind)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockReactor_DeleteReaction_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteReaction'
+type MockReactor_DeleteReaction_Call struct {
+	*mock.Call
+}
+
+// DeleteReaction is a helper method to define mock.On call
+//   - ctx context.Context
+//   - postID int
+//   - reactor models.ReactorID
+//   - kind models.ReactionKind
+func (_e *MockReactor_Expecter) DeleteReaction(ctx interface{}, postID interface{}, reactor interface{}, kind interface{}) *MockReactor_DeleteReaction_Call {
+	return &MockReactor_DeleteReaction_Call{Call: _e.mock.On("DeleteReaction", ctx, postID, reactor, kind)}
+}
+
+func (_c *MockReactor_DeleteReaction_Call) Run(run func(ctx context.Context, postID int, reactor models.ReactorID, kind models.ReactionKind)) *MockReactor_DeleteReaction_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 int
+		if args[1] != nil {
+			arg1 = args[1].(int)
+		}
+		var arg2 models.ReactorID
+		if args[2] != nil {
+			arg2 = args[2].(models.ReactorID)
+		}
+		var arg3 models.ReactionKind
+		if args[3] != nil {
+			arg3 = args[3].(models.ReactionKind)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+			arg3,
+		)
+	})
+	return _c
+}
+
+func (_c *MockReactor_DeleteReaction_Call) Return(err error) *MockReactor_DeleteReaction_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockReactor_DeleteReaction_Call) RunAndReturn(run func(ctx context.Context, postID int, reactor models.ReactorID, kind models.ReactionKind) error) *MockReactor_DeleteReaction_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SetReaction provides a mock function for the type MockReactor
+func (_mock *MockReactor) SetReaction(ctx context.Context, postID int, reactor models.ReactorID, kind models.ReactionKind) (models.PostReactions, error) {
+	ret := _mock.Called(ctx, postID, reactor, kind)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetReaction")
+	}
+
+	var r0 models.PostReactions
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int, models.ReactorID, models.ReactionKind) (models.PostReactions, error)); ok {
+		return returnFunc(ctx, postID, reactor, kind)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int, models.ReactorID, models.ReactionKind) models.PostReactions); ok {
+		r0 = returnFunc(ctx, postID, reactor, kind)
+	} else {
+		r0 = ret.Get(0).(models.PostReactions)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, int, models.ReactorID, models.ReactionKind) error); ok {
+		r1 = returnFunc(ctx, postID, reactor, kind)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockReactor_SetReaction_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetReaction'
+type MockReactor_SetReaction_Call struct {
+	*mock.Call
+}
+
+// SetReaction is a helper method to define mock.On call
+//   - ctx context.Context
+//   - postID int
+//   - reactor models.ReactorID
+//   - kind models.ReactionKind
+func (_e *MockReactor_Expecter) SetReaction(ctx interface{}, postID interface{}, reactor interface{}, kind interface{}) *MockReactor_SetReaction_Call {
+	return &MockReactor_SetReaction_Call{Call: _e.mock.On("SetReaction", ctx, postID, reactor, kind)}
+}
+
+func (_c *MockReactor_SetReaction_Call) Run(run func(ctx context.Context, postID int, reactor models.ReactorID, kind models.ReactionKind)) *MockReactor_SetReaction_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 int
+		if args[1] != nil {
+			arg1 = args[1].(int)
+		}
+		var arg2 models.ReactorID
+		if args[2] != nil {
+			arg2 = args[2].(models.ReactorID)
+		}
+		var arg3 models.ReactionKind
+		if args[3] != nil {
+			arg3 = args[3].(models.ReactionKind)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+			arg3,
+		)
+	})
+	return _c
+}
+
+func (_c *MockReactor_SetReaction_Call) Return(postReactions models.PostReactions, err error) *MockReactor_SetReaction_Call {
+	_c.Call.Return(postReactions, err)
+	return _c
+}
+
+func (_c *MockReactor_SetReaction_Call) RunAndReturn(run func(ctx context.Context, postID int, reactor models.ReactorID, kind models.ReactionKind) (models.PostReactions, error)) *MockReactor_SetReaction_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockRegistrar creates a new instance of MockRegistrar. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockRegistrar(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockRegistrar {
+	mock := &MockRegistrar{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// MockRegistrar is an autogenerated mock type for the Registrar type
+type MockRegistrar struct {
+	mock.Mock
+}
+
+type MockRegistrar_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockRegistrar) EXPECT() *MockRegistrar_Expecter {
+	return &MockRegistrar_Expecter{mock: &_m.Mock}
+}
+
+// DeleteUser provides a mock function for the type MockRegistrar
+func (_mock *MockRegistrar) DeleteUser(ctx context.Context, id int) error {
+	ret := _mock.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteUser")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int) error); ok {
+		r0 = returnFunc(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockRegistrar_DeleteUser_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteUser'
+type MockRegistrar_DeleteUser_Call struct {
+	*mock.Call
+}
+
+// DeleteUser is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id int
+func (_e *MockRegistrar_Expecter) DeleteUser(ctx interface{}, id interface{}) *MockRegistrar_DeleteUser_Call {
+	return &MockRegistrar_DeleteUser_Call{Call: _e.mock.On("DeleteUser", ctx, id)}
+}
+
+func (_c *MockRegistrar_DeleteUser_Call) Run(run func(ctx context.Context, id int)) *MockRegistrar_DeleteUser_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 int
+		if args[1] != nil {
+			arg1 = args[1].(int)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockRegistrar_DeleteUser_Call) Return(err error) *MockRegistrar_DeleteUser_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockRegistrar_DeleteUser_Call) RunAndReturn(run func(ctx context.Context, id int) error) *MockRegistrar_DeleteUser_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetUser provides a mock function for the type MockRegistrar
+func (_mock *MockRegistrar) GetUser(ctx context.Context, id int) (models.User, error) {
+	ret := _mock.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetUser")
+	}
+
+	var r0 models.User
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int) (models.User, error)); ok {
+		return returnFunc(ctx, id)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int) models.User); ok {
+		r0 = returnFunc(ctx, id)
+	} else {
+		r0 = ret.Get(0).(models.User)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = returnFunc(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockRegistrar_GetUser_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetUser'
+type MockRegistrar_GetUser_Call struct {
+	*mock.Call
+}
+
+// GetUser is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id int
+func (_e *MockRegistrar_Expecter) GetUser(ctx interface{}, id interface{}) *MockRegistrar_GetUser_Call {
+	return &MockRegistrar_GetUser_Call{Call: _e.mock.On("GetUser", ctx, id)}
+}
+
+func (_c *MockRegistrar_GetUser_Call) Run(run func(ctx context.Context, id int)) *MockRegistrar_GetUser_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 int
+		if args[1] != nil {
+			arg1 = args[1].(int)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockRegistrar_GetUser_Call) Return(user models.User, err error) *MockRegistrar_GetUser_Call {
+	_c.Call.Return(user, err)
+	return _c
+}
+
+func (_c *MockRegistrar_GetUser_Call) RunAndReturn(run func(ctx context.Context, id int) (models.User, error)) *MockRegistrar_GetUser_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetUserByEmail provides a mock function for the type MockRegistrar
+func (_mock *MockRegistrar) GetUserByEmail(ctx context.Context, email string) (models.User, error) {
+	ret := _mock.Called(ctx, email)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetUserByEmail")
+	}
+
+	var r0 models.User
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) (models.User, error)); ok {
+		return returnFunc(ctx, email)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) models.User); ok {
+		r0 = returnFunc(ctx, email)
+	} else {
+		r0 = ret.Get(0).(models.User)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = returnFunc(ctx, email)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockRegistrar_GetUserByEmail_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetUserByEmail'
+type MockRegistrar_GetUserByEmail_Call struct {
+	*mock.Call
+}
+
+// GetUserByEmail is a helper method to define mock.On call
+//   - ctx context.Context
+//   - email string
+func (_e *MockRegistrar_Expecter) GetUserByEmail(ctx interface{}, email interface{}) *MockRegistrar_GetUserByEmail_Call {
+	return &MockRegistrar_GetUserByEmail_Call{Call: _e.mock.On("GetUserByEmail", ctx, email)}
+}
+
+func (_c *MockRegistrar_GetUserByEmail_Call) Run(run func(ctx context.Context, email string)) *MockRegistrar_GetUserByEmail_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockRegistrar_GetUserByEmail_Call) Return(user models.User, err error) *MockRegistrar_GetUserByEmail_Call {
+	_c.Call.Return(user, err)
+	return _c
+}
+
+func (_c *MockRegistrar_GetUserByEmail_Call) RunAndReturn(run func(ctx context.Context, email string) (models.User, error)) *MockRegistrar_GetUserByEmail_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetUsers provides a mock function for the type MockRegistrar
+func (_mock *MockRegistrar) GetUsers(ctx context.Context) ([]models.User, error) {
+	ret := _mock.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetUsers")
+	}
+
+	var r0 []models.User
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context) ([]models.User, error)); ok {
+		return returnFunc(ctx)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context) []models.User); ok {
+		r0 = returnFunc(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.User)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = returnFunc(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockRegistrar_GetUsers_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetUsers'
+type MockRegistrar_GetUsers_Call struct {
+	*mock.Call
+}
+
+// GetUsers is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *MockRegistrar_Expecter) GetUsers(ctx interface{}) *MockRegistrar_GetUsers_Call {
+	return &MockRegistrar_GetUsers_Call{Call: _e.mock.On("GetUsers", ctx)}
+}
+
+func (_c *MockRegistrar_GetUsers_Call) Run(run func(ctx context.Context)) *MockRegistrar_GetUsers_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		run(
+			arg0,
+		)
+	})
+	return _c
+}
+
+func (_c *MockRegistrar_GetUsers_Call) Return(users []models.User, err error) *MockRegistrar_GetUsers_Call {
+	_c.Call.Return(users, err)
+	return _c
+}
+
+func (_c *MockRegistrar_GetUsers_Call) RunAndReturn(run func(ctx context.Context) ([]models.User, error)) *MockRegistrar_GetUsers_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// PatchUser provides a mock function for the type MockRegistrar
+func (_mock *MockRegistrar) PatchUser(ctx context.Context, id int, patch models.UserPatch) (models.User, error) {
+	ret := _mock.Called(ctx, id, patch)
+
+	if len(ret) == 0 {
+		panic("no return value specified for PatchUser")
+	}
+
+	var r0 models.User
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int, models.UserPatch) (models.User, error)); ok {
+		return returnFunc(ctx, id, patch)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int, models.UserPatch) models.User); ok {
+		r0 = returnFunc(ctx, id, patch)
+	} else {
+		r0 = ret.Get(0).(models.User)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, int, models.UserPatch) error); ok {
+		r1 = returnFunc(ctx, id, patch)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockRegistrar_PatchUser_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'PatchUser'
+type MockRegistrar_PatchUser_Call struct {
+	*mock.Call
+}
+
+// PatchUser is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id int
+//   - patch models.UserPatch
+func (_e *MockRegistrar_Expecter) PatchUser(ctx interface{}, id interface{}, patch interface{}) *MockRegistrar_PatchUser_Call {
+	return &MockRegistrar_PatchUser_Call{Call: _e.mock.On("PatchUser", ctx, id, patch)}
+}
+
+func (_c *MockRegistrar_PatchUser_Call) Run(run func(ctx context.Context, id int, patch models.UserPatch)) *MockRegistrar_PatchUser_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 int
+		if args[1] != nil {
+			arg1 = args[1].(int)
+		}
+		var arg2 models.UserPatch
+		if args[2] != nil {
+			arg2 = args[2].(models.UserPatch)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockRegistrar_PatchUser_Call) Return(user models.User, err error) *MockRegistrar_PatchUser_Call {
+	_c.Call.Return(user, err)
+	return _c
+}
+
+func (_c *MockRegistrar_PatchUser_Call) RunAndReturn(run func(ctx context.Context, id int, patch models.UserPatch) (models.User, error)) *MockRegistrar_PatchUser_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SaveUser provides a mock function for the type MockRegistrar
+func (_mock *MockRegistrar) SaveUser(ctx context.Context, user models.InputUser) (models.User, error) {
+	ret := _mock.Called(ctx, user)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveUser")
+	}
+
+	var r0 models.User
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, models.InputUser) (models.User, error)); ok {
+		return returnFunc(ctx, user)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, models.InputUser) models.User); ok {
+		r0 = returnFunc(ctx, user)
+	} else {
+		r0 = ret.Get(0).(models.User)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, models.InputUser) error); ok {
+		r1 = returnFunc(ctx, user)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockRegistrar_SaveUser_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SaveUser'
+type MockRegistrar_SaveUser_Call struct {
+	*mock.Call
+}
+
+// SaveUser is a helper method to define mock.On call
+//   - ctx context.Context
+//   - user models.InputUser
+func (_e *MockRegistrar_Expecter) SaveUser(ctx interface{}, user interface{}) *MockRegistrar_SaveUser_Call {
+	return &MockRegistrar_SaveUser_Call{Call: _e.mock.On("SaveUser", ctx, user)}
+}
+
+func (_c *MockRegistrar_SaveUser_Call) Run(run func(ctx context.Context, user models.InputUser)) *MockRegistrar_SaveUser_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 models.InputUser
+		if args[1] != nil {
+			arg1 = args[1].(models.InputUser)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockRegistrar_SaveUser_Call) Return(user models.User, err error) *MockRegistrar_SaveUser_Call {
+	_c.Call.Return(user, err)
+	return _c
+}
+
+func (_c *MockRegistrar_SaveUser_Call) RunAndReturn(run func(ctx context.Context, user models.InputUser) (models.User, error)) *MockRegistrar_SaveUser_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockReviser creates a new instance of MockReviser. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockReviser(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockReviser {
+	mock := &MockReviser{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// MockReviser is an autogenerated mock type for the Reviser type
+type MockReviser struct {
+	mock.Mock
+}
+
+type MockReviser_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockReviser) EXPECT() *MockReviser_Expecter {
+	return &MockReviser_Expecter{mock: &_m.Mock}
+}
+
+// GetRevision provides a mock function for the type MockReviser
+func (_mock *MockReviser) GetRevision(ctx context.Context, postID int, rev int) (models.Revision, error) {
+	ret := _mock.Called(ctx, postID, rev)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetRevision")
+	}
+
+	var r0 models.Revision
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int, int) (models.Revision, error)); ok {
+		return returnFunc(ctx, postID, rev)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int, int) models.Revision); ok {
+		r0 = returnFunc(ctx, postID, rev)
+	} else {
+		r0 = ret.Get(0).(models.Revision)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, int, int) error); ok {
+		r1 = returnFunc(ctx, postID, rev)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockReviser_GetRevision_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetRevision'
+type MockReviser_GetRevision_Call struct {
+	*mock.Call
+}
+
+// GetRevision is a helper method to define mock.On call
+//   - ctx context.Context
+//   - postID int
+//   - rev int
+func (_e *MockReviser_Expecter) GetRevision(ctx interface{}, postID interface{}, rev interface{}) *MockReviser_GetRevision_Call {
+	return &MockReviser_GetRevision_Call{Call: _e.mock.On("GetRevision", ctx, postID, rev)}
+}
+
+func (_c *MockReviser_GetRevision_Call) Run(run func(ctx context.Context, postID int, rev int)) *MockReviser_GetRevision_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 int
+		if args[1] != nil {
+			arg1 = args[1].(int)
+		}
+		var arg2 int
+		if args[2] != nil {
+			arg2 = args[2].(int)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockReviser_GetRevision_Call) Return(revision models.Revision, err error) *MockReviser_GetRevision_Call {
+	_c.Call.Return(revision, err)
+	return _c
+}
+
+func (_c *MockReviser_GetRevision_Call) RunAndReturn(run func(ctx context.Context, postID int, rev int) (models.Revision, error)) *MockReviser_GetRevision_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetRevisions provides a mock function for the type MockReviser
+func (_mock *MockReviser) GetRevisions(ctx context.Context, postID int) ([]models.Revision, error) {
+	ret := _mock.Called(ctx, postID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetRevisions")
+	}
+
+	var r0 []models.Revision
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int) ([]models.Revision, error)); ok {
+		return returnFunc(ctx, postID)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int) []models.Revision); ok {
+		r0 = returnFunc(ctx, postID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.Revision)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = returnFunc(ctx, postID)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockReviser_GetRevisions_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetRevisions'
+type MockReviser_GetRevisions_Call struct {
+	*mock.Call
+}
+
+// GetRevisions is a helper method to define mock.On call
+//   - ctx context.Context
+//   - postID int
+func (_e *MockReviser_Expecter) GetRevisions(ctx interface{}, postID interface{}) *MockReviser_GetRevisions_Call {
+	return &MockReviser_GetRevisions_Call{Call: _e.mock.On("GetRevisions", ctx, postID)}
+}
+
+func (_c *MockReviser_GetRevisions_Call) Run(run func(ctx context.Context, postID int)) *MockReviser_GetRevisions_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 int
+		if args[1] != nil {
+			arg1 = args[1].(int)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockReviser_GetRevisions_Call) Return(revisions []models.Revision, err error) *MockReviser_GetRevisions_Call {
+	_c.Call.Return(revisions, err)
+	return _c
+}
+
+func (_c *MockReviser_GetRevisions_Call) RunAndReturn(run func(ctx context.Context, postID int) ([]models.Revision, error)) *MockReviser_GetRevisions_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RevertPost provides a mock function for the type MockReviser
+func (_mock *MockReviser) RevertPost(ctx context.Context, postID int, rev int, editorID int) (models.OutputPost, error) {
+	ret := _mock.Called(ctx, postID, rev, editorID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RevertPost")
+	}
+
+	var r0 models.OutputPost
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int, int, int) (models.OutputPost, error)); ok {
+		return returnFunc(ctx, postID, rev, editorID)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int, int, int) models.OutputPost); ok {
+		r0 = returnFunc(ctx, postID, rev, editorID)
+	} else {
+		r0 = ret.Get(0).(models.OutputPost)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, int, int, int) error); ok {
+		r1 = returnFunc(ctx, postID, rev, editorID)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockReviser_RevertPost_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RevertPost'
+type MockReviser_RevertPost_Call struct {
+	*mock.Call
+}
+
+// RevertPost is a helper method to define mock.On call
+//   - ctx context.Context
+//   - postID int
+//   - rev int
+//   - editorID int
+func (_e *MockReviser_Expecter) RevertPost(ctx interface{}, postID interface{}, rev interface{}, editorID interface{}) *MockReviser_RevertPost_Call {
+	return &MockReviser_RevertPost_Call{Call: _e.mock.On("RevertPost", ctx, postID, rev, editorID)}
+}
+
+func (_c *MockReviser_RevertPost_Call) Run(run func(ctx context.Context, postID int, rev int, editorID int)) *MockReviser_RevertPost_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 int
+		if args[1] != nil {
+			arg1 = args[1].(int)
+		}
+		var arg2 int
+		if args[2] != nil {
+			arg2 = args[2].(int)
+		}
+		var arg3 int
+		if args[3] != nil {
+			arg3 = args[3].(int)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+			arg3,
+		)
+	})
+	return _c
+}
+
+func (_c *MockReviser_RevertPost_Call) Return(outputPost models.OutputPost, err error) *MockReviser_RevertPost_Call {
+	_c.Call.Return(outputPost, err)
+	return _c
+}
+
+func (_c *MockReviser_RevertPost_Call) RunAndReturn(run func(ctx context.Context, postID int, rev int, editorID int) (models.OutputPost, error)) *MockReviser_RevertPost_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockSectioner creates a new instance of MockSectioner. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockSectioner(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockSectioner {
+	mock := &MockSectioner{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// MockSectioner is an autogenerated mock type for the Sectioner type
+type MockSectioner struct {
+	mock.Mock
+}
+
+type MockSectioner_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockSectioner) EXPECT() *MockSectioner_Expecter {
+	return &MockSectioner_Expecter{mock: &_m.Mock}
+}
+
+// GetSection provides a mock function for the type MockSectioner
+func (_mock *MockSectioner) GetSection(ctx context.Context, slug string) (models.Section, error) {
+	ret := _mock.Called(ctx, slug)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetSection")
+	}
+
+	var r0 models.Section
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) (models.Section, error)); ok {
+		return returnFunc(ctx, slug)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) models.Section); ok {
+		r0 = returnFunc(ctx, slug)
+	} else {
+		r0 = ret.Get(0).(models.Section)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = returnFunc(ctx, slug)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockSectioner_GetSection_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetSection'
+type MockSectioner_GetSection_Call struct {
+	*mock.Call
+}
+
+// GetSection is a helper method to define mock.On call
+//   - ctx context.Context
+//   - slug string
+func (_e *MockSectioner_Expecter) GetSection(ctx interface{}, slug interface{}) *MockSectioner_GetSection_Call {
+	return &MockSectioner_GetSection_Call{Call: _e.mock.On("GetSection", ctx, slug)}
+}
+
+func (_c *MockSectioner_GetSection_Call) Run(run func(ctx context.Context, slug string)) *MockSectioner_GetSection_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockSectioner_GetSection_Call) Return(section models.Section, err error) *MockSectioner_GetSection_Call {
+	_c.Call.Return(section, err)
+	return _c
+}
+
+func (_c *MockSectioner_GetSection_Call) RunAndReturn(run func(ctx context.Context, slug string) (models.Section, error)) *MockSectioner_GetSection_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetSections provides a mock function for the type MockSectioner
+func (_mock *MockSectioner) GetSections(ctx context.Context) ([]models.Section, error) {
+	ret := _mock.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetSections")
+	}
+
+	var r0 []models.Section
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context) ([]models.Section, error)); ok {
+		return returnFunc(ctx)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context) []models.Section); ok {
+		r0 = returnFunc(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.Section)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = returnFunc(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockSectioner_GetSections_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetSections'
+type MockSectioner_GetSections_Call struct {
+	*mock.Call
+}
+
+// GetSections is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *MockSectioner_Expecter) GetSections(ctx interface{}) *MockSectioner_GetSections_Call {
+	return &MockSectioner_GetSections_Call{Call: _e.mock.On("GetSections", ctx)}
+}
+
+func (_c *MockSectioner_GetSections_Call) Run(run func(ctx context.Context)) *MockSectioner_GetSections_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		run(
+			arg0,
+		)
+	})
+	return _c
+}
+
+func (_c *MockSectioner_GetSections_Call) Return(sections []models.Section, err error) *MockSectioner_GetSections_Call {
+	_c.Call.Return(sections, err)
+	return _c
+}
+
+func (_c *MockSectioner_GetSections_Call) RunAndReturn(run func(ctx context.Context) ([]models.Section, error)) *MockSectioner_GetSections_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// PatchSection provides a mock function for the type MockSectioner
+func (_mock *MockSectioner) PatchSection(ctx context.Context, slug string, patch models.SectionPatch) (models.Section, error) {
+	ret := _mock.Called(ctx, slug, patch)
+
+	if len(ret) == 0 {
+		panic("no return value specified for PatchSection")
+	}
+
+	var r0 models.Section
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, models.SectionPatch) (models.Section, error)); ok {
+		return returnFunc(ctx, slug, patch)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, models.SectionPatch) models.Section); ok {
+		r0 = returnFunc(ctx, slug, patch)
+	} else {
+		r0 = ret.Get(0).(models.Section)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string, models.SectionPatch) error); ok {
+		r1 = returnFunc(ctx, slug, patch)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockSectioner_PatchSection_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'PatchSection'
+type MockSectioner_PatchSection_Call struct {
+	*mock.Call
+}
+
+// PatchSection is a helper method to define mock.On call
+//   - ctx context.Context
+//   - slug string
+//   - patch models.SectionPatch
+func (_e *MockSectioner_Expecter) PatchSection(ctx interface{}, slug interface{}, patch interface{}) *MockSectioner_PatchSection_Call {
+	return &MockSectioner_PatchSection_Call{Call: _e.mock.On("PatchSection", ctx, slug, patch)}
+}
+
+func (_c *MockSectioner_PatchSection_Call) Run(run func(ctx context.Context, slug string, patch models.SectionPatch)) *MockSectioner_PatchSection_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		var arg2 models.SectionPatch
+		if args[2] != nil {
+			arg2 = args[2].(models.SectionPatch)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockSectioner_PatchSection_Call) Return(section models.Section, err error) *MockSectioner_PatchSection_Call {
+	_c.Call.Return(section, err)
+	return _c
+}
+
+func (_c *MockSectioner_PatchSection_Call) RunAndReturn(run func(ctx context.Context, slug string, patch models.SectionPatch) (models.Section, error)) *MockSectioner_PatchSection_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SaveSection provides a mock function for the type MockSectioner
+func (_mock *MockSectioner) SaveSection(ctx context.Context, section models.InputSection) (models.Section, error) {
+	ret := _mock.Called(ctx, section)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveSection")
+	}
+
+	var r0 models.Section
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, models.InputSection) (models.Section, error)); ok {
+		return returnFunc(ctx, section)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, models.InputSection) models.Section); ok {
+		r0 = returnFunc(ctx, section)
+	} else {
+		r0 = ret.Get(0).(models.Section)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, models.InputSection) error); ok {
+		r1 = returnFunc(ctx, section)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockSectioner_SaveSection_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SaveSection'
+type MockSectioner_SaveSection_Call struct {
+	*mock.Call
+}
+
+// SaveSection is a helper method to define mock.On call
+//   - ctx context.Context
+//   - section models.InputSection
+func (_e *MockSectioner_Expecter) SaveSection(ctx interface{}, section interface{}) *MockSectioner_SaveSection_Call {
+	return &MockSectioner_SaveSection_Call{Call: _e.mock.On("SaveSection", ctx, section)}
+}
+
+func (_c *MockSectioner_SaveSection_Call) Run(run func(ctx context.Context, section models.InputSection)) *MockSectioner_SaveSection_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 models.InputSection
+		if args[1] != nil {
+			arg1 = args[1].(models.InputSection)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockSectioner_SaveSection_Call) Return(section models.Section, err error) *MockSectioner_SaveSection_Call {
+	_c.Call.Return(section, err)
+	return _c
+}
+
+func (_c *MockSectioner_SaveSection_Call) RunAndReturn(run func(ctx context.Context, section models.InputSection) (models.Section, error)) *MockSectioner_SaveSection_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockTagger creates a new instance of MockTagger. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockTagger(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockTagger {
+	mock := &MockTagger{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// MockTagger is an autogenerated mock type for the Tagger type
+type MockTagger struct {
+	mock.Mock
+}
+
+type MockTagger_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockTagger) EXPECT() *MockTagger_Expecter {
+	return &MockTagger_Expecter{mock: &_m.Mock}
+}
+
+// GetTags provides a mock function for the type MockTagger
+func (_mock *MockTagger) GetTags(ctx context.Context) ([]models.Tag, error) {
+	ret := _mock.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetTags")
+	}
+
+	var r0 []models.Tag
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context) ([]models.Tag, error)); ok {
+		return returnFunc(ctx)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context) []models.Tag); ok {
+		r0 = returnFunc(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.Tag)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = returnFunc(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockTagger_GetTags_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetTags'
+type MockTagger_GetTags_Call struct {
+	*mock.Call
+}
+
+// GetTags is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *MockTagger_Expecter) GetTags(ctx interface{}) *MockTagger_GetTags_Call {
+	return &MockTagger_GetTags_Call{Call: _e.mock.On("GetTags", ctx)}
+}
+
+func (_c *MockTagger_GetTags_Call) Run(run func(ctx context.Context)) *MockTagger_GetTags_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		run(
+			arg0,
+		)
+	})
+	return _c
+}
+
+func (_c *MockTagger_GetTags_Call) Return(tags []models.Tag, err error) *MockTagger_GetTags_Call {
+	_c.Call.Return(tags, err)
+	return _c
+}
+
+func (_c *MockTagger_GetTags_Call) RunAndReturn(run func(ctx context.Context) ([]models.Tag, error)) *MockTagger_GetTags_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// MergeTags provides a mock function for the type MockTagger
+func (_mock *MockTagger) MergeTags(ctx context.Context, from string, into string) (models.Tag, error) {
+	ret := _mock.Called(ctx, from, into)
+
+	if len(ret) == 0 {
+		panic("no return value specified for MergeTags")
+	}
+
+	var r0 models.Tag
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, string) (models.Tag, error)); ok {
+		return returnFunc(ctx, from, into)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, string) models.Tag); ok {
+		r0 = returnFunc(ctx, from, into)
+	} else {
+		r0 = ret.Get(0).(models.Tag)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = returnFunc(ctx, from, into)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockTagger_MergeTags_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'MergeTags'
+type MockTagger_MergeTags_Call struct {
+	*mock.Call
+}
+
+// MergeTags is a helper method to define mock.On call
+//   - ctx context.Context
+//   - from string
+//   - into string
+func (_e *MockTagger_Expecter) MergeTags(ctx interface{}, from interface{}, into interface{}) *MockTagger_MergeTags_Call {
+	return &MockTagger_MergeTags_Call{Call: _e.mock.On("MergeTags", ctx, from, into)}
+}
+
+func (_c *MockTagger_MergeTags_Call) Run(run func(ctx context.Context, from string, into string)) *MockTagger_MergeTags_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		var arg2 string
+		if args[2] != nil {
+			arg2 = args[2].(string)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockTagger_MergeTags_Call) Return(tag models.Tag, err error) *MockTagger_MergeTags_Call {
+	_c.Call.Return(tag, err)
+	return _c
+}
+
+func (_c *MockTagger_MergeTags_Call) RunAndReturn(run func(ctx context.Context, from string, into string) (models.Tag, error)) *MockTagger_MergeTags_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RenameTag provides a mock function for the type MockTagger
+func (_mock *MockTagger) RenameTag(ctx context.Context, name string, newName string) (models.Tag, error) {
+	ret := _mock.Called(ctx, name, newName)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RenameTag")
+	}
+
+	var r0 models.Tag
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, string) (models.Tag, error)); ok {
+		return returnFunc(ctx, name, newName)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, string) models.Tag); ok {
+		r0 = returnFunc(ctx, name, newName)
+	} else {
+		r0 = ret.Get(0).(models.Tag)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = returnFunc(ctx, name, newName)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockTagger_RenameTag_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RenameTag'
+type MockTagger_RenameTag_Call struct {
+	*mock.Call
+}
+
+// RenameTag is a helper method to define mock.On call
+//   - ctx context.Context
+//   - name string
+//   - newName string
+func (_e *MockTagger_Expecter) RenameTag(ctx interface{}, name interface{}, newName interface{}) *MockTagger_RenameTag_Call {
+	return &MockTagger_RenameTag_Call{Call: _e.mock.On("RenameTag", ctx, name, newName)}
+}
+
+func (_c *MockTagger_RenameTag_Call) Run(run func(ctx context.Context, name string, newName string)) *MockTagger_RenameTag_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		var arg2 string
+		if args[2] != nil {
+			arg2 = args[2].(string)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockTagger_RenameTag_Call) Return(tag models.Tag, err error) *MockTagger_RenameTag_Call {
+	_c.Call.Return(tag, err)
+	return _c
+}
+
+func (_c *MockTagger_RenameTag_Call) RunAndReturn(run func(ctx context.Context, name string, newName string) (models.Tag, error)) *MockTagger_RenameTag_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockTokenIssuer creates a new instance of MockTokenIssuer. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockTokenIssuer(t interface {
 	mock.TestingT
 	Cleanup(func())
-}) *MockPoster {
-	mock := &MockPoster{}
+}) *MockTokenIssuer {
+	mock := &MockTokenIssuer{}
 	mock.Mock.Test(t)
 
 	t.Cleanup(func() { mock.AssertExpectations(t) })
@@ -23,232 +3203,407 @@ func NewMockPoster(t interface {
 	return mock
 }
 
-// MockPoster is an autogenerated mock type for the Poster type
-type MockPoster struct {
+// MockTokenIssuer is an autogenerated mock type for the TokenIssuer type
+type MockTokenIssuer struct {
 	mock.Mock
 }
 
-type MockPoster_Expecter struct {
+type MockTokenIssuer_Expecter struct {
 	mock *mock.Mock
 }
 
-func (_m *MockPoster) EXPECT() *MockPoster_Expecter {
-	return &MockPoster_Expecter{mock: &_m.Mock}
+func (_m *MockTokenIssuer) EXPECT() *MockTokenIssuer_Expecter {
+	return &MockTokenIssuer_Expecter{mock: &_m.Mock}
 }
 
-// DeletePost provides a mock function for the type MockPoster
-func (_mock *MockPoster) DeletePost(id int) error {
-	ret := _mock.Called(id)
+// Login provides a mock function for the type MockTokenIssuer
+func (_mock *MockTokenIssuer) Login(ctx context.Context, email string, password string) (models.Tokens, error) {
+	ret := _mock.Called(ctx, email, password)
 
 	if len(ret) == 0 {
-		panic("no return value specified for DeletePost")
+		panic("no return value specified for Login")
+	}
+
+	var r0 models.Tokens
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, string) (models.Tokens, error)); ok {
+		return returnFunc(ctx, email, password)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, string) models.Tokens); ok {
+		r0 = returnFunc(ctx, email, password)
+	} else {
+		r0 = ret.Get(0).(models.Tokens)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = returnFunc(ctx, email, password)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockTokenIssuer_Login_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Login'
+type MockTokenIssuer_Login_Call struct {
+	*mock.Call
+}
+
+// Login is a helper method to define mock.On call
+//   - ctx context.Context
+//   - email string
+//   - password string
+func (_e *MockTokenIssuer_Expecter) Login(ctx interface{}, email interface{}, password interface{}) *MockTokenIssuer_Login_Call {
+	return &MockTokenIssuer_Login_Call{Call: _e.mock.On("Login", ctx, email, password)}
+}
+
+func (_c *MockTokenIssuer_Login_Call) Run(run func(ctx context.Context, email string, password string)) *MockTokenIssuer_Login_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		var arg2 string
+		if args[2] != nil {
+			arg2 = args[2].(string)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockTokenIssuer_Login_Call) Return(tokens models.Tokens, err error) *MockTokenIssuer_Login_Call {
+	_c.Call.Return(tokens, err)
+	return _c
+}
+
+func (_c *MockTokenIssuer_Login_Call) RunAndReturn(run func(ctx context.Context, email string, password string) (models.Tokens, error)) *MockTokenIssuer_Login_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Refresh provides a mock function for the type MockTokenIssuer
+func (_mock *MockTokenIssuer) Refresh(ctx context.Context, refreshToken string) (models.Tokens, error) {
+	ret := _mock.Called(ctx, refreshToken)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Refresh")
+	}
+
+	var r0 models.Tokens
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) (models.Tokens, error)); ok {
+		return returnFunc(ctx, refreshToken)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) models.Tokens); ok {
+		r0 = returnFunc(ctx, refreshToken)
+	} else {
+		r0 = ret.Get(0).(models.Tokens)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = returnFunc(ctx, refreshToken)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockTokenIssuer_Refresh_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Refresh'
+type MockTokenIssuer_Refresh_Call struct {
+	*mock.Call
+}
+
+// Refresh is a helper method to define mock.On call
+//   - ctx context.Context
+//   - refreshToken string
+func (_e *MockTokenIssuer_Expecter) Refresh(ctx interface{}, refreshToken interface{}) *MockTokenIssuer_Refresh_Call {
+	return &MockTokenIssuer_Refresh_Call{Call: _e.mock.On("Refresh", ctx, refreshToken)}
+}
+
+func (_c *MockTokenIssuer_Refresh_Call) Run(run func(ctx context.Context, refreshToken string)) *MockTokenIssuer_Refresh_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockTokenIssuer_Refresh_Call) Return(tokens models.Tokens, err error) *MockTokenIssuer_Refresh_Call {
+	_c.Call.Return(tokens, err)
+	return _c
+}
+
+func (_c *MockTokenIssuer_Refresh_Call) RunAndReturn(run func(ctx context.Context, refreshToken string) (models.Tokens, error)) *MockTokenIssuer_Refresh_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Revoke provides a mock function for the type MockTokenIssuer
+func (_mock *MockTokenIssuer) Revoke(ctx context.Context, token string) error {
+	ret := _mock.Called(ctx, token)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Revoke")
 	}
 
 	var r0 error
-	if returnFunc, ok := ret.Get(0).(func(int) error); ok {
-		r0 = returnFunc(id)
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = returnFunc(ctx, token)
 	} else {
 		r0 = ret.Error(0)
 	}
 	return r0
 }
 
-// MockPoster_DeletePost_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeletePost'
-type MockPoster_DeletePost_Call struct {
+// MockTokenIssuer_Revoke_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Revoke'
+type MockTokenIssuer_Revoke_Call struct {
 	*mock.Call
 }
 
-// DeletePost is a helper method to define mock.On call
-//   - id int
-func (_e *MockPoster_Expecter) DeletePost(id interface{}) *MockPoster_DeletePost_Call {
-	return &MockPoster_DeletePost_Call{Call: _e.mock.On("DeletePost", id)}
+// Revoke is a helper method to define mock.On call
+//   - ctx context.Context
+//   - token string
+func (_e *MockTokenIssuer_Expecter) Revoke(ctx interface{}, token interface{}) *MockTokenIssuer_Revoke_Call {
+	return &MockTokenIssuer_Revoke_Call{Call: _e.mock.On("Revoke", ctx, token)}
 }
 
-func (_c *MockPoster_DeletePost_Call) Run(run func(id int)) *MockPoster_DeletePost_Call {
+func (_c *MockTokenIssuer_Revoke_Call) Run(run func(ctx context.Context, token string)) *MockTokenIssuer_Revoke_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		var arg0 int
+		var arg0 context.Context
 		if args[0] != nil {
-			arg0 = args[0].(int)
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
 		}
 		run(
 			arg0,
+			arg1,
 		)
 	})
 	return _c
 }
 
-func (_c *MockPoster_DeletePost_Call) Return(err error) *MockPoster_DeletePost_Call {
+func (_c *MockTokenIssuer_Revoke_Call) Return(err error) *MockTokenIssuer_Revoke_Call {
 	_c.Call.Return(err)
 	return _c
 }
 
-func (_c *MockPoster_DeletePost_Call) RunAndReturn(run func(id int) error) *MockPoster_DeletePost_Call {
+func (_c *MockTokenIssuer_Revoke_Call) RunAndReturn(run func(ctx context.Context, token string) error) *MockTokenIssuer_Revoke_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// GetAllPosts provides a mock function for the type MockPoster
-func (_mock *MockPoster) GetAllPosts() ([]models.OutputPost, error) {
-	ret := _mock.Called()
+// NewMockTrasher creates a new instance of MockTrasher. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockTrasher(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockTrasher {
+	mock := &MockTrasher{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// MockTrasher is an autogenerated mock type for the Trasher type
+type MockTrasher struct {
+	mock.Mock
+}
+
+type MockTrasher_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockTrasher) EXPECT() *MockTrasher_Expecter {
+	return &MockTrasher_Expecter{mock: &_m.Mock}
+}
+
+// GetTrash provides a mock function for the type MockTrasher
+func (_mock *MockTrasher) GetTrash(ctx context.Context) ([]models.OutputPost, error) {
+	ret := _mock.Called(ctx)
 
 	if len(ret) == 0 {
-		panic("no return value specified for GetAllPosts")
+		panic("no return value specified for GetTrash")
 	}
 
 	var r0 []models.OutputPost
 	var r1 error
-	if returnFunc, ok := ret.Get(0).(func() ([]models.OutputPost, error)); ok {
-		return returnFunc()
+	if returnFunc, ok := ret.Get(0).(func(context.Context) ([]models.OutputPost, error)); ok {
+		return returnFunc(ctx)
 	}
-	if returnFunc, ok := ret.Get(0).(func() []models.OutputPost); ok {
-		r0 = returnFunc()
+	if returnFunc, ok := ret.Get(0).(func(context.Context) []models.OutputPost); ok {
+		r0 = returnFunc(ctx)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]models.OutputPost)
 		}
 	}
-	if returnFunc, ok := ret.Get(1).(func() error); ok {
-		r1 = returnFunc()
+	if returnFunc, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = returnFunc(ctx)
 	} else {
 		r1 = ret.Error(1)
 	}
 	return r0, r1
 }
 
-// MockPoster_GetAllPosts_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetAllPosts'
-type MockPoster_GetAllPosts_Call struct {
+// MockTrasher_GetTrash_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetTrash'
+type MockTrasher_GetTrash_Call struct {
 	*mock.Call
 }
 
-// GetAllPosts is a helper method to define mock.On call
-func (_e *MockPoster_Expecter) GetAllPosts() *MockPoster_GetAllPosts_Call {
-	return &MockPoster_GetAllPosts_Call{Call: _e.mock.On("GetAllPosts")}
+// GetTrash is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *MockTrasher_Expecter) GetTrash(ctx interface{}) *MockTrasher_GetTrash_Call {
+	return &MockTrasher_GetTrash_Call{Call: _e.mock.On("GetTrash", ctx)}
 }
 
-func (_c *MockPoster_GetAllPosts_Call) Run(run func()) *MockPoster_GetAllPosts_Call {
+func (_c *MockTrasher_GetTrash_Call) Run(run func(ctx context.Context)) *MockTrasher_GetTrash_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run()
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		run(
+			arg0,
+		)
 	})
 	return _c
 }
 
-func (_c *MockPoster_GetAllPosts_Call) Return(outputPosts []models.OutputPost, err error) *MockPoster_GetAllPosts_Call {
+func (_c *MockTrasher_GetTrash_Call) Return(outputPosts []models.OutputPost, err error) *MockTrasher_GetTrash_Call {
 	_c.Call.Return(outputPosts, err)
 	return _c
 }
 
-func (_c *MockPoster_GetAllPosts_Call) RunAndReturn(run func() ([]models.OutputPost, error)) *MockPoster_GetAllPosts_Call {
+func (_c *MockTrasher_GetTrash_Call) RunAndReturn(run func(ctx context.Context) ([]models.OutputPost, error)) *MockTrasher_GetTrash_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// GetPost provides a mock function for the type MockPoster
-func (_mock *MockPoster) GetPost(id int) (models.OutputPost, error) {
-	ret := _mock.Called(id)
+// PurgePost provides a mock function for the type MockTrasher
+func (_mock *MockTrasher) PurgePost(ctx context.Context, id int) error {
+	ret := _mock.Called(ctx, id)
 
 	if len(ret) == 0 {
-		panic("no return value specified for GetPost")
+		panic("no return value specified for PurgePost")
 	}
 
-	var r0 models.OutputPost
-	var r1 error
-	if returnFunc, ok := ret.Get(0).(func(int) (models.OutputPost, error)); ok {
-		return returnFunc(id)
-	}
-	if returnFunc, ok := ret.Get(0).(func(int) models.OutputPost); ok {
-		r0 = returnFunc(id)
-	} else {
-		r0 = ret.Get(0).(models.OutputPost)
-	}
-	if returnFunc, ok := ret.Get(1).(func(int) error); ok {
-		r1 = returnFunc(id)
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int) error); ok {
+		r0 = returnFunc(ctx, id)
 	} else {
-		r1 = ret.Error(1)
+		r0 = ret.Error(0)
 	}
-	return r0, r1
+	return r0
 }
 
-// MockPoster_GetPost_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetPost'
-type MockPoster_GetPost_Call struct {
+// MockTrasher_PurgePost_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'PurgePost'
+type MockTrasher_PurgePost_Call struct {
 	*mock.Call
 }
 
-// GetPost is a helper method to define mock.On call
+// PurgePost is a helper method to define mock.On call
+//   - ctx context.Context
 //   - id int
-func (_e *MockPoster_Expecter) GetPost(id interface{}) *MockPoster_GetPost_Call {
-	return &MockPoster_GetPost_Call{Call: _e.mock.On("GetPost", id)}
+func (_e *MockTrasher_Expecter) PurgePost(ctx interface{}, id interface{}) *MockTrasher_PurgePost_Call {
+	return &MockTrasher_PurgePost_Call{Call: _e.mock.On("PurgePost", ctx, id)}
 }
 
-func (_c *MockPoster_GetPost_Call) Run(run func(id int)) *MockPoster_GetPost_Call {
+func (_c *MockTrasher_PurgePost_Call) Run(run func(ctx context.Context, id int)) *MockTrasher_PurgePost_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		var arg0 int
+		var arg0 context.Context
 		if args[0] != nil {
-			arg0 = args[0].(int)
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 int
+		if args[1] != nil {
+			arg1 = args[1].(int)
 		}
 		run(
 			arg0,
+			arg1,
 		)
 	})
 	return _c
 }
 
-func (_c *MockPoster_GetPost_Call) Return(outputPost models.OutputPost, err error) *MockPoster_GetPost_Call {
-	_c.Call.Return(outputPost, err)
+func (_c *MockTrasher_PurgePost_Call) Return(err error) *MockTrasher_PurgePost_Call {
+	_c.Call.Return(err)
 	return _c
 }
 
-func (_c *MockPoster_GetPost_Call) RunAndReturn(run func(id int) (models.OutputPost, error)) *MockPoster_GetPost_Call {
+func (_c *MockTrasher_PurgePost_Call) RunAndReturn(run func(ctx context.Context, id int) error) *MockTrasher_PurgePost_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// PatchPost provides a mock function for the type MockPoster
-func (_mock *MockPoster) PatchPost(id int, inputPost models.InputPost) (models.OutputPost, error) {
-	ret := _mock.Called(id, inputPost)
+// RestorePost provides a mock function for the type MockTrasher
+func (_mock *MockTrasher) RestorePost(ctx context.Context, id int) (models.OutputPost, error) {
+	ret := _mock.Called(ctx, id)
 
 	if len(ret) == 0 {
-		panic("no return value specified for PatchPost")
+		panic("no return value specified for RestorePost")
 	}
 
 	var r0 models.OutputPost
 	var r1 error
-	if returnFunc, ok := ret.Get(0).(func(int, models.InputPost) (models.OutputPost, error)); ok {
-		return returnFunc(id, inputPost)
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int) (models.OutputPost, error)); ok {
+		return returnFunc(ctx, id)
 	}
-	if returnFunc, ok := ret.Get(0).(func(int, models.InputPost) models.OutputPost); ok {
-		r0 = returnFunc(id, inputPost)
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int) models.OutputPost); ok {
+		r0 = returnFunc(ctx, id)
 	} else {
 		r0 = ret.Get(0).(models.OutputPost)
 	}
-	if returnFunc, ok := ret.Get(1).(func(int, models.InputPost) error); ok {
-		r1 = returnFunc(id, inputPost)
+	if returnFunc, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = returnFunc(ctx, id)
 	} else {
 		r1 = ret.Error(1)
 	}
 	return r0, r1
 }
 
-// MockPoster_PatchPost_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'PatchPost'
-type MockPoster_PatchPost_Call struct {
+// MockTrasher_RestorePost_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RestorePost'
+type MockTrasher_RestorePost_Call struct {
 	*mock.Call
 }
 
-// PatchPost is a helper method to define mock.On call
+// RestorePost is a helper method to define mock.On call
+//   - ctx context.Context
 //   - id int
-//   - inputPost models.InputPost
-func (_e *MockPoster_Expecter) PatchPost(id interface{}, inputPost interface{}) *MockPoster_PatchPost_Call {
-	return &MockPoster_PatchPost_Call{Call: _e.mock.On("PatchPost", id, inputPost)}
+func (_e *MockTrasher_Expecter) RestorePost(ctx interface{}, id interface{}) *MockTrasher_RestorePost_Call {
+	return &MockTrasher_RestorePost_Call{Call: _e.mock.On("RestorePost", ctx, id)}
 }
 
-func (_c *MockPoster_PatchPost_Call) Run(run func(id int, inputPost models.InputPost)) *MockPoster_PatchPost_Call {
+func (_c *MockTrasher_RestorePost_Call) Run(run func(ctx context.Context, id int)) *MockTrasher_RestorePost_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		var arg0 int
+		var arg0 context.Context
 		if args[0] != nil {
-			arg0 = args[0].(int)
+			arg0 = args[0].(context.Context)
 		}
-		var arg1 models.InputPost
+		var arg1 int
 		if args[1] != nil {
-			arg1 = args[1].(models.InputPost)
+			arg1 = args[1].(int)
 		}
 		run(
 			arg0,
@@ -258,72 +3613,105 @@ func (_c *MockPoster_PatchPost_Call) Run(run func(id int, inputPost models.Input
 	return _c
 }
 
-func (_c *MockPoster_PatchPost_Call) Return(outputPost models.OutputPost, err error) *MockPoster_PatchPost_Call {
+func (_c *MockTrasher_RestorePost_Call) Return(outputPost models.OutputPost, err error) *MockTrasher_RestorePost_Call {
 	_c.Call.Return(outputPost, err)
 	return _c
 }
 
-func (_c *MockPoster_PatchPost_Call) RunAndReturn(run func(id int, inputPost models.InputPost) (models.OutputPost, error)) *MockPoster_PatchPost_Call {
+func (_c *MockTrasher_RestorePost_Call) RunAndReturn(run func(ctx context.Context, id int) (models.OutputPost, error)) *MockTrasher_RestorePost_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// SavePost provides a mock function for the type MockPoster
-func (_mock *MockPoster) SavePost(post models.InputPost) (models.OutputPost, error) {
-	ret := _mock.Called(post)
+// NewMockViewStats creates a new instance of MockViewStats. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockViewStats(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockViewStats {
+	mock := &MockViewStats{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// MockViewStats is an autogenerated mock type for the ViewStats type
+type MockViewStats struct {
+	mock.Mock
+}
+
+type MockViewStats_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockViewStats) EXPECT() *MockViewStats_Expecter {
+	return &MockViewStats_Expecter{mock: &_m.Mock}
+}
+
+// GetPostStats provides a mock function for the type MockViewStats
+func (_mock *MockViewStats) GetPostStats(ctx context.Context, postID int) (models.PostStats, error) {
+	ret := _mock.Called(ctx, postID)
 
 	if len(ret) == 0 {
-		panic("no return value specified for SavePost")
+		panic("no return value specified for GetPostStats")
 	}
 
-	var r0 models.OutputPost
+	var r0 models.PostStats
 	var r1 error
-	if returnFunc, ok := ret.Get(0).(func(models.InputPost) (models.OutputPost, error)); ok {
-		return returnFunc(post)
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int) (models.PostStats, error)); ok {
+		return returnFunc(ctx, postID)
 	}
-	if returnFunc, ok := ret.Get(0).(func(models.InputPost) models.OutputPost); ok {
-		r0 = returnFunc(post)
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int) models.PostStats); ok {
+		r0 = returnFunc(ctx, postID)
 	} else {
-		r0 = ret.Get(0).(models.OutputPost)
+		r0 = ret.Get(0).(models.PostStats)
 	}
-	if returnFunc, ok := ret.Get(1).(func(models.InputPost) error); ok {
-		r1 = returnFunc(post)
+	if returnFunc, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = returnFunc(ctx, postID)
 	} else {
 		r1 = ret.Error(1)
 	}
 	return r0, r1
 }
 
-// MockPoster_SavePost_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SavePost'
-type MockPoster_SavePost_Call struct {
+// MockViewStats_GetPostStats_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetPostStats'
+type MockViewStats_GetPostStats_Call struct {
 	*mock.Call
 }
 
-// SavePost is a helper method to define mock.On call
-//   - post models.InputPost
-func (_e *MockPoster_Expecter) SavePost(post interface{}) *MockPoster_SavePost_Call {
-	return &MockPoster_SavePost_Call{Call: _e.mock.On("SavePost", post)}
+// GetPostStats is a helper method to define mock.On call
+//   - ctx context.Context
+//   - postID int
+func (_e *MockViewStats_Expecter) GetPostStats(ctx interface{}, postID interface{}) *MockViewStats_GetPostStats_Call {
+	return &MockViewStats_GetPostStats_Call{Call: _e.mock.On("GetPostStats", ctx, postID)}
 }
 
-func (_c *MockPoster_SavePost_Call) Run(run func(post models.InputPost)) *MockPoster_SavePost_Call {
+func (_c *MockViewStats_GetPostStats_Call) Run(run func(ctx context.Context, postID int)) *MockViewStats_GetPostStats_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		var arg0 models.InputPost
+		var arg0 context.Context
 		if args[0] != nil {
-			arg0 = args[0].(models.InputPost)
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 int
+		if args[1] != nil {
+			arg1 = args[1].(int)
 		}
 		run(
 			arg0,
+			arg1,
 		)
 	})
 	return _c
 }
 
-func (_c *MockPoster_SavePost_Call) Return(outputPost models.OutputPost, err error) *MockPoster_SavePost_Call {
-	_c.Call.Return(outputPost, err)
+func (_c *MockViewStats_GetPostStats_Call) Return(postStats models.PostStats, err error) *MockViewStats_GetPostStats_Call {
+	_c.Call.Return(postStats, err)
 	return _c
 }
 
-func (_c *MockPoster_SavePost_Call) RunAndReturn(run func(post models.InputPost) (models.OutputPost, error)) *MockPoster_SavePost_Call {
+func (_c *MockViewStats_GetPostStats_Call) RunAndReturn(run func(ctx context.Context, postID int) (models.PostStats, error)) *MockViewStats_GetPostStats_Call {
 	_c.Call.Return(run)
 	return _c
 }
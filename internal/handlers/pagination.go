@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/RomanKovalev007/mai_news/internal/models"
+)
+
+const (
+	defaultPageSize = 20
+	// DefaultMaxPageSize caps ?limit= when the config leaves max_page_size unset.
+	DefaultMaxPageSize = 100
+)
+
+var (
+	errInvalidLimit = errors.New("Invalid limit")
+	errInvalidCursor = errors.New("Invalid cursor")
+)
+
+// postsPage is the response envelope of the posts list.
+type postsPage struct{
+	Posts []models.OutputPost `json:"posts"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// cursor is the JSON form of models.Cursor behind the opaque ?cursor= value.
+// It records the order it was issued for, so it cannot be replayed against
+// another one.
+type cursor struct{
+	SortBy models.SortField `json:"s"`
+	Desc bool `json:"d,omitempty"`
+	CreatedAt time.Time `json:"t,omitzero"`
+	Title string `json:"title,omitempty"`
+	ID int `json:"id"`
+}
+
+// parsePage reads ?limit= and ?cursor= of a list request ordered by filter.
+func parsePage(r *http.Request, filter models.PostFilter, maxPageSize int) (models.Page, error){
+	if maxPageSize <= 0{
+		maxPageSize = DefaultMaxPageSize
+	}
+
+	page := models.Page{Limit: min(defaultPageSize, maxPageSize)}
+	if v := r.URL.Query().Get("limit"); v != ""{
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxPageSize{
+			return models.Page{}, errInvalidLimit
+		}
+		page.Limit = n
+	}
+
+	if v := r.URL.Query().Get("cursor"); v != ""{
+		after, err := decodeCursor(v, filter)
+		if err != nil{
+			return models.Page{}, errInvalidCursor
+		}
+		page.After = &after
+	}
+
+	return page, nil
+}
+
+func encodeCursor(c models.Cursor, filter models.PostFilter) string{
+	enc := cursor{SortBy: filter.SortBy, Desc: filter.Desc, ID: c.ID}
+	if filter.SortBy == models.SortByTitle{
+		enc.Title = c.Title
+	} else {
+		enc.CreatedAt = c.CreatedAt
+	}
+
+	data, _ := json.Marshal(enc)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(s string, filter models.PostFilter) (models.Cursor, error){
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil{
+		return models.Cursor{}, err
+	}
+
+	var c cursor
+	if err = json.Unmarshal(data, &c); err != nil{
+		return models.Cursor{}, err
+	}
+	if c.ID < 1 || c.SortBy != filter.SortBy || c.Desc != filter.Desc{
+		return models.Cursor{}, errInvalidCursor
+	}
+	if filter.SortBy != models.SortByTitle && c.CreatedAt.IsZero(){
+		return models.Cursor{}, errInvalidCursor
+	}
+
+	return models.Cursor{CreatedAt: c.CreatedAt, Title: c.Title, ID: c.ID}, nil
+}
+
+// setPageLinks sets RFC 8288 Link headers pointing at the first page and,
+// unless this is the last page, at the next one.
+func setPageLinks(w http.ResponseWriter, r *http.Request, limit int, nextCursor string){
+	link := func(cursor, rel string) string{
+		u := *r.URL
+		q := u.Query()
+		q.Set("limit", strconv.Itoa(limit))
+		q.Del("cursor")
+		if cursor != ""{
+			q.Set("cursor", cursor)
+		}
+		u.RawQuery = q.Encode()
+		return fmt.Sprintf("<%s>; rel=%q", u.RequestURI(), rel)
+	}
+
+	w.Header().Add("Link", link("", "first"))
+	if nextCursor != ""{
+		w.Header().Add("Link", link(nextCursor, "next"))
+	}
+}
@@ -0,0 +1,164 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+
+	"github.com/RomanKovalev007/mai_news/internal/lib/jsonpatch"
+	"github.com/RomanKovalev007/mai_news/internal/lib/tags"
+	"github.com/RomanKovalev007/mai_news/internal/models"
+)
+
+const (
+	mergePatchType = "application/merge-patch+json"
+	jsonPatchType = "application/json-patch+json"
+)
+
+var errUnsupportedPatch = errors.New("Unsupported Content-Type")
+
+// postUpdate is a decoded PATCH body: either a merge patch or, for JSON
+// Patch bodies, a list of operations the store applies to the current post.
+type postUpdate struct{
+	patch models.PostPatch
+	ops []jsonpatch.Operation
+}
+
+// apply runs the update against the post on behalf of the user with
+// editorID, 0 if there is none.
+func (u postUpdate) apply(ctx context.Context, poster Poster, id, version, editorID int) (models.OutputPost, error){
+	if u.ops != nil{
+		return poster.ApplyPatch(ctx, id, version, editorID, u.ops)
+	}
+	patch := u.patch
+	patch.EditorID = editorID
+	return poster.PatchPost(ctx, id, version, patch)
+}
+
+// section returns the section the update moves the post to, if it sets one.
+func (u postUpdate) section() (string, bool){
+	if u.ops != nil{
+		return jsonpatch.Section(u.ops)
+	}
+	if u.patch.Section == nil{
+		return "", false
+	}
+	return *u.patch.Section, true
+}
+
+// patchError is a well-formed PATCH body that cannot apply to a post.
+type patchError struct{
+	err error
+}
+
+func (e patchError) Error() string{
+	return "Invalid JSON Patch: " + e.err.Error()
+}
+
+// decodePatch reads the body of a PATCH request as a partial update of a
+// post. Plain JSON bodies are read as merge patches too, as that is what
+// clients sending a subset of the post fields expect.
+func decodePatch(r *http.Request) (postUpdate, error){
+	mediaType := "application/json"
+	if v := r.Header.Get("Content-Type"); v != ""{
+		var err error
+		if mediaType, _, err = mime.ParseMediaType(v); err != nil{
+			return postUpdate{}, errUnsupportedPatch
+		}
+	}
+
+	switch mediaType{
+	case mergePatchType, "application/json":
+		patch, err := decodeMergePatch(r.Body)
+		return postUpdate{patch: patch}, err
+	case jsonPatchType:
+		ops, err := jsonpatch.Decode(r.Body)
+		var opErr *jsonpatch.OpError
+		if errors.As(err, &opErr){
+			return postUpdate{}, patchError{err}
+		}
+		if err != nil{
+			return postUpdate{}, errors.New("Invalid request payload")
+		}
+		return postUpdate{ops: ops}, nil
+	}
+	return postUpdate{}, errUnsupportedPatch
+}
+
+// decodeMergePatch reads an RFC 7396 JSON Merge Patch. Members left out of
+// the patch are left untouched; null would clear a field, which title,
+// content, slug and section do not allow, and removes all tags. Unknown members are ignored.
+func decodeMergePatch(body io.Reader) (models.PostPatch, error){
+	var doc map[string]json.RawMessage
+	if err := json.NewDecoder(body).Decode(&doc); err != nil || doc == nil{
+		return models.PostPatch{}, errors.New("Invalid request payload")
+	}
+
+	var patch models.PostPatch
+	fields := []struct{
+		name string
+		value **string
+	}{
+		{"title", &patch.Title},
+		{"content", &patch.Content},
+		{"slug", &patch.Slug},
+		{"section", &patch.Section},
+	}
+	for _, f := range fields{
+		raw, ok := doc[f.name]
+		if !ok{
+			continue
+		}
+		if string(raw) == "null"{
+			return models.PostPatch{}, fmt.Errorf("Field %s cannot be null", f.name)
+		}
+		var v string
+		if err := json.Unmarshal(raw, &v); err != nil{
+			return models.PostPatch{}, fmt.Errorf("Field %s must be a string", f.name)
+		}
+		*f.value = &v
+	}
+	if patch.Slug != nil{
+		if err := validSlug(*patch.Slug); err != nil || *patch.Slug == ""{
+			return models.PostPatch{}, errInvalidSlug
+		}
+	}
+	if patch.Section != nil{
+		if err := validSlug(*patch.Section); err != nil || *patch.Section == ""{
+			return models.PostPatch{}, errUnknownSection
+		}
+	}
+
+	// tags are optional, so null removes them all
+	if raw, ok := doc["tags"]; ok{
+		var names []string
+		if err := json.Unmarshal(raw, &names); err != nil{
+			return models.PostPatch{}, errors.New("Field tags must be an array of strings")
+		}
+		names, err := tags.NormalizeAll(names)
+		if err != nil{
+			return models.PostPatch{}, errInvalidTag
+		}
+		patch.Tags = &names
+	}
+
+	return patch, nil
+}
+
+// writePatchError answers a PATCH request whose body could not be decoded.
+func writePatchError(w http.ResponseWriter, err error){
+	if errors.Is(err, errUnsupportedPatch){
+		w.Header().Set("Accept-Patch", mergePatchType + ", " + jsonPatchType)
+		http.Error(w, err.Error(), http.StatusUnsupportedMediaType)
+		return
+	}
+	if errors.As(err, &patchError{}){
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+	http.Error(w, err.Error(), http.StatusBadRequest)
+}
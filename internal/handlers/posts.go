@@ -1,39 +1,100 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 
 	"log/slog"
 	"net/http"
 	"strconv"
 
+	"github.com/RomanKovalev007/mai_news/internal/authz"
+	"github.com/RomanKovalev007/mai_news/internal/lib/jsonpatch"
+	"github.com/RomanKovalev007/mai_news/internal/lib/tags"
+	"github.com/RomanKovalev007/mai_news/internal/middleware"
 	"github.com/RomanKovalev007/mai_news/internal/models"
+	"github.com/RomanKovalev007/mai_news/internal/storage"
 )
 
 
 type Poster interface{
-	GetAllPosts() ([]models.OutputPost, error)
-	GetPost(id int) (models.OutputPost, error)
-	SavePost(post models.InputPost) (models.OutputPost, error)
-	PatchPost(id int, inputPost models.InputPost) (models.OutputPost, error)
-	DeletePost(id int) error
+	GetAllPosts(ctx context.Context, filter models.PostFilter, page models.Page) (models.PostsPage, error)
+	GetPost(ctx context.Context, id int) (models.OutputPost, error)
+	// GetPostBySlug finds a post by its current or a former slug.
+	GetPostBySlug(ctx context.Context, slug string) (models.OutputPost, error)
+	// SavePost and PatchPost fail with storage.ErrSlugTaken when a requested
+	// slug belongs, now or formerly, to another post, with
+	// storage.ErrPostExists when another post of the section has the title
+	// and with storage.ErrSectionNotFound for unknown sections. SavePost
+	// fails with storage.ErrUserNotFound if the author does not exist.
+	SavePost(ctx context.Context, post models.InputPost) (models.OutputPost, error)
+	// PatchPost and DeletePost fail with storage.ErrVersionMismatch unless
+	// version is 0 or the current version of the post.
+	PatchPost(ctx context.Context, id, version int, patch models.PostPatch) (models.OutputPost, error)
+	// ApplyPatch runs JSON Patch operations against the post atomically and
+	// fails with jsonpatch.ErrTestFailed when a test operation does not hold,
+	// with a *jsonpatch.OpError for a tag index past the tags. editorID is
+	// kept like PostPatch.EditorID.
+	ApplyPatch(ctx context.Context, id, version, editorID int, ops []jsonpatch.Operation) (models.OutputPost, error)
+	DeletePost(ctx context.Context, id, version int) error
+	SearchPosts(ctx context.Context, query string, limit int) ([]models.SearchResult, error)
 }
 
-func GetAllPostsHandler(poster Poster, log *slog.Logger) http.HandlerFunc {
+// GetAllPostsHandler serves GET /posts/?limit=...&cursor=... with a page of
+// posts, newest first unless parseFilter parameters say otherwise.
+// maxPageSize caps limit. Conditional requests are answered like in
+// GetPostHandler, with a weak ETag of the page.
+func GetAllPostsHandler(poster Poster, maxPageSize int, log *slog.Logger) http.HandlerFunc {
 	return func (w http.ResponseWriter, r *http.Request){
-		w.Header().Set("Content-Type", "application/json")
-		posts, err := poster.GetAllPosts()
+		filter, err := parseFilter(r)
 		if err != nil {
-			http.Error(w, "Post not found", http.StatusNotFound)
-			log.Error("failed to get all posts", slog.String("error", err.Error()))
+			http.Error(w, err.Error(), http.StatusBadRequest)
 			return
 		}
-		json.NewEncoder(w).Encode(posts)
+
+		writePostsPage(w, r, poster, filter, maxPageSize, log)
+	}
+}
+
+// writePostsPage answers a posts list request with the page of posts
+// matching filter that the request asks for.
+func writePostsPage(w http.ResponseWriter, r *http.Request, poster Poster, filter models.PostFilter, maxPageSize int, log *slog.Logger){
+	page, err := parsePage(r, filter, maxPageSize)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	result, err := poster.GetAllPosts(r.Context(), filter, page)
+	if err != nil {
+		if writeContextError(w, r, log, err){
+			return
+		}
+		http.Error(w, "Post not found", http.StatusNotFound)
+		log.Error("failed to get all posts", slog.String("error", err.Error()))
+		return
+	}
+
+	resp := postsPage{Posts: result.Posts}
+	if resp.Posts == nil {
+		resp.Posts = []models.OutputPost{}
+	}
+	postsInZone(r, resp.Posts)
+	if result.Next != nil {
+		resp.NextCursor = encodeCursor(*result.Next, filter)
+	}
+	setPageLinks(w, r, page.Limit, resp.NextCursor)
+	if writeNotModified(w, r, pageETag(resp.Posts, resp.NextCursor), countedLastModified(result.LastModified, resp.Posts...)) {
+		return
 	}
+
+	json.NewEncoder(w).Encode(resp)
 }
 
 
-func CreatePostHandler(poster Poster, log *slog.Logger) http.HandlerFunc {
+func CreatePostHandler(poster Poster, authorizer Authorizer, log *slog.Logger) http.HandlerFunc {
 	return func (w http.ResponseWriter, r *http.Request){
 		var post models.InputPost
 
@@ -41,21 +102,66 @@ func CreatePostHandler(poster Poster, log *slog.Logger) http.HandlerFunc {
 			http.Error(w, "Invalid request payload", http.StatusBadRequest)
 			return
 		}
+		if err := validSlug(post.Slug); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := validSlug(post.Section); err != nil {
+			http.Error(w, errUnknownSection.Error(), http.StatusBadRequest)
+			return
+		}
+		names, err := tags.NormalizeAll(post.Tags)
+		if err != nil {
+			http.Error(w, errInvalidTag.Error(), http.StatusBadRequest)
+			return
+		}
+		post.Tags = names
+		if !authorizePublish(w, r, authorizer, post.Section, log){
+			return
+		}
+		// the post is by whoever is signed in; anonymous posts have no author
+		if user, ok := middleware.User(r.Context()); ok {
+			post.AuthorID = user.ID
+		}
 
-		createdPost, err := poster.SavePost(post)
+		createdPost, err := poster.SavePost(r.Context(), post)
 		if err != nil {
+			if writeContextError(w, r, log, err){
+				return
+			}
+			if errors.Is(err, storage.ErrSlugTaken){
+				http.Error(w, "Slug is already taken", http.StatusConflict)
+				return
+			}
+			if errors.Is(err, storage.ErrPostExists){
+				http.Error(w, "Another post already has this title", http.StatusConflict)
+				return
+			}
+			if errors.Is(err, storage.ErrSectionNotFound){
+				http.Error(w, errUnknownSection.Error(), http.StatusBadRequest)
+				return
+			}
+			if errors.Is(err, storage.ErrUserNotFound){
+				// the account was deleted after the request was authenticated
+				http.Error(w, "Unknown author", http.StatusUnauthorized)
+				return
+			}
 			http.Error(w, "failed to save post", http.StatusInternalServerError)
 			log.Error("failed to save post", slog.String("error", err.Error()))
 			return 
 		}
 
 		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("ETag", etag(createdPost))
 		w.WriteHeader(http.StatusCreated) 
-		json.NewEncoder(w).Encode(createdPost)
+		json.NewEncoder(w).Encode(inZone(r, createdPost))
 	}
 }
 
-func GetPostHandler(poster Poster, log *slog.Logger) http.HandlerFunc {
+// GetPostHandler serves a post with its ETag and Last-Modified, or 304 Not
+// Modified to conditional requests the client's copy still satisfies. Either
+// counts as a view of the post.
+func GetPostHandler(poster Poster, views ViewRecorder, log *slog.Logger) http.HandlerFunc {
 	return func (w http.ResponseWriter, r *http.Request){
 		w.Header().Set("Content-Type", "application/json")
 		id, err := strconv.Atoi(r.PathValue("id"))
@@ -63,21 +169,33 @@ func GetPostHandler(poster Poster, log *slog.Logger) http.HandlerFunc {
 			http.Error(w, "Invalid post ID", http.StatusBadRequest)
 			return
 		}
-		post, err := poster.GetPost(id)
+		post, err := poster.GetPost(r.Context(), id)
 		if err != nil {
+			if writeContextError(w, r, log, err){
+				return
+			}
 			http.Error(w, "Post not found", http.StatusNotFound)
 			log.Error("failed to get post", slog.String("error", err.Error()))
 			return
 		}
-		json.NewEncoder(w).Encode(post)
+		views.RecordView(post.ID)
+		if writeNotModified(w, r, etag(post), countedLastModified(post.UpdatedAt, post)) {
+			return
+		}
+		json.NewEncoder(w).Encode(inZone(r, post))
 	}
 }
 
-func PatchPostHandler(poster Poster, log *slog.Logger) http.HandlerFunc {
+// PatchPostHandler updates a post from a merge patch or a JSON Patch body,
+// see decodePatch. A failed JSON Patch test, a taken slug or a title in use
+// in the section answers 409. The If-Match header makes the update conditional on the ETag
+// of the post; requireIfMatch rejects requests without it. The user must be
+// allowed to edit the post.
+func PatchPostHandler(poster Poster, authorizer Authorizer, requireIfMatch bool, log *slog.Logger) http.HandlerFunc {
 	return func (w http.ResponseWriter, r *http.Request){
-		var inputPost models.InputPost
-		if err := json.NewDecoder(r.Body).Decode(&inputPost); err != nil {
-			http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		update, err := decodePatch(r)
+		if err != nil {
+			writePatchError(w, err)
 			return
 		}
 		w.Header().Set("Content-Type", "application/json")
@@ -88,18 +206,62 @@ func PatchPostHandler(poster Poster, log *slog.Logger) http.HandlerFunc {
 			return
 		}
 
+		version, err := ifMatchVersion(r, requireIfMatch)
+		if err != nil {
+			writePreconditionError(w, err)
+			return
+		}
+
+		if !authorizePost(w, r, authorizer, authz.EditPost, id, "Post not found", log){
+			return
+		}
+		if section, ok := update.section(); ok && !authorizeMove(w, r, authorizer, id, section, log){
+			return
+		}
 
-		post, err := poster.PatchPost(id, inputPost)
+		user, _ := middleware.User(r.Context())
+		post, err := update.apply(r.Context(), poster, id, version, user.ID)
 		if err != nil {
+			if writeContextError(w, r, log, err){
+				return
+			}
+			if errors.Is(err, storage.ErrVersionMismatch){
+				writePreconditionError(w, err)
+				return
+			}
+			if errors.Is(err, jsonpatch.ErrTestFailed){
+				http.Error(w, "JSON Patch test failed", http.StatusConflict)
+				return
+			}
+			var opErr *jsonpatch.OpError
+			if errors.As(err, &opErr){
+				writePatchError(w, patchError{err})
+				return
+			}
+			if errors.Is(err, storage.ErrSlugTaken){
+				http.Error(w, "Slug is already taken", http.StatusConflict)
+				return
+			}
+			if errors.Is(err, storage.ErrPostExists){
+				http.Error(w, "Another post already has this title", http.StatusConflict)
+				return
+			}
+			if errors.Is(err, storage.ErrSectionNotFound){
+				http.Error(w, errUnknownSection.Error(), http.StatusBadRequest)
+				return
+			}
 			http.Error(w, "Post not found", http.StatusNotFound)
 			log.Error("failed to patch post", slog.String("error", err.Error()))
 			return
 		}
-		json.NewEncoder(w).Encode(post)
+		w.Header().Set("ETag", etag(post))
+		json.NewEncoder(w).Encode(inZone(r, post))
 	}
 }
 
-func DeletePostHandler(poster Poster, log *slog.Logger) http.HandlerFunc {
+// DeletePostHandler moves a post to the trash, honouring If-Match and
+// checking the user like PatchPostHandler.
+func DeletePostHandler(poster Poster, authorizer Authorizer, requireIfMatch bool, log *slog.Logger) http.HandlerFunc {
 	return func (w http.ResponseWriter, r *http.Request){
 		
 		id, err := strconv.Atoi(r.PathValue("id"))
@@ -108,8 +270,25 @@ func DeletePostHandler(poster Poster, log *slog.Logger) http.HandlerFunc {
 			return
 		}
 
-		err = poster.DeletePost(id)
+		version, err := ifMatchVersion(r, requireIfMatch)
+		if err != nil {
+			writePreconditionError(w, err)
+			return
+		}
+
+		if !authorizePost(w, r, authorizer, authz.DeletePost, id, "Post not found", log){
+			return
+		}
+
+		err = poster.DeletePost(r.Context(), id, version)
 		if err != nil {
+			if writeContextError(w, r, log, err){
+				return
+			}
+			if errors.Is(err, storage.ErrVersionMismatch){
+				writePreconditionError(w, err)
+				return
+			}
 			http.Error(w, "Post not found", http.StatusNotFound)
 			log.Error("failed to delete post", slog.String("error", err.Error()))
 			return
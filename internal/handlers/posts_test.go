@@ -2,40 +2,207 @@ package handlers
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/RomanKovalev007/mai_news/internal/lib/jsonpatch"
+	"github.com/RomanKovalev007/mai_news/internal/lib/logger/slogdiscard"
+	"github.com/RomanKovalev007/mai_news/internal/middleware"
 	"github.com/RomanKovalev007/mai_news/internal/models"
+	"github.com/RomanKovalev007/mai_news/internal/storage"
+	"github.com/RomanKovalev007/mai_news/internal/storage/memstore"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 func TestGetAllPostsHandler(t *testing.T) {
+	newestFirst := models.PostFilter{SortBy: models.SortByCreatedAt, Desc: true}
+	next := models.Cursor{CreatedAt: time.Date(2025, 9, 1, 10, 0, 0, 0, time.UTC), ID: 2}
+	nextCursor := encodeCursor(next, newestFirst)
+
 	tests := []struct {
 		name           string
+		target         string
 		mockSetup      func(*MockPoster)
 		expectedStatus int
 		expectedBody   string
+		expectedLinks  []string
 	}{
 		{
-			name: "success",
+			name:   "success",
+			target: "/posts/?limit=2",
 			mockSetup: func(mp *MockPoster) {
-				mp.On("GetAllPosts").Return([]models.OutputPost{
-					{ID: 1, Title: "Test Post 1", Content: "Content 1", CreatedAt: ""},
-					{ID: 2, Title: "Test Post 2", Content: "Content 2", CreatedAt: ""},
+				mp.On("GetAllPosts", mock.Anything, newestFirst, models.Page{Limit: 2}).Return(models.PostsPage{
+					Posts: []models.OutputPost{
+						{ID: 1, Title: "Test Post 1", Content: "Content 1", CreatedAt: next.CreatedAt},
+						{ID: 2, Title: "Test Post 2", Content: "Content 2", CreatedAt: next.CreatedAt},
+					},
+					Next: &next,
 				}, nil)
 			},
 			expectedStatus: http.StatusOK,
-			expectedBody:   `[{"id":1,"title":"Test Post 1","content":"Content 1","created_at":""},{"id":2,"title":"Test Post 2","content":"Content 2","created_at":""}]`+"\n",
+			expectedBody:   `{"posts":[{"id":1,"title":"Test Post 1","content":"Content 1","created_at":"2025-09-01T10:00:00Z"},{"id":2,"title":"Test Post 2","content":"Content 2","created_at":"2025-09-01T10:00:00Z"}],"next_cursor":"` + nextCursor + `"}` + "\n",
+			expectedLinks: []string{
+				`</posts/?limit=2>; rel="first"`,
+				`</posts/?cursor=` + nextCursor + `&limit=2>; rel="next"`,
+			},
+		},
+		{
+			name:   "last page",
+			target: "/posts/?cursor=" + nextCursor,
+			mockSetup: func(mp *MockPoster) {
+				mp.On("GetAllPosts", mock.Anything, newestFirst, models.Page{Limit: defaultPageSize, After: &next}).Return(models.PostsPage{}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"posts":[]}` + "\n",
+			expectedLinks:  []string{`</posts/?limit=20>; rel="first"`},
+		},
+		{
+			name:           "invalid limit",
+			target:         "/posts/?limit=500",
+			mockSetup:      func(mp *MockPoster) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Invalid limit\n",
+		},
+		{
+			name:           "invalid cursor",
+			target:         "/posts/?cursor=bm9wZQ",
+			mockSetup:      func(mp *MockPoster) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Invalid cursor\n",
+		},
+		{
+			name:           "cursor of another order",
+			target:         "/posts/?order=asc&cursor=" + nextCursor,
+			mockSetup:      func(mp *MockPoster) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Invalid cursor\n",
+		},
+		{
+			name:   "filters",
+			target: "/posts/?from=2025-09-01&to=2025-09-07&title_prefix=%D0%9F%D1%80%D0%B8%D1%91%D0%BC&sort=title&order=desc",
+			mockSetup: func(mp *MockPoster) {
+				mp.On("GetAllPosts", mock.Anything, models.PostFilter{
+					From:        time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC),
+					To:          time.Date(2025, 9, 8, 0, 0, 0, 0, time.UTC),
+					TitlePrefix: "Приём",
+					SortBy:      models.SortByTitle,
+					Desc:        true,
+				}, models.Page{Limit: defaultPageSize}).Return(models.PostsPage{}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"posts":[]}` + "\n",
+			expectedLinks:  []string{`</posts/?from=2025-09-01&limit=20&order=desc&sort=title&title_prefix=%D0%9F%D1%80%D0%B8%D1%91%D0%BC&to=2025-09-07>; rel="first"`},
+		},
+		{
+			name:   "time range",
+			target: "/posts/?from=2025-09-01T10:00:00%2B03:00&sort=title",
+			mockSetup: func(mp *MockPoster) {
+				mp.On("GetAllPosts", mock.Anything, models.PostFilter{
+					From:   time.Date(2025, 9, 1, 7, 0, 0, 0, time.UTC),
+					SortBy: models.SortByTitle,
+				}, mock.Anything).Return(models.PostsPage{}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"posts":[]}` + "\n",
+			expectedLinks:  []string{`</posts/?from=2025-09-01T10%3A00%3A00%2B03%3A00&limit=20&sort=title>; rel="first"`},
+		},
+		{
+			name:   "dates in display time zone",
+			target: "/posts/?from=2025-09-01&to=2025-09-01&tz=Europe/Moscow",
+			mockSetup: func(mp *MockPoster) {
+				mp.On("GetAllPosts", mock.Anything, models.PostFilter{
+					From:   time.Date(2025, 8, 31, 21, 0, 0, 0, time.UTC),
+					To:     time.Date(2025, 9, 1, 21, 0, 0, 0, time.UTC),
+					SortBy: models.SortByCreatedAt,
+					Desc:   true,
+				}, mock.Anything).Return(models.PostsPage{}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"posts":[]}` + "\n",
+			expectedLinks:  []string{`</posts/?from=2025-09-01&limit=20&to=2025-09-01&tz=Europe%2FMoscow>; rel="first"`},
+		},
+		{
+			name:           "invalid from",
+			target:         "/posts/?from=yesterday",
+			mockSetup:      func(mp *MockPoster) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Invalid from\n",
+		},
+		{
+			name:           "invalid to",
+			target:         "/posts/?to=2025-13-01",
+			mockSetup:      func(mp *MockPoster) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Invalid to\n",
+		},
+		{
+			name:           "empty range",
+			target:         "/posts/?from=2025-09-08&to=2025-09-01",
+			mockSetup:      func(mp *MockPoster) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Invalid date range\n",
+		},
+		{
+			name:           "invalid sort",
+			target:         "/posts/?sort=content",
+			mockSetup:      func(mp *MockPoster) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Invalid sort\n",
+		},
+		{
+			name:           "invalid order",
+			target:         "/posts/?order=random",
+			mockSetup:      func(mp *MockPoster) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Invalid order\n",
+		},
+		{
+			name:   "tags",
+			target: "/posts/?tag=Sports&tag=admissions&tag=sports&tag_mode=all",
+			mockSetup: func(mp *MockPoster) {
+				mp.On("GetAllPosts", mock.Anything, models.PostFilter{
+					Tags:    []string{"admissions", "sports"},
+					AllTags: true,
+					SortBy:  models.SortByCreatedAt,
+					Desc:    true,
+				}, mock.Anything).Return(models.PostsPage{Posts: []models.OutputPost{
+					{ID: 1, Title: "Test Post", Tags: []string{"admissions", "sports"}},
+				}}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"posts":[{"id":1,"title":"Test Post","content":"","tags":["admissions","sports"],"created_at":"0001-01-01T00:00:00Z"}]}` + "\n",
+			expectedLinks:  []string{`</posts/?limit=20&tag=Sports&tag=admissions&tag=sports&tag_mode=all>; rel="first"`},
 		},
 		{
-			name: "not found",
+			name:           "invalid tag",
+			target:         "/posts/?tag=",
+			mockSetup:      func(mp *MockPoster) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Invalid tag\n",
+		},
+		{
+			name:           "invalid tag mode",
+			target:         "/posts/?tag=sports&tag_mode=none",
+			mockSetup:      func(mp *MockPoster) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Invalid tag_mode\n",
+		},
+		{
+			name:   "not found",
+			target: "/posts/",
 			mockSetup: func(mp *MockPoster) {
-				mp.On("GetAllPosts").Return([]models.OutputPost{}, errors.New("not found"))
+				mp.On("GetAllPosts", mock.Anything, newestFirst, models.Page{Limit: defaultPageSize}).Return(models.PostsPage{}, errors.New("not found"))
 			},
 			expectedStatus: http.StatusNotFound,
 			expectedBody:   "Post not found\n",
@@ -47,23 +214,62 @@ func TestGetAllPostsHandler(t *testing.T) {
 			mockPoster := NewMockPoster(t)
 			tt.mockSetup(mockPoster)
 
-			handler := GetAllPostsHandler(mockPoster, slog.Default())
-			req := httptest.NewRequest("GET", "/posts", nil)
+			handler := middleware.TimeZone(time.UTC)(GetAllPostsHandler(mockPoster, 0, slog.Default()))
+			req := httptest.NewRequest("GET", tt.target, nil)
 			w := httptest.NewRecorder()
 
-			handler(w, req)
+			handler.ServeHTTP(w, req)
 
 			assert.Equal(t, tt.expectedStatus, w.Code)
 			assert.Equal(t, tt.expectedBody, w.Body.String())
+			assert.Equal(t, tt.expectedLinks, w.Header().Values("Link"))
 			mockPoster.AssertExpectations(t)
 		})
 	}
 }
 
+func TestGetAllPostsPagination(t *testing.T) {
+	poster := memstore.New()
+	for i := 1; i <= 5; i++ {
+		_, err := poster.SavePost(context.Background(), models.InputPost{Title: fmt.Sprintf("post %d", i)})
+		assert.NoError(t, err)
+	}
+	handler := GetAllPostsHandler(poster, 2, slogdiscard.NewDiscardLogger())
+
+	w := httptest.NewRecorder()
+	handler(w, httptest.NewRequest("GET", "/posts/?limit=3", nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	var ids []int
+	target := "/posts/"
+	for target != "" {
+		w := httptest.NewRecorder()
+		handler(w, httptest.NewRequest("GET", target, nil))
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var page postsPage
+		assert.NoError(t, json.NewDecoder(w.Body).Decode(&page))
+		for _, post := range page.Posts {
+			ids = append(ids, post.ID)
+		}
+
+		target = ""
+		if page.NextCursor != "" {
+			target = "/posts/?cursor=" + page.NextCursor
+		}
+	}
+	assert.Equal(t, []int{5, 4, 3, 2, 1}, ids)
+}
+
 func TestGetPostHandler(t *testing.T) {
+	updatedAt := time.Date(2025, 9, 1, 10, 0, 0, 500, time.UTC)
+	post := models.OutputPost{ID: 1, Title: "Test Post", Content: "Test Content", UpdatedAt: updatedAt, Version: 3}
+	body := `{"id":1,"title":"Test Post","content":"Test Content","created_at":"0001-01-01T00:00:00Z","updated_at":"2025-09-01T10:00:00.0000005Z"}` + "\n"
+
 	tests := []struct {
 		name           string
 		postID         string
+		headers        map[string]string
 		mockSetup      func(*MockPoster)
 		expectedStatus int
 		expectedBody   string
@@ -72,12 +278,48 @@ func TestGetPostHandler(t *testing.T) {
 			name:   "success",
 			postID: "1",
 			mockSetup: func(mp *MockPoster) {
-				mp.On("GetPost", 1).Return(models.OutputPost{
-					ID: 1, Title: "Test Post", Content: "Test Content",
-				}, nil)
+				mp.On("GetPost", mock.Anything, 1).Return(post, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   body,
+		},
+		{
+			name:    "etag matches",
+			postID:  "1",
+			headers: map[string]string{"If-None-Match": `"2", W/"3"`},
+			mockSetup: func(mp *MockPoster) {
+				mp.On("GetPost", mock.Anything, 1).Return(post, nil)
+			},
+			expectedStatus: http.StatusNotModified,
+		},
+		{
+			name:    "stale etag",
+			postID:  "1",
+			headers: map[string]string{"If-None-Match": `"2"`, "If-Modified-Since": "Mon, 01 Sep 2025 10:00:00 GMT"},
+			mockSetup: func(mp *MockPoster) {
+				mp.On("GetPost", mock.Anything, 1).Return(post, nil)
 			},
 			expectedStatus: http.StatusOK,
-			expectedBody:   `{"id":1,"title":"Test Post","content":"Test Content","created_at":""}` + "\n",
+			expectedBody:   body,
+		},
+		{
+			name:    "not modified since",
+			postID:  "1",
+			headers: map[string]string{"If-Modified-Since": "Mon, 01 Sep 2025 10:00:00 GMT"},
+			mockSetup: func(mp *MockPoster) {
+				mp.On("GetPost", mock.Anything, 1).Return(post, nil)
+			},
+			expectedStatus: http.StatusNotModified,
+		},
+		{
+			name:    "modified since",
+			postID:  "1",
+			headers: map[string]string{"If-Modified-Since": "Mon, 01 Sep 2025 09:59:59 GMT"},
+			mockSetup: func(mp *MockPoster) {
+				mp.On("GetPost", mock.Anything, 1).Return(post, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   body,
 		},
 		{
 			name:   "invalid id",
@@ -92,7 +334,7 @@ func TestGetPostHandler(t *testing.T) {
 			name:   "not found",
 			postID: "999",
 			mockSetup: func(mp *MockPoster) {
-				mp.On("GetPost", 999).Return(models.OutputPost{}, errors.New("not found"))
+				mp.On("GetPost", mock.Anything, 999).Return(models.OutputPost{}, errors.New("not found"))
 			},
 			expectedStatus: http.StatusNotFound,
 			expectedBody:   "Post not found\n",
@@ -104,20 +346,135 @@ func TestGetPostHandler(t *testing.T) {
 			mockPoster := NewMockPoster(t)
 			tt.mockSetup(mockPoster)
 
-			handler := GetPostHandler(mockPoster, slog.Default())
+			var views viewLog
+			handler := GetPostHandler(mockPoster, &views, slog.Default())
 			req := httptest.NewRequest("GET", "/posts/"+tt.postID, nil)
 			req.SetPathValue("id", tt.postID)
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
 			w := httptest.NewRecorder()
 
 			handler(w, req)
 
 			assert.Equal(t, tt.expectedStatus, w.Code)
 			assert.Equal(t, tt.expectedBody, w.Body.String())
+			if w.Code == http.StatusOK || w.Code == http.StatusNotModified {
+				assert.Equal(t, `"3"`, w.Header().Get("ETag"))
+				assert.Equal(t, "Mon, 01 Sep 2025 10:00:00 GMT", w.Header().Get("Last-Modified"))
+				// conditional reads count as views too
+				assert.Equal(t, viewLog{1}, views)
+			} else {
+				assert.Empty(t, views)
+			}
 			mockPoster.AssertExpectations(t)
 		})
 	}
 }
 
+func TestGetPostHandlerCounts(t *testing.T) {
+	post := models.OutputPost{ID: 1, Title: "Test Post", UpdatedAt: time.Date(2025, 9, 1, 10, 0, 0, 0, time.UTC), CommentCount: 2, Version: 3}
+	get := func(post models.OutputPost, headers map[string]string) *httptest.ResponseRecorder {
+		mockPoster := NewMockPoster(t)
+		mockPoster.On("GetPost", mock.Anything, 1).Return(post, nil)
+		req := httptest.NewRequest("GET", "/posts/1/", nil)
+		req.SetPathValue("id", "1")
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		w := httptest.NewRecorder()
+		GetPostHandler(mockPoster, &viewLog{}, slog.Default())(w, req)
+		return w
+	}
+
+	// the counts are not part of the version, but they are of the tag
+	w := get(post, map[string]string{"If-None-Match": `"3"`})
+	assert.Equal(t, http.StatusOK, w.Code)
+	tag := w.Header().Get("ETag")
+	assert.True(t, strings.HasPrefix(tag, `"3-`), tag)
+	assert.Empty(t, w.Header().Get("Last-Modified"))
+	assert.Equal(t, http.StatusNotModified, get(post, map[string]string{"If-None-Match": tag}).Code)
+	assert.Equal(t, http.StatusOK, get(post, map[string]string{"If-Modified-Since": "Mon, 01 Sep 2025 10:00:00 GMT"}).Code)
+
+	post.Reactions = map[models.ReactionKind]int{models.ReactionLike: 1}
+	assert.Equal(t, http.StatusOK, get(post, map[string]string{"If-None-Match": tag}).Code)
+	post.Reactions, post.CommentCount = nil, 3
+	assert.Equal(t, http.StatusOK, get(post, map[string]string{"If-None-Match": tag}).Code)
+
+	// edits are made against the version alone
+	req := httptest.NewRequest("PATCH", "/posts/1/", nil)
+	req.Header.Set("If-Match", tag)
+	version, err := ifMatchVersion(req, true)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, version)
+}
+
+func TestGetPostHandlerTimeZone(t *testing.T) {
+	mockPoster := NewMockPoster(t)
+	createdAt := time.Date(2025, 9, 1, 10, 0, 0, 0, time.UTC)
+	mockPoster.On("GetPost", mock.Anything, 1).Return(models.OutputPost{
+		ID: 1, Title: "Test Post", CreatedAt: createdAt, UpdatedAt: createdAt, Version: 1,
+	}, nil)
+
+	handler := middleware.TimeZone(time.UTC)(GetPostHandler(mockPoster, &viewLog{}, slog.Default()))
+	req := httptest.NewRequest("GET", "/posts/1/?tz=Europe/Moscow", nil)
+	req.SetPathValue("id", "1")
+	w := httptest.NewRecorder()
+
+	handler.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `{"id":1,"title":"Test Post","content":"","created_at":"2025-09-01T13:00:00+03:00","updated_at":"2025-09-01T13:00:00+03:00"}`+"\n", w.Body.String())
+	assert.Equal(t, "Mon, 01 Sep 2025 10:00:00 GMT", w.Header().Get("Last-Modified"))
+}
+
+func TestGetAllPostsConditional(t *testing.T) {
+	poster := memstore.New()
+	for i := 1; i <= 3; i++ {
+		_, err := poster.SavePost(context.Background(), models.InputPost{Title: fmt.Sprintf("post %d", i)})
+		assert.NoError(t, err)
+	}
+	handler := GetAllPostsHandler(poster, 0, slogdiscard.NewDiscardLogger())
+
+	get := func(headers map[string]string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/posts/?limit=2", nil)
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		w := httptest.NewRecorder()
+		handler(w, req)
+		return w
+	}
+
+	w := get(nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	tag, lastModified := w.Header().Get("ETag"), w.Header().Get("Last-Modified")
+	assert.True(t, strings.HasPrefix(tag, `W/"`), tag)
+	assert.NotEmpty(t, lastModified)
+
+	w = get(map[string]string{"If-None-Match": tag})
+	assert.Equal(t, http.StatusNotModified, w.Code)
+	assert.Empty(t, w.Body.String())
+	w = get(map[string]string{"If-Modified-Since": lastModified})
+	assert.Equal(t, http.StatusNotModified, w.Code)
+
+	// trashing a post on the page changes it
+	assert.NoError(t, poster.DeletePost(context.Background(), 3, 0))
+	w = get(map[string]string{"If-None-Match": tag})
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotEqual(t, tag, w.Header().Get("ETag"))
+
+	// and so does a comment on one of its posts, which leaves its version
+	// and updated_at alone
+	tag, lastModified = w.Header().Get("ETag"), w.Header().Get("Last-Modified")
+	_, err := poster.SaveComment(context.Background(), models.InputComment{PostID: 2, Content: "Hi", Status: models.CommentApproved})
+	assert.NoError(t, err)
+	w = get(map[string]string{"If-None-Match": tag})
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotEqual(t, tag, w.Header().Get("ETag"))
+	assert.Equal(t, http.StatusOK, get(map[string]string{"If-Modified-Since": lastModified}).Code)
+}
+
 func TestCreatePostHandler(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -133,12 +490,12 @@ func TestCreatePostHandler(t *testing.T) {
 				Content: "New Content",
 			},
 			mockSetup: func(mp *MockPoster) {
-				mp.On("SavePost", mock.AnythingOfType("models.InputPost")).Return(models.OutputPost{
+				mp.On("SavePost", mock.Anything, mock.AnythingOfType("models.InputPost")).Return(models.OutputPost{
 					ID: 1, Title: "New Post", Content: "New Content",
 				}, nil)
 			},
 			expectedStatus: http.StatusCreated,
-			expectedBody:   `{"id":1,"title":"New Post","content":"New Content","created_at":""}` + "\n",
+			expectedBody:   `{"id":1,"title":"New Post","content":"New Content","created_at":"0001-01-01T00:00:00Z"}` + "\n",
 		},
 		{
 			name:           "invalid json",
@@ -147,6 +504,76 @@ func TestCreatePostHandler(t *testing.T) {
 			expectedStatus: http.StatusBadRequest,
 			expectedBody:   "Invalid request payload\n",
 		},
+		{
+			name:        "tags",
+			requestBody: `{"title":"New Post","tags":["Sports","admissions","sports"]}`,
+			mockSetup: func(mp *MockPoster) {
+				mp.On("SavePost", mock.Anything, models.InputPost{Title: "New Post", Tags: []string{"admissions", "sports"}}).Return(models.OutputPost{
+					ID: 1, Title: "New Post", Tags: []string{"admissions", "sports"},
+				}, nil)
+			},
+			expectedStatus: http.StatusCreated,
+			expectedBody:   `{"id":1,"title":"New Post","content":"","tags":["admissions","sports"],"created_at":"0001-01-01T00:00:00Z"}` + "\n",
+		},
+		{
+			name:           "invalid tag",
+			requestBody:    `{"title":"New Post","tags":["a/b"]}`,
+			mockSetup:      func(mp *MockPoster) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Invalid tag\n",
+		},
+		{
+			name:           "invalid slug",
+			requestBody:    models.InputPost{Title: "New Post", Slug: "New Post"},
+			mockSetup:      func(mp *MockPoster) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Invalid slug\n",
+		},
+		{
+			name:        "slug taken",
+			requestBody: models.InputPost{Title: "New Post", Slug: "new-post"},
+			mockSetup: func(mp *MockPoster) {
+				mp.On("SavePost", mock.Anything, models.InputPost{Title: "New Post", Slug: "new-post"}).Return(models.OutputPost{}, storage.ErrSlugTaken)
+			},
+			expectedStatus: http.StatusConflict,
+			expectedBody:   "Slug is already taken\n",
+		},
+		{
+			name:        "section",
+			requestBody: models.InputPost{Title: "New Post", Section: "it"},
+			mockSetup: func(mp *MockPoster) {
+				mp.On("SavePost", mock.Anything, models.InputPost{Title: "New Post", Section: "it"}).Return(models.OutputPost{
+					ID: 1, Title: "New Post", Section: "it",
+				}, nil)
+			},
+			expectedStatus: http.StatusCreated,
+			expectedBody:   `{"id":1,"title":"New Post","content":"","section":"it","created_at":"0001-01-01T00:00:00Z"}` + "\n",
+		},
+		{
+			name:        "unknown section",
+			requestBody: models.InputPost{Title: "New Post", Section: "missing"},
+			mockSetup: func(mp *MockPoster) {
+				mp.On("SavePost", mock.Anything, models.InputPost{Title: "New Post", Section: "missing"}).Return(models.OutputPost{}, storage.ErrSectionNotFound)
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Unknown section\n",
+		},
+		{
+			name:           "invalid section",
+			requestBody:    models.InputPost{Title: "New Post", Section: "Институт"},
+			mockSetup:      func(mp *MockPoster) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Unknown section\n",
+		},
+		{
+			name:        "title in use in the section",
+			requestBody: models.InputPost{Title: "New Post"},
+			mockSetup: func(mp *MockPoster) {
+				mp.On("SavePost", mock.Anything, models.InputPost{Title: "New Post"}).Return(models.OutputPost{}, storage.ErrPostExists)
+			},
+			expectedStatus: http.StatusConflict,
+			expectedBody:   "Another post already has this title\n",
+		},
 		{
 			name: "save error",
 			requestBody: models.InputPost{
@@ -154,7 +581,7 @@ func TestCreatePostHandler(t *testing.T) {
 				Content: "Error Content",
 			},
 			mockSetup: func(mp *MockPoster) {
-				mp.On("SavePost", mock.AnythingOfType("models.InputPost")).Return(models.OutputPost{}, errors.New("save error"))
+				mp.On("SavePost", mock.Anything, mock.AnythingOfType("models.InputPost")).Return(models.OutputPost{}, errors.New("save error"))
 			},
 			expectedStatus: http.StatusInternalServerError,
 			expectedBody:   "failed to save post\n",
@@ -174,7 +601,7 @@ func TestCreatePostHandler(t *testing.T) {
 				bodyBytes, _ = json.Marshal(v)
 			}
 
-			handler := CreatePostHandler(mockPoster, slog.Default())
+			handler := CreatePostHandler(mockPoster, allowAll{}, slog.Default())
 			req := httptest.NewRequest("POST", "/posts", bytes.NewReader(bodyBytes))
 			w := httptest.NewRecorder()
 
@@ -187,29 +614,338 @@ func TestCreatePostHandler(t *testing.T) {
 	}
 }
 
+func TestCreatePostHandlerAuthor(t *testing.T) {
+	mockPoster := NewMockPoster(t)
+	mockPoster.On("SavePost", mock.Anything, models.InputPost{Title: "New Post", AuthorID: 3}).Return(models.OutputPost{
+		ID: 1, Title: "New Post", Author: &models.Author{ID: 3, Name: "Анна"},
+	}, nil)
+
+	handler := CreatePostHandler(mockPoster, allowAll{}, slog.Default())
+	req := httptest.NewRequest("POST", "/posts", strings.NewReader(`{"title":"New Post"}`))
+	req = req.WithContext(middleware.WithUser(req.Context(), models.User{ID: 3, Name: "Анна"}))
+	w := httptest.NewRecorder()
+
+	handler(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, `{"id":1,"title":"New Post","content":"","author":{"id":3,"name":"Анна"},"created_at":"0001-01-01T00:00:00Z"}`+"\n", w.Body.String())
+
+	// an account deleted after the request was authenticated
+	mockPoster.On("SavePost", mock.Anything, models.InputPost{Title: "Other", AuthorID: 4}).Return(models.OutputPost{}, storage.ErrUserNotFound)
+	req = httptest.NewRequest("POST", "/posts", strings.NewReader(`{"title":"Other"}`))
+	req = req.WithContext(middleware.WithUser(req.Context(), models.User{ID: 4}))
+	w = httptest.NewRecorder()
+
+	handler(w, req)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Equal(t, "Unknown author\n", w.Body.String())
+}
+
 func TestPatchPostHandler(t *testing.T) {
 	tests := []struct {
 		name           string
 		postID         string
+		ifMatch        string
+		contentType    string
 		requestBody    interface{}
 		mockSetup      func(*MockPoster)
 		expectedStatus int
 		expectedBody   string
+		expectedETag   string
 	}{
 		{
-			name:   "success",
-			postID: "1",
+			name:    "success",
+			postID:  "1",
+			ifMatch: `"3"`,
 			requestBody: models.InputPost{
 				Title:   "Updated Post",
 				Content: "Updated Content",
 			},
 			mockSetup: func(mp *MockPoster) {
-				mp.On("PatchPost", 1, mock.AnythingOfType("models.InputPost")).Return(models.OutputPost{
-					ID: 1, Title: "Updated Post", Content: "Updated Content",
+				mp.On("PatchPost", mock.Anything, 1, 3, mock.AnythingOfType("models.PostPatch")).Return(models.OutputPost{
+					ID: 1, Title: "Updated Post", Content: "Updated Content", Version: 4,
+				}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"id":1,"title":"Updated Post","content":"Updated Content","created_at":"0001-01-01T00:00:00Z"}` + "\n",
+			expectedETag:   `"4"`,
+		},
+		{
+			name:        "any version",
+			postID:      "1",
+			ifMatch:     "*",
+			requestBody: models.InputPost{Title: "Updated Post"},
+			mockSetup: func(mp *MockPoster) {
+				mp.On("PatchPost", mock.Anything, 1, 0, mock.AnythingOfType("models.PostPatch")).Return(models.OutputPost{
+					ID: 1, Title: "Updated Post", Version: 2,
+				}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"id":1,"title":"Updated Post","content":"","created_at":"0001-01-01T00:00:00Z"}` + "\n",
+			expectedETag:   `"2"`,
+		},
+		{
+			name:        "merge patch",
+			postID:      "1",
+			ifMatch:     `"3"`,
+			contentType: "application/merge-patch+json; charset=utf-8",
+			requestBody: `{"title":"Updated Post","author":"ignored"}`,
+			mockSetup: func(mp *MockPoster) {
+				title := "Updated Post"
+				mp.On("PatchPost", mock.Anything, 1, 3, models.PostPatch{Title: &title}).Return(models.OutputPost{
+					ID: 1, Title: "Updated Post", Content: "Kept", Version: 4,
 				}, nil)
 			},
 			expectedStatus: http.StatusOK,
-			expectedBody:   `{"id":1,"title":"Updated Post","content":"Updated Content","created_at":""}` + "\n",
+			expectedBody:   `{"id":1,"title":"Updated Post","content":"Kept","created_at":"0001-01-01T00:00:00Z"}` + "\n",
+			expectedETag:   `"4"`,
+		},
+		{
+			name:           "null title",
+			postID:         "1",
+			ifMatch:        `"3"`,
+			contentType:    "application/merge-patch+json",
+			requestBody:    `{"title":null}`,
+			mockSetup:      func(mp *MockPoster) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Field title cannot be null\n",
+		},
+		{
+			name:           "content of wrong type",
+			postID:         "1",
+			ifMatch:        `"3"`,
+			contentType:    "application/merge-patch+json",
+			requestBody:    `{"content":42}`,
+			mockSetup:      func(mp *MockPoster) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Field content must be a string\n",
+		},
+		{
+			name:           "invalid slug",
+			postID:         "1",
+			ifMatch:        `"3"`,
+			contentType:    "application/merge-patch+json",
+			requestBody:    `{"slug":"-bad-"}`,
+			mockSetup:      func(mp *MockPoster) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Invalid slug\n",
+		},
+		{
+			name:        "slug taken",
+			postID:      "1",
+			ifMatch:     `"3"`,
+			contentType: "application/merge-patch+json",
+			requestBody: `{"slug":"taken"}`,
+			mockSetup: func(mp *MockPoster) {
+				slug := "taken"
+				mp.On("PatchPost", mock.Anything, 1, 3, models.PostPatch{Slug: &slug}).Return(models.OutputPost{}, storage.ErrSlugTaken)
+			},
+			expectedStatus: http.StatusConflict,
+			expectedBody:   "Slug is already taken\n",
+		},
+		{
+			name:        "move to another section",
+			postID:      "1",
+			ifMatch:     `"3"`,
+			contentType: "application/merge-patch+json",
+			requestBody: `{"section":"it"}`,
+			mockSetup: func(mp *MockPoster) {
+				section := "it"
+				mp.On("PatchPost", mock.Anything, 1, 3, models.PostPatch{Section: &section}).Return(models.OutputPost{
+					ID: 1, Title: "Post", Section: "it", Version: 4,
+				}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"id":1,"title":"Post","content":"","section":"it","created_at":"0001-01-01T00:00:00Z"}` + "\n",
+			expectedETag:   `"4"`,
+		},
+		{
+			name:        "title in use in the new section",
+			postID:      "1",
+			ifMatch:     `"3"`,
+			contentType: "application/merge-patch+json",
+			requestBody: `{"section":"it"}`,
+			mockSetup: func(mp *MockPoster) {
+				section := "it"
+				mp.On("PatchPost", mock.Anything, 1, 3, models.PostPatch{Section: &section}).Return(models.OutputPost{}, storage.ErrPostExists)
+			},
+			expectedStatus: http.StatusConflict,
+			expectedBody:   "Another post already has this title\n",
+		},
+		{
+			name:        "unknown section",
+			postID:      "1",
+			ifMatch:     `"3"`,
+			contentType: "application/merge-patch+json",
+			requestBody: `{"section":"missing"}`,
+			mockSetup: func(mp *MockPoster) {
+				section := "missing"
+				mp.On("PatchPost", mock.Anything, 1, 3, models.PostPatch{Section: &section}).Return(models.OutputPost{}, storage.ErrSectionNotFound)
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Unknown section\n",
+		},
+		{
+			name:           "null section",
+			postID:         "1",
+			ifMatch:        `"3"`,
+			contentType:    "application/merge-patch+json",
+			requestBody:    `{"section":null}`,
+			mockSetup:      func(mp *MockPoster) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Field section cannot be null\n",
+		},
+		{
+			name:        "tags",
+			postID:      "1",
+			ifMatch:     `"3"`,
+			contentType: "application/merge-patch+json",
+			requestBody: `{"tags":["Sports"]}`,
+			mockSetup: func(mp *MockPoster) {
+				mp.On("PatchPost", mock.Anything, 1, 3, models.PostPatch{Tags: &[]string{"sports"}}).Return(models.OutputPost{
+					ID: 1, Title: "Post", Tags: []string{"sports"}, Version: 4,
+				}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"id":1,"title":"Post","content":"","tags":["sports"],"created_at":"0001-01-01T00:00:00Z"}` + "\n",
+			expectedETag:   `"4"`,
+		},
+		{
+			name:        "null tags",
+			postID:      "1",
+			ifMatch:     `"3"`,
+			contentType: "application/merge-patch+json",
+			requestBody: `{"tags":null}`,
+			mockSetup: func(mp *MockPoster) {
+				mp.On("PatchPost", mock.Anything, 1, 3, models.PostPatch{Tags: new([]string)}).Return(models.OutputPost{
+					ID: 1, Title: "Post", Version: 4,
+				}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"id":1,"title":"Post","content":"","created_at":"0001-01-01T00:00:00Z"}` + "\n",
+			expectedETag:   `"4"`,
+		},
+		{
+			name:           "tags of wrong type",
+			postID:         "1",
+			ifMatch:        `"3"`,
+			contentType:    "application/merge-patch+json",
+			requestBody:    `{"tags":"sports"}`,
+			mockSetup:      func(mp *MockPoster) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Field tags must be an array of strings\n",
+		},
+		{
+			name:           "not an object",
+			postID:         "1",
+			ifMatch:        `"3"`,
+			contentType:    "application/merge-patch+json",
+			requestBody:    `["title"]`,
+			mockSetup:      func(mp *MockPoster) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Invalid request payload\n",
+		},
+		{
+			name:        "json patch",
+			postID:      "1",
+			ifMatch:     `"3"`,
+			contentType: "application/json-patch+json",
+			requestBody: `[{"op":"test","path":"/title","value":"Post"},{"op":"replace","path":"/title","value":"Updated Post"}]`,
+			mockSetup: func(mp *MockPoster) {
+				mp.On("ApplyPatch", mock.Anything, 1, 3, 0, []jsonpatch.Operation{
+					{Op: jsonpatch.Test, Field: "title", Value: "Post"},
+					{Op: jsonpatch.Replace, Field: "title", Value: "Updated Post"},
+				}).Return(models.OutputPost{
+					ID: 1, Title: "Updated Post", Content: "Kept", Version: 4,
+				}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"id":1,"title":"Updated Post","content":"Kept","created_at":"0001-01-01T00:00:00Z"}` + "\n",
+			expectedETag:   `"4"`,
+		},
+		{
+			name:        "json patch test failed",
+			postID:      "1",
+			ifMatch:     `"3"`,
+			contentType: "application/json-patch+json",
+			requestBody: `[{"op":"test","path":"/title","value":"Other"}]`,
+			mockSetup: func(mp *MockPoster) {
+				mp.On("ApplyPatch", mock.Anything, 1, 3, 0, mock.Anything).Return(models.OutputPost{}, jsonpatch.ErrTestFailed)
+			},
+			expectedStatus: http.StatusConflict,
+			expectedBody:   "JSON Patch test failed\n",
+		},
+		{
+			name:           "json patch of unsupported path",
+			postID:         "1",
+			ifMatch:        `"3"`,
+			contentType:    "application/json-patch+json",
+			requestBody:    `[{"op":"replace","path":"/id","value":"2"}]`,
+			mockSetup:      func(mp *MockPoster) {},
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedBody:   "Invalid JSON Patch: operation 0: unsupported path \"/id\", supported paths are /title, /content, /slug, /section, /tags, /tags/- and /tags/{index}\n",
+		},
+		{
+			name:        "json patch of a missing tag",
+			postID:      "1",
+			ifMatch:     `"3"`,
+			contentType: "application/json-patch+json",
+			requestBody: `[{"op":"remove","path":"/tags/5"}]`,
+			mockSetup: func(mp *MockPoster) {
+				mp.On("ApplyPatch", mock.Anything, 1, 3, 0, []jsonpatch.Operation{{Op: jsonpatch.Remove, Field: "tag", Index: 5}}).
+					Return(models.OutputPost{}, &jsonpatch.OpError{Index: 0, Reason: "no tag at index 5"})
+			},
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedBody:   "Invalid JSON Patch: operation 0: no tag at index 5\n",
+		},
+		{
+			name:           "malformed json patch",
+			postID:         "1",
+			ifMatch:        `"3"`,
+			contentType:    "application/json-patch+json",
+			requestBody:    `{"op":"replace"}`,
+			mockSetup:      func(mp *MockPoster) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Invalid request payload\n",
+		},
+		{
+			name:           "unsupported content type",
+			postID:         "1",
+			ifMatch:        `"3"`,
+			contentType:    "text/plain",
+			requestBody:    `title=x`,
+			mockSetup:      func(mp *MockPoster) {},
+			expectedStatus: http.StatusUnsupportedMediaType,
+			expectedBody:   "Unsupported Content-Type\n",
+		},
+		{
+			name:           "missing if-match",
+			postID:         "1",
+			requestBody:    models.InputPost{Title: "Test"},
+			mockSetup:      func(mp *MockPoster) {},
+			expectedStatus: http.StatusPreconditionRequired,
+			expectedBody:   "If-Match header is required\n",
+		},
+		{
+			name:           "weak if-match",
+			postID:         "1",
+			ifMatch:        `W/"3"`,
+			requestBody:    models.InputPost{Title: "Test"},
+			mockSetup:      func(mp *MockPoster) {},
+			expectedStatus: http.StatusPreconditionFailed,
+			expectedBody:   "Post has been modified\n",
+		},
+		{
+			name:        "version mismatch",
+			postID:      "1",
+			ifMatch:     `"3"`,
+			requestBody: models.InputPost{Title: "Test"},
+			mockSetup: func(mp *MockPoster) {
+				mp.On("PatchPost", mock.Anything, 1, 3, mock.AnythingOfType("models.PostPatch")).Return(models.OutputPost{}, storage.ErrVersionMismatch)
+			},
+			expectedStatus: http.StatusPreconditionFailed,
+			expectedBody:   "Post has been modified\n",
 		},
 		{
 			name:           "invalid id",
@@ -228,14 +964,15 @@ func TestPatchPostHandler(t *testing.T) {
 			expectedBody:   "Invalid request payload\n",
 		},
 		{
-			name:   "not found",
-			postID: "999",
+			name:    "not found",
+			postID:  "999",
+			ifMatch: `"1"`,
 			requestBody: models.InputPost{
 				Title:   "Non-existent",
 				Content: "Content",
 			},
 			mockSetup: func(mp *MockPoster) {
-				mp.On("PatchPost", 999, mock.AnythingOfType("models.InputPost")).Return(models.OutputPost{}, errors.New("not found"))
+				mp.On("PatchPost", mock.Anything, 999, 1, mock.AnythingOfType("models.PostPatch")).Return(models.OutputPost{}, errors.New("not found"))
 			},
 			expectedStatus: http.StatusNotFound,
 			expectedBody:   "Post not found\n",
@@ -255,37 +992,91 @@ func TestPatchPostHandler(t *testing.T) {
 				bodyBytes, _ = json.Marshal(v)
 			}
 
-			handler := PatchPostHandler(mockPoster, slog.Default())
+			handler := PatchPostHandler(mockPoster, allowAll{}, true, slog.Default())
 			req := httptest.NewRequest("PATCH", "/posts/"+tt.postID, bytes.NewReader(bodyBytes))
 			req.SetPathValue("id", tt.postID)
+			if tt.ifMatch != "" {
+				req.Header.Set("If-Match", tt.ifMatch)
+			}
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
 			w := httptest.NewRecorder()
 
 			handler(w, req)
 
 			assert.Equal(t, tt.expectedStatus, w.Code)
 			assert.Equal(t, tt.expectedBody, w.Body.String())
+			assert.Equal(t, tt.expectedETag, w.Header().Get("ETag"))
 			mockPoster.AssertExpectations(t)
 		})
 	}
 }
 
+func TestPatchPostHandlerKeepsEditor(t *testing.T) {
+	mockPoster := NewMockPoster(t)
+	title := "Updated Post"
+	mockPoster.On("PatchPost", mock.Anything, 1, 3, models.PostPatch{Title: &title, EditorID: 7}).Return(models.OutputPost{
+		ID: 1, Title: "Updated Post", Version: 4,
+	}, nil)
+	mockPoster.On("ApplyPatch", mock.Anything, 1, 4, 7, mock.Anything).Return(models.OutputPost{
+		ID: 1, Title: "Updated Post", Version: 5,
+	}, nil)
+
+	handler := PatchPostHandler(mockPoster, allowAll{}, true, slog.Default())
+	for _, tt := range []struct{ ifMatch, contentType, body string }{
+		{`"3"`, "application/merge-patch+json", `{"title":"Updated Post"}`},
+		{`"4"`, "application/json-patch+json", `[{"op":"replace","path":"/content","value":"New"}]`},
+	} {
+		req := httptest.NewRequest("PATCH", "/posts/1", bytes.NewBufferString(tt.body))
+		req = req.WithContext(middleware.WithUser(req.Context(), models.User{ID: 7, Role: models.RoleAuthor}))
+		req.SetPathValue("id", "1")
+		req.Header.Set("If-Match", tt.ifMatch)
+		req.Header.Set("Content-Type", tt.contentType)
+		w := httptest.NewRecorder()
+
+		handler(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	}
+}
+
 func TestDeletePostHandler(t *testing.T) {
 	tests := []struct {
 		name           string
 		postID         string
+		ifMatch        string
 		mockSetup      func(*MockPoster)
 		expectedStatus int
 		expectedBody   string
 	}{
 		{
-			name:   "success",
-			postID: "1",
+			name:    "success",
+			postID:  "1",
+			ifMatch: `"2"`,
 			mockSetup: func(mp *MockPoster) {
-				mp.On("DeletePost", 1).Return(nil)
+				mp.On("DeletePost", mock.Anything, 1, 2).Return(nil)
 			},
 			expectedStatus: http.StatusOK,
 			expectedBody:   "",
 		},
+		{
+			name:           "missing if-match",
+			postID:         "1",
+			mockSetup:      func(mp *MockPoster) {},
+			expectedStatus: http.StatusPreconditionRequired,
+			expectedBody:   "If-Match header is required\n",
+		},
+		{
+			name:    "version mismatch",
+			postID:  "1",
+			ifMatch: `"2"`,
+			mockSetup: func(mp *MockPoster) {
+				mp.On("DeletePost", mock.Anything, 1, 2).Return(storage.ErrVersionMismatch)
+			},
+			expectedStatus: http.StatusPreconditionFailed,
+			expectedBody:   "Post has been modified\n",
+		},
 		{
 			name:           "invalid id",
 			postID:         "invalid",
@@ -294,10 +1085,11 @@ func TestDeletePostHandler(t *testing.T) {
 			expectedBody:   "Invalid post ID\n",
 		},
 		{
-			name:   "not found",
-			postID: "999",
+			name:    "not found",
+			postID:  "999",
+			ifMatch: "*",
 			mockSetup: func(mp *MockPoster) {
-				mp.On("DeletePost", 999).Return(errors.New("not found"))
+				mp.On("DeletePost", mock.Anything, 999, 0).Return(errors.New("not found"))
 			},
 			expectedStatus: http.StatusNotFound,
 			expectedBody:   "Post not found\n",
@@ -309,9 +1101,12 @@ func TestDeletePostHandler(t *testing.T) {
 			mockPoster := NewMockPoster(t)
 			tt.mockSetup(mockPoster)
 
-			handler := DeletePostHandler(mockPoster, slog.Default())
+			handler := DeletePostHandler(mockPoster, allowAll{}, true, slog.Default())
 			req := httptest.NewRequest("DELETE", "/posts/"+tt.postID, nil)
 			req.SetPathValue("id", tt.postID)
+			if tt.ifMatch != "" {
+				req.Header.Set("If-Match", tt.ifMatch)
+			}
 			w := httptest.NewRecorder()
 
 			handler(w, req)
@@ -321,4 +1116,159 @@ func TestDeletePostHandler(t *testing.T) {
 			mockPoster.AssertExpectations(t)
 		})
 	}
-}
\ No newline at end of file
+}
+
+func TestPatchPostHandlerJSONPatch(t *testing.T) {
+	poster := memstore.New()
+	_, err := poster.SaveSection(context.Background(), models.InputSection{Slug: "it", Title: "Институт №8"})
+	require.NoError(t, err)
+	post, err := poster.SavePost(context.Background(), models.InputPost{Title: "Hello", Tags: []string{"news", "sports"}})
+	require.NoError(t, err)
+	handler := PatchPostHandler(poster, allowAll{}, false, slogdiscard.NewDiscardLogger())
+
+	patch := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("PATCH", "/posts/1/", bytes.NewBufferString(body))
+		req.SetPathValue("id", strconv.Itoa(post.ID))
+		req.Header.Set("Content-Type", "application/json-patch+json")
+		w := httptest.NewRecorder()
+		handler(w, req)
+		return w
+	}
+
+	w := patch(`[
+		{"op":"test","path":"/tags/1","value":"sports"},
+		{"op":"remove","path":"/tags/1"},
+		{"op":"add","path":"/tags/-","value":"Admissions"},
+		{"op":"replace","path":"/slug","value":"hello-world"},
+		{"op":"replace","path":"/section","value":"it"}
+	]`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Contains(t, w.Body.String(), `"slug":"hello-world","tags":["admissions","news"],"section":"it"`)
+
+	w = patch(`[{"op":"remove","path":"/tags"}]`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.NotContains(t, w.Body.String(), `"tags"`)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, patch(`[{"op":"remove","path":"/tags/0"}]`).Code)
+	assert.Equal(t, http.StatusBadRequest, patch(`[{"op":"replace","path":"/section","value":"missing"}]`).Code)
+}
+
+func TestPostsLifecycle(t *testing.T) {
+	poster := memstore.New()
+	log := slogdiscard.NewDiscardLogger()
+
+	r := http.NewServeMux()
+	r.HandleFunc("GET /posts/", GetAllPostsHandler(poster, 0, log))
+	r.HandleFunc("POST /posts/", CreatePostHandler(poster, allowAll{}, log))
+	r.HandleFunc("GET /posts/{id}/", GetPostHandler(poster, &viewLog{}, log))
+	r.HandleFunc("PATCH /posts/{id}/", PatchPostHandler(poster, allowAll{}, true, log))
+	r.HandleFunc("DELETE /posts/{id}/", DeletePostHandler(poster, allowAll{}, true, log))
+
+	do := func(method, target, body string, header ...string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(method, target, bytes.NewBufferString(body))
+		for i := 0; i+1 < len(header); i += 2 {
+			req.Header.Set(header[i], header[i+1])
+		}
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := do("POST", "/posts/", `{"title":"Hello","content":"World"}`)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var created models.OutputPost
+	assert.NoError(t, json.NewDecoder(w.Body).Decode(&created))
+	createdETag := w.Header().Get("ETag")
+	assert.Equal(t, `"1"`, createdETag)
+
+	w = do("PATCH", fmt.Sprintf("/posts/%d/", created.ID), `{"title":"Hello","content":"Changed"}`, "If-Match", createdETag)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// the second editor still holds the old ETag
+	w = do("PATCH", fmt.Sprintf("/posts/%d/", created.ID), `{"title":"Hello","content":"Lost"}`, "If-Match", createdETag)
+	assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+
+	w = do("GET", fmt.Sprintf("/posts/%d/", created.ID), "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	var got models.OutputPost
+	assert.NoError(t, json.NewDecoder(w.Body).Decode(&got))
+	assert.Equal(t, "Changed", got.Content)
+	currentETag := w.Header().Get("ETag")
+	assert.Equal(t, `"2"`, currentETag)
+
+	w = do("DELETE", fmt.Sprintf("/posts/%d/", created.ID), "", "If-Match", createdETag)
+	assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+
+	w = do("DELETE", fmt.Sprintf("/posts/%d/", created.ID), "", "If-Match", currentETag)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = do("GET", fmt.Sprintf("/posts/%d/", created.ID), "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestContextErrors(t *testing.T) {
+	tests := []struct {
+		name           string
+		ctx            func() (context.Context, context.CancelFunc)
+		storageErr     error
+		expectedStatus int
+	}{
+		{
+			name: "client closed request",
+			ctx: func() (context.Context, context.CancelFunc) {
+				ctx, cancel := context.WithCancel(context.Background())
+				cancel()
+				return ctx, cancel
+			},
+			storageErr:     context.Canceled,
+			expectedStatus: StatusClientClosedRequest,
+		},
+		{
+			name: "deadline exceeded",
+			ctx: func() (context.Context, context.CancelFunc) {
+				return context.WithTimeout(context.Background(), -time.Second)
+			},
+			storageErr:     context.DeadlineExceeded,
+			expectedStatus: http.StatusGatewayTimeout,
+		},
+		{
+			name: "server shutting down",
+			ctx: func() (context.Context, context.CancelFunc) {
+				ctx, cancel := context.WithCancelCause(context.Background())
+				cancel(ErrShuttingDown)
+				return ctx, func() {}
+			},
+			storageErr:     context.Canceled,
+			expectedStatus: http.StatusServiceUnavailable,
+		},
+		{
+			name: "wrapped storage error",
+			ctx: func() (context.Context, context.CancelFunc) {
+				ctx, cancel := context.WithCancel(context.Background())
+				cancel()
+				return ctx, cancel
+			},
+			storageErr:     fmt.Errorf("storage.sqlstore.GetPost: scan row: %w", context.Canceled),
+			expectedStatus: StatusClientClosedRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockPoster := NewMockPoster(t)
+			mockPoster.On("GetPost", mock.Anything, 1).Return(models.OutputPost{}, tt.storageErr)
+
+			ctx, cancel := tt.ctx()
+			defer cancel()
+
+			handler := GetPostHandler(mockPoster, &viewLog{}, slogdiscard.NewDiscardLogger())
+			req := httptest.NewRequest("GET", "/posts/1", nil).WithContext(ctx)
+			req.SetPathValue("id", "1")
+			w := httptest.NewRecorder()
+
+			handler(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+}
@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/RomanKovalev007/mai_news/internal/models"
+)
+
+var (
+	errPreconditionRequired = errors.New("If-Match header is required")
+	errPreconditionFailed = errors.New("Post has been modified")
+)
+
+// etag formats the strong entity tag of a post: its version, followed by a
+// hash of its comment count and reactions if it has any. Those are served
+// with the post but are not part of its version, so the tag must change
+// with them for a cached copy not to be confirmed after they do.
+func etag(post models.OutputPost) string{
+	if !hasCounts(post){
+		return `"` + strconv.Itoa(post.Version) + `"`
+	}
+	h := fnv.New64a()
+	fmt.Fprintf(h, "%d:%v", post.CommentCount, post.Reactions)
+	return fmt.Sprintf(`"%d-%x"`, post.Version, h.Sum64())
+}
+
+// pageETag derives a weak entity tag from the posts of a page and where the
+// next page starts. It changes whenever a post is added to or dropped from
+// the page or one of its posts changes, comments and reactions included.
+func pageETag(posts []models.OutputPost, nextCursor string) string{
+	h := fnv.New64a()
+	for _, post := range posts{
+		fmt.Fprintf(h, "%d:%d:%d:%v,", post.ID, post.Version, post.CommentCount, post.Reactions)
+	}
+	h.Write([]byte(nextCursor))
+	return fmt.Sprintf(`W/"%x"`, h.Sum64())
+}
+
+// hasCounts reports whether any of posts has comments or reactions.
+func hasCounts(posts ...models.OutputPost) bool{
+	for _, post := range posts{
+		if post.CommentCount > 0 || len(post.Reactions) > 0{
+			return true
+		}
+	}
+	return false
+}
+
+// countedLastModified returns lastModified of posts, or the zero time if any
+// of them has comments or reactions: their changes leave UpdatedAt alone,
+// so it cannot tell that a copy is current.
+func countedLastModified(lastModified time.Time, posts ...models.OutputPost) time.Time{
+	if hasCounts(posts...){
+		return time.Time{}
+	}
+	return lastModified
+}
+
+// writeNotModified sets the ETag and Last-Modified headers of a GET response
+// and, if the If-None-Match or If-Modified-Since header of r shows that the
+// client already has this representation, answers 304 Not Modified. It
+// reports whether it did. If-None-Match takes precedence as RFC 9110
+// requires; a zero lastModified is not sent and never matches.
+func writeNotModified(w http.ResponseWriter, r *http.Request, tag string, lastModified time.Time) bool{
+	w.Header().Set("ETag", tag)
+	if !lastModified.IsZero(){
+		w.Header().Set("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+	}
+
+	var match bool
+	if v := r.Header.Get("If-None-Match"); v != ""{
+		match = noneMatchHit(v, tag)
+	} else if since, err := http.ParseTime(r.Header.Get("If-Modified-Since")); err == nil && !lastModified.IsZero(){
+		// Last-Modified has whole seconds only
+		match = !lastModified.Truncate(time.Second).After(since)
+	}
+	if !match{
+		return false
+	}
+
+	w.Header().Del("Content-Type")
+	w.WriteHeader(http.StatusNotModified)
+	return true
+}
+
+// noneMatchHit reports whether an If-None-Match header lists tag, comparing
+// tags weakly.
+func noneMatchHit(header, tag string) bool{
+	if strings.TrimSpace(header) == "*"{
+		return true
+	}
+	for _, t := range strings.Split(header, ","){
+		if strings.TrimPrefix(strings.TrimSpace(t), "W/") == strings.TrimPrefix(tag, "W/"){
+			return true
+		}
+	}
+	return false
+}
+
+// ifMatchVersion returns the post version the If-Match header of r requires,
+// or 0 when any version will do. A missing header is an error only if
+// required is set. The hash of counts in a tag from etag is ignored, as new
+// comments and reactions do not conflict with edits. Lists of several tags
+// and weak tags are not supported and never match.
+func ifMatchVersion(r *http.Request, required bool) (int, error){
+	v := strings.TrimSpace(r.Header.Get("If-Match"))
+	switch {
+	case v == "" && required:
+		return 0, errPreconditionRequired
+	case v == "", v == "*":
+		return 0, nil
+	}
+
+	tag, ok := strings.CutPrefix(v, `"`)
+	if !ok{
+		return 0, errPreconditionFailed
+	}
+	tag, ok = strings.CutSuffix(tag, `"`)
+	if !ok{
+		return 0, errPreconditionFailed
+	}
+	tag, _, _ = strings.Cut(tag, "-")
+	version, err := strconv.Atoi(tag)
+	if err != nil || version < 1{
+		return 0, errPreconditionFailed
+	}
+
+	return version, nil
+}
+
+// writePreconditionError answers a request whose If-Match header could not be
+// satisfied.
+func writePreconditionError(w http.ResponseWriter, err error){
+	if errors.Is(err, errPreconditionRequired){
+		http.Error(w, err.Error(), http.StatusPreconditionRequired)
+		return
+	}
+	http.Error(w, errPreconditionFailed.Error(), http.StatusPreconditionFailed)
+}
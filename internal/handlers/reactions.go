@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/RomanKovalev007/mai_news/internal/middleware"
+	"github.com/RomanKovalev007/mai_news/internal/models"
+	"github.com/RomanKovalev007/mai_news/internal/storage"
+)
+
+// fingerprintHeader carries an identifier anonymous clients pick for
+// themselves, such as a random id kept in local storage. It is stored
+// hashed only.
+const fingerprintHeader = "X-Client-Fingerprint"
+
+const (
+	minFingerprintLength = 16
+	maxFingerprintLength = 256
+)
+
+// Reactor stores the reactions to posts.
+type Reactor interface{
+	// SetReaction replaces the reaction of the reactor to the post and
+	// returns the counts after it. It fails with storage.ErrPostNotFound for
+	// unknown posts and posts in the trash and with storage.ErrUserNotFound
+	// if the reacting user does not exist.
+	SetReaction(ctx context.Context, postID int, reactor models.ReactorID, kind models.ReactionKind) (models.PostReactions, error)
+	// DeleteReaction fails with storage.ErrReactionNotFound unless the
+	// reactor's reaction to the post is of the given kind.
+	DeleteReaction(ctx context.Context, postID int, reactor models.ReactorID, kind models.ReactionKind) error
+}
+
+// SetReactionHandler serves PUT /posts/{id}/reactions/{kind}/. Signed-in
+// users react as themselves, anonymous clients by the fingerprint they
+// send; either has one reaction per post, which a new one replaces.
+func SetReactionHandler(reactor Reactor, log *slog.Logger) http.HandlerFunc {
+	return func (w http.ResponseWriter, r *http.Request){
+		id, kind, who, ok := reactionRequest(w, r)
+		if !ok {
+			return
+		}
+
+		state, err := reactor.SetReaction(r.Context(), id, who, kind)
+		if err != nil {
+			if writeContextError(w, r, log, err){
+				return
+			}
+			if errors.Is(err, storage.ErrUserNotFound){
+				// the account was deleted after the request was authenticated
+				http.Error(w, "Unknown user", http.StatusUnauthorized)
+				return
+			}
+			writeReactionError(w, err, log)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(state)
+	}
+}
+
+// DeleteReactionHandler serves DELETE /posts/{id}/reactions/{kind}/ and
+// takes back the reaction of the user or client if it is of that kind.
+func DeleteReactionHandler(reactor Reactor, log *slog.Logger) http.HandlerFunc {
+	return func (w http.ResponseWriter, r *http.Request){
+		id, kind, who, ok := reactionRequest(w, r)
+		if !ok {
+			return
+		}
+
+		if err := reactor.DeleteReaction(r.Context(), id, who, kind); err != nil {
+			if writeContextError(w, r, log, err){
+				return
+			}
+			writeReactionError(w, err, log)
+			return
+		}
+	}
+}
+
+// reactionRequest reads the post id and reaction kind from the path and
+// tells who reacts. It answers 400 and returns false if any of them is
+// missing or invalid.
+func reactionRequest(w http.ResponseWriter, r *http.Request) (int, models.ReactionKind, models.ReactorID, bool){
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "Invalid post ID", http.StatusBadRequest)
+		return 0, "", models.ReactorID{}, false
+	}
+	kind := models.ReactionKind(r.PathValue("kind"))
+	if !kind.Valid() {
+		http.Error(w, "Reaction must be one of like, heart, laugh, wow, sad and fire", http.StatusBadRequest)
+		return 0, "", models.ReactorID{}, false
+	}
+
+	if user, ok := middleware.User(r.Context()); ok {
+		return id, kind, models.ReactorID{UserID: user.ID}, true
+	}
+	fingerprint := strings.TrimSpace(r.Header.Get(fingerprintHeader))
+	if len(fingerprint) < minFingerprintLength || len(fingerprint) > maxFingerprintLength {
+		http.Error(w, "Sign in or send a "+fingerprintHeader+" of 16 to 256 characters", http.StatusBadRequest)
+		return 0, "", models.ReactorID{}, false
+	}
+	sum := sha256.Sum256([]byte(fingerprint))
+	return id, kind, models.ReactorID{Fingerprint: hex.EncodeToString(sum[:])}, true
+}
+
+func writeReactionError(w http.ResponseWriter, err error, log *slog.Logger){
+	switch {
+	case errors.Is(err, storage.ErrReactionNotFound):
+		http.Error(w, "Reaction not found", http.StatusNotFound)
+	case errors.Is(err, storage.ErrPostNotFound):
+		http.Error(w, "Post not found", http.StatusNotFound)
+	default:
+		http.Error(w, "failed to access reaction", http.StatusInternalServerError)
+		log.Error("failed to access reaction", slog.String("error", err.Error()))
+	}
+}
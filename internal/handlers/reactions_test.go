@@ -0,0 +1,202 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/RomanKovalev007/mai_news/internal/middleware"
+	"github.com/RomanKovalev007/mai_news/internal/models"
+	"github.com/RomanKovalev007/mai_news/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const testFingerprint = "0123456789abcdef"
+
+func hashedFingerprint() models.ReactorID {
+	sum := sha256.Sum256([]byte(testFingerprint))
+	return models.ReactorID{Fingerprint: hex.EncodeToString(sum[:])}
+}
+
+func TestSetReactionHandler(t *testing.T) {
+	user := models.User{ID: 3, Name: "Анна", Role: models.RoleReader}
+
+	tests := []struct {
+		name           string
+		postID         string
+		kind           string
+		user           *models.User
+		fingerprint    string
+		mockSetup      func(*MockReactor)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:   "user",
+			postID: "1",
+			kind:   "like",
+			user:   &user,
+			// a signed-in user reacts as themselves whatever they send
+			fingerprint: testFingerprint,
+			mockSetup: func(mr *MockReactor) {
+				mr.On("SetReaction", mock.Anything, 1, models.ReactorID{UserID: 3}, models.ReactionLike).
+					Return(models.PostReactions{PostID: 1, Kind: models.ReactionLike, Reactions: map[models.ReactionKind]int{models.ReactionLike: 2}}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"post_id":1,"kind":"like","reactions":{"like":2}}` + "\n",
+		},
+		{
+			name:        "anonymous client",
+			postID:      "1",
+			kind:        "fire",
+			fingerprint: " " + testFingerprint + " ",
+			mockSetup: func(mr *MockReactor) {
+				mr.On("SetReaction", mock.Anything, 1, hashedFingerprint(), models.ReactionFire).
+					Return(models.PostReactions{PostID: 1, Kind: models.ReactionFire, Reactions: map[models.ReactionKind]int{models.ReactionFire: 1}}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"post_id":1,"kind":"fire","reactions":{"fire":1}}` + "\n",
+		},
+		{
+			name:           "no fingerprint",
+			postID:         "1",
+			kind:           "like",
+			mockSetup:      func(mr *MockReactor) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Sign in or send a X-Client-Fingerprint of 16 to 256 characters\n",
+		},
+		{
+			name:           "short fingerprint",
+			postID:         "1",
+			kind:           "like",
+			fingerprint:    "abc",
+			mockSetup:      func(mr *MockReactor) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Sign in or send a X-Client-Fingerprint of 16 to 256 characters\n",
+		},
+		{
+			name:           "unknown kind",
+			postID:         "1",
+			kind:           "angry",
+			user:           &user,
+			mockSetup:      func(mr *MockReactor) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Reaction must be one of like, heart, laugh, wow, sad and fire\n",
+		},
+		{
+			name:           "invalid id",
+			postID:         "invalid",
+			kind:           "like",
+			user:           &user,
+			mockSetup:      func(mr *MockReactor) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Invalid post ID\n",
+		},
+		{
+			name:   "post not found",
+			postID: "2",
+			kind:   "like",
+			user:   &user,
+			mockSetup: func(mr *MockReactor) {
+				mr.On("SetReaction", mock.Anything, 2, models.ReactorID{UserID: 3}, models.ReactionLike).
+					Return(models.PostReactions{}, storage.ErrPostNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   "Post not found\n",
+		},
+		{
+			name:   "user deleted",
+			postID: "1",
+			kind:   "like",
+			user:   &user,
+			mockSetup: func(mr *MockReactor) {
+				mr.On("SetReaction", mock.Anything, 1, models.ReactorID{UserID: 3}, models.ReactionLike).
+					Return(models.PostReactions{}, storage.ErrUserNotFound)
+			},
+			expectedStatus: http.StatusUnauthorized,
+			expectedBody:   "Unknown user\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockReactor := NewMockReactor(t)
+			tt.mockSetup(mockReactor)
+
+			handler := SetReactionHandler(mockReactor, slog.Default())
+			req := httptest.NewRequest("PUT", "/posts/"+tt.postID+"/reactions/"+tt.kind+"/", nil)
+			req.SetPathValue("id", tt.postID)
+			req.SetPathValue("kind", tt.kind)
+			if tt.fingerprint != "" {
+				req.Header.Set("X-Client-Fingerprint", tt.fingerprint)
+			}
+			if tt.user != nil {
+				req = req.WithContext(middleware.WithUser(req.Context(), *tt.user))
+			}
+			w := httptest.NewRecorder()
+
+			handler(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Equal(t, tt.expectedBody, w.Body.String())
+		})
+	}
+}
+
+func TestDeleteReactionHandler(t *testing.T) {
+	tests := []struct {
+		name           string
+		kind           string
+		mockSetup      func(*MockReactor)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name: "success",
+			kind: "like",
+			mockSetup: func(mr *MockReactor) {
+				mr.On("DeleteReaction", mock.Anything, 1, hashedFingerprint(), models.ReactionLike).Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "not found",
+			kind: "heart",
+			mockSetup: func(mr *MockReactor) {
+				mr.On("DeleteReaction", mock.Anything, 1, hashedFingerprint(), models.ReactionHeart).Return(storage.ErrReactionNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   "Reaction not found\n",
+		},
+		{
+			name:           "unknown kind",
+			kind:           "angry",
+			mockSetup:      func(mr *MockReactor) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Reaction must be one of like, heart, laugh, wow, sad and fire\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockReactor := NewMockReactor(t)
+			tt.mockSetup(mockReactor)
+
+			handler := DeleteReactionHandler(mockReactor, slog.Default())
+			req := httptest.NewRequest("DELETE", "/posts/1/reactions/"+tt.kind+"/", nil)
+			req.SetPathValue("id", "1")
+			req.SetPathValue("kind", tt.kind)
+			req.Header.Set("X-Client-Fingerprint", testFingerprint)
+			w := httptest.NewRecorder()
+
+			handler(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Equal(t, tt.expectedBody, w.Body.String())
+		})
+	}
+}
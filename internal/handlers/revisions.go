@@ -0,0 +1,201 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/RomanKovalev007/mai_news/internal/authz"
+	"github.com/RomanKovalev007/mai_news/internal/lib/diff"
+	"github.com/RomanKovalev007/mai_news/internal/middleware"
+	"github.com/RomanKovalev007/mai_news/internal/models"
+	"github.com/RomanKovalev007/mai_news/internal/storage"
+)
+
+// Reviser gives access to the previous versions of posts.
+type Reviser interface{
+	GetRevisions(ctx context.Context, postID int) ([]models.Revision, error)
+	GetRevision(ctx context.Context, postID, rev int) (models.Revision, error)
+	// RevertPost keeps editorID like PostPatch.EditorID.
+	RevertPost(ctx context.Context, postID, rev, editorID int) (models.OutputPost, error)
+}
+
+const (
+	diffModeLine = "line"
+	diffModeWord = "word"
+)
+
+func GetRevisionsHandler(reviser Reviser, log *slog.Logger) http.HandlerFunc {
+	return func (w http.ResponseWriter, r *http.Request){
+		id, err := strconv.Atoi(r.PathValue("id"))
+		if err != nil {
+			http.Error(w, "Invalid post ID", http.StatusBadRequest)
+			return
+		}
+
+		revisions, err := reviser.GetRevisions(r.Context(), id)
+		if err != nil {
+			writeRevisionError(w, r, log, err, "failed to get revisions")
+			return
+		}
+		if revisions == nil {
+			revisions = []models.Revision{}
+		}
+
+		for i := range revisions{
+			revisions[i] = revisionInZone(r, revisions[i])
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(revisions)
+	}
+}
+
+func GetRevisionHandler(reviser Reviser, log *slog.Logger) http.HandlerFunc {
+	return func (w http.ResponseWriter, r *http.Request){
+		id, rev, ok := parseRevisionPath(w, r)
+		if !ok {
+			return
+		}
+
+		revision, err := reviser.GetRevision(r.Context(), id, rev)
+		if err != nil {
+			writeRevisionError(w, r, log, err, "failed to get revision")
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(revisionInZone(r, revision))
+	}
+}
+
+// DiffRevisionHandler compares revision {rev} with the revision given in the
+// "to" query parameter or, without it, with the current version of the post.
+// The "mode" parameter selects a line (default) or word diff.
+func DiffRevisionHandler(reviser Reviser, poster Poster, log *slog.Logger) http.HandlerFunc {
+	return func (w http.ResponseWriter, r *http.Request){
+		id, rev, ok := parseRevisionPath(w, r)
+		if !ok {
+			return
+		}
+
+		mode := r.URL.Query().Get("mode")
+		var diffFunc func(a, b string) []diff.Chunk
+		switch mode {
+		case diffModeLine, "":
+			mode, diffFunc = diffModeLine, diff.Lines
+		case diffModeWord:
+			diffFunc = diff.Words
+		default:
+			http.Error(w, "Invalid diff mode", http.StatusBadRequest)
+			return
+		}
+
+		to := 0
+		if v := r.URL.Query().Get("to"); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 1 {
+				http.Error(w, "Invalid revision", http.StatusBadRequest)
+				return
+			}
+			to = n
+		}
+
+		from, err := reviser.GetRevision(r.Context(), id, rev)
+		if err != nil {
+			writeRevisionError(w, r, log, err, "failed to get revision")
+			return
+		}
+
+		var toTitle, toContent string
+		if to == 0 {
+			post, err := poster.GetPost(r.Context(), id)
+			if err != nil {
+				writeRevisionError(w, r, log, err, "failed to get post")
+				return
+			}
+			toTitle, toContent = post.Title, post.Content
+		} else {
+			revision, err := reviser.GetRevision(r.Context(), id, to)
+			if err != nil {
+				writeRevisionError(w, r, log, err, "failed to get revision")
+				return
+			}
+			toTitle, toContent = revision.Title, revision.Content
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(models.RevisionDiff{
+			PostID: id,
+			From: rev,
+			To: to,
+			Mode: mode,
+			Title: diffFunc(from.Title, toTitle),
+			Content: diffFunc(from.Content, toContent),
+		})
+	}
+}
+
+// RevertPostHandler reverts a post to a revision, which is an edit of the
+// post.
+func RevertPostHandler(reviser Reviser, authorizer Authorizer, log *slog.Logger) http.HandlerFunc {
+	return func (w http.ResponseWriter, r *http.Request){
+		id, rev, ok := parseRevisionPath(w, r)
+		if !ok {
+			return
+		}
+
+		if !authorizePost(w, r, authorizer, authz.EditPost, id, "Post not found", log){
+			return
+		}
+
+		user, _ := middleware.User(r.Context())
+		post, err := reviser.RevertPost(r.Context(), id, rev, user.ID)
+		if err != nil {
+			if errors.Is(err, storage.ErrPostExists){
+				http.Error(w, "Another post already has this title", http.StatusConflict)
+				return
+			}
+			writeRevisionError(w, r, log, err, "failed to revert post")
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("ETag", etag(post))
+		json.NewEncoder(w).Encode(inZone(r, post))
+	}
+}
+
+func parseRevisionPath(w http.ResponseWriter, r *http.Request) (id, rev int, ok bool){
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "Invalid post ID", http.StatusBadRequest)
+		return 0, 0, false
+	}
+
+	rev, err = strconv.Atoi(r.PathValue("rev"))
+	if err != nil || rev < 1 {
+		http.Error(w, "Invalid revision", http.StatusBadRequest)
+		return 0, 0, false
+	}
+
+	return id, rev, true
+}
+
+func writeRevisionError(w http.ResponseWriter, r *http.Request, log *slog.Logger, err error, msg string){
+	if writeContextError(w, r, log, err){
+		return
+	}
+
+	switch {
+	case errors.Is(err, storage.ErrPostNotFound):
+		http.Error(w, "Post not found", http.StatusNotFound)
+	case errors.Is(err, storage.ErrRevisionNotFound):
+		http.Error(w, "Revision not found", http.StatusNotFound)
+	default:
+		http.Error(w, msg, http.StatusInternalServerError)
+		log.Error(msg, slog.String("error", err.Error()))
+	}
+}
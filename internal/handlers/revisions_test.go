@@ -0,0 +1,267 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/RomanKovalev007/mai_news/internal/middleware"
+	"github.com/RomanKovalev007/mai_news/internal/models"
+	"github.com/RomanKovalev007/mai_news/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestGetRevisionsHandler(t *testing.T) {
+	tests := []struct {
+		name           string
+		postID         string
+		mockSetup      func(*MockReviser)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:   "success",
+			postID: "1",
+			mockSetup: func(mr *MockReviser) {
+				mr.On("GetRevisions", mock.Anything, 1).Return([]models.Revision{
+					{PostID: 1, Rev: 2, Title: "Older", Content: "Old content", RevisedAt: time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC), RevisedBy: &models.Author{ID: 7, Name: "Анна"}},
+					{PostID: 1, Rev: 1, Title: "Old", Content: "Old content", RevisedAt: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)},
+				}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `[{"post_id":1,"rev":2,"title":"Older","content":"Old content","revised_at":"2025-01-02T00:00:00Z","revised_by":{"id":7,"name":"Анна"}},` +
+				`{"post_id":1,"rev":1,"title":"Old","content":"Old content","revised_at":"2025-01-01T00:00:00Z"}]` + "\n",
+		},
+		{
+			name:   "no revisions",
+			postID: "1",
+			mockSetup: func(mr *MockReviser) {
+				mr.On("GetRevisions", mock.Anything, 1).Return(nil, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   "[]\n",
+		},
+		{
+			name:           "invalid id",
+			postID:         "invalid",
+			mockSetup:      func(mr *MockReviser) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Invalid post ID\n",
+		},
+		{
+			name:   "post not found",
+			postID: "999",
+			mockSetup: func(mr *MockReviser) {
+				mr.On("GetRevisions", mock.Anything, 999).Return(nil, storage.ErrPostNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   "Post not found\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockReviser := NewMockReviser(t)
+			tt.mockSetup(mockReviser)
+
+			handler := GetRevisionsHandler(mockReviser, slog.Default())
+			req := httptest.NewRequest("GET", "/posts/"+tt.postID+"/revisions/", nil)
+			req.SetPathValue("id", tt.postID)
+			w := httptest.NewRecorder()
+
+			handler(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Equal(t, tt.expectedBody, w.Body.String())
+		})
+	}
+}
+
+func TestGetRevisionHandler(t *testing.T) {
+	tests := []struct {
+		name           string
+		rev            string
+		mockSetup      func(*MockReviser)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name: "success",
+			rev:  "2",
+			mockSetup: func(mr *MockReviser) {
+				mr.On("GetRevision", mock.Anything, 1, 2).Return(models.Revision{
+					PostID: 1, Rev: 2, Title: "Old", Content: "Old content", RevisedAt: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+				}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"post_id":1,"rev":2,"title":"Old","content":"Old content","revised_at":"2025-01-01T00:00:00Z"}` + "\n",
+		},
+		{
+			name:           "invalid revision",
+			rev:            "0",
+			mockSetup:      func(mr *MockReviser) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Invalid revision\n",
+		},
+		{
+			name: "revision not found",
+			rev:  "5",
+			mockSetup: func(mr *MockReviser) {
+				mr.On("GetRevision", mock.Anything, 1, 5).Return(models.Revision{}, storage.ErrRevisionNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   "Revision not found\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockReviser := NewMockReviser(t)
+			tt.mockSetup(mockReviser)
+
+			handler := GetRevisionHandler(mockReviser, slog.Default())
+			req := httptest.NewRequest("GET", "/posts/1/revisions/"+tt.rev+"/", nil)
+			req.SetPathValue("id", "1")
+			req.SetPathValue("rev", tt.rev)
+			w := httptest.NewRecorder()
+
+			handler(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Equal(t, tt.expectedBody, w.Body.String())
+		})
+	}
+}
+
+func TestDiffRevisionHandler(t *testing.T) {
+	tests := []struct {
+		name           string
+		query          string
+		mockSetup      func(*MockReviser, *MockPoster)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:  "against current version",
+			query: "",
+			mockSetup: func(mr *MockReviser, mp *MockPoster) {
+				mr.On("GetRevision", mock.Anything, 1, 1).Return(models.Revision{PostID: 1, Rev: 1, Title: "Title", Content: "a\nb\n"}, nil)
+				mp.On("GetPost", mock.Anything, 1).Return(models.OutputPost{ID: 1, Title: "Title", Content: "a\nc\n"}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: `{"post_id":1,"from":1,"mode":"line","title":[{"op":"equal","text":"Title"}],` +
+				`"content":[{"op":"equal","text":"a\n"},{"op":"delete","text":"b\n"},{"op":"insert","text":"c\n"}]}` + "\n",
+		},
+		{
+			name:  "between revisions by word",
+			query: "?to=2&mode=word",
+			mockSetup: func(mr *MockReviser, mp *MockPoster) {
+				mr.On("GetRevision", mock.Anything, 1, 1).Return(models.Revision{PostID: 1, Rev: 1, Title: "Old title", Content: "x"}, nil)
+				mr.On("GetRevision", mock.Anything, 1, 2).Return(models.Revision{PostID: 1, Rev: 2, Title: "New title", Content: "x"}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: `{"post_id":1,"from":1,"to":2,"mode":"word","title":[{"op":"delete","text":"Old"},{"op":"insert","text":"New"},{"op":"equal","text":" title"}],` +
+				`"content":[{"op":"equal","text":"x"}]}` + "\n",
+		},
+		{
+			name:           "invalid mode",
+			query:          "?mode=char",
+			mockSetup:      func(mr *MockReviser, mp *MockPoster) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Invalid diff mode\n",
+		},
+		{
+			name:           "invalid target revision",
+			query:          "?to=x",
+			mockSetup:      func(mr *MockReviser, mp *MockPoster) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Invalid revision\n",
+		},
+		{
+			name:  "target revision not found",
+			query: "?to=9",
+			mockSetup: func(mr *MockReviser, mp *MockPoster) {
+				mr.On("GetRevision", mock.Anything, 1, 1).Return(models.Revision{PostID: 1, Rev: 1}, nil)
+				mr.On("GetRevision", mock.Anything, 1, 9).Return(models.Revision{}, storage.ErrRevisionNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   "Revision not found\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockReviser := NewMockReviser(t)
+			mockPoster := NewMockPoster(t)
+			tt.mockSetup(mockReviser, mockPoster)
+
+			handler := DiffRevisionHandler(mockReviser, mockPoster, slog.Default())
+			req := httptest.NewRequest("GET", "/posts/1/revisions/1/diff/"+tt.query, nil)
+			req.SetPathValue("id", "1")
+			req.SetPathValue("rev", "1")
+			w := httptest.NewRecorder()
+
+			handler(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Equal(t, tt.expectedBody, w.Body.String())
+		})
+	}
+}
+
+func TestRevertPostHandler(t *testing.T) {
+	tests := []struct {
+		name           string
+		mockSetup      func(*MockReviser)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name: "success",
+			mockSetup: func(mr *MockReviser) {
+				mr.On("RevertPost", mock.Anything, 1, 1, 7).Return(models.OutputPost{ID: 1, Title: "Old", Content: "Old content"}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"id":1,"title":"Old","content":"Old content","created_at":"0001-01-01T00:00:00Z"}` + "\n",
+		},
+		{
+			name: "title taken",
+			mockSetup: func(mr *MockReviser) {
+				mr.On("RevertPost", mock.Anything, 1, 1, 7).Return(models.OutputPost{}, storage.ErrPostExists)
+			},
+			expectedStatus: http.StatusConflict,
+			expectedBody:   "Another post already has this title\n",
+		},
+		{
+			name: "storage error",
+			mockSetup: func(mr *MockReviser) {
+				mr.On("RevertPost", mock.Anything, 1, 1, 7).Return(models.OutputPost{}, errors.New("db is down"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   "failed to revert post\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockReviser := NewMockReviser(t)
+			tt.mockSetup(mockReviser)
+
+			handler := RevertPostHandler(mockReviser, allowAll{}, slog.Default())
+			req := httptest.NewRequest("POST", "/posts/1/revisions/1/revert/", nil)
+			req = req.WithContext(middleware.WithUser(req.Context(), models.User{ID: 7, Role: models.RoleEditor}))
+			req.SetPathValue("id", "1")
+			req.SetPathValue("rev", "1")
+			w := httptest.NewRecorder()
+
+			handler(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Equal(t, tt.expectedBody, w.Body.String())
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/RomanKovalev007/mai_news/internal/storage"
+)
+
+const (
+	defaultSearchLimit = 20
+	maxSearchLimit = 100
+)
+
+// SearchPostsHandler serves GET /posts/search/?q=...&limit=... with posts
+// ranked by relevance.
+func SearchPostsHandler(poster Poster, log *slog.Logger) http.HandlerFunc {
+	return func (w http.ResponseWriter, r *http.Request){
+		query := strings.TrimSpace(r.URL.Query().Get("q"))
+		if query == "" {
+			http.Error(w, "Search query is required", http.StatusBadRequest)
+			return
+		}
+
+		limit := defaultSearchLimit
+		if v := r.URL.Query().Get("limit"); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 1 || n > maxSearchLimit {
+				http.Error(w, "Invalid limit", http.StatusBadRequest)
+				return
+			}
+			limit = n
+		}
+
+		results, err := poster.SearchPosts(r.Context(), query, limit)
+		if err != nil {
+			if writeContextError(w, r, log, err){
+				return
+			}
+			if errors.Is(err, storage.ErrSearchUnavailable){
+				http.Error(w, "Search is not available", http.StatusNotImplemented)
+				return
+			}
+			http.Error(w, "failed to search posts", http.StatusInternalServerError)
+			log.Error("failed to search posts", slog.String("error", err.Error()))
+			return
+		}
+
+		for i := range results{
+			results[i].OutputPost = inZone(r, results[i].OutputPost)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(results)
+	}
+}
@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/RomanKovalev007/mai_news/internal/models"
+	"github.com/RomanKovalev007/mai_news/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestSearchPostsHandler(t *testing.T) {
+	tests := []struct {
+		name           string
+		query          url.Values
+		mockSetup      func(*MockPoster)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:  "success",
+			query: url.Values{"q": {"приём"}},
+			mockSetup: func(mp *MockPoster) {
+				mp.On("SearchPosts", mock.Anything, "приём", defaultSearchLimit).Return([]models.SearchResult{{
+					OutputPost:     models.OutputPost{ID: 1, Title: "Приём документов", Content: "Начался приём"},
+					Score:          10,
+					TitleHighlight: "<mark>Приём</mark> документов",
+					Snippet:        "Начался <mark>приём</mark>",
+				}}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: `[{"id":1,"title":"Приём документов","content":"Начался приём","created_at":"0001-01-01T00:00:00Z",` +
+				`"score":10,"title_highlight":"\u003cmark\u003eПриём\u003c/mark\u003e документов",` +
+				`"snippet":"Начался \u003cmark\u003eприём\u003c/mark\u003e"}]` + "\n",
+		},
+		{
+			name:  "custom limit",
+			query: url.Values{"q": {"news"}, "limit": {"5"}},
+			mockSetup: func(mp *MockPoster) {
+				mp.On("SearchPosts", mock.Anything, "news", 5).Return([]models.SearchResult{}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   "[]\n",
+		},
+		{
+			name:           "missing query",
+			query:          url.Values{"q": {"  "}},
+			mockSetup:      func(mp *MockPoster) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Search query is required\n",
+		},
+		{
+			name:           "invalid limit",
+			query:          url.Values{"q": {"news"}, "limit": {"1000"}},
+			mockSetup:      func(mp *MockPoster) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Invalid limit\n",
+		},
+		{
+			name:  "search unavailable",
+			query: url.Values{"q": {"news"}},
+			mockSetup: func(mp *MockPoster) {
+				mp.On("SearchPosts", mock.Anything, "news", defaultSearchLimit).Return(nil, storage.ErrSearchUnavailable)
+			},
+			expectedStatus: http.StatusNotImplemented,
+			expectedBody:   "Search is not available\n",
+		},
+		{
+			name:  "storage error",
+			query: url.Values{"q": {"news"}},
+			mockSetup: func(mp *MockPoster) {
+				mp.On("SearchPosts", mock.Anything, "news", defaultSearchLimit).Return(nil, errors.New("db is down"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   "failed to search posts\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockPoster := NewMockPoster(t)
+			tt.mockSetup(mockPoster)
+
+			handler := SearchPostsHandler(mockPoster, slog.Default())
+			req := httptest.NewRequest("GET", "/posts/search/?"+tt.query.Encode(), nil)
+			w := httptest.NewRecorder()
+
+			handler(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Equal(t, tt.expectedBody, w.Body.String())
+		})
+	}
+}
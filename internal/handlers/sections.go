@@ -0,0 +1,216 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/mail"
+	"strings"
+
+	"github.com/RomanKovalev007/mai_news/internal/authz"
+	"github.com/RomanKovalev007/mai_news/internal/models"
+	"github.com/RomanKovalev007/mai_news/internal/storage"
+)
+
+var (
+	errInvalidEditor = errors.New("Invalid editor")
+	errMissingTitle = errors.New("Title is required")
+	errUnknownSection = errors.New("Unknown section")
+)
+
+// Sectioner manages the sections posts are published in. GetSection and
+// PatchSection fail with storage.ErrSectionNotFound for unknown slugs.
+type Sectioner interface{
+	GetSections(ctx context.Context) ([]models.Section, error)
+	GetSection(ctx context.Context, slug string) (models.Section, error)
+	// SaveSection fails with storage.ErrSectionExists if the slug is in use.
+	SaveSection(ctx context.Context, section models.InputSection) (models.Section, error)
+	PatchSection(ctx context.Context, slug string, patch models.SectionPatch) (models.Section, error)
+}
+
+// GetSectionsHandler serves GET /sections/ with every section.
+func GetSectionsHandler(sectioner Sectioner, log *slog.Logger) http.HandlerFunc {
+	return func (w http.ResponseWriter, r *http.Request){
+		result, err := sectioner.GetSections(r.Context())
+		if err != nil {
+			if writeContextError(w, r, log, err){
+				return
+			}
+			http.Error(w, "failed to get sections", http.StatusInternalServerError)
+			log.Error("failed to get sections", slog.String("error", err.Error()))
+			return
+		}
+		if result == nil {
+			result = []models.Section{}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(result)
+	}
+}
+
+func GetSectionHandler(sectioner Sectioner, log *slog.Logger) http.HandlerFunc {
+	return func (w http.ResponseWriter, r *http.Request){
+		section, err := sectioner.GetSection(r.Context(), r.PathValue("slug"))
+		if err != nil {
+			if writeContextError(w, r, log, err){
+				return
+			}
+			writeSectionError(w, err, log)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(section)
+	}
+}
+
+// CreateSectionHandler serves POST /sections/. The slug and title are
+// required; a slug in use answers 409.
+func CreateSectionHandler(sectioner Sectioner, log *slog.Logger) http.HandlerFunc {
+	return func (w http.ResponseWriter, r *http.Request){
+		var input models.InputSection
+		if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+			http.Error(w, "Invalid request payload", http.StatusBadRequest)
+			return
+		}
+		if err := validSlug(input.Slug); err != nil || input.Slug == "" {
+			http.Error(w, errInvalidSlug.Error(), http.StatusBadRequest)
+			return
+		}
+		input.Title = strings.TrimSpace(input.Title)
+		if input.Title == "" {
+			http.Error(w, errMissingTitle.Error(), http.StatusBadRequest)
+			return
+		}
+		editors, err := normalizeEditors(input.Editors)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		input.Editors = editors
+
+		section, err := sectioner.SaveSection(r.Context(), input)
+		if err != nil {
+			if writeContextError(w, r, log, err){
+				return
+			}
+			writeSectionError(w, err, log)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(section)
+	}
+}
+
+// PatchSectionHandler serves PATCH /sections/{slug}/ with any of title,
+// description and editors; editors replace the current ones. The user must
+// be allowed to edit the section, and to manage its editors to change them.
+func PatchSectionHandler(sectioner Sectioner, authorizer Authorizer, log *slog.Logger) http.HandlerFunc {
+	return func (w http.ResponseWriter, r *http.Request){
+		var patch models.SectionPatch
+		if err := json.NewDecoder(r.Body).Decode(&patch); err != nil {
+			http.Error(w, "Invalid request payload", http.StatusBadRequest)
+			return
+		}
+		if patch.Title != nil {
+			title := strings.TrimSpace(*patch.Title)
+			if title == "" {
+				http.Error(w, errMissingTitle.Error(), http.StatusBadRequest)
+				return
+			}
+			patch.Title = &title
+		}
+		if patch.Editors != nil {
+			editors, err := normalizeEditors(*patch.Editors)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			patch.Editors = &editors
+		}
+
+		if !authorizeSection(w, r, authorizer, authz.EditSection, r.PathValue("slug"), log){
+			return
+		}
+		if patch.Editors != nil && !authorizeSection(w, r, authorizer, authz.ManageSectionEditors, r.PathValue("slug"), log){
+			return
+		}
+
+		section, err := sectioner.PatchSection(r.Context(), r.PathValue("slug"), patch)
+		if err != nil {
+			if writeContextError(w, r, log, err){
+				return
+			}
+			writeSectionError(w, err, log)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(section)
+	}
+}
+
+// GetSectionPostsHandler serves GET /sections/{slug}/posts/, the posts list
+// of GetAllPostsHandler narrowed down to one section.
+func GetSectionPostsHandler(sectioner Sectioner, poster Poster, maxPageSize int, log *slog.Logger) http.HandlerFunc {
+	return func (w http.ResponseWriter, r *http.Request){
+		section, err := sectioner.GetSection(r.Context(), r.PathValue("slug"))
+		if err != nil {
+			if writeContextError(w, r, log, err){
+				return
+			}
+			writeSectionError(w, err, log)
+			return
+		}
+
+		filter, err := parseFilter(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		filter.Section = section.Slug
+
+		writePostsPage(w, r, poster, filter, maxPageSize, log)
+	}
+}
+
+// normalizeEditors checks that editors are bare e-mail addresses and
+// lowercases them.
+func normalizeEditors(editors []string) ([]string, error){
+	result := make([]string, 0, len(editors))
+	for _, editor := range editors{
+		email, ok := normalizeEmail(editor)
+		if !ok{
+			return nil, errInvalidEditor
+		}
+		result = append(result, email)
+	}
+	return result, nil
+}
+
+// normalizeEmail lowercases email if it is a bare e-mail address, without
+// a display name or angle brackets.
+func normalizeEmail(email string) (string, bool){
+	addr, err := mail.ParseAddress(email)
+	if err != nil || addr.Name != "" || addr.Address != strings.TrimSpace(email){
+		return "", false
+	}
+	return strings.ToLower(addr.Address), true
+}
+
+func writeSectionError(w http.ResponseWriter, err error, log *slog.Logger){
+	switch {
+	case errors.Is(err, storage.ErrSectionNotFound):
+		http.Error(w, "Section not found", http.StatusNotFound)
+	case errors.Is(err, storage.ErrSectionExists):
+		http.Error(w, "Section already exists", http.StatusConflict)
+	default:
+		http.Error(w, "failed to access section", http.StatusInternalServerError)
+		log.Error("failed to access section", slog.String("error", err.Error()))
+	}
+}
@@ -0,0 +1,349 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/RomanKovalev007/mai_news/internal/models"
+	"github.com/RomanKovalev007/mai_news/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var testSection = models.Section{
+	ID:          2,
+	Slug:        "it",
+	Title:       "Институт №8",
+	Description: "Компьютерные науки",
+	Editors:     []string{"editor@mai.ru"},
+	CreatedAt:   time.Date(2025, 9, 1, 10, 0, 0, 0, time.UTC),
+}
+
+const testSectionJSON = `{"id":2,"slug":"it","title":"Институт №8","description":"Компьютерные науки","editors":["editor@mai.ru"],"created_at":"2025-09-01T10:00:00Z"}`
+
+func TestGetSectionsHandler(t *testing.T) {
+	tests := []struct {
+		name           string
+		mockSetup      func(*MockSectioner)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name: "success",
+			mockSetup: func(ms *MockSectioner) {
+				ms.On("GetSections", mock.Anything).Return([]models.Section{testSection}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   "[" + testSectionJSON + "]\n",
+		},
+		{
+			name: "no sections",
+			mockSetup: func(ms *MockSectioner) {
+				ms.On("GetSections", mock.Anything).Return(nil, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   "[]\n",
+		},
+		{
+			name: "error",
+			mockSetup: func(ms *MockSectioner) {
+				ms.On("GetSections", mock.Anything).Return(nil, errors.New("db error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   "failed to get sections\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockSectioner := NewMockSectioner(t)
+			tt.mockSetup(mockSectioner)
+
+			handler := GetSectionsHandler(mockSectioner, slog.Default())
+			req := httptest.NewRequest("GET", "/sections/", nil)
+			w := httptest.NewRecorder()
+
+			handler(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Equal(t, tt.expectedBody, w.Body.String())
+			mockSectioner.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGetSectionHandler(t *testing.T) {
+	tests := []struct {
+		name           string
+		slug           string
+		mockSetup      func(*MockSectioner)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name: "success",
+			slug: "it",
+			mockSetup: func(ms *MockSectioner) {
+				ms.On("GetSection", mock.Anything, "it").Return(testSection, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   testSectionJSON + "\n",
+		},
+		{
+			name: "not found",
+			slug: "missing",
+			mockSetup: func(ms *MockSectioner) {
+				ms.On("GetSection", mock.Anything, "missing").Return(models.Section{}, storage.ErrSectionNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   "Section not found\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockSectioner := NewMockSectioner(t)
+			tt.mockSetup(mockSectioner)
+
+			handler := GetSectionHandler(mockSectioner, slog.Default())
+			req := httptest.NewRequest("GET", "/sections/"+tt.slug+"/", nil)
+			req.SetPathValue("slug", tt.slug)
+			w := httptest.NewRecorder()
+
+			handler(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Equal(t, tt.expectedBody, w.Body.String())
+			mockSectioner.AssertExpectations(t)
+		})
+	}
+}
+
+func TestCreateSectionHandler(t *testing.T) {
+	tests := []struct {
+		name           string
+		requestBody    string
+		mockSetup      func(*MockSectioner)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:        "success",
+			requestBody: `{"slug":"it","title":" Институт №8 ","description":"Компьютерные науки","editors":["Editor@MAI.ru"]}`,
+			mockSetup: func(ms *MockSectioner) {
+				ms.On("SaveSection", mock.Anything, models.InputSection{
+					Slug:        "it",
+					Title:       "Институт №8",
+					Description: "Компьютерные науки",
+					Editors:     []string{"editor@mai.ru"},
+				}).Return(testSection, nil)
+			},
+			expectedStatus: http.StatusCreated,
+			expectedBody:   testSectionJSON + "\n",
+		},
+		{
+			name:           "missing slug",
+			requestBody:    `{"title":"Институт №8"}`,
+			mockSetup:      func(ms *MockSectioner) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Invalid slug\n",
+		},
+		{
+			name:           "invalid slug",
+			requestBody:    `{"slug":"Институт","title":"Институт №8"}`,
+			mockSetup:      func(ms *MockSectioner) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Invalid slug\n",
+		},
+		{
+			name:           "missing title",
+			requestBody:    `{"slug":"it","title":"  "}`,
+			mockSetup:      func(ms *MockSectioner) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Title is required\n",
+		},
+		{
+			name:           "invalid editor",
+			requestBody:    `{"slug":"it","title":"Институт №8","editors":["Editor <editor@mai.ru>"]}`,
+			mockSetup:      func(ms *MockSectioner) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Invalid editor\n",
+		},
+		{
+			name:           "invalid json",
+			requestBody:    `invalid json`,
+			mockSetup:      func(ms *MockSectioner) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Invalid request payload\n",
+		},
+		{
+			name:        "slug in use",
+			requestBody: `{"slug":"it","title":"Институт №8"}`,
+			mockSetup: func(ms *MockSectioner) {
+				ms.On("SaveSection", mock.Anything, models.InputSection{Slug: "it", Title: "Институт №8", Editors: []string{}}).
+					Return(models.Section{}, storage.ErrSectionExists)
+			},
+			expectedStatus: http.StatusConflict,
+			expectedBody:   "Section already exists\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockSectioner := NewMockSectioner(t)
+			tt.mockSetup(mockSectioner)
+
+			handler := CreateSectionHandler(mockSectioner, slog.Default())
+			req := httptest.NewRequest("POST", "/sections/", strings.NewReader(tt.requestBody))
+			w := httptest.NewRecorder()
+
+			handler(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Equal(t, tt.expectedBody, w.Body.String())
+			mockSectioner.AssertExpectations(t)
+		})
+	}
+}
+
+func TestPatchSectionHandler(t *testing.T) {
+	title := "Институт №8"
+	editors := []string{"editor@mai.ru"}
+
+	tests := []struct {
+		name           string
+		slug           string
+		requestBody    string
+		mockSetup      func(*MockSectioner)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:        "success",
+			slug:        "it",
+			requestBody: `{"title":"Институт №8 ","editors":["editor@mai.ru"]}`,
+			mockSetup: func(ms *MockSectioner) {
+				ms.On("PatchSection", mock.Anything, "it", models.SectionPatch{Title: &title, Editors: &editors}).Return(testSection, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   testSectionJSON + "\n",
+		},
+		{
+			name:           "empty title",
+			slug:           "it",
+			requestBody:    `{"title":""}`,
+			mockSetup:      func(ms *MockSectioner) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Title is required\n",
+		},
+		{
+			name:           "invalid editor",
+			slug:           "it",
+			requestBody:    `{"editors":["editor"]}`,
+			mockSetup:      func(ms *MockSectioner) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Invalid editor\n",
+		},
+		{
+			name:        "not found",
+			slug:        "missing",
+			requestBody: `{"title":"Институт №8"}`,
+			mockSetup: func(ms *MockSectioner) {
+				ms.On("PatchSection", mock.Anything, "missing", models.SectionPatch{Title: &title}).Return(models.Section{}, storage.ErrSectionNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   "Section not found\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockSectioner := NewMockSectioner(t)
+			tt.mockSetup(mockSectioner)
+
+			handler := PatchSectionHandler(mockSectioner, allowAll{}, slog.Default())
+			req := httptest.NewRequest("PATCH", "/sections/"+tt.slug+"/", strings.NewReader(tt.requestBody))
+			req.SetPathValue("slug", tt.slug)
+			w := httptest.NewRecorder()
+
+			handler(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Equal(t, tt.expectedBody, w.Body.String())
+			mockSectioner.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGetSectionPostsHandler(t *testing.T) {
+	inSection := models.PostFilter{Section: "it", SortBy: models.SortByCreatedAt, Desc: true}
+	createdAt := time.Date(2025, 9, 1, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		slug           string
+		target         string
+		mockSetup      func(*MockSectioner, *MockPoster)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:   "success",
+			slug:   "it",
+			target: "/sections/it/posts/?limit=2",
+			mockSetup: func(ms *MockSectioner, mp *MockPoster) {
+				ms.On("GetSection", mock.Anything, "it").Return(testSection, nil)
+				mp.On("GetAllPosts", mock.Anything, inSection, models.Page{Limit: 2}).Return(models.PostsPage{
+					Posts: []models.OutputPost{{ID: 1, Title: "Расписание", Content: "Content", Section: "it", CreatedAt: createdAt}},
+				}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"posts":[{"id":1,"title":"Расписание","content":"Content","section":"it","created_at":"2025-09-01T10:00:00Z"}]}` + "\n",
+		},
+		{
+			name:   "unknown section",
+			slug:   "missing",
+			target: "/sections/missing/posts/",
+			mockSetup: func(ms *MockSectioner, mp *MockPoster) {
+				ms.On("GetSection", mock.Anything, "missing").Return(models.Section{}, storage.ErrSectionNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   "Section not found\n",
+		},
+		{
+			name:   "invalid filter",
+			slug:   "it",
+			target: "/sections/it/posts/?sort=views",
+			mockSetup: func(ms *MockSectioner, mp *MockPoster) {
+				ms.On("GetSection", mock.Anything, "it").Return(testSection, nil)
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Invalid sort\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockSectioner := NewMockSectioner(t)
+			mockPoster := NewMockPoster(t)
+			tt.mockSetup(mockSectioner, mockPoster)
+
+			handler := GetSectionPostsHandler(mockSectioner, mockPoster, DefaultMaxPageSize, slog.Default())
+			req := httptest.NewRequest("GET", tt.target, nil)
+			req.SetPathValue("slug", tt.slug)
+			w := httptest.NewRecorder()
+
+			handler(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Equal(t, tt.expectedBody, w.Body.String())
+			mockSectioner.AssertExpectations(t)
+			mockPoster.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/url"
+
+	"github.com/RomanKovalev007/mai_news/internal/lib/slug"
+)
+
+var errInvalidSlug = errors.New("Invalid slug")
+
+// validSlug accepts an empty slug, which leaves the choice to the store.
+func validSlug(s string) error{
+	if s != "" && !slug.Valid(s){
+		return errInvalidSlug
+	}
+	return nil
+}
+
+// GetPostBySlugHandler serves GET /posts/by-slug/{slug}/ like GetPostHandler.
+// A former slug of a post answers 301 Moved Permanently to its current one;
+// only the request that follows the redirect counts as a view.
+func GetPostBySlugHandler(poster Poster, views ViewRecorder, log *slog.Logger) http.HandlerFunc {
+	return func (w http.ResponseWriter, r *http.Request){
+		w.Header().Set("Content-Type", "application/json")
+		requested := r.PathValue("slug")
+		post, err := poster.GetPostBySlug(r.Context(), requested)
+		if err != nil {
+			if writeContextError(w, r, log, err){
+				return
+			}
+			http.Error(w, "Post not found", http.StatusNotFound)
+			log.Error("failed to get post by slug", slog.String("error", err.Error()))
+			return
+		}
+		if post.Slug != requested {
+			location := url.URL{Path: "/posts/by-slug/" + post.Slug + "/", RawQuery: r.URL.RawQuery}
+			w.Header().Del("Content-Type")
+			http.Redirect(w, r, location.String(), http.StatusMovedPermanently)
+			return
+		}
+		views.RecordView(post.ID)
+		if writeNotModified(w, r, etag(post), countedLastModified(post.UpdatedAt, post)) {
+			return
+		}
+		json.NewEncoder(w).Encode(inZone(r, post))
+	}
+}
@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/RomanKovalev007/mai_news/internal/models"
+	"github.com/RomanKovalev007/mai_news/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestGetPostBySlugHandler(t *testing.T) {
+	post := models.OutputPost{ID: 1, Title: "Расписание", Slug: "raspisanie", Version: 2}
+
+	tests := []struct {
+		name             string
+		target           string
+		slug             string
+		headers          map[string]string
+		mockSetup        func(*MockPoster)
+		expectedStatus   int
+		expectedBody     string
+		expectedLocation string
+	}{
+		{
+			name:   "success",
+			target: "/posts/by-slug/raspisanie/",
+			slug:   "raspisanie",
+			mockSetup: func(mp *MockPoster) {
+				mp.On("GetPostBySlug", mock.Anything, "raspisanie").Return(post, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"id":1,"title":"Расписание","content":"","slug":"raspisanie","created_at":"0001-01-01T00:00:00Z"}` + "\n",
+		},
+		{
+			name:    "etag matches",
+			target:  "/posts/by-slug/raspisanie/",
+			slug:    "raspisanie",
+			headers: map[string]string{"If-None-Match": `"2"`},
+			mockSetup: func(mp *MockPoster) {
+				mp.On("GetPostBySlug", mock.Anything, "raspisanie").Return(post, nil)
+			},
+			expectedStatus: http.StatusNotModified,
+		},
+		{
+			name:   "former slug",
+			target: "/posts/by-slug/novosti-mai/?tz=Europe/Moscow",
+			slug:   "novosti-mai",
+			mockSetup: func(mp *MockPoster) {
+				mp.On("GetPostBySlug", mock.Anything, "novosti-mai").Return(post, nil)
+			},
+			expectedStatus:   http.StatusMovedPermanently,
+			expectedLocation: "/posts/by-slug/raspisanie/?tz=Europe/Moscow",
+		},
+		{
+			name:   "not found",
+			target: "/posts/by-slug/missing/",
+			slug:   "missing",
+			mockSetup: func(mp *MockPoster) {
+				mp.On("GetPostBySlug", mock.Anything, "missing").Return(models.OutputPost{}, storage.ErrPostNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   "Post not found\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockPoster := NewMockPoster(t)
+			tt.mockSetup(mockPoster)
+
+			var views viewLog
+			handler := GetPostBySlugHandler(mockPoster, &views, slog.Default())
+			req := httptest.NewRequest("GET", tt.target, nil)
+			req.SetPathValue("slug", tt.slug)
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+			w := httptest.NewRecorder()
+
+			handler(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedBody != "" {
+				assert.Equal(t, tt.expectedBody, w.Body.String())
+			}
+			assert.Equal(t, tt.expectedLocation, w.Header().Get("Location"))
+			if w.Code == http.StatusOK || w.Code == http.StatusNotModified {
+				assert.Equal(t, viewLog{1}, views)
+			} else {
+				assert.Empty(t, views)
+			}
+			mockPoster.AssertExpectations(t)
+		})
+	}
+}
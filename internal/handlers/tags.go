@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/RomanKovalev007/mai_news/internal/lib/tags"
+	"github.com/RomanKovalev007/mai_news/internal/models"
+	"github.com/RomanKovalev007/mai_news/internal/storage"
+)
+
+// Tagger manages the tags of posts. RenameTag and MergeTags fail with
+// storage.ErrTagNotFound for tags no post carries.
+type Tagger interface{
+	GetTags(ctx context.Context) ([]models.Tag, error)
+	// RenameTag fails with storage.ErrTagExists if newName is in use.
+	RenameTag(ctx context.Context, name, newName string) (models.Tag, error)
+	MergeTags(ctx context.Context, from, into string) (models.Tag, error)
+}
+
+// GetTagsHandler serves GET /tags/ with the tags in use and how many posts
+// carry each, most used first.
+func GetTagsHandler(tagger Tagger, log *slog.Logger) http.HandlerFunc {
+	return func (w http.ResponseWriter, r *http.Request){
+		result, err := tagger.GetTags(r.Context())
+		if err != nil {
+			if writeContextError(w, r, log, err){
+				return
+			}
+			http.Error(w, "failed to get tags", http.StatusInternalServerError)
+			log.Error("failed to get tags", slog.String("error", err.Error()))
+			return
+		}
+		if result == nil {
+			result = []models.Tag{}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(result)
+	}
+}
+
+// RenameTagHandler serves PATCH /tags/{name}/ with a body of {"name": ...}.
+// A name already in use answers 409; MergeTagHandler joins tags instead.
+func RenameTagHandler(tagger Tagger, log *slog.Logger) http.HandlerFunc {
+	return func (w http.ResponseWriter, r *http.Request){
+		var req struct{
+			Name string `json:"name"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request payload", http.StatusBadRequest)
+			return
+		}
+		name, err := tags.Normalize(r.PathValue("name"))
+		if err != nil {
+			http.Error(w, errInvalidTag.Error(), http.StatusBadRequest)
+			return
+		}
+		newName, err := tags.Normalize(req.Name)
+		if err != nil {
+			http.Error(w, errInvalidTag.Error(), http.StatusBadRequest)
+			return
+		}
+
+		tag, err := tagger.RenameTag(r.Context(), name, newName)
+		if err != nil {
+			if writeContextError(w, r, log, err){
+				return
+			}
+			writeTagError(w, err, log)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(tag)
+	}
+}
+
+// MergeTagHandler serves POST /tags/{name}/merge/ with a body of
+// {"into": ...}: the posts carrying the tag get the tag into instead, and
+// the tag is removed.
+func MergeTagHandler(tagger Tagger, log *slog.Logger) http.HandlerFunc {
+	return func (w http.ResponseWriter, r *http.Request){
+		var req struct{
+			Into string `json:"into"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request payload", http.StatusBadRequest)
+			return
+		}
+		name, err := tags.Normalize(r.PathValue("name"))
+		if err != nil {
+			http.Error(w, errInvalidTag.Error(), http.StatusBadRequest)
+			return
+		}
+		into, err := tags.Normalize(req.Into)
+		if err != nil {
+			http.Error(w, errInvalidTag.Error(), http.StatusBadRequest)
+			return
+		}
+		if into == name {
+			http.Error(w, "Cannot merge a tag into itself", http.StatusBadRequest)
+			return
+		}
+
+		tag, err := tagger.MergeTags(r.Context(), name, into)
+		if err != nil {
+			if writeContextError(w, r, log, err){
+				return
+			}
+			writeTagError(w, err, log)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(tag)
+	}
+}
+
+func writeTagError(w http.ResponseWriter, err error, log *slog.Logger){
+	switch {
+	case errors.Is(err, storage.ErrTagNotFound):
+		http.Error(w, "Tag not found", http.StatusNotFound)
+	case errors.Is(err, storage.ErrTagExists):
+		http.Error(w, "Tag already exists", http.StatusConflict)
+	default:
+		http.Error(w, "failed to update tag", http.StatusInternalServerError)
+		log.Error("failed to update tag", slog.String("error", err.Error()))
+	}
+}
@@ -0,0 +1,208 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/RomanKovalev007/mai_news/internal/models"
+	"github.com/RomanKovalev007/mai_news/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestGetTagsHandler(t *testing.T) {
+	tests := []struct {
+		name           string
+		mockSetup      func(*MockTagger)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name: "success",
+			mockSetup: func(mt *MockTagger) {
+				mt.On("GetTags", mock.Anything).Return([]models.Tag{{Name: "sports", Count: 2}, {Name: "admissions", Count: 1}}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `[{"name":"sports","count":2},{"name":"admissions","count":1}]` + "\n",
+		},
+		{
+			name: "no tags",
+			mockSetup: func(mt *MockTagger) {
+				mt.On("GetTags", mock.Anything).Return(nil, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   "[]\n",
+		},
+		{
+			name: "error",
+			mockSetup: func(mt *MockTagger) {
+				mt.On("GetTags", mock.Anything).Return(nil, errors.New("db error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   "failed to get tags\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockTagger := NewMockTagger(t)
+			tt.mockSetup(mockTagger)
+
+			handler := GetTagsHandler(mockTagger, slog.Default())
+			req := httptest.NewRequest("GET", "/tags/", nil)
+			w := httptest.NewRecorder()
+
+			handler(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Equal(t, tt.expectedBody, w.Body.String())
+			mockTagger.AssertExpectations(t)
+		})
+	}
+}
+
+func TestRenameTagHandler(t *testing.T) {
+	tests := []struct {
+		name           string
+		tag            string
+		requestBody    string
+		mockSetup      func(*MockTagger)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:        "success",
+			tag:         "Sports",
+			requestBody: `{"name":" Sport "}`,
+			mockSetup: func(mt *MockTagger) {
+				mt.On("RenameTag", mock.Anything, "sports", "sport").Return(models.Tag{Name: "sport", Count: 2}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"name":"sport","count":2}` + "\n",
+		},
+		{
+			name:           "invalid name",
+			tag:            "sports",
+			requestBody:    `{"name":""}`,
+			mockSetup:      func(mt *MockTagger) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Invalid tag\n",
+		},
+		{
+			name:           "invalid json",
+			tag:            "sports",
+			requestBody:    `invalid json`,
+			mockSetup:      func(mt *MockTagger) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Invalid request payload\n",
+		},
+		{
+			name:        "not found",
+			tag:         "missing",
+			requestBody: `{"name":"sport"}`,
+			mockSetup: func(mt *MockTagger) {
+				mt.On("RenameTag", mock.Anything, "missing", "sport").Return(models.Tag{}, storage.ErrTagNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   "Tag not found\n",
+		},
+		{
+			name:        "name in use",
+			tag:         "sports",
+			requestBody: `{"name":"admissions"}`,
+			mockSetup: func(mt *MockTagger) {
+				mt.On("RenameTag", mock.Anything, "sports", "admissions").Return(models.Tag{}, storage.ErrTagExists)
+			},
+			expectedStatus: http.StatusConflict,
+			expectedBody:   "Tag already exists\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockTagger := NewMockTagger(t)
+			tt.mockSetup(mockTagger)
+
+			handler := RenameTagHandler(mockTagger, slog.Default())
+			req := httptest.NewRequest("PATCH", "/tags/"+tt.tag+"/", strings.NewReader(tt.requestBody))
+			req.SetPathValue("name", tt.tag)
+			w := httptest.NewRecorder()
+
+			handler(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Equal(t, tt.expectedBody, w.Body.String())
+			mockTagger.AssertExpectations(t)
+		})
+	}
+}
+
+func TestMergeTagHandler(t *testing.T) {
+	tests := []struct {
+		name           string
+		tag            string
+		requestBody    string
+		mockSetup      func(*MockTagger)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:        "success",
+			tag:         "scholarships",
+			requestBody: `{"into":"Admissions"}`,
+			mockSetup: func(mt *MockTagger) {
+				mt.On("MergeTags", mock.Anything, "scholarships", "admissions").Return(models.Tag{Name: "admissions", Count: 3}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"name":"admissions","count":3}` + "\n",
+		},
+		{
+			name:           "into itself",
+			tag:            "sports",
+			requestBody:    `{"into":"Sports"}`,
+			mockSetup:      func(mt *MockTagger) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Cannot merge a tag into itself\n",
+		},
+		{
+			name:           "missing into",
+			tag:            "sports",
+			requestBody:    `{}`,
+			mockSetup:      func(mt *MockTagger) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Invalid tag\n",
+		},
+		{
+			name:        "not found",
+			tag:         "sports",
+			requestBody: `{"into":"missing"}`,
+			mockSetup: func(mt *MockTagger) {
+				mt.On("MergeTags", mock.Anything, "sports", "missing").Return(models.Tag{}, storage.ErrTagNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   "Tag not found\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockTagger := NewMockTagger(t)
+			tt.mockSetup(mockTagger)
+
+			handler := MergeTagHandler(mockTagger, slog.Default())
+			req := httptest.NewRequest("POST", "/tags/"+tt.tag+"/merge/", strings.NewReader(tt.requestBody))
+			req.SetPathValue("name", tt.tag)
+			w := httptest.NewRecorder()
+
+			handler(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Equal(t, tt.expectedBody, w.Body.String())
+			mockTagger.AssertExpectations(t)
+		})
+	}
+}
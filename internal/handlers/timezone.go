@@ -0,0 +1,39 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/RomanKovalev007/mai_news/internal/middleware"
+	"github.com/RomanKovalev007/mai_news/internal/models"
+)
+
+// inZone converts the times of post to the display time zone of the request,
+// see middleware.TimeZone.
+func inZone(r *http.Request, post models.OutputPost) models.OutputPost{
+	loc := middleware.Location(r.Context())
+	post.CreatedAt = post.CreatedAt.In(loc)
+	post.UpdatedAt = post.UpdatedAt.In(loc)
+	post.DeletedAt = post.DeletedAt.In(loc)
+	return post
+}
+
+// postsInZone converts the times of posts in place, see inZone.
+func postsInZone(r *http.Request, posts []models.OutputPost){
+	for i := range posts{
+		posts[i] = inZone(r, posts[i])
+	}
+}
+
+// revisionInZone converts the time of revision like inZone.
+func revisionInZone(r *http.Request, revision models.Revision) models.Revision{
+	revision.RevisedAt = revision.RevisedAt.In(middleware.Location(r.Context()))
+	return revision
+}
+
+// commentInZone converts the times of comment like inZone.
+func commentInZone(r *http.Request, comment models.Comment) models.Comment{
+	loc := middleware.Location(r.Context())
+	comment.CreatedAt = comment.CreatedAt.In(loc)
+	comment.UpdatedAt = comment.UpdatedAt.In(loc)
+	return comment
+}
@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+
+	"github.com/RomanKovalev007/mai_news/internal/authz"
+	"github.com/RomanKovalev007/mai_news/internal/models"
+	"github.com/RomanKovalev007/mai_news/internal/storage"
+)
+
+// Trasher manages posts removed with DeletePost.
+type Trasher interface{
+	GetTrash(ctx context.Context) ([]models.OutputPost, error)
+	RestorePost(ctx context.Context, id int) (models.OutputPost, error)
+	PurgePost(ctx context.Context, id int) error
+}
+
+// GetTrashHandler lists the deleted posts of the sections the user edits,
+// or all of them for an admin.
+func GetTrashHandler(trasher Trasher, authorizer Authorizer, log *slog.Logger) http.HandlerFunc {
+	return func (w http.ResponseWriter, r *http.Request){
+		sections, all, ok := reachedSections(w, r, authorizer, authz.ViewTrash, log)
+		if !ok{
+			return
+		}
+
+		posts, err := trasher.GetTrash(r.Context())
+		if err != nil {
+			if writeContextError(w, r, log, err){
+				return
+			}
+			http.Error(w, "failed to get trash", http.StatusInternalServerError)
+			log.Error("failed to get trash", slog.String("error", err.Error()))
+			return
+		}
+		if !all{
+			posts = slices.DeleteFunc(posts, func(post models.OutputPost) bool{
+				return !slices.Contains(sections, post.Section)
+			})
+		}
+		if posts == nil {
+			posts = []models.OutputPost{}
+		}
+
+		postsInZone(r, posts)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(posts)
+	}
+}
+
+func RestorePostHandler(trasher Trasher, authorizer Authorizer, log *slog.Logger) http.HandlerFunc {
+	return func (w http.ResponseWriter, r *http.Request){
+		id, err := strconv.Atoi(r.PathValue("id"))
+		if err != nil {
+			http.Error(w, "Invalid post ID", http.StatusBadRequest)
+			return
+		}
+
+		if !authorizePost(w, r, authorizer, authz.RestorePost, id, "Post not found in trash", log){
+			return
+		}
+
+		post, err := trasher.RestorePost(r.Context(), id)
+		if err != nil {
+			if writeContextError(w, r, log, err){
+				return
+			}
+			if errors.Is(err, storage.ErrPostNotFound){
+				http.Error(w, "Post not found in trash", http.StatusNotFound)
+				return
+			}
+			http.Error(w, "failed to restore post", http.StatusInternalServerError)
+			log.Error("failed to restore post", slog.String("error", err.Error()))
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("ETag", etag(post))
+		json.NewEncoder(w).Encode(inZone(r, post))
+	}
+}
+
+func PurgePostHandler(trasher Trasher, authorizer Authorizer, log *slog.Logger) http.HandlerFunc {
+	return func (w http.ResponseWriter, r *http.Request){
+		id, err := strconv.Atoi(r.PathValue("id"))
+		if err != nil {
+			http.Error(w, "Invalid post ID", http.StatusBadRequest)
+			return
+		}
+
+		if !authorizePost(w, r, authorizer, authz.PurgePost, id, "Post not found in trash", log){
+			return
+		}
+
+		err = trasher.PurgePost(r.Context(), id)
+		if err != nil {
+			if writeContextError(w, r, log, err){
+				return
+			}
+			if errors.Is(err, storage.ErrPostNotFound){
+				http.Error(w, "Post not found in trash", http.StatusNotFound)
+				return
+			}
+			http.Error(w, "failed to purge post", http.StatusInternalServerError)
+			log.Error("failed to purge post", slog.String("error", err.Error()))
+			return
+		}
+	}
+}
@@ -0,0 +1,224 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/RomanKovalev007/mai_news/internal/authz"
+	"github.com/RomanKovalev007/mai_news/internal/middleware"
+	"github.com/RomanKovalev007/mai_news/internal/models"
+	"github.com/RomanKovalev007/mai_news/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestGetTrashHandler(t *testing.T) {
+	user := models.User{ID: 2, Email: "boris@mai.ru", Role: models.RoleEditor}
+	trash := []models.OutputPost{
+		{ID: 1, Title: "Deleted", Content: "Content", Section: "general", DeletedAt: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{ID: 2, Title: "Schedule", Content: "Content", Section: "it", DeletedAt: time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)},
+	}
+	tests := []struct {
+		name           string
+		mockSetup      func(*MockTrasher, *MockAuthorizer)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name: "all sections",
+			mockSetup: func(mt *MockTrasher, ma *MockAuthorizer) {
+				ma.On("ReachedSections", mock.Anything, user, authz.ViewTrash).Return(nil, true, nil)
+				mt.On("GetTrash", mock.Anything).Return(trash, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: `[{"id":1,"title":"Deleted","content":"Content","section":"general","created_at":"0001-01-01T00:00:00Z","deleted_at":"2025-01-01T00:00:00Z"},` +
+				`{"id":2,"title":"Schedule","content":"Content","section":"it","created_at":"0001-01-01T00:00:00Z","deleted_at":"2025-01-02T00:00:00Z"}]` + "\n",
+		},
+		{
+			name: "sections edited",
+			mockSetup: func(mt *MockTrasher, ma *MockAuthorizer) {
+				ma.On("ReachedSections", mock.Anything, user, authz.ViewTrash).Return([]string{"it"}, false, nil)
+				mt.On("GetTrash", mock.Anything).Return(slices.Clone(trash), nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `[{"id":2,"title":"Schedule","content":"Content","section":"it","created_at":"0001-01-01T00:00:00Z","deleted_at":"2025-01-02T00:00:00Z"}]` + "\n",
+		},
+		{
+			name: "no sections edited",
+			mockSetup: func(mt *MockTrasher, ma *MockAuthorizer) {
+				ma.On("ReachedSections", mock.Anything, user, authz.ViewTrash).Return(nil, false, nil)
+				mt.On("GetTrash", mock.Anything).Return(slices.Clone(trash), nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   "[]\n",
+		},
+		{
+			name: "empty trash",
+			mockSetup: func(mt *MockTrasher, ma *MockAuthorizer) {
+				ma.On("ReachedSections", mock.Anything, user, authz.ViewTrash).Return(nil, true, nil)
+				mt.On("GetTrash", mock.Anything).Return(nil, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   "[]\n",
+		},
+		{
+			name: "denied",
+			mockSetup: func(mt *MockTrasher, ma *MockAuthorizer) {
+				ma.On("ReachedSections", mock.Anything, user, authz.ViewTrash).Return(nil, false, &authz.Denied{
+					Action: authz.ViewTrash, Reason: authz.ReasonRole, Message: `Role "editor" may not do this`,
+				})
+			},
+			expectedStatus: http.StatusForbidden,
+			expectedBody:   `{"action":"posts.trash","reason":"role","message":"Role \"editor\" may not do this"}` + "\n",
+		},
+		{
+			name: "storage error",
+			mockSetup: func(mt *MockTrasher, ma *MockAuthorizer) {
+				ma.On("ReachedSections", mock.Anything, user, authz.ViewTrash).Return(nil, true, nil)
+				mt.On("GetTrash", mock.Anything).Return(nil, errors.New("db is down"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   "failed to get trash\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockTrasher := NewMockTrasher(t)
+			mockAuthorizer := NewMockAuthorizer(t)
+			tt.mockSetup(mockTrasher, mockAuthorizer)
+
+			handler := GetTrashHandler(mockTrasher, mockAuthorizer, slog.Default())
+			req := httptest.NewRequest("GET", "/posts/trash/", nil)
+			req = req.WithContext(middleware.WithUser(req.Context(), user))
+			w := httptest.NewRecorder()
+
+			handler(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Equal(t, tt.expectedBody, w.Body.String())
+		})
+	}
+}
+
+func TestRestorePostHandler(t *testing.T) {
+	tests := []struct {
+		name           string
+		postID         string
+		mockSetup      func(*MockTrasher)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:   "success",
+			postID: "1",
+			mockSetup: func(mt *MockTrasher) {
+				mt.On("RestorePost", mock.Anything, 1).Return(models.OutputPost{
+					ID: 1, Title: "Restored", Content: "Content",
+				}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"id":1,"title":"Restored","content":"Content","created_at":"0001-01-01T00:00:00Z"}` + "\n",
+		},
+		{
+			name:           "invalid id",
+			postID:         "invalid",
+			mockSetup:      func(mt *MockTrasher) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Invalid post ID\n",
+		},
+		{
+			name:   "not in trash",
+			postID: "2",
+			mockSetup: func(mt *MockTrasher) {
+				mt.On("RestorePost", mock.Anything, 2).Return(models.OutputPost{}, storage.ErrPostNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   "Post not found in trash\n",
+		},
+		{
+			name:   "storage error",
+			postID: "3",
+			mockSetup: func(mt *MockTrasher) {
+				mt.On("RestorePost", mock.Anything, 3).Return(models.OutputPost{}, errors.New("db is down"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   "failed to restore post\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockTrasher := NewMockTrasher(t)
+			tt.mockSetup(mockTrasher)
+
+			handler := RestorePostHandler(mockTrasher, allowAll{}, slog.Default())
+			req := httptest.NewRequest("POST", "/posts/"+tt.postID+"/restore/", nil)
+			req.SetPathValue("id", tt.postID)
+			w := httptest.NewRecorder()
+
+			handler(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Equal(t, tt.expectedBody, w.Body.String())
+		})
+	}
+}
+
+func TestPurgePostHandler(t *testing.T) {
+	tests := []struct {
+		name           string
+		postID         string
+		mockSetup      func(*MockTrasher)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:   "success",
+			postID: "1",
+			mockSetup: func(mt *MockTrasher) {
+				mt.On("PurgePost", mock.Anything, 1).Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   "",
+		},
+		{
+			name:           "invalid id",
+			postID:         "invalid",
+			mockSetup:      func(mt *MockTrasher) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Invalid post ID\n",
+		},
+		{
+			name:   "not in trash",
+			postID: "2",
+			mockSetup: func(mt *MockTrasher) {
+				mt.On("PurgePost", mock.Anything, 2).Return(storage.ErrPostNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   "Post not found in trash\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockTrasher := NewMockTrasher(t)
+			tt.mockSetup(mockTrasher)
+
+			handler := PurgePostHandler(mockTrasher, allowAll{}, slog.Default())
+			req := httptest.NewRequest("DELETE", "/posts/"+tt.postID+"/purge/", nil)
+			req.SetPathValue("id", tt.postID)
+			w := httptest.NewRecorder()
+
+			handler(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Equal(t, tt.expectedBody, w.Body.String())
+		})
+	}
+}
@@ -0,0 +1,280 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/RomanKovalev007/mai_news/internal/lib/password"
+	"github.com/RomanKovalev007/mai_news/internal/models"
+	"github.com/RomanKovalev007/mai_news/internal/storage"
+)
+
+var (
+	errMissingName = errors.New("Name is required")
+	errInvalidEmail = errors.New("Invalid email")
+	errInvalidPassword = errors.New("Password must be 8 to 128 characters long")
+	errInvalidRole = errors.New("Role must be one of reader, author, editor and admin")
+)
+
+// Registrar manages user accounts. GetUser, PatchUser and DeleteUser fail
+// with storage.ErrUserNotFound for unknown ids.
+type Registrar interface{
+	GetUsers(ctx context.Context) ([]models.User, error)
+	GetUser(ctx context.Context, id int) (models.User, error)
+	// GetUserByEmail expects the email lowercased.
+	GetUserByEmail(ctx context.Context, email string) (models.User, error)
+	// SaveUser and PatchUser fail with storage.ErrUserExists if the email
+	// is in use.
+	SaveUser(ctx context.Context, user models.InputUser) (models.User, error)
+	PatchUser(ctx context.Context, id int, patch models.UserPatch) (models.User, error)
+	// DeleteUser keeps the posts of the user, without an author.
+	DeleteUser(ctx context.Context, id int) error
+}
+
+// userRequest is the body of POST /admin/users/ and, with any of the
+// fields left out, of PATCH /admin/users/{id}/. Only the hash of the
+// password reaches the store. New users without a role are readers.
+type userRequest struct{
+	Name *string `json:"name"`
+	Email *string `json:"email"`
+	Password *string `json:"password"`
+	Role *models.Role `json:"role"`
+}
+
+// GetUsersHandler serves GET /admin/users/ with every user.
+func GetUsersHandler(registrar Registrar, log *slog.Logger) http.HandlerFunc {
+	return func (w http.ResponseWriter, r *http.Request){
+		result, err := registrar.GetUsers(r.Context())
+		if err != nil {
+			if writeContextError(w, r, log, err){
+				return
+			}
+			http.Error(w, "failed to get users", http.StatusInternalServerError)
+			log.Error("failed to get users", slog.String("error", err.Error()))
+			return
+		}
+		if result == nil {
+			result = []models.User{}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(result)
+	}
+}
+
+func GetUserHandler(registrar Registrar, log *slog.Logger) http.HandlerFunc {
+	return func (w http.ResponseWriter, r *http.Request){
+		id, err := strconv.Atoi(r.PathValue("id"))
+		if err != nil {
+			http.Error(w, "Invalid user ID", http.StatusBadRequest)
+			return
+		}
+
+		user, err := registrar.GetUser(r.Context(), id)
+		if err != nil {
+			if writeContextError(w, r, log, err){
+				return
+			}
+			writeUserError(w, err, log)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(user)
+	}
+}
+
+// CreateUserHandler serves POST /admin/users/. Name, email and password are
+// required, role is optional; an email in use answers 409.
+func CreateUserHandler(registrar Registrar, log *slog.Logger) http.HandlerFunc {
+	return func (w http.ResponseWriter, r *http.Request){
+		var req userRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request payload", http.StatusBadRequest)
+			return
+		}
+		if req.Name == nil || req.Email == nil || req.Password == nil {
+			http.Error(w, "Name, email and password are required", http.StatusBadRequest)
+			return
+		}
+		patch, err := req.toPatch()
+		if err != nil {
+			writeUserRequestError(w, err, log)
+			return
+		}
+
+		input := models.InputUser{
+			Name: *patch.Name,
+			Email: *patch.Email,
+			PasswordHash: *patch.PasswordHash,
+			Role: models.RoleReader,
+		}
+		if patch.Role != nil {
+			input.Role = *patch.Role
+		}
+
+		user, err := registrar.SaveUser(r.Context(), input)
+		if err != nil {
+			if writeContextError(w, r, log, err){
+				return
+			}
+			writeUserError(w, err, log)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(user)
+	}
+}
+
+// PatchUserHandler serves PATCH /admin/users/{id}/ with any of name, email,
+// password and role.
+func PatchUserHandler(registrar Registrar, log *slog.Logger) http.HandlerFunc {
+	return func (w http.ResponseWriter, r *http.Request){
+		id, err := strconv.Atoi(r.PathValue("id"))
+		if err != nil {
+			http.Error(w, "Invalid user ID", http.StatusBadRequest)
+			return
+		}
+
+		var req userRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request payload", http.StatusBadRequest)
+			return
+		}
+		patch, err := req.toPatch()
+		if err != nil {
+			writeUserRequestError(w, err, log)
+			return
+		}
+
+		user, err := registrar.PatchUser(r.Context(), id, patch)
+		if err != nil {
+			if writeContextError(w, r, log, err){
+				return
+			}
+			writeUserError(w, err, log)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(user)
+	}
+}
+
+// DeleteUserHandler serves DELETE /admin/users/{id}/. The posts of the user
+// stay, without an author.
+func DeleteUserHandler(registrar Registrar, log *slog.Logger) http.HandlerFunc {
+	return func (w http.ResponseWriter, r *http.Request){
+		id, err := strconv.Atoi(r.PathValue("id"))
+		if err != nil {
+			http.Error(w, "Invalid user ID", http.StatusBadRequest)
+			return
+		}
+
+		if err = registrar.DeleteUser(r.Context(), id); err != nil {
+			if writeContextError(w, r, log, err){
+				return
+			}
+			writeUserError(w, err, log)
+			return
+		}
+	}
+}
+
+// GetUserPostsHandler serves GET /users/{id}/posts/, the posts list of
+// GetAllPostsHandler narrowed down to the posts of one author.
+func GetUserPostsHandler(registrar Registrar, poster Poster, maxPageSize int, log *slog.Logger) http.HandlerFunc {
+	return func (w http.ResponseWriter, r *http.Request){
+		id, err := strconv.Atoi(r.PathValue("id"))
+		if err != nil {
+			http.Error(w, "Invalid user ID", http.StatusBadRequest)
+			return
+		}
+
+		user, err := registrar.GetUser(r.Context(), id)
+		if err != nil {
+			if writeContextError(w, r, log, err){
+				return
+			}
+			writeUserError(w, err, log)
+			return
+		}
+
+		filter, err := parseFilter(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		filter.AuthorID = user.ID
+
+		writePostsPage(w, r, poster, filter, maxPageSize, log)
+	}
+}
+
+// toPatch validates the fields set in req, trims the name, lowercases the
+// email, hashes the password and checks the role.
+func (req userRequest) toPatch() (models.UserPatch, error){
+	var patch models.UserPatch
+	if req.Name != nil{
+		name := strings.TrimSpace(*req.Name)
+		if name == ""{
+			return models.UserPatch{}, errMissingName
+		}
+		patch.Name = &name
+	}
+	if req.Email != nil{
+		email, ok := normalizeEmail(*req.Email)
+		if !ok{
+			return models.UserPatch{}, errInvalidEmail
+		}
+		patch.Email = &email
+	}
+	if req.Password != nil{
+		if err := password.Check(*req.Password); err != nil{
+			return models.UserPatch{}, errInvalidPassword
+		}
+		hash, err := password.Hash(*req.Password)
+		if err != nil{
+			return models.UserPatch{}, fmt.Errorf("hash password: %w", err)
+		}
+		patch.PasswordHash = &hash
+	}
+	if req.Role != nil{
+		if !req.Role.Valid(){
+			return models.UserPatch{}, errInvalidRole
+		}
+		patch.Role = req.Role
+	}
+	return patch, nil
+}
+
+// writeUserRequestError answers a request toPatch rejected: 400 for invalid
+// fields, 500 if the password could not be hashed.
+func writeUserRequestError(w http.ResponseWriter, err error, log *slog.Logger){
+	if errors.Is(err, errMissingName) || errors.Is(err, errInvalidEmail) || errors.Is(err, errInvalidPassword) || errors.Is(err, errInvalidRole){
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	http.Error(w, "failed to save user", http.StatusInternalServerError)
+	log.Error("failed to save user", slog.String("error", err.Error()))
+}
+
+func writeUserError(w http.ResponseWriter, err error, log *slog.Logger){
+	switch {
+	case errors.Is(err, storage.ErrUserNotFound):
+		http.Error(w, "User not found", http.StatusNotFound)
+	case errors.Is(err, storage.ErrUserExists):
+		http.Error(w, "User with this email already exists", http.StatusConflict)
+	default:
+		http.Error(w, "failed to access user", http.StatusInternalServerError)
+		log.Error("failed to access user", slog.String("error", err.Error()))
+	}
+}
@@ -0,0 +1,424 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/RomanKovalev007/mai_news/internal/lib/password"
+	"github.com/RomanKovalev007/mai_news/internal/models"
+	"github.com/RomanKovalev007/mai_news/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var testUser = models.User{
+	ID:           3,
+	Name:         "Анна",
+	Email:        "anna@mai.ru",
+	PasswordHash: "secret hash",
+	Role:         models.RoleAuthor,
+	CreatedAt:    time.Date(2025, 9, 1, 10, 0, 0, 0, time.UTC),
+}
+
+// the password hash is never sent
+const testUserJSON = `{"id":3,"name":"Анна","email":"anna@mai.ru","role":"author","created_at":"2025-09-01T10:00:00Z"}`
+
+// hashOf matches a password hash of plain.
+func hashOf(plain string) any {
+	return mock.MatchedBy(func(hash string) bool { return password.Verify(hash, plain) })
+}
+
+func TestGetUsersHandler(t *testing.T) {
+	tests := []struct {
+		name           string
+		mockSetup      func(*MockRegistrar)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name: "success",
+			mockSetup: func(mr *MockRegistrar) {
+				mr.On("GetUsers", mock.Anything).Return([]models.User{testUser}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   "[" + testUserJSON + "]\n",
+		},
+		{
+			name: "no users",
+			mockSetup: func(mr *MockRegistrar) {
+				mr.On("GetUsers", mock.Anything).Return(nil, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   "[]\n",
+		},
+		{
+			name: "error",
+			mockSetup: func(mr *MockRegistrar) {
+				mr.On("GetUsers", mock.Anything).Return(nil, errors.New("db error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   "failed to get users\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRegistrar := NewMockRegistrar(t)
+			tt.mockSetup(mockRegistrar)
+
+			handler := GetUsersHandler(mockRegistrar, slog.Default())
+			req := httptest.NewRequest("GET", "/admin/users/", nil)
+			w := httptest.NewRecorder()
+
+			handler(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Equal(t, tt.expectedBody, w.Body.String())
+			mockRegistrar.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGetUserHandler(t *testing.T) {
+	tests := []struct {
+		name           string
+		id             string
+		mockSetup      func(*MockRegistrar)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name: "success",
+			id:   "3",
+			mockSetup: func(mr *MockRegistrar) {
+				mr.On("GetUser", mock.Anything, 3).Return(testUser, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   testUserJSON + "\n",
+		},
+		{
+			name: "not found",
+			id:   "4",
+			mockSetup: func(mr *MockRegistrar) {
+				mr.On("GetUser", mock.Anything, 4).Return(models.User{}, storage.ErrUserNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   "User not found\n",
+		},
+		{
+			name:           "invalid id",
+			id:             "anna",
+			mockSetup:      func(mr *MockRegistrar) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Invalid user ID\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRegistrar := NewMockRegistrar(t)
+			tt.mockSetup(mockRegistrar)
+
+			handler := GetUserHandler(mockRegistrar, slog.Default())
+			req := httptest.NewRequest("GET", "/admin/users/"+tt.id+"/", nil)
+			req.SetPathValue("id", tt.id)
+			w := httptest.NewRecorder()
+
+			handler(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Equal(t, tt.expectedBody, w.Body.String())
+			mockRegistrar.AssertExpectations(t)
+		})
+	}
+}
+
+func TestCreateUserHandler(t *testing.T) {
+	tests := []struct {
+		name           string
+		requestBody    string
+		mockSetup      func(*MockRegistrar)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:        "success",
+			requestBody: `{"name":" Анна ","email":"Anna@MAI.ru","password":"correct horse"}`,
+			mockSetup: func(mr *MockRegistrar) {
+				mr.On("SaveUser", mock.Anything, mock.MatchedBy(func(input models.InputUser) bool {
+					return input.Name == "Анна" && input.Email == "anna@mai.ru" && input.Role == models.RoleReader && password.Verify(input.PasswordHash, "correct horse")
+				})).Return(testUser, nil)
+			},
+			expectedStatus: http.StatusCreated,
+			expectedBody:   testUserJSON + "\n",
+		},
+		{
+			name:        "with role",
+			requestBody: `{"name":"Анна","email":"anna@mai.ru","password":"correct horse","role":"author"}`,
+			mockSetup: func(mr *MockRegistrar) {
+				mr.On("SaveUser", mock.Anything, mock.MatchedBy(func(input models.InputUser) bool {
+					return input.Role == models.RoleAuthor
+				})).Return(testUser, nil)
+			},
+			expectedStatus: http.StatusCreated,
+			expectedBody:   testUserJSON + "\n",
+		},
+		{
+			name:           "invalid role",
+			requestBody:    `{"name":"Анна","email":"anna@mai.ru","password":"correct horse","role":"owner"}`,
+			mockSetup:      func(mr *MockRegistrar) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Role must be one of reader, author, editor and admin\n",
+		},
+		{
+			name:           "missing password",
+			requestBody:    `{"name":"Анна","email":"anna@mai.ru"}`,
+			mockSetup:      func(mr *MockRegistrar) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Name, email and password are required\n",
+		},
+		{
+			name:           "empty name",
+			requestBody:    `{"name":" ","email":"anna@mai.ru","password":"correct horse"}`,
+			mockSetup:      func(mr *MockRegistrar) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Name is required\n",
+		},
+		{
+			name:           "invalid email",
+			requestBody:    `{"name":"Анна","email":"Анна <anna@mai.ru>","password":"correct horse"}`,
+			mockSetup:      func(mr *MockRegistrar) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Invalid email\n",
+		},
+		{
+			name:           "short password",
+			requestBody:    `{"name":"Анна","email":"anna@mai.ru","password":"horse"}`,
+			mockSetup:      func(mr *MockRegistrar) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Password must be 8 to 128 characters long\n",
+		},
+		{
+			name:           "invalid json",
+			requestBody:    `invalid json`,
+			mockSetup:      func(mr *MockRegistrar) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Invalid request payload\n",
+		},
+		{
+			name:        "email in use",
+			requestBody: `{"name":"Анна","email":"anna@mai.ru","password":"correct horse"}`,
+			mockSetup: func(mr *MockRegistrar) {
+				mr.On("SaveUser", mock.Anything, mock.Anything).Return(models.User{}, storage.ErrUserExists)
+			},
+			expectedStatus: http.StatusConflict,
+			expectedBody:   "User with this email already exists\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRegistrar := NewMockRegistrar(t)
+			tt.mockSetup(mockRegistrar)
+
+			handler := CreateUserHandler(mockRegistrar, slog.Default())
+			req := httptest.NewRequest("POST", "/admin/users/", strings.NewReader(tt.requestBody))
+			w := httptest.NewRecorder()
+
+			handler(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Equal(t, tt.expectedBody, w.Body.String())
+			mockRegistrar.AssertExpectations(t)
+		})
+	}
+}
+
+func TestPatchUserHandler(t *testing.T) {
+	name := "Анна Петрова"
+	editor := models.RoleEditor
+
+	tests := []struct {
+		name           string
+		id             string
+		requestBody    string
+		mockSetup      func(*MockRegistrar)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:        "rename",
+			id:          "3",
+			requestBody: `{"name":"Анна Петрова"}`,
+			mockSetup: func(mr *MockRegistrar) {
+				mr.On("PatchUser", mock.Anything, 3, models.UserPatch{Name: &name}).Return(testUser, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   testUserJSON + "\n",
+		},
+		{
+			name:        "new password",
+			id:          "3",
+			requestBody: `{"password":"battery staple"}`,
+			mockSetup: func(mr *MockRegistrar) {
+				mr.On("PatchUser", mock.Anything, 3, mock.MatchedBy(func(patch models.UserPatch) bool {
+					return patch.Name == nil && patch.Email == nil && patch.PasswordHash != nil && password.Verify(*patch.PasswordHash, "battery staple")
+				})).Return(testUser, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   testUserJSON + "\n",
+		},
+		{
+			name:        "promote",
+			id:          "3",
+			requestBody: `{"role":"editor"}`,
+			mockSetup: func(mr *MockRegistrar) {
+				mr.On("PatchUser", mock.Anything, 3, models.UserPatch{Role: &editor}).Return(testUser, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   testUserJSON + "\n",
+		},
+		{
+			name:           "invalid email",
+			id:             "3",
+			requestBody:    `{"email":"anna"}`,
+			mockSetup:      func(mr *MockRegistrar) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Invalid email\n",
+		},
+		{
+			name:        "not found",
+			id:          "4",
+			requestBody: `{"name":"Анна Петрова"}`,
+			mockSetup: func(mr *MockRegistrar) {
+				mr.On("PatchUser", mock.Anything, 4, models.UserPatch{Name: &name}).Return(models.User{}, storage.ErrUserNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   "User not found\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRegistrar := NewMockRegistrar(t)
+			tt.mockSetup(mockRegistrar)
+
+			handler := PatchUserHandler(mockRegistrar, slog.Default())
+			req := httptest.NewRequest("PATCH", "/admin/users/"+tt.id+"/", strings.NewReader(tt.requestBody))
+			req.SetPathValue("id", tt.id)
+			w := httptest.NewRecorder()
+
+			handler(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Equal(t, tt.expectedBody, w.Body.String())
+			mockRegistrar.AssertExpectations(t)
+		})
+	}
+}
+
+func TestDeleteUserHandler(t *testing.T) {
+	tests := []struct {
+		name           string
+		id             string
+		mockSetup      func(*MockRegistrar)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name: "success",
+			id:   "3",
+			mockSetup: func(mr *MockRegistrar) {
+				mr.On("DeleteUser", mock.Anything, 3).Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "not found",
+			id:   "4",
+			mockSetup: func(mr *MockRegistrar) {
+				mr.On("DeleteUser", mock.Anything, 4).Return(storage.ErrUserNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   "User not found\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRegistrar := NewMockRegistrar(t)
+			tt.mockSetup(mockRegistrar)
+
+			handler := DeleteUserHandler(mockRegistrar, slog.Default())
+			req := httptest.NewRequest("DELETE", "/admin/users/"+tt.id+"/", nil)
+			req.SetPathValue("id", tt.id)
+			w := httptest.NewRecorder()
+
+			handler(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Equal(t, tt.expectedBody, w.Body.String())
+			mockRegistrar.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGetUserPostsHandler(t *testing.T) {
+	byAuthor := models.PostFilter{AuthorID: 3, SortBy: models.SortByCreatedAt, Desc: true}
+	createdAt := time.Date(2025, 9, 1, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		id             string
+		mockSetup      func(*MockRegistrar, *MockPoster)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name: "success",
+			id:   "3",
+			mockSetup: func(mr *MockRegistrar, mp *MockPoster) {
+				mr.On("GetUser", mock.Anything, 3).Return(testUser, nil)
+				mp.On("GetAllPosts", mock.Anything, byAuthor, models.Page{Limit: defaultPageSize}).Return(models.PostsPage{
+					Posts: []models.OutputPost{{ID: 1, Title: "Title", Content: "Content", Author: &models.Author{ID: 3, Name: "Анна"}, CreatedAt: createdAt}},
+				}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"posts":[{"id":1,"title":"Title","content":"Content","author":{"id":3,"name":"Анна"},"created_at":"2025-09-01T10:00:00Z"}]}` + "\n",
+		},
+		{
+			name: "unknown user",
+			id:   "4",
+			mockSetup: func(mr *MockRegistrar, mp *MockPoster) {
+				mr.On("GetUser", mock.Anything, 4).Return(models.User{}, storage.ErrUserNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   "User not found\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRegistrar := NewMockRegistrar(t)
+			mockPoster := NewMockPoster(t)
+			tt.mockSetup(mockRegistrar, mockPoster)
+
+			handler := GetUserPostsHandler(mockRegistrar, mockPoster, DefaultMaxPageSize, slog.Default())
+			req := httptest.NewRequest("GET", "/users/"+tt.id+"/posts/", nil)
+			req.SetPathValue("id", tt.id)
+			w := httptest.NewRecorder()
+
+			handler(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Equal(t, tt.expectedBody, w.Body.String())
+			mockRegistrar.AssertExpectations(t)
+			mockPoster.AssertExpectations(t)
+		})
+	}
+}
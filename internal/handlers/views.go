@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/RomanKovalev007/mai_news/internal/models"
+	"github.com/RomanKovalev007/mai_news/internal/storage"
+)
+
+// ViewRecorder counts views of posts. It is called on every read of a post,
+// so it must not wait for the storage, like views.Aggregator.
+type ViewRecorder interface{
+	RecordView(postID int)
+}
+
+// ViewStats reads the counted views of posts.
+type ViewStats interface{
+	// GetPostStats fails with storage.ErrPostNotFound for unknown posts and
+	// posts in the trash.
+	GetPostStats(ctx context.Context, postID int) (models.PostStats, error)
+}
+
+// GetPostStatsHandler serves GET /posts/{id}/stats/ with the views of the
+// post by UTC day. Views are written in batches, so the latest ones show up
+// after the next flush.
+func GetPostStatsHandler(stats ViewStats, log *slog.Logger) http.HandlerFunc {
+	return func (w http.ResponseWriter, r *http.Request){
+		id, err := strconv.Atoi(r.PathValue("id"))
+		if err != nil {
+			http.Error(w, "Invalid post ID", http.StatusBadRequest)
+			return
+		}
+
+		postStats, err := stats.GetPostStats(r.Context(), id)
+		if err != nil {
+			if writeContextError(w, r, log, err){
+				return
+			}
+			if errors.Is(err, storage.ErrPostNotFound){
+				http.Error(w, "Post not found", http.StatusNotFound)
+				return
+			}
+			http.Error(w, "failed to get post stats", http.StatusInternalServerError)
+			log.Error("failed to get post stats", slog.String("error", err.Error()))
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(postStats)
+	}
+}
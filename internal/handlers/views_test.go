@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/RomanKovalev007/mai_news/internal/models"
+	"github.com/RomanKovalev007/mai_news/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// viewLog records the ids of viewed posts in order.
+type viewLog []int
+
+func (v *viewLog) RecordView(postID int) {
+	*v = append(*v, postID)
+}
+
+func TestGetPostStatsHandler(t *testing.T) {
+	tests := []struct {
+		name           string
+		postID         string
+		mockSetup      func(*MockViewStats)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:   "success",
+			postID: "1",
+			mockSetup: func(ms *MockViewStats) {
+				ms.On("GetPostStats", mock.Anything, 1).Return(models.PostStats{PostID: 1, Views: 5, Days: []models.DayViews{
+					{Day: "2025-09-01", Views: 2},
+					{Day: "2025-09-02", Views: 3},
+				}}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"post_id":1,"views":5,"days":[{"day":"2025-09-01","views":2},{"day":"2025-09-02","views":3}]}` + "\n",
+		},
+		{
+			name:   "no views",
+			postID: "1",
+			mockSetup: func(ms *MockViewStats) {
+				ms.On("GetPostStats", mock.Anything, 1).Return(models.PostStats{PostID: 1, Days: []models.DayViews{}}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"post_id":1,"views":0,"days":[]}` + "\n",
+		},
+		{
+			name:           "invalid id",
+			postID:         "invalid",
+			mockSetup:      func(ms *MockViewStats) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "Invalid post ID\n",
+		},
+		{
+			name:   "not found",
+			postID: "2",
+			mockSetup: func(ms *MockViewStats) {
+				ms.On("GetPostStats", mock.Anything, 2).Return(models.PostStats{}, storage.ErrPostNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   "Post not found\n",
+		},
+		{
+			name:   "storage error",
+			postID: "1",
+			mockSetup: func(ms *MockViewStats) {
+				ms.On("GetPostStats", mock.Anything, 1).Return(models.PostStats{}, errors.New("disk I/O error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   "failed to get post stats\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockStats := NewMockViewStats(t)
+			tt.mockSetup(mockStats)
+
+			handler := GetPostStatsHandler(mockStats, slog.Default())
+			req := httptest.NewRequest("GET", "/posts/"+tt.postID+"/stats/", nil)
+			req.SetPathValue("id", tt.postID)
+			w := httptest.NewRecorder()
+
+			handler(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Equal(t, tt.expectedBody, w.Body.String())
+		})
+	}
+}
@@ -0,0 +1,33 @@
+// Package apikey makes the secrets of API keys. The keys are random, so
+// unlike passwords they cannot be guessed and a fast hash is enough to
+// store them; it also lets a key be looked up by its hash.
+package apikey
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+const (
+	// Scheme is the authorization scheme of requests made with a key:
+	// `Authorization: ApiKey <key>`.
+	Scheme = "ApiKey"
+
+	keyPrefix = "mai_"
+	// PrefixLen is the length of the start of a key kept in clear.
+	PrefixLen = len(keyPrefix) + 8
+)
+
+// Generate returns a new key, its prefix and its hash.
+func Generate() (key, prefix, hash string) {
+	key = keyPrefix + strings.ToLower(rand.Text())
+	return key, key[:PrefixLen], Hash(key)
+}
+
+// Hash returns the hash a key is stored and looked up by.
+func Hash(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
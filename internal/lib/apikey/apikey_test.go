@@ -0,0 +1,22 @@
+package apikey
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerate(t *testing.T) {
+	key, prefix, hash := Generate()
+
+	assert.True(t, strings.HasPrefix(key, "mai_"), key)
+	assert.Len(t, key, len("mai_")+26)
+	assert.Equal(t, key[:PrefixLen], prefix)
+	assert.Equal(t, Hash(key), hash)
+	assert.Len(t, hash, 64)
+
+	other, _, otherHash := Generate()
+	assert.NotEqual(t, key, other)
+	assert.NotEqual(t, hash, otherHash)
+}
@@ -0,0 +1,225 @@
+package diff
+
+import (
+	"strings"
+	"unicode"
+)
+
+type Op string
+
+const (
+	Equal  Op = "equal"
+	Insert Op = "insert"
+	Delete Op = "delete"
+)
+
+// Chunk is a run of text that is kept, inserted or deleted. Concatenating
+// the Equal and Delete chunks gives the old text, Equal and Insert the new one.
+type Chunk struct {
+	Op   Op     `json:"op"`
+	Text string `json:"text"`
+}
+
+// Lines diffs a and b line by line.
+func Lines(a, b string) []Chunk {
+	return tokens(strings.SplitAfter(a, "\n"), strings.SplitAfter(b, "\n"))
+}
+
+// Words diffs a and b word by word; runs of whitespace are tokens of their own.
+func Words(a, b string) []Chunk {
+	return tokens(splitWords(a), splitWords(b))
+}
+
+func splitWords(s string) []string {
+	var words []string
+	start, prevSpace := 0, false
+	for i, r := range s {
+		space := unicode.IsSpace(r)
+		if i > start && space != prevSpace {
+			words = append(words, s[start:i])
+			start = i
+		}
+		prevSpace = space
+	}
+	if start < len(s) {
+		words = append(words, s[start:])
+	}
+	return words
+}
+
+// tokens computes a shortest edit script between a and b with Myers'
+// algorithm. Splitting at the middle snake keeps memory linear in the number
+// of tokens, so long posts cannot make a diff allocate a quadratic table.
+func tokens(a, b []string) []Chunk {
+	edits := compare(nil, a, b)
+
+	// within a changed run, deletions go before insertions
+	ordered := make([]Chunk, 0, len(edits))
+	var inserted []Chunk
+	for _, e := range edits {
+		if e.Text == "" {
+			continue
+		}
+		switch e.Op {
+		case Insert:
+			inserted = append(inserted, e)
+			continue
+		case Equal:
+			ordered = append(ordered, inserted...)
+			inserted = inserted[:0]
+		}
+		ordered = append(ordered, e)
+	}
+	ordered = append(ordered, inserted...)
+
+	return merge(ordered)
+}
+
+// compare appends to edits one chunk per token of a shortest edit script
+// turning a into b.
+func compare(edits []Chunk, a, b []string) []Chunk {
+	// common prefix and suffix are kept as they are
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	for _, t := range a[:prefix] {
+		edits = append(edits, Chunk{Op: Equal, Text: t})
+	}
+
+	x, y := a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]
+	switch {
+	case len(x) == 0:
+		for _, t := range y {
+			edits = append(edits, Chunk{Op: Insert, Text: t})
+		}
+	case len(y) == 0:
+		for _, t := range x {
+			edits = append(edits, Chunk{Op: Delete, Text: t})
+		}
+	default:
+		if i, j, ok := middleSnake(x, y); ok {
+			edits = compare(edits, x[:i], y[:j])
+			edits = compare(edits, x[i:], y[j:])
+		} else {
+			for _, t := range x {
+				edits = append(edits, Chunk{Op: Delete, Text: t})
+			}
+			for _, t := range y {
+				edits = append(edits, Chunk{Op: Insert, Text: t})
+			}
+		}
+	}
+
+	for _, t := range a[len(a)-suffix:] {
+		edits = append(edits, Chunk{Op: Equal, Text: t})
+	}
+
+	return edits
+}
+
+// middleSnake runs the forward and the reverse search for a shortest edit
+// script of the non-empty a and b at the same time and returns the point
+// where they meet, which splits the script in two. ok is false when a and b
+// have nothing in common.
+func middleSnake(a, b []string) (i, j int, ok bool) {
+	n, m := len(a), len(b)
+	maxD := (n + m + 1) / 2
+	offset := maxD
+	// forward[offset+k] is the furthest x reached on diagonal k = x-y from
+	// the start, backward[offset+k] the same from the end
+	forward := make([]int, 2*maxD+2)
+	backward := make([]int, 2*maxD+2)
+	for k := range forward {
+		forward[k] = -1
+		backward[k] = -1
+	}
+	forward[offset+1] = 0
+	backward[offset+1] = 0
+
+	delta := n - m
+	// with an odd delta the searches meet while going forward
+	odd := delta%2 != 0
+	// diagonals that ran off the edges are skipped
+	fStart, fEnd, bStart, bEnd := 0, 0, 0, 0
+	for d := 0; d < maxD; d++ {
+		for k := -d + fStart; k <= d-fEnd; k += 2 {
+			ko := offset + k
+			var x int
+			if k == -d || k != d && forward[ko-1] < forward[ko+1] {
+				x = forward[ko+1]
+			} else {
+				x = forward[ko-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			forward[ko] = x
+			switch {
+			case x > n:
+				fEnd += 2
+			case y > m:
+				fStart += 2
+			case odd:
+				bo := offset + delta - k
+				if bo >= 0 && bo < len(backward) && backward[bo] != -1 && x >= n-backward[bo] {
+					return x, y, true
+				}
+			}
+		}
+
+		for k := -d + bStart; k <= d-bEnd; k += 2 {
+			ko := offset + k
+			var x int
+			if k == -d || k != d && backward[ko-1] < backward[ko+1] {
+				x = backward[ko+1]
+			} else {
+				x = backward[ko-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[n-x-1] == b[m-y-1] {
+				x++
+				y++
+			}
+			backward[ko] = x
+			switch {
+			case x > n:
+				bEnd += 2
+			case y > m:
+				bStart += 2
+			case !odd:
+				fo := offset + delta - k
+				if fo >= 0 && fo < len(forward) && forward[fo] != -1 {
+					fx := forward[fo]
+					if fx >= n-x {
+						return fx, offset + fx - fo, true
+					}
+				}
+			}
+		}
+	}
+
+	return 0, 0, false
+}
+
+// merge joins runs of edits with the same operation into one chunk.
+func merge(edits []Chunk) []Chunk {
+	var chunks []Chunk
+	var text strings.Builder
+	for i, e := range edits {
+		text.WriteString(e.Text)
+		if i+1 < len(edits) && edits[i+1].Op == e.Op {
+			continue
+		}
+		chunks = append(chunks, Chunk{Op: e.Op, Text: text.String()})
+		text.Reset()
+	}
+	return chunks
+}
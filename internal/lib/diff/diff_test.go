@@ -0,0 +1,137 @@
+package diff
+
+import (
+	"math/rand/v2"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// rebuild returns the old and new texts described by chunks.
+func rebuild(chunks []Chunk) (string, string) {
+	var a, b strings.Builder
+	for _, c := range chunks {
+		if c.Op != Insert {
+			a.WriteString(c.Text)
+		}
+		if c.Op != Delete {
+			b.WriteString(c.Text)
+		}
+	}
+	return a.String(), b.String()
+}
+
+func TestLines(t *testing.T) {
+	a := "first\nsecond\nthird\n"
+	b := "first\nchanged\nthird\nfourth\n"
+
+	chunks := Lines(a, b)
+
+	assert.Equal(t, []Chunk{
+		{Op: Equal, Text: "first\n"},
+		{Op: Delete, Text: "second\n"},
+		{Op: Insert, Text: "changed\n"},
+		{Op: Equal, Text: "third\n"},
+		{Op: Insert, Text: "fourth\n"},
+	}, chunks)
+}
+
+func TestWords(t *testing.T) {
+	chunks := Words("Приём документов начнётся 20 июня", "Приём документов начнётся 1 июля")
+
+	assert.Equal(t, []Chunk{
+		{Op: Equal, Text: "Приём документов начнётся "},
+		{Op: Delete, Text: "20"},
+		{Op: Insert, Text: "1"},
+		{Op: Equal, Text: " "},
+		{Op: Delete, Text: "июня"},
+		{Op: Insert, Text: "июля"},
+	}, chunks)
+}
+
+func TestRoundTrip(t *testing.T) {
+	for _, tt := range []struct{ a, b string }{
+		{"", ""},
+		{"", "new text"},
+		{"old text", ""},
+		{"same", "same"},
+		{"a b c d e", "a x c y e f"},
+		{"  leading and trailing  ", "leading  and trailing"},
+		{"line 1\nline 2", "line 0\nline 1\nline 2\n"},
+	} {
+		for _, f := range []func(string, string) []Chunk{Lines, Words} {
+			a, b := rebuild(f(tt.a, tt.b))
+			assert.Equal(t, tt.a, a)
+			assert.Equal(t, tt.b, b)
+		}
+	}
+}
+
+func TestIdenticalTextsAreOneChunk(t *testing.T) {
+	assert.Equal(t, []Chunk{{Op: Equal, Text: "a b\nc"}}, Words("a b\nc", "a b\nc"))
+	assert.Nil(t, Lines("", ""))
+}
+
+// lcsLength is the quadratic reference for the length of the longest common
+// subsequence of a and b.
+func lcsLength(a, b []string) int {
+	prev := make([]int, len(b)+1)
+	for i := range a {
+		cur := make([]int, len(b)+1)
+		for j := range b {
+			if a[i] == b[j] {
+				cur[j+1] = prev[j] + 1
+			} else {
+				cur[j+1] = max(prev[j+1], cur[j])
+			}
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
+
+func TestShortestEditScript(t *testing.T) {
+	rnd := rand.New(rand.NewPCG(1, 2))
+	randomTokens := func() []string {
+		tokens := make([]string, rnd.IntN(12))
+		for i := range tokens {
+			tokens[i] = string(rune('a' + rnd.IntN(4)))
+		}
+		return tokens
+	}
+
+	for range 1000 {
+		a, b := randomTokens(), randomTokens()
+		chunks := tokens(a, b)
+
+		oldText, newText := rebuild(chunks)
+		assert.Equal(t, strings.Join(a, ""), oldText)
+		assert.Equal(t, strings.Join(b, ""), newText)
+
+		kept := 0
+		for _, c := range chunks {
+			if c.Op == Equal {
+				kept += len(c.Text)
+			}
+		}
+		assert.Equal(t, lcsLength(a, b), kept, "%q -> %q", a, b)
+	}
+}
+
+func TestLongTexts(t *testing.T) {
+	words := make([]string, 200000)
+	for i := range words {
+		words[i] = "слово "
+	}
+	a := strings.Join(words, "")
+	words[100000] = "правка "
+	b := strings.Join(words, "")
+
+	assert.Equal(t, []Chunk{
+		{Op: Equal, Text: a[:len("слово ")*100000]},
+		{Op: Delete, Text: "слово"},
+		{Op: Insert, Text: "правка"},
+		{Op: Equal, Text: a[len("слово ")*100000+len("слово"):]},
+	}, Words(a, b))
+}
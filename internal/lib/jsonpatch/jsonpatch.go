@@ -0,0 +1,254 @@
+// Package jsonpatch implements the subset of JSON Patch (RFC 6902) that
+// makes sense for a post: add, remove, replace and test operations on its
+// title, content, slug, section and tags.
+package jsonpatch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/RomanKovalev007/mai_news/internal/lib/slug"
+	"github.com/RomanKovalev007/mai_news/internal/lib/tags"
+	"github.com/RomanKovalev007/mai_news/internal/models"
+)
+
+type Op string
+
+const (
+	Add     Op = "add"
+	Remove  Op = "remove"
+	Replace Op = "replace"
+	Test    Op = "test"
+)
+
+// ErrTestFailed is returned by Apply when a test operation does not hold.
+var ErrTestFailed = errors.New("json patch test operation failed")
+
+// Operation is a decoded patch operation. Field is the post field the path
+// points at, or "tag" for a single tag, whose position Index holds.
+type Operation struct {
+	Op    Op
+	Field string
+	Index int
+	// Value is the value of operations on fields other than tags, and of
+	// operations on a single tag.
+	Value string
+	// Tags is the value of operations on all the tags.
+	Tags []string
+}
+
+// End is the Index of "/tags/-", the position past the last tag.
+const End = -1
+
+// OpError describes an operation that cannot be applied to a post.
+type OpError struct {
+	Index  int
+	Reason string
+}
+
+func (e *OpError) Error() string {
+	return fmt.Sprintf("operation %d: %s", e.Index, e.Reason)
+}
+
+// fields maps the supported JSON Pointers of whole fields to post fields.
+var fields = map[string]string{
+	"/title":   "title",
+	"/content": "content",
+	"/slug":    "slug",
+	"/section": "section",
+	"/tags":    "tags",
+}
+
+// supportedPaths lists the paths Decode accepts, for errors.
+const supportedPaths = "/title, /content, /slug, /section, /tags, /tags/- and /tags/{index}"
+
+// parsePath returns the field and the tag index the path points at.
+func parsePath(path string) (field string, index int, ok bool) {
+	if field, ok := fields[path]; ok {
+		return field, 0, true
+	}
+	rest, ok := strings.CutPrefix(path, "/tags/")
+	if !ok {
+		return "", 0, false
+	}
+	if rest == "-" {
+		return "tag", End, true
+	}
+	// array indexes have no sign and no leading zeros
+	index, err := strconv.Atoi(rest)
+	if err != nil || index < 0 || rest != strconv.Itoa(index) {
+		return "", 0, false
+	}
+	return "tag", index, true
+}
+
+// Decode reads a JSON Patch document. Syntax errors are returned as is,
+// operations that cannot apply to a post as *OpError. Slugs and sections
+// written must be valid slugs, and tags are normalized.
+func Decode(r io.Reader) ([]Operation, error) {
+	var doc []struct {
+		Op    Op              `json:"op"`
+		Path  string          `json:"path"`
+		Value json.RawMessage `json:"value"`
+	}
+	if err := json.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, err
+	}
+
+	ops := make([]Operation, 0, len(doc))
+	for i, d := range doc {
+		field, index, ok := parsePath(d.Path)
+		switch {
+		case d.Op != Add && d.Op != Remove && d.Op != Replace && d.Op != Test:
+			return nil, &OpError{Index: i, Reason: fmt.Sprintf("unsupported op %q", d.Op)}
+		case !ok:
+			return nil, &OpError{Index: i, Reason: fmt.Sprintf("unsupported path %q, supported paths are %s", d.Path, supportedPaths)}
+		case d.Op == Remove && field != "tags" && field != "tag":
+			return nil, &OpError{Index: i, Reason: fmt.Sprintf("%s is required and cannot be removed", field)}
+		case index == End && d.Op != Add:
+			return nil, &OpError{Index: i, Reason: `"/tags/-" is only for add`}
+		}
+
+		op := Operation{Op: d.Op, Field: field, Index: index}
+		if d.Op != Remove {
+			if err := decodeValue(&op, d.Value); err != nil {
+				return nil, &OpError{Index: i, Reason: err.Error()}
+			}
+		}
+		ops = append(ops, op)
+	}
+
+	return ops, nil
+}
+
+// decodeValue reads the value of op, which is a list of tags for all the
+// tags and a string otherwise.
+func decodeValue(op *Operation, raw json.RawMessage) error {
+	if op.Field == "tags" {
+		if raw == nil || string(raw) == "null" || json.Unmarshal(raw, &op.Tags) != nil {
+			return errors.New("value of tags must be an array of strings")
+		}
+		names, err := tags.NormalizeAll(op.Tags)
+		if err != nil {
+			return errors.New("invalid tag")
+		}
+		op.Tags = names
+		return nil
+	}
+
+	if raw == nil || string(raw) == "null" || json.Unmarshal(raw, &op.Value) != nil {
+		return fmt.Errorf("value of %s must be a string", op.Field)
+	}
+	switch op.Field {
+	case "tag":
+		name, err := tags.Normalize(op.Value)
+		if err != nil {
+			return errors.New("invalid tag")
+		}
+		op.Value = name
+	case "slug", "section":
+		if op.Op != Test && !slug.Valid(op.Value) {
+			return fmt.Errorf("invalid %s", op.Field)
+		}
+	}
+	return nil
+}
+
+// Apply runs ops in order against post. Indexes into the tags are taken
+// against the tags as they stand after the operations before, sorted by
+// name, as the stores keep them. The returned patch sets the fields the
+// operations wrote. A tag index past the tags fails with *OpError.
+func Apply(ops []Operation, post models.OutputPost) (models.PostPatch, error) {
+	values := map[string]*string{"title": &post.Title, "content": &post.Content, "slug": &post.Slug, "section": &post.Section}
+	names := slices.Clone(post.Tags)
+	written := map[string]bool{}
+
+	for i, op := range ops {
+		switch op.Field {
+		case "tags":
+			if op.Op == Test {
+				if !slices.Equal(names, op.Tags) {
+					return models.PostPatch{}, ErrTestFailed
+				}
+				continue
+			}
+			names = nil
+			if op.Op != Remove {
+				names = slices.Clone(op.Tags)
+			}
+			written["tags"] = true
+
+		case "tag":
+			index := op.Index
+			if index == End {
+				index = len(names)
+			}
+			if index > len(names) || index == len(names) && op.Op != Add {
+				return models.PostPatch{}, &OpError{Index: i, Reason: fmt.Sprintf("no tag at index %d", op.Index)}
+			}
+			switch op.Op {
+			case Test:
+				if names[index] != op.Value {
+					return models.PostPatch{}, ErrTestFailed
+				}
+				continue
+			case Add:
+				names = slices.Insert(names, index, op.Value)
+			case Remove:
+				names = slices.Delete(names, index, index+1)
+			case Replace:
+				names[index] = op.Value
+			}
+			names = tags.Set(names)
+			written["tags"] = true
+
+		default:
+			if op.Op == Test {
+				if *values[op.Field] != op.Value {
+					return models.PostPatch{}, ErrTestFailed
+				}
+				continue
+			}
+			*values[op.Field] = op.Value
+			written[op.Field] = true
+		}
+	}
+
+	var patch models.PostPatch
+	if written["title"] {
+		patch.Title = &post.Title
+	}
+	if written["content"] {
+		patch.Content = &post.Content
+	}
+	if written["slug"] {
+		patch.Slug = &post.Slug
+	}
+	if written["section"] {
+		patch.Section = &post.Section
+	}
+	if written["tags"] {
+		if len(names) == 0 {
+			names = nil
+		}
+		patch.Tags = &names
+	}
+	return patch, nil
+}
+
+// Section returns the section ops move the post to, if they do.
+func Section(ops []Operation) (string, bool) {
+	var section string
+	var ok bool
+	for _, op := range ops {
+		if op.Field == "section" && op.Op != Test {
+			section, ok = op.Value, true
+		}
+	}
+	return section, ok
+}
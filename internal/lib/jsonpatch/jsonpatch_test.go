@@ -0,0 +1,151 @@
+package jsonpatch
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/RomanKovalev007/mai_news/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecode(t *testing.T) {
+	ops, err := Decode(strings.NewReader(`[
+		{"op": "test", "path": "/title", "value": "Old"},
+		{"op": "replace", "path": "/title", "value": "New"},
+		{"op": "add", "path": "/content", "value": "Text"},
+		{"op": "replace", "path": "/slug", "value": "new-title"},
+		{"op": "replace", "path": "/section", "value": "it"},
+		{"op": "add", "path": "/tags/-", "value": " Sports "},
+		{"op": "remove", "path": "/tags/1"},
+		{"op": "replace", "path": "/tags", "value": ["B", "a", "b"]},
+		{"op": "remove", "path": "/tags"}
+	]`))
+	require.NoError(t, err)
+	assert.Equal(t, []Operation{
+		{Op: Test, Field: "title", Value: "Old"},
+		{Op: Replace, Field: "title", Value: "New"},
+		{Op: Add, Field: "content", Value: "Text"},
+		{Op: Replace, Field: "slug", Value: "new-title"},
+		{Op: Replace, Field: "section", Value: "it"},
+		{Op: Add, Field: "tag", Index: End, Value: "sports"},
+		{Op: Remove, Field: "tag", Index: 1},
+		{Op: Replace, Field: "tags", Tags: []string{"a", "b"}},
+		{Op: Remove, Field: "tags"},
+	}, ops)
+
+	tests := []struct {
+		doc    string
+		reason string
+	}{
+		{`[{"op": "move", "from": "/title", "path": "/content"}]`, `operation 0: unsupported op "move"`},
+		{`[{"op": "test", "path": "/title", "value": "a"}, {"op": "replace", "path": "/id", "value": 2}]`, `operation 1: unsupported path "/id", supported paths are /title, /content, /slug, /section, /tags, /tags/- and /tags/{index}`},
+		{`[{"op": "remove", "path": "/tags/01"}]`, `operation 0: unsupported path "/tags/01", supported paths are /title, /content, /slug, /section, /tags, /tags/- and /tags/{index}`},
+		{`[{"op": "remove", "path": "/content"}]`, `operation 0: content is required and cannot be removed`},
+		{`[{"op": "remove", "path": "/section"}]`, `operation 0: section is required and cannot be removed`},
+		{`[{"op": "replace", "path": "/tags/-", "value": "a"}]`, `operation 0: "/tags/-" is only for add`},
+		{`[{"op": "replace", "path": "/slug", "value": "New Title"}]`, `operation 0: invalid slug`},
+		{`[{"op": "add", "path": "/tags/0", "value": "a/b"}]`, `operation 0: invalid tag`},
+		{`[{"op": "add", "path": "/tags", "value": "a"}]`, `operation 0: value of tags must be an array of strings`},
+		{`[{"op": "replace", "path": "/title"}]`, `operation 0: value of title must be a string`},
+		{`[{"op": "replace", "path": "/title", "value": null}]`, `operation 0: value of title must be a string`},
+	}
+	for _, tt := range tests {
+		_, err := Decode(strings.NewReader(tt.doc))
+		var opErr *OpError
+		require.True(t, errors.As(err, &opErr), tt.doc)
+		assert.Equal(t, tt.reason, opErr.Error())
+	}
+
+	_, err = Decode(strings.NewReader(`{"op": "test"}`))
+	assert.Error(t, err)
+}
+
+func TestApply(t *testing.T) {
+	post := models.OutputPost{Title: "Old", Content: "Text", Slug: "old", Section: "general", Tags: []string{"news", "sports"}}
+
+	patch, err := Apply([]Operation{
+		{Op: Test, Field: "title", Value: "Old"},
+		{Op: Replace, Field: "title", Value: "New"},
+		// tests see the result of the operations before them
+		{Op: Test, Field: "title", Value: "New"},
+	}, post)
+	require.NoError(t, err)
+	title := "New"
+	assert.Equal(t, models.PostPatch{Title: &title}, patch)
+
+	patch, err = Apply([]Operation{{Op: Test, Field: "content", Value: "Text"}}, post)
+	require.NoError(t, err)
+	assert.Equal(t, models.PostPatch{}, patch)
+
+	_, err = Apply([]Operation{
+		{Op: Replace, Field: "content", Value: "New"},
+		{Op: Test, Field: "content", Value: "Text"},
+	}, post)
+	assert.ErrorIs(t, err, ErrTestFailed)
+
+	patch, err = Apply([]Operation{
+		{Op: Test, Field: "section", Value: "general"},
+		{Op: Replace, Field: "slug", Value: "new"},
+		{Op: Replace, Field: "section", Value: "it"},
+	}, post)
+	require.NoError(t, err)
+	slug, section := "new", "it"
+	assert.Equal(t, models.PostPatch{Slug: &slug, Section: &section}, patch)
+}
+
+func TestApplyTags(t *testing.T) {
+	post := models.OutputPost{Title: "Old", Tags: []string{"news", "sports"}}
+
+	tests := []struct {
+		name string
+		ops  []Operation
+		tags []string
+	}{
+		{"append", []Operation{{Op: Add, Field: "tag", Index: End, Value: "admissions"}}, []string{"admissions", "news", "sports"}},
+		{"insert", []Operation{{Op: Add, Field: "tag", Index: 2, Value: "exams"}}, []string{"exams", "news", "sports"}},
+		{"remove one", []Operation{{Op: Remove, Field: "tag", Index: 0}}, []string{"sports"}},
+		{"replace one", []Operation{{Op: Replace, Field: "tag", Index: 1, Value: "sport"}}, []string{"news", "sport"}},
+		{"add a duplicate", []Operation{{Op: Add, Field: "tag", Index: End, Value: "news"}}, []string{"news", "sports"}},
+		{"replace all", []Operation{{Op: Replace, Field: "tags", Tags: []string{"exams"}}}, []string{"exams"}},
+		{"remove all", []Operation{{Op: Remove, Field: "tags"}}, nil},
+		{"remove the last", []Operation{{Op: Remove, Field: "tag", Index: 1}, {Op: Remove, Field: "tag", Index: 0}}, nil},
+		// indexes point into the tags sorted after the operations before
+		{"indexes after add", []Operation{
+			{Op: Add, Field: "tag", Index: End, Value: "admissions"},
+			{Op: Test, Field: "tag", Index: 0, Value: "admissions"},
+			{Op: Remove, Field: "tag", Index: 2},
+		}, []string{"admissions", "news"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			patch, err := Apply(tt.ops, post)
+			require.NoError(t, err)
+			require.NotNil(t, patch.Tags)
+			assert.Equal(t, tt.tags, *patch.Tags)
+		})
+	}
+	assert.Equal(t, []string{"news", "sports"}, post.Tags)
+
+	patch, err := Apply([]Operation{{Op: Test, Field: "tags", Tags: []string{"news", "sports"}}}, post)
+	require.NoError(t, err)
+	assert.Nil(t, patch.Tags)
+	_, err = Apply([]Operation{{Op: Test, Field: "tag", Index: 0, Value: "sports"}}, post)
+	assert.ErrorIs(t, err, ErrTestFailed)
+
+	var opErr *OpError
+	_, err = Apply([]Operation{{Op: Remove, Field: "tag", Index: 2}}, post)
+	require.ErrorAs(t, err, &opErr)
+	assert.Equal(t, "operation 0: no tag at index 2", opErr.Error())
+	_, err = Apply([]Operation{{Op: Add, Field: "tag", Index: 3, Value: "exams"}}, post)
+	assert.ErrorAs(t, err, &opErr)
+}
+
+func TestSection(t *testing.T) {
+	_, ok := Section([]Operation{{Op: Test, Field: "section", Value: "it"}, {Op: Replace, Field: "title", Value: "New"}})
+	assert.False(t, ok)
+	section, ok := Section([]Operation{{Op: Replace, Field: "section", Value: "it"}, {Op: Add, Field: "section", Value: "sport"}})
+	assert.True(t, ok)
+	assert.Equal(t, "sport", section)
+}
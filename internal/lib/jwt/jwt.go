@@ -0,0 +1,178 @@
+// Package jwt signs and verifies compact JSON Web Tokens (RFC 7519) with
+// HS256 or EdDSA (Ed25519). It supports the few registered claims the API
+// uses and nothing else; a token must be signed with exactly the algorithm
+// it is verified with, so "none" and algorithm confusion are rejected.
+package jwt
+
+import (
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var (
+	ErrMalformed = errors.New("malformed token")
+	ErrAlgorithm = errors.New("unexpected signing algorithm")
+	ErrSignature = errors.New("invalid signature")
+	ErrExpired   = errors.New("token has expired")
+)
+
+// MinSecretLen is the shortest secret HS256 accepts, the size of the
+// hash as RFC 7518 requires.
+const MinSecretLen = 32
+
+var encoding = base64.RawURLEncoding
+
+// Claims are the claims of a token. Times are Unix seconds.
+type Claims struct {
+	Subject string `json:"sub"`
+	ID      string `json:"jti"`
+	// Type tells access tokens from refresh tokens.
+	Type      string `json:"typ,omitempty"`
+	IssuedAt  int64  `json:"iat"`
+	ExpiresAt int64  `json:"exp"`
+}
+
+// Method signs and verifies tokens with one algorithm.
+type Method interface {
+	// Alg is the name of the algorithm in the token header.
+	Alg() string
+	sign(input []byte) []byte
+	verify(input, sig []byte) bool
+}
+
+type hs256 struct {
+	secret []byte
+}
+
+// HS256 returns HMAC-SHA256 with secret, which must be at least
+// MinSecretLen bytes long.
+func HS256(secret []byte) (Method, error) {
+	if len(secret) < MinSecretLen {
+		return nil, fmt.Errorf("HS256 secret must be at least %d bytes", MinSecretLen)
+	}
+	return hs256{secret: secret}, nil
+}
+
+func (hs256) Alg() string { return "HS256" }
+
+func (m hs256) sign(input []byte) []byte {
+	mac := hmac.New(sha256.New, m.secret)
+	mac.Write(input)
+	return mac.Sum(nil)
+}
+
+func (m hs256) verify(input, sig []byte) bool {
+	return hmac.Equal(m.sign(input), sig)
+}
+
+type eddsa struct {
+	key ed25519.PrivateKey
+}
+
+// EdDSA returns Ed25519 signatures made with key.
+func EdDSA(key ed25519.PrivateKey) (Method, error) {
+	if len(key) != ed25519.PrivateKeySize {
+		return nil, errors.New("invalid Ed25519 private key")
+	}
+	return eddsa{key: key}, nil
+}
+
+func (eddsa) Alg() string { return "EdDSA" }
+
+func (m eddsa) sign(input []byte) []byte {
+	return ed25519.Sign(m.key, input)
+}
+
+func (m eddsa) verify(input, sig []byte) bool {
+	return ed25519.Verify(m.key.Public().(ed25519.PublicKey), input, sig)
+}
+
+// ParseEd25519Key reads an Ed25519 private key from a PEM "PRIVATE KEY"
+// block in PKCS #8, as written by `openssl genpkey -algorithm ed25519`.
+func ParseEd25519Key(data []byte) (ed25519.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != "PRIVATE KEY" {
+		return nil, errors.New("no PEM private key found")
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	edKey, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, errors.New("private key is not an Ed25519 key")
+	}
+	return edKey, nil
+}
+
+type header struct {
+	Alg string `json:"alg"`
+	Typ string `json:"typ,omitempty"`
+}
+
+// Sign returns the compact serialization of claims signed with m.
+func Sign(m Method, claims Claims) (string, error) {
+	h, err := json.Marshal(header{Alg: m.Alg(), Typ: "JWT"})
+	if err != nil {
+		return "", err
+	}
+	c, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	input := encoding.EncodeToString(h) + "." + encoding.EncodeToString(c)
+	return input + "." + encoding.EncodeToString(m.sign([]byte(input))), nil
+}
+
+// Parse verifies token with m and returns its claims if it has not expired
+// at now.
+func Parse(m Method, token string, now time.Time) (Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return Claims{}, ErrMalformed
+	}
+
+	var h header
+	if err := decode(parts[0], &h); err != nil {
+		return Claims{}, err
+	}
+	if h.Alg != m.Alg() {
+		return Claims{}, ErrAlgorithm
+	}
+	sig, err := encoding.DecodeString(parts[2])
+	if err != nil {
+		return Claims{}, ErrMalformed
+	}
+	if !m.verify([]byte(parts[0]+"."+parts[1]), sig) {
+		return Claims{}, ErrSignature
+	}
+
+	var claims Claims
+	if err := decode(parts[1], &claims); err != nil {
+		return Claims{}, err
+	}
+	if claims.ExpiresAt == 0 || now.Unix() >= claims.ExpiresAt {
+		return Claims{}, ErrExpired
+	}
+	return claims, nil
+}
+
+func decode(part string, v any) error {
+	data, err := encoding.DecodeString(part)
+	if err != nil {
+		return ErrMalformed
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return ErrMalformed
+	}
+	return nil
+}
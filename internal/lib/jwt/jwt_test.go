@@ -0,0 +1,97 @@
+package jwt
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testSecret = []byte("0123456789abcdef0123456789abcdef")
+
+func TestSignParse(t *testing.T) {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	edMethod, err := EdDSA(key)
+	require.NoError(t, err)
+	hsMethod, err := HS256(testSecret)
+	require.NoError(t, err)
+
+	now := time.Unix(1_750_000_000, 0)
+	claims := Claims{Subject: "3", ID: "abc", Type: "access", IssuedAt: now.Unix(), ExpiresAt: now.Add(time.Minute).Unix()}
+
+	for _, m := range []Method{hsMethod, edMethod} {
+		t.Run(m.Alg(), func(t *testing.T) {
+			token, err := Sign(m, claims)
+			require.NoError(t, err)
+			assert.Equal(t, 2, strings.Count(token, "."))
+
+			got, err := Parse(m, token, now)
+			require.NoError(t, err)
+			assert.Equal(t, claims, got)
+
+			_, err = Parse(m, token, now.Add(time.Minute))
+			assert.ErrorIs(t, err, ErrExpired)
+
+			// a changed payload breaks the signature
+			parts := strings.Split(token, ".")
+			forged, err := Sign(m, Claims{Subject: "1", ID: "abc", ExpiresAt: claims.ExpiresAt})
+			require.NoError(t, err)
+			parts[1] = strings.Split(forged, ".")[1]
+			_, err = Parse(m, strings.Join(parts, "."), now)
+			assert.ErrorIs(t, err, ErrSignature)
+		})
+	}
+
+	// tokens of one algorithm are not accepted by the other
+	token, err := Sign(hsMethod, claims)
+	require.NoError(t, err)
+	_, err = Parse(edMethod, token, now)
+	assert.ErrorIs(t, err, ErrAlgorithm)
+}
+
+func TestParseRejects(t *testing.T) {
+	m, err := HS256(testSecret)
+	require.NoError(t, err)
+	now := time.Unix(1_750_000_000, 0)
+
+	unsigned := encoding.EncodeToString([]byte(`{"alg":"none"}`)) + "." +
+		encoding.EncodeToString([]byte(`{"sub":"1","exp":1760000000}`)) + "."
+	_, err = Parse(m, unsigned, now)
+	assert.ErrorIs(t, err, ErrAlgorithm)
+
+	noExpiry, err := Sign(m, Claims{Subject: "1"})
+	require.NoError(t, err)
+	_, err = Parse(m, noExpiry, now)
+	assert.ErrorIs(t, err, ErrExpired)
+
+	for _, token := range []string{"", "a.b", "a.b.c.d", "!.b.c"} {
+		_, err = Parse(m, token, now)
+		assert.ErrorIs(t, err, ErrMalformed, token)
+	}
+}
+
+func TestHS256ShortSecret(t *testing.T) {
+	_, err := HS256([]byte("short"))
+	assert.Error(t, err)
+}
+
+func TestParseEd25519Key(t *testing.T) {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+
+	got, err := ParseEd25519Key(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+	require.NoError(t, err)
+	assert.True(t, key.Equal(got))
+
+	_, err = ParseEd25519Key([]byte("not a key"))
+	assert.Error(t, err)
+}
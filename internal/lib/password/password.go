@@ -0,0 +1,88 @@
+// Package password hashes user passwords with PBKDF2-HMAC-SHA256. A hash is
+// stored as "pbkdf2-sha256$<iterations>$<salt>$<key>" with the salt and key
+// in unpadded base64, so the cost can be raised without invalidating the
+// hashes already stored.
+package password
+
+import (
+	"crypto/hmac"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// MinLen and MaxLen bound the length of passwords in characters.
+	MinLen = 8
+	MaxLen = 128
+
+	// Iterations follows the OWASP recommendation for PBKDF2-HMAC-SHA256.
+	Iterations = 600_000
+
+	scheme  = "pbkdf2-sha256"
+	saltLen = 16
+	keyLen  = 32
+)
+
+// ErrInvalid is returned by Check for passwords of the wrong length.
+var ErrInvalid = errors.New("invalid password")
+
+var encoding = base64.RawStdEncoding
+
+// Check reports passwords too short or too long to be accepted.
+func Check(password string) error {
+	if n := utf8.RuneCountInString(password); n < MinLen || n > MaxLen {
+		return ErrInvalid
+	}
+	return nil
+}
+
+// Hash returns the encoded hash of password with a fresh random salt.
+func Hash(password string) (string, error) {
+	return hash(password, Iterations)
+}
+
+func hash(password string, iterations int) (string, error) {
+	salt := make([]byte, saltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("read salt: %w", err)
+	}
+	key, err := pbkdf2.Key(sha256.New, password, salt, iterations, keyLen)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s$%d$%s$%s", scheme, iterations, encoding.EncodeToString(salt), encoding.EncodeToString(key)), nil
+}
+
+// Verify reports whether password matches the encoded hash. Malformed
+// hashes match nothing.
+func Verify(encoded, password string) bool {
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 4 || parts[0] != scheme {
+		return false
+	}
+	iterations, err := strconv.Atoi(parts[1])
+	if err != nil || iterations <= 0 {
+		return false
+	}
+	salt, err := encoding.DecodeString(parts[2])
+	if err != nil {
+		return false
+	}
+	want, err := encoding.DecodeString(parts[3])
+	if err != nil || len(want) == 0 {
+		return false
+	}
+
+	got, err := pbkdf2.Key(sha256.New, password, salt, iterations, len(want))
+	if err != nil {
+		return false
+	}
+	return hmac.Equal(got, want)
+}
@@ -0,0 +1,54 @@
+package password
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHashVerify(t *testing.T) {
+	encoded, err := hash("correct horse", 1000)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(encoded, "pbkdf2-sha256$1000$"))
+
+	assert.True(t, Verify(encoded, "correct horse"))
+	assert.False(t, Verify(encoded, "correct horse "))
+	assert.False(t, Verify(encoded, ""))
+
+	// every hash gets its own salt
+	other, err := hash("correct horse", 1000)
+	require.NoError(t, err)
+	assert.NotEqual(t, encoded, other)
+	assert.True(t, Verify(other, "correct horse"))
+}
+
+func TestHashUsesIterations(t *testing.T) {
+	encoded, err := Hash("correct horse")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(encoded, "pbkdf2-sha256$600000$"))
+	assert.True(t, Verify(encoded, "correct horse"))
+}
+
+func TestVerifyMalformed(t *testing.T) {
+	for _, encoded := range []string{
+		"",
+		"plain",
+		"bcrypt$1000$c2FsdA$a2V5",
+		"pbkdf2-sha256$x$c2FsdA$a2V5",
+		"pbkdf2-sha256$0$c2FsdA$a2V5",
+		"pbkdf2-sha256$1000$!!$a2V5",
+		"pbkdf2-sha256$1000$c2FsdA$",
+		"pbkdf2-sha256$1000$c2FsdA$a2V5$extra",
+	} {
+		assert.False(t, Verify(encoded, "password"), encoded)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	assert.NoError(t, Check("12345678"))
+	assert.NoError(t, Check("пароль12"))
+	assert.ErrorIs(t, Check("1234567"), ErrInvalid)
+	assert.ErrorIs(t, Check(strings.Repeat("a", MaxLen+1)), ErrInvalid)
+}
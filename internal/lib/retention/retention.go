@@ -0,0 +1,34 @@
+package retention
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// TrashPurger permanently removes posts trashed before the given time.
+type TrashPurger interface {
+	PurgeTrash(ctx context.Context, deletedBefore time.Time) (int, error)
+}
+
+// PurgeTrash removes posts that have been in the trash longer than
+// retention, once immediately and then every interval until ctx is done.
+func PurgeTrash(ctx context.Context, log *slog.Logger, purger TrashPurger, retention, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		n, err := purger.PurgeTrash(ctx, time.Now().Add(-retention))
+		if err != nil && ctx.Err() == nil {
+			log.Error("failed to purge trash", slog.String("error", err.Error()))
+		} else if n > 0 {
+			log.Info("purged trash", slog.Int("posts", n))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
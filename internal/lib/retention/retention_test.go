@@ -0,0 +1,50 @@
+package retention
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/RomanKovalev007/mai_news/internal/lib/logger/slogdiscard"
+	"github.com/stretchr/testify/assert"
+)
+
+type purgerFunc func(ctx context.Context, deletedBefore time.Time) (int, error)
+
+func (f purgerFunc) PurgeTrash(ctx context.Context, deletedBefore time.Time) (int, error) {
+	return f(ctx, deletedBefore)
+}
+
+func TestPurgeTrash(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	var mu sync.Mutex
+	var calls []time.Time
+	purger := purgerFunc(func(_ context.Context, deletedBefore time.Time) (int, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		calls = append(calls, deletedBefore)
+		if len(calls) == 2 {
+			cancel()
+		}
+		return 1, nil
+	})
+
+	done := make(chan struct{})
+	go func() {
+		PurgeTrash(ctx, slogdiscard.NewDiscardLogger(), purger, 24*time.Hour, time.Millisecond)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("PurgeTrash did not stop after the context was canceled")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Len(t, calls, 2)
+	assert.WithinDuration(t, time.Now().Add(-24*time.Hour), calls[0], time.Second)
+}
@@ -0,0 +1,51 @@
+package search
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Terms splits a user search query into lower-cased words made of letters
+// and digits. Everything else, including query syntax of the underlying
+// engines, is dropped, so the terms are safe to embed into FTS5 and tsquery
+// expressions. Ё is folded into е as Russian texts use them interchangeably.
+func Terms(query string) []string {
+	words := strings.FieldsFunc(query, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	terms := make([]string, 0, len(words))
+	for _, w := range words {
+		terms = append(terms, Fold(w))
+	}
+	return terms
+}
+
+// Fold lower-cases s and replaces ё with е.
+func Fold(s string) string {
+	return strings.ReplaceAll(strings.ToLower(s), "ё", "е")
+}
+
+// maxYoVariants caps how many е of a term Spellings tries as ё.
+const maxYoVariants = 3
+
+// Spellings returns the ways a folded term may be written in a text, for
+// engines that index words as they are: "прием" gives "прием" and "приём".
+// Only the first few е are varied to keep the number of spellings small.
+func Spellings(term string) []string {
+	spellings := []string{""}
+	varied := 0
+	for _, r := range term {
+		n := len(spellings)
+		for i := 0; i < n; i++ {
+			if r == 'е' && varied < maxYoVariants {
+				spellings = append(spellings, spellings[i]+"ё")
+			}
+			spellings[i] += string(r)
+		}
+		if r == 'е' {
+			varied++
+		}
+	}
+	return spellings
+}
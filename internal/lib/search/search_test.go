@@ -0,0 +1,30 @@
+package search
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTerms(t *testing.T) {
+	tests := []struct {
+		query string
+		terms []string
+	}{
+		{"Приём документов", []string{"прием", "документов"}},
+		{`  "title" OR content* NEAR(a b) `, []string{"title", "or", "content", "near", "a", "b"}},
+		{"МАИ-2025", []string{"маи", "2025"}},
+		{"*:&|!", []string{}},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.terms, Terms(tt.query), tt.query)
+	}
+}
+
+func TestSpellings(t *testing.T) {
+	assert.Equal(t, []string{"news"}, Spellings("news"))
+	assert.Equal(t, []string{"прием", "приём"}, Spellings("прием"))
+	assert.Equal(t, []string{"еж", "ёж"}, Spellings("еж"))
+	assert.Len(t, Spellings("переезжее"), 8)
+}
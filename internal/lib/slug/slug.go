@@ -0,0 +1,98 @@
+// Package slug turns post titles into URL slugs: lower-case Latin letters and
+// digits separated by single hyphens. Cyrillic is transliterated following
+// the scheme of Russian international passports (ICAO Doc 9303).
+package slug
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// MaxLen caps the length of slugs; Make cuts longer ones at a word boundary.
+const MaxLen = 80
+
+// fallback is the slug of titles with nothing to transliterate.
+const fallback = "post"
+
+var translit = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "e",
+	'ж': "zh", 'з': "z", 'и': "i", 'й': "i", 'к': "k", 'л': "l", 'м': "m",
+	'н': "n", 'о': "o", 'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u",
+	'ф': "f", 'х': "kh", 'ц': "ts", 'ч': "ch", 'ш': "sh", 'щ': "shch",
+	'ъ': "ie", 'ы': "y", 'ь': "", 'э': "e", 'ю': "iu", 'я': "ia",
+}
+
+// Make builds the slug of title: "Приём документов 2025" gives
+// "priem-dokumentov-2025".
+func Make(title string) string {
+	var b strings.Builder
+	hyphen := false
+	for _, r := range strings.ToLower(title) {
+		var s string
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			s = string(r)
+		case translit[r] != "":
+			s = translit[r]
+		case r == 'ь':
+			continue
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			// letters of other scripts are dropped rather than split words
+			continue
+		default:
+			hyphen = b.Len() > 0
+			continue
+		}
+		if hyphen {
+			b.WriteByte('-')
+			hyphen = false
+		}
+		b.WriteString(s)
+	}
+
+	slug := b.String()
+	if len(slug) > MaxLen {
+		slug = slug[:MaxLen]
+		if i := strings.LastIndexByte(slug, '-'); i > 0 {
+			slug = slug[:i]
+		}
+		slug = strings.TrimSuffix(slug, "-")
+	}
+	if slug == "" {
+		return fallback
+	}
+	return slug
+}
+
+// WithSuffix returns the n-th candidate for slug when earlier ones are
+// taken: slug itself for n = 1, then slug-2, slug-3 and so on.
+func WithSuffix(slug string, n int) string {
+	if n <= 1 {
+		return slug
+	}
+	suffix := "-" + strconv.Itoa(n)
+	if len(slug)+len(suffix) > MaxLen {
+		slug = strings.TrimRight(slug[:MaxLen-len(suffix)], "-")
+	}
+	return slug + suffix
+}
+
+// Valid reports whether s is a well-formed slug, for slugs chosen by
+// clients.
+func Valid(s string) bool {
+	if s == "" || len(s) > MaxLen {
+		return false
+	}
+	for _, part := range strings.Split(s, "-") {
+		if part == "" {
+			return false
+		}
+		for _, r := range part {
+			if !(r >= 'a' && r <= 'z') && !(r >= '0' && r <= '9') {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,54 @@
+package slug
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMake(t *testing.T) {
+	tests := []struct {
+		title string
+		slug  string
+	}{
+		{"Приём документов 2025", "priem-dokumentov-2025"},
+		{"  Щука, ёж и Объявление!  ", "shchuka-ezh-i-obieiavlenie"},
+		{"МАИ — лучший вуз", "mai-luchshii-vuz"},
+		{"Подъезд № 5", "podieezd-5"},
+		{"Hello, World", "hello-world"},
+		{"Семья и жизнь", "semia-i-zhizn"},
+		{"日本語", "post"},
+		{"!!!", "post"},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.slug, Make(tt.title), tt.title)
+		assert.True(t, Valid(Make(tt.title)), tt.title)
+	}
+}
+
+func TestMakeLong(t *testing.T) {
+	slug := Make(strings.Repeat("новость ", 20))
+	assert.LessOrEqual(t, len(slug), MaxLen)
+	assert.True(t, Valid(slug))
+	assert.True(t, strings.HasSuffix(slug, "novost"), slug)
+}
+
+func TestWithSuffix(t *testing.T) {
+	assert.Equal(t, "news", WithSuffix("news", 1))
+	assert.Equal(t, "news-2", WithSuffix("news", 2))
+
+	long := strings.Repeat("a", MaxLen)
+	assert.Len(t, WithSuffix(long, 12), MaxLen)
+	assert.True(t, strings.HasSuffix(WithSuffix(long, 12), "-12"))
+}
+
+func TestValid(t *testing.T) {
+	for _, s := range []string{"news", "priem-2025", "a-b-c"} {
+		assert.True(t, Valid(s), s)
+	}
+	for _, s := range []string{"", "News", "-news", "news-", "a--b", "новость", "a b", strings.Repeat("a", MaxLen+1)} {
+		assert.False(t, Valid(s), s)
+	}
+}
@@ -0,0 +1,54 @@
+// Package tags normalizes the names of post tags: trimmed, lower-case, with
+// single spaces between words, so "Sports" and " sports " are one tag.
+package tags
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// MaxLen caps the length of tag names in characters.
+const MaxLen = 32
+
+// ErrInvalid is returned for empty or too long names and for names with
+// characters that do not fit in a URL path segment or a query list.
+var ErrInvalid = errors.New("invalid tag")
+
+// Normalize returns the normal form of name.
+func Normalize(name string) (string, error) {
+	name = strings.ToLower(strings.Join(strings.Fields(name), " "))
+	if name == "" || utf8.RuneCountInString(name) > MaxLen {
+		return "", ErrInvalid
+	}
+	for _, r := range name {
+		if r == '/' || r == ',' || unicode.IsControl(r) {
+			return "", ErrInvalid
+		}
+	}
+	return name, nil
+}
+
+// NormalizeAll normalizes names and returns them as a Set, nil if there are
+// none.
+func NormalizeAll(names []string) ([]string, error) {
+	var normal []string
+	for _, name := range names {
+		name, err := Normalize(name)
+		if err != nil {
+			return nil, err
+		}
+		normal = append(normal, name)
+	}
+	return Set(normal), nil
+}
+
+// Set returns names sorted and without duplicates, the form in which the
+// stores keep and return the tags of a post. It does not modify names.
+func Set(names []string) []string {
+	set := slices.Clone(names)
+	slices.Sort(set)
+	return slices.Compact(set)
+}
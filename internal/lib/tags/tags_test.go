@@ -0,0 +1,50 @@
+package tags
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name   string
+		normal string
+		err    error
+	}{
+		{"Sports", "sports", nil},
+		{"  Приёмная   КОМИССИЯ ", "приёмная комиссия", nil},
+		{"", "", ErrInvalid},
+		{"   ", "", ErrInvalid},
+		{"a/b", "", ErrInvalid},
+		{"a,b", "", ErrInvalid},
+		{strings.Repeat("я", MaxLen), strings.Repeat("я", MaxLen), nil},
+		{strings.Repeat("я", MaxLen+1), "", ErrInvalid},
+	}
+
+	for _, tt := range tests {
+		normal, err := Normalize(tt.name)
+		assert.ErrorIs(t, err, tt.err, tt.name)
+		assert.Equal(t, tt.normal, normal, tt.name)
+	}
+}
+
+func TestNormalizeAll(t *testing.T) {
+	names, err := NormalizeAll([]string{"Sports", "admissions", " sports"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"admissions", "sports"}, names)
+
+	_, err = NormalizeAll([]string{"sports", ""})
+	assert.ErrorIs(t, err, ErrInvalid)
+
+	names, err = NormalizeAll([]string{})
+	assert.NoError(t, err)
+	assert.Nil(t, names)
+}
+
+func TestSet(t *testing.T) {
+	names := []string{"b", "a", "b"}
+	assert.Equal(t, []string{"a", "b"}, Set(names))
+	assert.Equal(t, []string{"b", "a", "b"}, names)
+}
@@ -0,0 +1,117 @@
+// Package views counts the views of posts in memory and writes them to the
+// storage in batches, so that reading a post does not cost a write.
+package views
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/RomanKovalev007/mai_news/internal/models"
+)
+
+// finalFlushTimeout bounds the flush Run makes when it stops.
+const finalFlushTimeout = 5 * time.Second
+
+// Store adds view counts to the per-day views of posts. Counts of posts that
+// no longer exist are dropped.
+type Store interface {
+	AddViews(ctx context.Context, counts []models.ViewCount) error
+}
+
+type bucket struct {
+	postID int
+	day    string
+}
+
+// Aggregator buffers views until they are flushed to the Store. It is safe
+// for concurrent use.
+type Aggregator struct {
+	store Store
+	log   *slog.Logger
+	// now is replaced in tests
+	now func() time.Time
+
+	mu      sync.Mutex
+	pending map[bucket]int
+}
+
+func New(store Store, log *slog.Logger) *Aggregator {
+	return &Aggregator{
+		store:   store,
+		log:     log,
+		now:     time.Now,
+		pending: make(map[bucket]int),
+	}
+}
+
+// RecordView counts a view of the post on the current UTC day.
+func (a *Aggregator) RecordView(postID int) {
+	b := bucket{postID: postID, day: a.now().UTC().Format(time.DateOnly)}
+
+	a.mu.Lock()
+	a.pending[b]++
+	a.mu.Unlock()
+}
+
+// Flush writes the buffered views to the store. If that fails, the views
+// are kept for the next flush.
+func (a *Aggregator) Flush(ctx context.Context) error {
+	a.mu.Lock()
+	pending := a.pending
+	a.pending = make(map[bucket]int)
+	a.mu.Unlock()
+
+	if len(pending) == 0 {
+		return nil
+	}
+
+	counts := make([]models.ViewCount, 0, len(pending))
+	for b, n := range pending {
+		counts = append(counts, models.ViewCount{PostID: b.postID, Day: b.day, Views: n})
+	}
+	// sorted so that the store sees the same batch for the same views
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].PostID != counts[j].PostID {
+			return counts[i].PostID < counts[j].PostID
+		}
+		return counts[i].Day < counts[j].Day
+	})
+
+	if err := a.store.AddViews(ctx, counts); err != nil {
+		a.mu.Lock()
+		for b, n := range pending {
+			a.pending[b] += n
+		}
+		a.mu.Unlock()
+		return err
+	}
+
+	return nil
+}
+
+// Run flushes the views every interval until ctx is done, and once more
+// then so that no views are lost on shutdown. Callers stop recording views
+// before canceling ctx and wait for Run to return before closing the store.
+func (a *Aggregator) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			flushCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), finalFlushTimeout)
+			defer cancel()
+			if err := a.Flush(flushCtx); err != nil {
+				a.log.Error("failed to flush views", slog.String("error", err.Error()))
+			}
+			return
+		case <-ticker.C:
+			if err := a.Flush(ctx); err != nil && ctx.Err() == nil {
+				a.log.Error("failed to flush views", slog.String("error", err.Error()))
+			}
+		}
+	}
+}
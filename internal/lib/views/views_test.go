@@ -0,0 +1,92 @@
+package views
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/RomanKovalev007/mai_news/internal/lib/logger/slogdiscard"
+	"github.com/RomanKovalev007/mai_news/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type storeFunc func(ctx context.Context, counts []models.ViewCount) error
+
+func (f storeFunc) AddViews(ctx context.Context, counts []models.ViewCount) error {
+	return f(ctx, counts)
+}
+
+func TestFlush(t *testing.T) {
+	var batches [][]models.ViewCount
+	fail := true
+	store := storeFunc(func(_ context.Context, counts []models.ViewCount) error {
+		if fail {
+			return errors.New("database is locked")
+		}
+		batches = append(batches, counts)
+		return nil
+	})
+
+	a := New(store, slogdiscard.NewDiscardLogger())
+	day := time.Date(2025, 9, 1, 23, 0, 0, 0, time.UTC)
+	a.now = func() time.Time { return day }
+	a.RecordView(2)
+	a.RecordView(1)
+	a.RecordView(2)
+
+	// failed views are kept for the next flush
+	assert.Error(t, a.Flush(context.Background()))
+	fail = false
+	day = day.Add(2 * time.Hour)
+	a.RecordView(2)
+	require.NoError(t, a.Flush(context.Background()))
+	require.NoError(t, a.Flush(context.Background()))
+
+	require.Len(t, batches, 1)
+	assert.Equal(t, []models.ViewCount{
+		{PostID: 1, Day: "2025-09-01", Views: 1},
+		{PostID: 2, Day: "2025-09-01", Views: 2},
+		{PostID: 2, Day: "2025-09-02", Views: 1},
+	}, batches[0])
+}
+
+func TestRunFlushesOnStop(t *testing.T) {
+	var mu sync.Mutex
+	views := 0
+	store := storeFunc(func(ctx context.Context, counts []models.ViewCount) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		for _, c := range counts {
+			views += c.Views
+		}
+		return nil
+	})
+
+	a := New(store, slogdiscard.NewDiscardLogger())
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		a.Run(ctx, time.Hour)
+		close(done)
+	}()
+
+	a.RecordView(1)
+	a.RecordView(1)
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Run did not stop after the context was canceled")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, 2, views)
+}
@@ -0,0 +1,92 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/RomanKovalev007/mai_news/internal/auth"
+	"github.com/RomanKovalev007/mai_news/internal/lib/apikey"
+	"github.com/RomanKovalev007/mai_news/internal/models"
+)
+
+// KeyAuthenticator resolves API keys to the users they act for and their
+// scopes; auth.Keyring implements it.
+type KeyAuthenticator interface {
+	// Authenticate fails with auth.ErrInvalidKey for keys it does not
+	// accept.
+	Authenticate(ctx context.Context, key string) (models.User, []string, error)
+}
+
+type scopesKey struct{}
+
+// WithScopes returns a copy of ctx that carries the scopes of the API key
+// a request was made with.
+func WithScopes(ctx context.Context, scopes []string) context.Context {
+	return context.WithValue(ctx, scopesKey{}, scopes)
+}
+
+// Scopes returns the scopes of the API key of the request context; ok is
+// false for requests made without a key, which are not limited by scopes.
+func Scopes(ctx context.Context) (scopes []string, ok bool) {
+	scopes, ok = ctx.Value(scopesKey{}).([]string)
+	return scopes, ok
+}
+
+// APIKey checks the key of requests with `Authorization: ApiKey ...` and
+// puts the user it acts for and its scopes in the request context. An
+// invalid key is rejected with 401; other requests pass on untouched.
+func APIKey(authenticator KeyAuthenticator, log *slog.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key, ok := apiKey(r)
+			if !ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			user, scopes, err := authenticator.Authenticate(r.Context(), key)
+			if err != nil {
+				if errors.Is(err, auth.ErrInvalidKey) {
+					w.Header().Set("WWW-Authenticate", apikey.Scheme)
+					http.Error(w, "Invalid API key", http.StatusUnauthorized)
+					return
+				}
+				http.Error(w, "failed to authenticate", http.StatusInternalServerError)
+				log.Error("failed to authenticate", slog.String("error", err.Error()))
+				return
+			}
+
+			ctx := WithScopes(WithUser(r.Context(), user), scopes)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// RequireScope answers 403 to requests made with an API key that lacks
+// scope. Requests without a key pass; RequireUser decides on those.
+func RequireScope(scope string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if scopes, ok := Scopes(r.Context()); ok && !slices.Contains(scopes, scope) {
+				http.Error(w, "API key lacks scope "+scope, http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// apiKey returns the key of an `Authorization: ApiKey ...` header. The
+// scheme is case-insensitive.
+func apiKey(r *http.Request) (string, bool) {
+	scheme, key, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, apikey.Scheme) {
+		return "", false
+	}
+	key = strings.TrimSpace(key)
+	return key, key != ""
+}
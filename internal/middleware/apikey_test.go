@@ -0,0 +1,104 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/RomanKovalev007/mai_news/internal/auth"
+	"github.com/RomanKovalev007/mai_news/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+type keyAuthenticatorFunc func(ctx context.Context, key string) (models.User, []string, error)
+
+func (f keyAuthenticatorFunc) Authenticate(ctx context.Context, key string) (models.User, []string, error) {
+	return f(ctx, key)
+}
+
+func TestAPIKey(t *testing.T) {
+	authenticator := keyAuthenticatorFunc(func(ctx context.Context, key string) (models.User, []string, error) {
+		switch key {
+		case "good":
+			return models.User{ID: 3}, []string{models.ScopePostsRead}, nil
+		case "broken":
+			return models.User{}, nil, errors.New("db error")
+		}
+		return models.User{}, nil, auth.ErrInvalidKey
+	})
+
+	tests := []struct {
+		name           string
+		authorization  string
+		expectedStatus int
+		expectedUser   int
+		expectedScopes []string
+	}{
+		{"anonymous", "", http.StatusOK, 0, nil},
+		{"valid", "ApiKey good", http.StatusOK, 3, []string{models.ScopePostsRead}},
+		{"scheme case", "apikey good", http.StatusOK, 3, []string{models.ScopePostsRead}},
+		{"invalid", "ApiKey bad", http.StatusUnauthorized, 0, nil},
+		{"bearer", "Bearer good", http.StatusOK, 0, nil},
+		{"error", "ApiKey broken", http.StatusInternalServerError, 0, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var userID int
+			var scopes []string
+			handler := APIKey(authenticator, slog.Default())(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if user, ok := User(r.Context()); ok {
+					userID = user.ID
+				}
+				scopes, _ = Scopes(r.Context())
+			}))
+			req := httptest.NewRequest("POST", "/posts/", nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			w := httptest.NewRecorder()
+
+			handler.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Equal(t, tt.expectedUser, userID)
+			assert.Equal(t, tt.expectedScopes, scopes)
+			if tt.expectedStatus == http.StatusUnauthorized {
+				assert.Equal(t, "ApiKey", w.Header().Get("WWW-Authenticate"))
+			}
+		})
+	}
+}
+
+func TestRequireScope(t *testing.T) {
+	handler := RequireScope(models.ScopePostsWrite)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	tests := []struct {
+		name           string
+		ctx            func(context.Context) context.Context
+		expectedStatus int
+	}{
+		{"without key", func(ctx context.Context) context.Context { return ctx }, http.StatusOK},
+		{"with scope", func(ctx context.Context) context.Context {
+			return WithScopes(ctx, []string{models.ScopePostsRead, models.ScopePostsWrite})
+		}, http.StatusOK},
+		{"without scope", func(ctx context.Context) context.Context {
+			return WithScopes(ctx, []string{models.ScopePostsRead})
+		}, http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/posts/", nil)
+			req = req.WithContext(tt.ctx(req.Context()))
+			w := httptest.NewRecorder()
+
+			handler.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/RomanKovalev007/mai_news/internal/auth"
+	"github.com/RomanKovalev007/mai_news/internal/models"
+)
+
+// Authenticator resolves access tokens to users; auth.Service implements it.
+type Authenticator interface {
+	// Authenticate fails with auth.ErrInvalidToken for tokens it does not
+	// accept.
+	Authenticate(ctx context.Context, accessToken string) (models.User, error)
+}
+
+// Authenticate checks the token of requests with `Authorization: Bearer
+// ...` and puts its user in the request context, see User. An invalid token
+// is rejected with 401. Requests without a bearer token pass on anonymously;
+// RequireUser turns them away where a user is needed.
+func Authenticate(authenticator Authenticator, log *slog.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			token, ok := bearerToken(r)
+			if !ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			user, err := authenticator.Authenticate(r.Context(), token)
+			if err != nil {
+				if errors.Is(err, auth.ErrInvalidToken) {
+					w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+					http.Error(w, "Invalid token", http.StatusUnauthorized)
+					return
+				}
+				http.Error(w, "failed to authenticate", http.StatusInternalServerError)
+				log.Error("failed to authenticate", slog.String("error", err.Error()))
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(WithUser(r.Context(), user)))
+		})
+	}
+}
+
+// RequireUser answers 401 to requests Authenticate found no user for.
+func RequireUser(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := User(r.Context()); !ok {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, "Authentication required", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// bearerToken returns the token of an `Authorization: Bearer ...` header.
+// The scheme is case-insensitive.
+func bearerToken(r *http.Request) (string, bool) {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
@@ -0,0 +1,82 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/RomanKovalev007/mai_news/internal/auth"
+	"github.com/RomanKovalev007/mai_news/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+type authenticatorFunc func(ctx context.Context, token string) (models.User, error)
+
+func (f authenticatorFunc) Authenticate(ctx context.Context, token string) (models.User, error) {
+	return f(ctx, token)
+}
+
+func TestAuthenticate(t *testing.T) {
+	authenticator := authenticatorFunc(func(ctx context.Context, token string) (models.User, error) {
+		switch token {
+		case "good":
+			return models.User{ID: 3, Name: "Анна"}, nil
+		case "broken":
+			return models.User{}, errors.New("db error")
+		}
+		return models.User{}, auth.ErrInvalidToken
+	})
+
+	tests := []struct {
+		name           string
+		authorization  string
+		expectedStatus int
+		expectedUser   int
+	}{
+		{"anonymous", "", http.StatusOK, 0},
+		{"valid", "Bearer good", http.StatusOK, 3},
+		{"scheme case", "bearer good", http.StatusOK, 3},
+		{"invalid", "Bearer bad", http.StatusUnauthorized, 0},
+		{"other scheme", "Basic YW5uYTpob3JzZQ==", http.StatusOK, 0},
+		{"error", "Bearer broken", http.StatusInternalServerError, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var userID int
+			handler := Authenticate(authenticator, slog.Default())(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if user, ok := User(r.Context()); ok {
+					userID = user.ID
+				}
+			}))
+			req := httptest.NewRequest("POST", "/posts/", nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			w := httptest.NewRecorder()
+
+			handler.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Equal(t, tt.expectedUser, userID)
+		})
+	}
+}
+
+func TestRequireUser(t *testing.T) {
+	handler := RequireUser(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("POST", "/posts/", nil))
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Equal(t, "Bearer", w.Header().Get("WWW-Authenticate"))
+
+	req := httptest.NewRequest("POST", "/posts/", nil)
+	req = req.WithContext(WithUser(req.Context(), models.User{ID: 3}))
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
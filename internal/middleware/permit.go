@@ -0,0 +1,40 @@
+package middleware
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/RomanKovalev007/mai_news/internal/authz"
+	"github.com/RomanKovalev007/mai_news/internal/models"
+)
+
+// RouteAuthorizer decides whether a user may do an action at all;
+// authz.Authorizer implements it.
+type RouteAuthorizer interface {
+	// Authorize fails with a *authz.Denied for actions the user may not do.
+	Authorize(user models.User, action authz.Action) error
+}
+
+// Permit answers 403 to requests whose user may not do action, with the
+// reason as JSON. Anonymous requests are refused too, so Permit goes after
+// RequireUser. Whether the user may do action to a particular post is up to
+// the handler.
+func Permit(authorizer RouteAuthorizer, action authz.Action, log *slog.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			user, _ := User(r.Context())
+			if err := authorizer.Authorize(user, action); err != nil {
+				var denied *authz.Denied
+				if errors.As(err, &denied) {
+					authz.Write(w, denied)
+					return
+				}
+				http.Error(w, "failed to authorize", http.StatusInternalServerError)
+				log.Error("failed to authorize", slog.String("error", err.Error()))
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
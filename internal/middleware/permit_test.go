@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/RomanKovalev007/mai_news/internal/authz"
+	"github.com/RomanKovalev007/mai_news/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPermit(t *testing.T) {
+	handler := Permit(authz.New(nil, nil), authz.PurgePost, slog.Default())(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	tests := []struct {
+		name           string
+		role           models.Role
+		expectedStatus int
+		expectedBody   string
+	}{
+		{"editor", models.RoleEditor, http.StatusOK, ""},
+		{"author", models.RoleAuthor, http.StatusForbidden, `{"action":"posts.purge","reason":"role","message":"Role \"author\" may not do this"}` + "\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("DELETE", "/posts/1/purge/", nil)
+			req = req.WithContext(WithUser(req.Context(), models.User{ID: 1, Role: tt.role}))
+			w := httptest.NewRecorder()
+
+			handler.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Equal(t, tt.expectedBody, w.Body.String())
+		})
+	}
+}
@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+type locationKey struct{}
+
+// TimeZone reads the display time zone of a request from its tz query
+// parameter, an IANA name such as Europe/Moscow, and falls back to def.
+// Unknown zones are rejected with 400. Handlers get the zone with Location.
+func TimeZone(def *time.Location) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			loc := def
+			if name := r.URL.Query().Get("tz"); name != "" {
+				var err error
+				if loc, err = time.LoadLocation(name); err != nil {
+					http.Error(w, "Invalid tz", http.StatusBadRequest)
+					return
+				}
+			}
+
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), locationKey{}, loc)))
+		})
+	}
+}
+
+// Location returns the display time zone TimeZone chose for the request
+// context, or UTC outside of it.
+func Location(ctx context.Context) *time.Location {
+	if loc, ok := ctx.Value(locationKey{}).(*time.Location); ok && loc != nil {
+		return loc
+	}
+	return time.UTC
+}
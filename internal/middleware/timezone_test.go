@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimeZone(t *testing.T) {
+	moscow, err := time.LoadLocation("Europe/Moscow")
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name           string
+		target         string
+		expectedStatus int
+		expectedZone   string
+	}{
+		{"default", "/posts/", http.StatusOK, "Europe/Moscow"},
+		{"requested", "/posts/?tz=Asia/Yekaterinburg", http.StatusOK, "Asia/Yekaterinburg"},
+		{"utc", "/posts/?tz=UTC", http.StatusOK, "UTC"},
+		{"unknown", "/posts/?tz=Mars/Olympus", http.StatusBadRequest, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var zone string
+			h := TimeZone(moscow)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				zone = Location(r.Context()).String()
+			}))
+
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, httptest.NewRequest("GET", tt.target, nil))
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Equal(t, tt.expectedZone, zone)
+		})
+	}
+}
+
+func TestLocationOutsideTimeZone(t *testing.T) {
+	r := httptest.NewRequest("GET", "/", nil)
+	assert.Equal(t, time.UTC, Location(r.Context()))
+}
@@ -0,0 +1,22 @@
+package middleware
+
+import (
+	"context"
+
+	"github.com/RomanKovalev007/mai_news/internal/models"
+)
+
+type userKey struct{}
+
+// WithUser returns a copy of ctx that carries the authenticated user of a
+// request. Handlers get it back with User.
+func WithUser(ctx context.Context, user models.User) context.Context {
+	return context.WithValue(ctx, userKey{}, user)
+}
+
+// User returns the authenticated user of the request context; ok is false
+// for anonymous requests.
+func User(ctx context.Context) (user models.User, ok bool) {
+	user, ok = ctx.Value(userKey{}).(models.User)
+	return user, ok
+}
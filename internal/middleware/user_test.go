@@ -0,0 +1,20 @@
+package middleware
+
+import (
+	"context"
+	"testing"
+
+	"github.com/RomanKovalev007/mai_news/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUser(t *testing.T) {
+	_, ok := User(context.Background())
+	assert.False(t, ok)
+
+	ctx := WithUser(context.Background(), models.User{ID: 3, Name: "Анна"})
+	user, ok := User(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, 3, user.ID)
+	assert.Equal(t, "Анна", user.Name)
+}
@@ -0,0 +1,43 @@
+package models
+
+import "time"
+
+// Scopes an API key can be granted. A key acts for the user who created it,
+// but only within its scopes.
+const (
+    ScopePostsRead   = "posts:read"
+    ScopePostsWrite  = "posts:write"
+    ScopePostsDelete = "posts:delete"
+    ScopeAdmin       = "admin"
+)
+
+// APIKey is a long-lived credential of a machine client, such as a bot or
+// an import script. The key itself is shown once, when it is created or
+// rotated; only its hash is stored.
+type APIKey struct {
+    ID         int        `json:"id"`
+    Name       string     `json:"name"`
+    // Prefix is the start of the key, to tell keys apart by.
+    Prefix     string     `json:"prefix"`
+    Hash       string     `json:"-"`
+    Scopes     []string   `json:"scopes"`
+    UserID     int        `json:"user_id"`
+    CreatedAt  time.Time  `json:"created_at"`
+    // LastUsedAt is nil for keys not used since they were created or
+    // rotated. It is updated at most once a minute.
+    LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+}
+
+type InputAPIKey struct {
+    Name       string
+    Prefix     string
+    Hash       string
+    Scopes     []string
+    UserID     int
+}
+
+// IssuedAPIKey is an API key together with the key itself.
+type IssuedAPIKey struct {
+    APIKey
+    Key        string     `json:"key"`
+}
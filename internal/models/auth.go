@@ -0,0 +1,12 @@
+package models
+
+// Tokens are issued on login and refresh. The access token authenticates
+// requests as `Authorization: Bearer ...`; the refresh token is exchanged
+// for a new pair once the access token expires.
+type Tokens struct {
+    AccessToken  string    `json:"access_token"`
+    RefreshToken string    `json:"refresh_token"`
+    TokenType    string    `json:"token_type"`
+    // ExpiresIn is the lifetime of the access token in seconds.
+    ExpiresIn    int       `json:"expires_in"`
+}
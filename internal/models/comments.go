@@ -0,0 +1,55 @@
+package models
+
+import "time"
+
+// CommentStatus is where a comment is in moderation. Only approved comments
+// are shown under posts.
+type CommentStatus string
+
+const (
+    CommentPending  CommentStatus = "pending"
+    CommentApproved CommentStatus = "approved"
+    CommentRejected CommentStatus = "rejected"
+)
+
+// Valid reports whether s is one of the statuses above.
+func (s CommentStatus) Valid() bool {
+    switch s {
+    case CommentPending, CommentApproved, CommentRejected:
+        return true
+    }
+    return false
+}
+
+// Comment is a comment on a post or, with a ParentID, a reply to another
+// comment of the same post.
+type Comment struct {
+    ID        int           `json:"id"`
+    PostID    int           `json:"post_id"`
+    // ParentID is 0 for comments on the post itself.
+    ParentID  int           `json:"parent_id,omitempty"`
+    // Author is nil for comments of deleted users.
+    Author    *Author       `json:"author,omitempty"`
+    Content   string        `json:"content"`
+    Status    CommentStatus `json:"status"`
+    CreatedAt time.Time     `json:"created_at"`
+    // UpdatedAt is when the content last changed.
+    UpdatedAt time.Time     `json:"updated_at"`
+    // Replies are filled in by the handlers when they thread comments.
+    Replies   []Comment     `json:"replies,omitempty"`
+}
+
+type InputComment struct {
+    PostID   int
+    ParentID int
+    AuthorID int
+    Content  string
+    Status   CommentStatus
+}
+
+// CommentPatch is a partial update of a comment. Nil fields are left
+// untouched.
+type CommentPatch struct {
+    Content *string
+    Status  *CommentStatus
+}
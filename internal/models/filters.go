@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+// SortField is a column the posts list can be ordered by. Posts with equal
+// values are ordered by id in the same direction.
+type SortField string
+
+const (
+    SortByCreatedAt SortField = "created_at"
+    SortByTitle     SortField = "title"
+)
+
+// PostFilter narrows down and orders the posts list. Zero fields do not
+// filter; the zero PostFilter lists every post, oldest first.
+type PostFilter struct {
+    // From and To bound created_at: From <= created_at < To.
+    From          time.Time
+    To            time.Time
+    // TitlePrefix keeps posts whose title starts with it, case-sensitively.
+    TitlePrefix   string
+    // Tags keeps posts with any of the tags, or with all of them if
+    // AllTags is set.
+    Tags          []string
+    AllTags       bool
+    // Section keeps the posts of the section with this slug.
+    Section       string
+    // AuthorID keeps the posts of the user with this id.
+    AuthorID      int
+    SortBy        SortField
+    Desc          bool
+}
@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// Cursor marks the last post of a page; the next page starts right after it
+// in the (sort field, id) order of the posts list.
+type Cursor struct {
+    CreatedAt     time.Time
+    Title         string
+    ID            int
+}
+
+// Page asks for up to Limit posts following After. A nil After asks for the
+// first page.
+type Page struct {
+    Limit         int
+    After         *Cursor
+}
+
+// PostsPage is a page of posts. Next is nil on the last page.
+// LastModified is the latest change of any post, including ones trashed
+// since, so it moves whenever the contents of any page may have changed.
+type PostsPage struct {
+    Posts         []OutputPost
+    Next          *Cursor
+    LastModified  time.Time
+}
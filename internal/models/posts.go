@@ -1,15 +1,61 @@
 package models
 
-
+import "time"
 
 type InputPost struct {
     Title     string    `json:"title"`
     Content   string    `json:"content"`
+    // Slug is optional; by default it is made from the title.
+    Slug      string    `json:"slug,omitempty"`
+    Tags      []string  `json:"tags,omitempty"`
+    // Section is the slug of the section of the post; empty means
+    // DefaultSection.
+    Section   string    `json:"section,omitempty"`
+    // AuthorID is the id of the user writing the post, 0 if there is none.
+    AuthorID  int       `json:"-"`
+}
+
+// PostPatch is a partial update of a post. Nil fields are left untouched.
+// A changed title gives the post a new slug unless Slug is set as well.
+type PostPatch struct {
+    Title     *string
+    Content   *string
+    Slug      *string
+    // Tags replaces the tags of the post; an empty slice removes them all.
+    Tags      *[]string
+    // Section moves the post to the section with this slug.
+    Section   *string
+    // EditorID is the id of the user making the change, 0 if there is none.
+    // It becomes the RevisedBy of the revision the change saves.
+    EditorID  int
 }
 
+// OutputPost is a stored post. The stores return its times in UTC; they are
+// serialised as RFC 3339.
 type OutputPost struct {
-    ID        int    `json:"id"`
+    ID        int       `json:"id"`
     Title     string    `json:"title"`
     Content   string    `json:"content"`
-    CreatedAt string`json:"created_at"`
+    // Slug addresses the post in /posts/by-slug/{slug}/; former slugs
+    // redirect to it.
+    Slug      string    `json:"slug,omitempty"`
+    // Tags are sorted by name.
+    Tags      []string  `json:"tags,omitempty"`
+    // Section is the slug of the section of the post.
+    Section   string    `json:"section,omitempty"`
+    // Author is nil for posts written before there were users and for
+    // posts whose author was deleted.
+    Author    *Author   `json:"author,omitempty"`
+    // CommentCount is the number of comments shown under the post. It is
+    // not part of the version of the post, only of its ETag.
+    CommentCount int    `json:"comment_count,omitempty"`
+    // Reactions counts the reactions to the post by kind. Like the comment
+    // count, it is not part of the version of the post, only of its ETag.
+    Reactions map[ReactionKind]int `json:"reactions,omitempty"`
+    CreatedAt time.Time `json:"created_at"`
+    // UpdatedAt is when the post last changed; it is sent as Last-Modified.
+    UpdatedAt time.Time `json:"updated_at,omitzero"`
+    DeletedAt time.Time `json:"deleted_at,omitzero"`
+    // Version grows with every change of the post; it is sent in the ETag.
+    Version   int       `json:"-"`
 }
@@ -0,0 +1,47 @@
+package models
+
+import "strconv"
+
+// ReactionKind is a reaction a reader can leave on a post.
+type ReactionKind string
+
+const (
+    ReactionLike  ReactionKind = "like"
+    ReactionHeart ReactionKind = "heart"
+    ReactionLaugh ReactionKind = "laugh"
+    ReactionWow   ReactionKind = "wow"
+    ReactionSad   ReactionKind = "sad"
+    ReactionFire  ReactionKind = "fire"
+)
+
+// Valid reports whether k is one of the reactions above.
+func (k ReactionKind) Valid() bool {
+    switch k {
+    case ReactionLike, ReactionHeart, ReactionLaugh, ReactionWow, ReactionSad, ReactionFire:
+        return true
+    }
+    return false
+}
+
+// ReactorID tells who reacts: a user or, for anonymous clients, a hash of
+// the fingerprint the client sends. Each has one reaction per post.
+type ReactorID struct {
+    UserID      int
+    Fingerprint string
+}
+
+// Key identifies the reactor among the reactions to a post.
+func (id ReactorID) Key() string {
+    if id.UserID != 0 {
+        return "user:" + strconv.Itoa(id.UserID)
+    }
+    return "client:" + id.Fingerprint
+}
+
+// PostReactions is the reaction of a client to a post with the counts of
+// all reactions to it.
+type PostReactions struct {
+    PostID    int                  `json:"post_id"`
+    Kind      ReactionKind         `json:"kind"`
+    Reactions map[ReactionKind]int `json:"reactions"`
+}
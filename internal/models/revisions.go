@@ -0,0 +1,30 @@
+package models
+
+import (
+    "time"
+
+    "github.com/RomanKovalev007/mai_news/internal/lib/diff"
+)
+
+// Revision is a previous version of a post, saved when the post was patched.
+type Revision struct {
+    PostID    int       `json:"post_id"`
+    Rev       int       `json:"rev"`
+    Title     string    `json:"title"`
+    Content   string    `json:"content"`
+    RevisedAt time.Time `json:"revised_at"`
+    // RevisedBy is the user whose change replaced this version. It is nil
+    // for changes made without a signed-in user or before revisions kept
+    // their editor, and once the user is deleted.
+    RevisedBy *Author   `json:"revised_by,omitempty"`
+}
+
+// RevisionDiff describes the changes between two versions of a post.
+type RevisionDiff struct {
+    PostID    int           `json:"post_id"`
+    From      int           `json:"from"`
+    To        int           `json:"to,omitempty"` // omitted when comparing with the current version
+    Mode      string        `json:"mode"`
+    Title     []diff.Chunk  `json:"title"`
+    Content   []diff.Chunk  `json:"content"`
+}
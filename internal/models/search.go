@@ -0,0 +1,10 @@
+package models
+
+// SearchResult is a post matching a search query. Title and Snippet carry
+// the matched words wrapped in <mark></mark>.
+type SearchResult struct {
+    OutputPost
+    Score             float64   `json:"score"`
+    TitleHighlight    string    `json:"title_highlight"`
+    Snippet           string    `json:"snippet"`
+}
@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+// DefaultSection is the slug of the section posts go to unless they name
+// another. It holds the posts written before there were sections.
+const DefaultSection = "general"
+
+// Section is a news feed of its own, e.g. of a faculty. Post titles are
+// unique within a section.
+type Section struct {
+    ID          int       `json:"id"`
+    Slug        string    `json:"slug"`
+    Title       string    `json:"title"`
+    Description string    `json:"description"`
+    // Editors are the e-mail addresses of the people running the section,
+    // sorted.
+    Editors     []string  `json:"editors"`
+    CreatedAt   time.Time `json:"created_at"`
+}
+
+type InputSection struct {
+    Slug        string    `json:"slug"`
+    Title       string    `json:"title"`
+    Description string    `json:"description"`
+    Editors     []string  `json:"editors"`
+}
+
+// SectionPatch is a partial update of a section. Nil fields are left
+// untouched; the slug of a section does not change.
+type SectionPatch struct {
+    Title       *string   `json:"title"`
+    Description *string   `json:"description"`
+    Editors     *[]string `json:"editors"`
+}
@@ -0,0 +1,8 @@
+package models
+
+// Tag is a label of posts. Count is the number of posts outside the trash
+// that carry it.
+type Tag struct {
+    Name      string    `json:"name"`
+    Count     int       `json:"count"`
+}
@@ -0,0 +1,56 @@
+package models
+
+import "time"
+
+// Role is what a user may do, see package authz. Each role can do what the
+// roles before it can.
+type Role string
+
+const (
+    RoleReader Role = "reader"
+    RoleAuthor Role = "author"
+    RoleEditor Role = "editor"
+    RoleAdmin  Role = "admin"
+)
+
+// Valid reports whether r is one of the roles above.
+func (r Role) Valid() bool {
+    switch r {
+    case RoleReader, RoleAuthor, RoleEditor, RoleAdmin:
+        return true
+    }
+    return false
+}
+
+// User is an account of someone writing or running the news. The password
+// itself is never stored, only its hash made by the password package.
+type User struct {
+    ID           int       `json:"id"`
+    Name         string    `json:"name"`
+    // Email is lowercased and unique; users log in with it.
+    Email        string    `json:"email"`
+    PasswordHash string    `json:"-"`
+    Role         Role      `json:"role"`
+    CreatedAt    time.Time `json:"created_at"`
+}
+
+type InputUser struct {
+    Name         string
+    Email        string
+    PasswordHash string
+    Role         Role
+}
+
+// UserPatch is a partial update of a user. Nil fields are left untouched.
+type UserPatch struct {
+    Name         *string
+    Email        *string
+    PasswordHash *string
+    Role         *Role
+}
+
+// Author is the part of a user shown with their posts.
+type Author struct {
+    ID           int       `json:"id"`
+    Name         string    `json:"name"`
+}
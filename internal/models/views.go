@@ -0,0 +1,26 @@
+package models
+
+// ViewCount is a number of views of a post on a day, the unit in which
+// buffered views are written out.
+type ViewCount struct {
+    PostID int
+    // Day is a UTC date in the form 2006-01-02.
+    Day    string
+    Views  int
+}
+
+// DayViews is the number of views of a post on a UTC day.
+type DayViews struct {
+    Day   string `json:"day"`
+    Views int    `json:"views"`
+}
+
+// PostStats is the view statistics of a post, served by
+// GET /posts/{id}/stats/.
+type PostStats struct {
+    PostID int        `json:"post_id"`
+    // Views is the total over Days.
+    Views  int        `json:"views"`
+    // Days lists the days with views, oldest first.
+    Days   []DayViews `json:"days"`
+}
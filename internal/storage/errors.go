@@ -4,4 +4,19 @@ import "errors"
 
 var (
 	ErrPostNotFound = errors.New("post not found")
-)
\ No newline at end of file
+	ErrPostExists = errors.New("post with this title already exists in the section")
+	ErrRevisionNotFound = errors.New("revision not found")
+	ErrSearchUnavailable = errors.New("full-text search is not available")
+	ErrVersionMismatch = errors.New("post version does not match")
+	ErrSlugTaken = errors.New("slug is already taken")
+	ErrTagNotFound = errors.New("tag not found")
+	ErrTagExists = errors.New("tag with this name already exists")
+	ErrSectionNotFound = errors.New("section not found")
+	ErrSectionExists = errors.New("section with this slug already exists")
+	ErrUserNotFound = errors.New("user not found")
+	ErrUserExists = errors.New("user with this email already exists")
+	ErrTokenRevoked = errors.New("token is already revoked")
+	ErrAPIKeyNotFound = errors.New("api key not found")
+	ErrCommentNotFound = errors.New("comment not found")
+	ErrReactionNotFound = errors.New("reaction not found")
+)
@@ -0,0 +1,159 @@
+package memstore
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/RomanKovalev007/mai_news/internal/models"
+	"github.com/RomanKovalev007/mai_news/internal/storage"
+)
+
+// GetAPIKeys returns every API key, oldest first.
+func (s *Storage) GetAPIKeys(ctx context.Context) ([]models.APIKey, error){
+	if err := ctx.Err(); err != nil{
+		return []models.APIKey{}, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var result []models.APIKey
+	for _, key := range s.apiKeys{
+		result = append(result, copyAPIKey(key))
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ID < result[j].ID })
+
+	return result, nil
+}
+
+func (s *Storage) GetAPIKey(ctx context.Context, id int) (models.APIKey, error){
+	if err := ctx.Err(); err != nil{
+		return models.APIKey{}, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	key, ok := s.apiKeys[id]
+	if !ok{
+		return models.APIKey{}, storage.ErrAPIKeyNotFound
+	}
+
+	return copyAPIKey(key), nil
+}
+
+// GetAPIKeyByHash returns the API key with the given hash, see apikey.Hash.
+func (s *Storage) GetAPIKeyByHash(ctx context.Context, hash string) (models.APIKey, error){
+	if err := ctx.Err(); err != nil{
+		return models.APIKey{}, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, key := range s.apiKeys{
+		if key.Hash == hash{
+			return copyAPIKey(key), nil
+		}
+	}
+
+	return models.APIKey{}, storage.ErrAPIKeyNotFound
+}
+
+// SaveAPIKey creates an API key of the user. It fails with
+// storage.ErrUserNotFound if there is no such user.
+func (s *Storage) SaveAPIKey(ctx context.Context, input models.InputAPIKey) (models.APIKey, error){
+	if err := ctx.Err(); err != nil{
+		return models.APIKey{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.users[input.UserID]; !ok{
+		return models.APIKey{}, storage.ErrUserNotFound
+	}
+
+	s.lastAPIKeyID++
+	key := &models.APIKey{
+		ID: s.lastAPIKeyID,
+		Name: input.Name,
+		Prefix: input.Prefix,
+		Hash: input.Hash,
+		Scopes: slices.Clone(input.Scopes),
+		UserID: input.UserID,
+		CreatedAt: time.Now().UTC(),
+	}
+	s.apiKeys[key.ID] = key
+
+	return copyAPIKey(key), nil
+}
+
+// RotateAPIKey replaces the key of an API key, which keeps its id, name and
+// scopes. The old key stops working at once.
+func (s *Storage) RotateAPIKey(ctx context.Context, id int, prefix, hash string) (models.APIKey, error){
+	if err := ctx.Err(); err != nil{
+		return models.APIKey{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key, ok := s.apiKeys[id]
+	if !ok{
+		return models.APIKey{}, storage.ErrAPIKeyNotFound
+	}
+	key.Prefix = prefix
+	key.Hash = hash
+	key.LastUsedAt = nil
+
+	return copyAPIKey(key), nil
+}
+
+// DeleteAPIKey revokes an API key for good.
+func (s *Storage) DeleteAPIKey(ctx context.Context, id int) error{
+	if err := ctx.Err(); err != nil{
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.apiKeys[id]; !ok{
+		return storage.ErrAPIKeyNotFound
+	}
+	delete(s.apiKeys, id)
+
+	return nil
+}
+
+// TouchAPIKey records that the API key was used at usedAt. A key deleted
+// in the meantime is not an error.
+func (s *Storage) TouchAPIKey(ctx context.Context, id int, usedAt time.Time) error{
+	if err := ctx.Err(); err != nil{
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if key, ok := s.apiKeys[id]; ok{
+		usedAt = usedAt.UTC()
+		key.LastUsedAt = &usedAt
+	}
+
+	return nil
+}
+
+// copyAPIKey returns a copy of key that shares no memory with it.
+func copyAPIKey(key *models.APIKey) models.APIKey{
+	result := *key
+	result.Scopes = slices.Clone(key.Scopes)
+	if key.LastUsedAt != nil{
+		lastUsedAt := *key.LastUsedAt
+		result.LastUsedAt = &lastUsedAt
+	}
+	return result
+}
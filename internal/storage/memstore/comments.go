@@ -0,0 +1,232 @@
+package memstore
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/RomanKovalev007/mai_news/internal/models"
+	"github.com/RomanKovalev007/mai_news/internal/storage"
+)
+
+// GetComments returns the comments shown under the post, oldest first. It
+// fails with storage.ErrPostNotFound if the post does not exist or is in
+// the trash.
+func (s *Storage) GetComments(ctx context.Context, postID int) ([]models.Comment, error){
+	if err := ctx.Err(); err != nil{
+		return []models.Comment{}, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	rec, ok := s.posts[postID]
+	if !ok || rec.trashed(){
+		return []models.Comment{}, storage.ErrPostNotFound
+	}
+
+	var result []models.Comment
+	for _, comment := range s.shownComments(postID){
+		result = append(result, s.outputComment(comment))
+	}
+
+	return result, nil
+}
+
+// GetCommentQueue returns the comments awaiting moderation on posts outside
+// the trash, oldest first. Unless sections is nil, only those on posts of
+// the sections with these slugs.
+func (s *Storage) GetCommentQueue(ctx context.Context, sections []string) ([]models.Comment, error){
+	if err := ctx.Err(); err != nil{
+		return []models.Comment{}, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var result []models.Comment
+	for _, comment := range s.sortedComments(){
+		rec, ok := s.posts[comment.PostID]
+		if comment.Status == models.CommentPending && ok && !rec.trashed() && (sections == nil || slices.Contains(sections, rec.post.Section)){
+			result = append(result, s.outputComment(comment))
+		}
+	}
+
+	return result, nil
+}
+
+// GetComment returns a comment in any status.
+func (s *Storage) GetComment(ctx context.Context, id int) (models.Comment, error){
+	if err := ctx.Err(); err != nil{
+		return models.Comment{}, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	comment, ok := s.comments[id]
+	if !ok{
+		return models.Comment{}, storage.ErrCommentNotFound
+	}
+
+	return s.outputComment(comment), nil
+}
+
+// SaveComment stores a new comment. It fails with storage.ErrPostNotFound if
+// the post does not exist or is in the trash, with
+// storage.ErrCommentNotFound if the parent is not a shown comment of the
+// same post and with storage.ErrUserNotFound if the author does not exist.
+func (s *Storage) SaveComment(ctx context.Context, input models.InputComment) (models.Comment, error){
+	if err := ctx.Err(); err != nil{
+		return models.Comment{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rec, ok := s.posts[input.PostID]
+	if !ok || rec.trashed(){
+		return models.Comment{}, storage.ErrPostNotFound
+	}
+	if input.ParentID != 0 && !slices.ContainsFunc(s.shownComments(input.PostID), func(c *models.Comment) bool { return c.ID == input.ParentID }){
+		return models.Comment{}, storage.ErrCommentNotFound
+	}
+	var author *models.Author
+	if input.AuthorID != 0{
+		user, ok := s.users[input.AuthorID]
+		if !ok{
+			return models.Comment{}, storage.ErrUserNotFound
+		}
+		author = &models.Author{ID: user.ID, Name: user.Name}
+	}
+
+	now := time.Now().UTC()
+	s.lastCommentID++
+	comment := &models.Comment{
+		ID: s.lastCommentID,
+		PostID: input.PostID,
+		ParentID: input.ParentID,
+		Author: author,
+		Content: input.Content,
+		Status: input.Status,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	s.comments[comment.ID] = comment
+	s.countComments(input.PostID)
+
+	return s.outputComment(comment), nil
+}
+
+// PatchComment updates the fields set in patch. A new content changes the
+// update time of the comment, a new status does not.
+func (s *Storage) PatchComment(ctx context.Context, id int, patch models.CommentPatch) (models.Comment, error){
+	if err := ctx.Err(); err != nil{
+		return models.Comment{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	comment, ok := s.comments[id]
+	if !ok{
+		return models.Comment{}, storage.ErrCommentNotFound
+	}
+	if patch.Content != nil{
+		comment.Content = *patch.Content
+		comment.UpdatedAt = time.Now().UTC()
+	}
+	if patch.Status != nil{
+		comment.Status = *patch.Status
+		s.countComments(comment.PostID)
+	}
+
+	return s.outputComment(comment), nil
+}
+
+// DeleteComment deletes a comment together with the replies to it.
+func (s *Storage) DeleteComment(ctx context.Context, id int) error{
+	if err := ctx.Err(); err != nil{
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	comment, ok := s.comments[id]
+	if !ok{
+		return storage.ErrCommentNotFound
+	}
+
+	// replies come after the comments they answer
+	deleted := map[int]bool{id: true}
+	for _, c := range s.sortedComments(){
+		if deleted[c.ID] || deleted[c.ParentID]{
+			deleted[c.ID] = true
+			delete(s.comments, c.ID)
+		}
+	}
+	s.countComments(comment.PostID)
+
+	return nil
+}
+
+// sortedComments returns every comment, oldest first. Callers must hold
+// s.mu.
+func (s *Storage) sortedComments() []*models.Comment{
+	result := make([]*models.Comment, 0, len(s.comments))
+	for _, comment := range s.comments{
+		result = append(result, comment)
+	}
+	slices.SortFunc(result, func(a, b *models.Comment) int { return a.ID - b.ID })
+	return result
+}
+
+// shownComments returns the comments shown under the post: the approved
+// ones whose parents are shown too, oldest first. Callers must hold s.mu.
+func (s *Storage) shownComments(postID int) []*models.Comment{
+	var result []*models.Comment
+	shown := make(map[int]bool)
+	for _, comment := range s.sortedComments(){
+		if comment.PostID != postID || comment.Status != models.CommentApproved{
+			continue
+		}
+		if comment.ParentID == 0 || shown[comment.ParentID]{
+			shown[comment.ID] = true
+			result = append(result, comment)
+		}
+	}
+	return result
+}
+
+// countComments updates the comment count of the post. Callers must hold
+// s.mu.
+func (s *Storage) countComments(postID int){
+	if rec, ok := s.posts[postID]; ok{
+		rec.post.CommentCount = len(s.shownComments(postID))
+	}
+}
+
+// dropComments deletes the comments of a post that is gone for good.
+// Callers must hold s.mu.
+func (s *Storage) dropComments(postID int){
+	for id, comment := range s.comments{
+		if comment.PostID == postID{
+			delete(s.comments, id)
+		}
+	}
+}
+
+// outputComment returns a copy of comment with the current name of its
+// author, or without an author if the user was deleted. Callers must hold
+// s.mu.
+func (s *Storage) outputComment(comment *models.Comment) models.Comment{
+	result := *comment
+	result.Author = nil
+	if comment.Author != nil{
+		if user, ok := s.users[comment.Author.ID]; ok{
+			result.Author = &models.Author{ID: user.ID, Name: user.Name}
+		}
+	}
+	return result
+}
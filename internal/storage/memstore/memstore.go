@@ -0,0 +1,137 @@
+package memstore
+
+import (
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/RomanKovalev007/mai_news/internal/models"
+)
+
+// Storage keeps posts in process memory. It is meant for development,
+// demos and tests; everything is lost when the process exits.
+type Storage struct{
+	mu sync.RWMutex
+	posts map[int]*record
+	lastID int
+	// lastModified is the time of the latest change of any post
+	lastModified time.Time
+	// sections are keyed by slug
+	sections map[string]*models.Section
+	lastSectionID int
+	users map[int]*models.User
+	lastUserID int
+	// revokedTokens maps token ids to when the tokens expire
+	revokedTokens map[string]time.Time
+	apiKeys map[int]*models.APIKey
+	lastAPIKeyID int
+	comments map[int]*models.Comment
+	lastCommentID int
+}
+
+// record is a stored post together with the bookkeeping the SQL backends
+// keep in extra columns.
+type record struct{
+	post models.OutputPost
+	deletedAt time.Time
+	revisions []models.Revision
+	// formerSlugs are the slugs the post had before, mirroring post_slugs
+	formerSlugs []string
+	// reactions maps reactor keys to their reactions, mirroring
+	// post_reactions; post.Reactions holds the counts
+	reactions map[string]models.ReactionKind
+	// views maps UTC days to the views of the post, mirroring post_views
+	views map[string]int
+}
+
+func (r *record) trashed() bool{
+	return !r.deletedAt.IsZero()
+}
+
+// touch records a change of r: it bumps the version and update time of the
+// post. Callers must hold s.mu.
+func (s *Storage) touch(r *record){
+	s.lastModified = time.Now().UTC()
+	r.post.UpdatedAt = s.lastModified
+	r.post.Version++
+}
+
+func (r *record) cursor() models.Cursor{
+	return models.Cursor{CreatedAt: r.post.CreatedAt, Title: r.post.Title, ID: r.post.ID}
+}
+
+// matches reports whether r passes the conditions of filter.
+func (r *record) matches(filter models.PostFilter) bool{
+	switch {
+	case !filter.From.IsZero() && r.post.CreatedAt.Before(filter.From):
+		return false
+	case !filter.To.IsZero() && !r.post.CreatedAt.Before(filter.To):
+		return false
+	}
+	if !strings.HasPrefix(r.post.Title, filter.TitlePrefix){
+		return false
+	}
+	if filter.Section != "" && r.post.Section != filter.Section{
+		return false
+	}
+	if filter.AuthorID != 0 && (r.post.Author == nil || r.post.Author.ID != filter.AuthorID){
+		return false
+	}
+	if len(filter.Tags) == 0{
+		return true
+	}
+	for _, tag := range filter.Tags{
+		has := slices.Contains(r.post.Tags, tag)
+		if has && !filter.AllTags{
+			return true
+		}
+		if !has && filter.AllTags{
+			return false
+		}
+	}
+	return filter.AllTags
+}
+
+// after reports whether r comes after c in the order of filter.
+func (r *record) after(c models.Cursor, filter models.PostFilter) bool{
+	var cmp int
+	if filter.SortBy == models.SortByTitle{
+		cmp = strings.Compare(r.post.Title, c.Title)
+	} else {
+		cmp = r.post.CreatedAt.Compare(c.CreatedAt)
+	}
+	if cmp == 0{
+		cmp = r.post.ID - c.ID
+	}
+	if filter.Desc{
+		return cmp < 0
+	}
+	return cmp > 0
+}
+
+// New returns an empty Storage with just the default section.
+func New() *Storage {
+	s := &Storage{
+		posts: make(map[int]*record),
+		sections: make(map[string]*models.Section),
+		users: make(map[int]*models.User),
+		revokedTokens: make(map[string]time.Time),
+		apiKeys: make(map[int]*models.APIKey),
+		comments: make(map[int]*models.Comment),
+	}
+	s.lastSectionID++
+	s.sections[models.DefaultSection] = &models.Section{
+		ID: s.lastSectionID,
+		Slug: models.DefaultSection,
+		Title: "Общие новости",
+		Editors: []string{},
+		CreatedAt: time.Now().UTC(),
+	}
+	return s
+}
+
+// Close is a no-op; it lets Storage be shut down like the database backends.
+func (s *Storage) Close() error {
+	return nil
+}
@@ -0,0 +1,254 @@
+package memstore
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/RomanKovalev007/mai_news/internal/lib/jsonpatch"
+	"github.com/RomanKovalev007/mai_news/internal/lib/tags"
+	"github.com/RomanKovalev007/mai_news/internal/models"
+	"github.com/RomanKovalev007/mai_news/internal/storage"
+)
+
+// GetAllPosts returns a page of the posts matching filter, in its order.
+func (s *Storage) GetAllPosts(ctx context.Context, filter models.PostFilter, page models.Page) (models.PostsPage, error){
+	if err := ctx.Err(); err != nil{
+		return models.PostsPage{}, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var recs []*record
+	for _, rec := range s.posts{
+		if !rec.trashed() && rec.matches(filter) && (page.After == nil || rec.after(*page.After, filter)){
+			recs = append(recs, rec)
+		}
+	}
+	sort.Slice(recs, func(i, j int) bool { return recs[j].after(recs[i].cursor(), filter) })
+
+	result := models.PostsPage{LastModified: s.lastModified}
+	for _, rec := range recs{
+		if len(result.Posts) == page.Limit{
+			next := recs[len(result.Posts)-1].cursor()
+			result.Next = &next
+			break
+		}
+		result.Posts = append(result.Posts, rec.post)
+	}
+
+	return result, nil
+}
+
+func (s *Storage) SavePost(ctx context.Context, inputPost models.InputPost) (models.OutputPost, error){
+	if err := ctx.Err(); err != nil{
+		return models.OutputPost{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	section := inputPost.Section
+	if section == ""{
+		section = models.DefaultSection
+	}
+	if _, ok := s.sections[section]; !ok{
+		return models.OutputPost{}, storage.ErrSectionNotFound
+	}
+	var author *models.Author
+	if inputPost.AuthorID != 0{
+		user, ok := s.users[inputPost.AuthorID]
+		if !ok{
+			return models.OutputPost{}, storage.ErrUserNotFound
+		}
+		author = &models.Author{ID: user.ID, Name: user.Name}
+	}
+	if s.titleTaken(section, inputPost.Title, 0){
+		return models.OutputPost{}, storage.ErrPostExists
+	}
+	slug, err := s.newSlug(inputPost.Slug, inputPost.Title, 0)
+	if err != nil{
+		return models.OutputPost{}, err
+	}
+
+	s.lastID++
+	now := time.Now().UTC()
+	post := models.OutputPost{
+		ID: s.lastID,
+		Title: inputPost.Title,
+		Content: inputPost.Content,
+		Slug: slug,
+		Tags: tags.Set(inputPost.Tags),
+		Section: section,
+		Author: author,
+		CreatedAt: now,
+		UpdatedAt: now,
+		Version: 1,
+	}
+	s.lastModified = post.UpdatedAt
+	s.posts[post.ID] = &record{post: post}
+
+	return post, nil
+}
+
+func (s *Storage) GetPost(ctx context.Context, id int) (models.OutputPost, error){
+	if err := ctx.Err(); err != nil{
+		return models.OutputPost{}, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	rec, ok := s.posts[id]
+	if !ok || rec.trashed(){
+		return models.OutputPost{}, storage.ErrPostNotFound
+	}
+
+	return rec.post, nil
+}
+
+// PatchPost updates the fields set in patch and keeps the previous version of
+// the post as a revision. Unless version is 0 the post is only updated while
+// it is at that version.
+func (s *Storage) PatchPost(ctx context.Context, id, version int, patch models.PostPatch) (models.OutputPost, error){
+	if err := ctx.Err(); err != nil{
+		return models.OutputPost{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rec, ok := s.posts[id]
+	if !ok || rec.trashed(){
+		return models.OutputPost{}, storage.ErrPostNotFound
+	}
+	if version != 0 && version != rec.post.Version{
+		return models.OutputPost{}, storage.ErrVersionMismatch
+	}
+	return s.patchPost(rec, patch)
+}
+
+// ApplyPatch runs JSON Patch operations against the post. Unless version is
+// 0 the post is only updated while it is at that version.
+func (s *Storage) ApplyPatch(ctx context.Context, id, version, editorID int, ops []jsonpatch.Operation) (models.OutputPost, error){
+	if err := ctx.Err(); err != nil{
+		return models.OutputPost{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rec, ok := s.posts[id]
+	if !ok || rec.trashed(){
+		return models.OutputPost{}, storage.ErrPostNotFound
+	}
+	if version != 0 && version != rec.post.Version{
+		return models.OutputPost{}, storage.ErrVersionMismatch
+	}
+
+	patch, err := jsonpatch.Apply(ops, rec.post)
+	if err != nil{
+		return models.OutputPost{}, err
+	}
+	patch.EditorID = editorID
+	return s.patchPost(rec, patch)
+}
+
+// patchPost keeps the current version of rec as a revision and updates the
+// fields set in patch. Tags and the section are not kept in revisions, but a
+// change of them is a change of the post. Callers must hold s.mu.
+func (s *Storage) patchPost(rec *record, patch models.PostPatch) (models.OutputPost, error){
+	section, title := rec.post.Section, rec.post.Title
+	if patch.Section != nil{
+		if _, ok := s.sections[*patch.Section]; !ok{
+			return models.OutputPost{}, storage.ErrSectionNotFound
+		}
+		section = *patch.Section
+	}
+	if patch.Title != nil{
+		title = *patch.Title
+	}
+	if (patch.Title != nil || patch.Section != nil) && s.titleTaken(section, title, rec.post.ID){
+		return models.OutputPost{}, storage.ErrPostExists
+	}
+	slug, err := s.patchSlug(rec, patch)
+	if err != nil{
+		return models.OutputPost{}, err
+	}
+	names, tagsChanged := rec.post.Tags, false
+	if patch.Tags != nil && !slices.Equal(tags.Set(*patch.Tags), names){
+		names, tagsChanged = tags.Set(*patch.Tags), true
+	}
+	if patch.Title == nil && patch.Content == nil && slug == "" && !tagsChanged && patch.Section == nil{
+		return rec.post, nil
+	}
+
+	if (patch.Title != nil && *patch.Title != rec.post.Title) || (patch.Content != nil && *patch.Content != rec.post.Content){
+		var editor *models.Author
+		if _, ok := s.users[patch.EditorID]; ok{
+			editor = &models.Author{ID: patch.EditorID}
+		}
+		rec.revisions = append(rec.revisions, models.Revision{
+			PostID: rec.post.ID,
+			Rev: len(rec.revisions) + 1,
+			Title: rec.post.Title,
+			Content: rec.post.Content,
+			RevisedAt: time.Now().UTC(),
+			RevisedBy: editor,
+		})
+	}
+
+	if patch.Title != nil{
+		rec.post.Title = *patch.Title
+	}
+	if patch.Content != nil{
+		rec.post.Content = *patch.Content
+	}
+	if slug != ""{
+		rec.setSlug(slug)
+	}
+	if tagsChanged{
+		rec.post.Tags = names
+	}
+	rec.post.Section = section
+	s.touch(rec)
+
+	return rec.post, nil
+}
+
+// DeletePost moves the post to the trash. Unless version is 0 the post is
+// only deleted while it is at that version.
+func (s *Storage) DeletePost(ctx context.Context, id, version int) error{
+	if err := ctx.Err(); err != nil{
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rec, ok := s.posts[id]
+	if !ok || rec.trashed(){
+		return storage.ErrPostNotFound
+	}
+	if version != 0 && version != rec.post.Version{
+		return storage.ErrVersionMismatch
+	}
+	rec.deletedAt = time.Now().UTC()
+	s.touch(rec)
+
+	return nil
+}
+
+// titleTaken reports whether a post other than exceptID already uses title
+// in the section, mirroring the UNIQUE constraint on post(section_id, title).
+// Callers must hold s.mu.
+func (s *Storage) titleTaken(section, title string, exceptID int) bool{
+	for id, rec := range s.posts{
+		if id != exceptID && rec.post.Section == section && rec.post.Title == title{
+			return true
+		}
+	}
+	return false
+}
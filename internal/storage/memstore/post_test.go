@@ -0,0 +1,48 @@
+package memstore
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/RomanKovalev007/mai_news/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIDsAreNotReused(t *testing.T) {
+	ctx := context.Background()
+	s := New()
+
+	first, err := s.SavePost(ctx, models.InputPost{Title: "1"})
+	require.NoError(t, err)
+	require.NoError(t, s.DeletePost(ctx, first.ID, 0))
+
+	second, err := s.SavePost(ctx, models.InputPost{Title: "2"})
+	require.NoError(t, err)
+	assert.Greater(t, second.ID, first.ID)
+}
+
+func TestConcurrentSave(t *testing.T) {
+	ctx := context.Background()
+	s := New()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, err := s.SavePost(ctx, models.InputPost{Title: fmt.Sprintf("post %d", i)})
+			assert.NoError(t, err)
+		}(i)
+	}
+	wg.Wait()
+
+	page, err := s.GetAllPosts(ctx, models.PostFilter{}, models.Page{Limit: 100})
+	require.NoError(t, err)
+	require.Len(t, page.Posts, 50)
+	for i, post := range page.Posts {
+		assert.Equal(t, i+1, post.ID)
+	}
+}
@@ -0,0 +1,87 @@
+package memstore
+
+import (
+	"context"
+	"maps"
+
+	"github.com/RomanKovalev007/mai_news/internal/models"
+	"github.com/RomanKovalev007/mai_news/internal/storage"
+)
+
+// SetReaction makes kind the reaction of the reactor to the post, replacing
+// the reactor's previous reaction, and returns the counts after it. It fails
+// with storage.ErrPostNotFound if the post does not exist or is in the
+// trash and with storage.ErrUserNotFound if the reacting user does not
+// exist.
+func (s *Storage) SetReaction(ctx context.Context, postID int, reactor models.ReactorID, kind models.ReactionKind) (models.PostReactions, error){
+	if err := ctx.Err(); err != nil{
+		return models.PostReactions{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rec, ok := s.posts[postID]
+	if !ok || rec.trashed(){
+		return models.PostReactions{}, storage.ErrPostNotFound
+	}
+	if reactor.UserID != 0{
+		if _, ok := s.users[reactor.UserID]; !ok{
+			return models.PostReactions{}, storage.ErrUserNotFound
+		}
+	}
+
+	if rec.reactions == nil{
+		rec.reactions = make(map[string]models.ReactionKind)
+	}
+	rec.reactions[reactor.Key()] = kind
+	rec.countReactions()
+
+	return models.PostReactions{PostID: postID, Kind: kind, Reactions: maps.Clone(rec.post.Reactions)}, nil
+}
+
+// DeleteReaction takes back the reaction of the reactor to the post. It
+// fails with storage.ErrReactionNotFound unless the reactor's reaction is
+// of the given kind.
+func (s *Storage) DeleteReaction(ctx context.Context, postID int, reactor models.ReactorID, kind models.ReactionKind) error{
+	if err := ctx.Err(); err != nil{
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rec, ok := s.posts[postID]
+	if !ok || rec.reactions[reactor.Key()] != kind{
+		return storage.ErrReactionNotFound
+	}
+	delete(rec.reactions, reactor.Key())
+	rec.countReactions()
+
+	return nil
+}
+
+// dropReactions deletes the reactions of a reactor that is gone, like
+// ON DELETE CASCADE does for users. Callers must hold s.mu.
+func (s *Storage) dropReactions(reactor models.ReactorID){
+	key := reactor.Key()
+	for _, rec := range s.posts{
+		if _, ok := rec.reactions[key]; ok{
+			delete(rec.reactions, key)
+			rec.countReactions()
+		}
+	}
+}
+
+// countReactions recounts the reactions to the post. The counts go into a
+// new map, so posts handed out before keep the counts they had.
+func (r *record) countReactions(){
+	var counts map[models.ReactionKind]int
+	for _, kind := range r.reactions{
+		if counts == nil{
+			counts = make(map[models.ReactionKind]int)
+		}
+		counts[kind]++
+	}
+	r.post.Reactions = counts
+}
@@ -0,0 +1,84 @@
+package memstore
+
+import (
+	"context"
+
+	"github.com/RomanKovalev007/mai_news/internal/models"
+	"github.com/RomanKovalev007/mai_news/internal/storage"
+)
+
+func (s *Storage) GetRevisions(ctx context.Context, postID int) ([]models.Revision, error){
+	if err := ctx.Err(); err != nil{
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	rec, ok := s.posts[postID]
+	if !ok || rec.trashed(){
+		return nil, storage.ErrPostNotFound
+	}
+
+	revisions := []models.Revision{}
+	for i := len(rec.revisions) - 1; i >= 0; i--{
+		revisions = append(revisions, s.outputRevision(rec.revisions[i]))
+	}
+
+	return revisions, nil
+}
+
+func (s *Storage) GetRevision(ctx context.Context, postID, rev int) (models.Revision, error){
+	if err := ctx.Err(); err != nil{
+		return models.Revision{}, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	rec, ok := s.posts[postID]
+	if !ok || rec.trashed(){
+		return models.Revision{}, storage.ErrPostNotFound
+	}
+	if rev < 1 || rev > len(rec.revisions){
+		return models.Revision{}, storage.ErrRevisionNotFound
+	}
+
+	return s.outputRevision(rec.revisions[rev-1]), nil
+}
+
+// RevertPost restores the title and content of the given revision. The
+// version being replaced is kept as a new revision, so a revert can itself
+// be reverted.
+func (s *Storage) RevertPost(ctx context.Context, postID, rev, editorID int) (models.OutputPost, error){
+	if err := ctx.Err(); err != nil{
+		return models.OutputPost{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rec, ok := s.posts[postID]
+	if !ok || rec.trashed(){
+		return models.OutputPost{}, storage.ErrPostNotFound
+	}
+	if rev < 1 || rev > len(rec.revisions){
+		return models.OutputPost{}, storage.ErrRevisionNotFound
+	}
+
+	revision := rec.revisions[rev-1]
+	return s.patchPost(rec, models.PostPatch{Title: &revision.Title, Content: &revision.Content, EditorID: editorID})
+}
+
+// outputRevision returns revision with the current name of its editor, or
+// without an editor if the user was deleted. Callers must hold s.mu.
+func (s *Storage) outputRevision(revision models.Revision) models.Revision{
+	if revision.RevisedBy != nil{
+		user, ok := s.users[revision.RevisedBy.ID]
+		revision.RevisedBy = nil
+		if ok{
+			revision.RevisedBy = &models.Author{ID: user.ID, Name: user.Name}
+		}
+	}
+	return revision
+}
@@ -0,0 +1,26 @@
+package memstore
+
+import (
+	"context"
+	"testing"
+
+	"github.com/RomanKovalev007/mai_news/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPurgeRemovesRevisions(t *testing.T) {
+	ctx := context.Background()
+	s := New()
+
+	post, err := s.SavePost(ctx, models.InputPost{Title: "v1", Content: "first"})
+	require.NoError(t, err)
+	title := "v2"
+	_, err = s.PatchPost(ctx, post.ID, 0, models.PostPatch{Title: &title})
+	require.NoError(t, err)
+
+	require.NoError(t, s.DeletePost(ctx, post.ID, 0))
+	require.NoError(t, s.PurgePost(ctx, post.ID))
+
+	assert.Empty(t, s.posts)
+}
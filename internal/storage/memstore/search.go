@@ -0,0 +1,168 @@
+package memstore
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/RomanKovalev007/mai_news/internal/lib/search"
+	"github.com/RomanKovalev007/mai_news/internal/models"
+)
+
+const snippetWords = 24
+
+// SearchPosts returns up to limit posts matching every word of query, best
+// matches first. Each word also matches longer words starting with it.
+func (s *Storage) SearchPosts(ctx context.Context, query string, limit int) ([]models.SearchResult, error){
+	if err := ctx.Err(); err != nil{
+		return nil, err
+	}
+
+	terms := search.Terms(query)
+	if len(terms) == 0{
+		return nil, nil
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var results []models.SearchResult
+	for _, rec := range s.posts{
+		if rec.trashed(){
+			continue
+		}
+
+		title, content := splitWords(rec.post.Title), splitWords(rec.post.Content)
+		titleHits, contentHits := matchWords(title, terms), matchWords(content, terms)
+		if !allTermsFound(terms, title, content){
+			continue
+		}
+
+		results = append(results, models.SearchResult{
+			OutputPost: rec.post,
+			// title matches weigh ten times more than content matches
+			Score: float64(10*len(titleHits) + len(contentHits)),
+			TitleHighlight: highlight(rec.post.Title, title, titleHits, 0),
+			Snippet: highlight(rec.post.Content, content, contentHits, snippetWords),
+		})
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score{
+			return results[i].Score > results[j].Score
+		}
+		return results[i].ID > results[j].ID
+	})
+	if len(results) > limit{
+		results = results[:limit]
+	}
+
+	return results, nil
+}
+
+// word is a run of letters and digits in a text, with its byte offsets.
+type word struct{
+	start, end int
+	folded string
+}
+
+func splitWords(text string) []word{
+	var words []word
+	start := -1
+	for i, r := range text{
+		isWord := unicode.IsLetter(r) || unicode.IsDigit(r)
+		switch {
+		case isWord && start < 0:
+			start = i
+		case !isWord && start >= 0:
+			words = append(words, word{start: start, end: i, folded: search.Fold(text[start:i])})
+			start = -1
+		}
+	}
+	if start >= 0{
+		words = append(words, word{start: start, end: len(text), folded: search.Fold(text[start:])})
+	}
+	return words
+}
+
+// matchWords returns the indexes of words starting with any of terms.
+func matchWords(words []word, terms []string) map[int]bool{
+	hits := map[int]bool{}
+	for i, w := range words{
+		for _, term := range terms{
+			if strings.HasPrefix(w.folded, term){
+				hits[i] = true
+				break
+			}
+		}
+	}
+	return hits
+}
+
+func allTermsFound(terms []string, texts ...[]word) bool{
+	for _, term := range terms{
+		found := false
+		for _, words := range texts{
+			for _, w := range words{
+				if strings.HasPrefix(w.folded, term){
+					found = true
+					break
+				}
+			}
+		}
+		if !found{
+			return false
+		}
+	}
+	return true
+}
+
+// highlight wraps the hit words of text in <mark></mark>. With maxWords > 0
+// only a fragment of that many words around the first hit is returned.
+func highlight(text string, words []word, hits map[int]bool, maxWords int) string{
+	from, to := 0, len(words)
+	if maxWords > 0 && len(words) > maxWords{
+		first := 0
+		for i := range words{
+			if hits[i]{
+				first = i
+				break
+			}
+		}
+		from = max(0, first-maxWords/3)
+		to = min(len(words), from+maxWords)
+		from = max(0, to-maxWords)
+	}
+	if from == to{
+		return text
+	}
+
+	var b strings.Builder
+	start, end := 0, len(text)
+	if from > 0{
+		b.WriteString("…")
+		start = words[from].start
+	}
+	if to < len(words){
+		end = words[to-1].end
+	}
+
+	pos := start
+	for i := from; i < to; i++{
+		if !hits[i]{
+			continue
+		}
+		b.WriteString(text[pos:words[i].start])
+		b.WriteString("<mark>")
+		b.WriteString(text[words[i].start:words[i].end])
+		b.WriteString("</mark>")
+		pos = words[i].end
+	}
+	b.WriteString(text[pos:end])
+	if to < len(words){
+		b.WriteString("…")
+	}
+
+	return b.String()
+}
@@ -0,0 +1,117 @@
+package memstore
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/RomanKovalev007/mai_news/internal/models"
+	"github.com/RomanKovalev007/mai_news/internal/storage"
+)
+
+// GetSections returns every section, ordered by title.
+func (s *Storage) GetSections(ctx context.Context) ([]models.Section, error){
+	if err := ctx.Err(); err != nil{
+		return []models.Section{}, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var result []models.Section
+	for _, section := range s.sections{
+		result = append(result, cloneSection(section))
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Title != result[j].Title{
+			return result[i].Title < result[j].Title
+		}
+		return result[i].ID < result[j].ID
+	})
+
+	return result, nil
+}
+
+func (s *Storage) GetSection(ctx context.Context, slug string) (models.Section, error){
+	if err := ctx.Err(); err != nil{
+		return models.Section{}, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	section, ok := s.sections[slug]
+	if !ok{
+		return models.Section{}, storage.ErrSectionNotFound
+	}
+
+	return cloneSection(section), nil
+}
+
+// SaveSection creates a section. It fails with storage.ErrSectionExists if
+// the slug is in use.
+func (s *Storage) SaveSection(ctx context.Context, input models.InputSection) (models.Section, error){
+	if err := ctx.Err(); err != nil{
+		return models.Section{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.sections[input.Slug]; ok{
+		return models.Section{}, storage.ErrSectionExists
+	}
+
+	s.lastSectionID++
+	section := &models.Section{
+		ID: s.lastSectionID,
+		Slug: input.Slug,
+		Title: input.Title,
+		Description: input.Description,
+		Editors: editorSet(input.Editors),
+		CreatedAt: time.Now().UTC(),
+	}
+	s.sections[section.Slug] = section
+
+	return cloneSection(section), nil
+}
+
+// PatchSection updates the fields set in patch. Editors, if set, replace
+// the editors of the section.
+func (s *Storage) PatchSection(ctx context.Context, slug string, patch models.SectionPatch) (models.Section, error){
+	if err := ctx.Err(); err != nil{
+		return models.Section{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	section, ok := s.sections[slug]
+	if !ok{
+		return models.Section{}, storage.ErrSectionNotFound
+	}
+	if patch.Title != nil{
+		section.Title = *patch.Title
+	}
+	if patch.Description != nil{
+		section.Description = *patch.Description
+	}
+	if patch.Editors != nil{
+		section.Editors = editorSet(*patch.Editors)
+	}
+
+	return cloneSection(section), nil
+}
+
+// editorSet sorts and deduplicates emails, like the primary key and the
+// ordering of section_editors do.
+func editorSet(emails []string) []string{
+	return slices.Compact(slices.Sorted(slices.Values(emails)))
+}
+
+func cloneSection(section *models.Section) models.Section{
+	clone := *section
+	clone.Editors = slices.Clone(section.Editors)
+	return clone
+}
@@ -0,0 +1,100 @@
+package memstore
+
+import (
+	"context"
+
+	"github.com/RomanKovalev007/mai_news/internal/lib/slug"
+	"github.com/RomanKovalev007/mai_news/internal/models"
+	"github.com/RomanKovalev007/mai_news/internal/storage"
+)
+
+// GetPostBySlug returns the post addressed by slug, now or formerly. A post
+// found by a former slug has a different Slug, which callers redirect to.
+func (s *Storage) GetPostBySlug(ctx context.Context, slug string) (models.OutputPost, error){
+	if err := ctx.Err(); err != nil{
+		return models.OutputPost{}, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, rec := range s.posts{
+		if !rec.trashed() && rec.hasSlug(slug){
+			return rec.post, nil
+		}
+	}
+	return models.OutputPost{}, storage.ErrPostNotFound
+}
+
+// hasSlug reports whether slug is the current or a former slug of r.
+func (r *record) hasSlug(slug string) bool{
+	if r.post.Slug == slug{
+		return true
+	}
+	for _, former := range r.formerSlugs{
+		if former == slug{
+			return true
+		}
+	}
+	return false
+}
+
+// newSlug returns the slug for a post with the given title: requested if it
+// is set and free, otherwise the first free candidate made from title. Slugs
+// of exceptID itself, current or former, count as free. Callers must hold
+// s.mu.
+func (s *Storage) newSlug(requested, title string, exceptID int) (string, error){
+	if requested != ""{
+		if s.slugTaken(requested, exceptID){
+			return "", storage.ErrSlugTaken
+		}
+		return requested, nil
+	}
+
+	base := slug.Make(title)
+	for n := 1; ; n++{
+		if candidate := slug.WithSuffix(base, n); !s.slugTaken(candidate, exceptID){
+			return candidate, nil
+		}
+	}
+}
+
+// slugTaken reports whether a post other than exceptID uses slug now or
+// used it before. Callers must hold s.mu.
+func (s *Storage) slugTaken(slug string, exceptID int) bool{
+	for id, rec := range s.posts{
+		if id != exceptID && rec.hasSlug(slug){
+			return true
+		}
+	}
+	return false
+}
+
+// patchSlug works out the slug of rec after patch. It returns "" when the
+// slug stays as it is. Callers must hold s.mu.
+func (s *Storage) patchSlug(rec *record, patch models.PostPatch) (string, error){
+	next := rec.post.Slug
+	var err error
+	switch {
+	case patch.Slug != nil:
+		next, err = s.newSlug(*patch.Slug, "", rec.post.ID)
+	case patch.Title != nil && *patch.Title != rec.post.Title:
+		next, err = s.newSlug("", *patch.Title, rec.post.ID)
+	}
+	if err != nil || next == rec.post.Slug{
+		return "", err
+	}
+	return next, nil
+}
+
+// setSlug gives rec a new slug and keeps the current one as a former slug.
+func (r *record) setSlug(next string){
+	formers := r.formerSlugs[:0]
+	for _, former := range r.formerSlugs{
+		if former != next{
+			formers = append(formers, former)
+		}
+	}
+	r.formerSlugs = append(formers, r.post.Slug)
+	r.post.Slug = next
+}
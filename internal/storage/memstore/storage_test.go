@@ -0,0 +1,13 @@
+package memstore
+
+import (
+	"testing"
+
+	"github.com/RomanKovalev007/mai_news/internal/storage/storagetest"
+)
+
+func TestStorage(t *testing.T) {
+	storagetest.Run(t, func(t *testing.T) storagetest.Storage {
+		return New()
+	})
+}
@@ -0,0 +1,130 @@
+package memstore
+
+import (
+	"context"
+	"slices"
+	"sort"
+
+	"github.com/RomanKovalev007/mai_news/internal/lib/tags"
+	"github.com/RomanKovalev007/mai_news/internal/models"
+	"github.com/RomanKovalev007/mai_news/internal/storage"
+)
+
+// Tags live only on the posts that carry them: a tag exists while some
+// post, trashed or not, does.
+
+// GetTags returns the tags in use, most used first.
+func (s *Storage) GetTags(ctx context.Context) ([]models.Tag, error){
+	if err := ctx.Err(); err != nil{
+		return []models.Tag{}, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	counts := make(map[string]int)
+	for _, rec := range s.posts{
+		for _, name := range rec.post.Tags{
+			// tags of trashed posts are listed, but not counted
+			n := counts[name]
+			if !rec.trashed(){
+				n++
+			}
+			counts[name] = n
+		}
+	}
+
+	var result []models.Tag
+	for name, count := range counts{
+		result = append(result, models.Tag{Name: name, Count: count})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Count != result[j].Count{
+			return result[i].Count > result[j].Count
+		}
+		return result[i].Name < result[j].Name
+	})
+
+	return result, nil
+}
+
+// RenameTag renames the tag on every post that carries it. It fails with
+// storage.ErrTagExists if newName is in use; MergeTags joins two tags.
+func (s *Storage) RenameTag(ctx context.Context, name, newName string) (models.Tag, error){
+	if err := ctx.Err(); err != nil{
+		return models.Tag{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.tagUsed(name){
+		return models.Tag{}, storage.ErrTagNotFound
+	}
+	if newName != name{
+		if s.tagUsed(newName){
+			return models.Tag{}, storage.ErrTagExists
+		}
+		s.retag(name, newName)
+	}
+
+	return s.tag(newName), nil
+}
+
+// MergeTags replaces the tag from with the tag into on every post and
+// removes from.
+func (s *Storage) MergeTags(ctx context.Context, from, into string) (models.Tag, error){
+	if err := ctx.Err(); err != nil{
+		return models.Tag{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.tagUsed(from) || !s.tagUsed(into){
+		return models.Tag{}, storage.ErrTagNotFound
+	}
+	if from != into{
+		s.retag(from, into)
+	}
+
+	return s.tag(into), nil
+}
+
+// retag replaces the tag from with to on the posts carrying it, which is a
+// change of each. Callers must hold s.mu.
+func (s *Storage) retag(from, to string){
+	for _, rec := range s.posts{
+		i := slices.Index(rec.post.Tags, from)
+		if i < 0{
+			continue
+		}
+		// the slice may be shared with posts returned earlier
+		names := slices.Clone(rec.post.Tags)
+		names[i] = to
+		rec.post.Tags = tags.Set(names)
+		s.touch(rec)
+	}
+}
+
+// tagUsed reports whether some post carries the tag. Callers must hold s.mu.
+func (s *Storage) tagUsed(name string) bool{
+	for _, rec := range s.posts{
+		if slices.Contains(rec.post.Tags, name){
+			return true
+		}
+	}
+	return false
+}
+
+// tag counts the posts outside the trash carrying the tag. Callers must
+// hold s.mu.
+func (s *Storage) tag(name string) models.Tag{
+	tag := models.Tag{Name: name}
+	for _, rec := range s.posts{
+		if !rec.trashed() && slices.Contains(rec.post.Tags, name){
+			tag.Count++
+		}
+	}
+	return tag
+}
@@ -0,0 +1,45 @@
+package memstore
+
+import (
+	"context"
+	"time"
+
+	"github.com/RomanKovalev007/mai_news/internal/storage"
+)
+
+// RevokeToken records the token id as revoked until expiresAt, after which
+// the token is rejected anyway. It fails with storage.ErrTokenRevoked if the
+// token was revoked before. Revocations past their expiry are dropped.
+func (s *Storage) RevokeToken(ctx context.Context, id string, expiresAt time.Time) error{
+	if err := ctx.Err(); err != nil{
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for revokedID, expiry := range s.revokedTokens{
+		if expiry.Before(now){
+			delete(s.revokedTokens, revokedID)
+		}
+	}
+	if _, ok := s.revokedTokens[id]; ok{
+		return storage.ErrTokenRevoked
+	}
+	s.revokedTokens[id] = expiresAt
+
+	return nil
+}
+
+func (s *Storage) TokenRevoked(ctx context.Context, id string) (bool, error){
+	if err := ctx.Err(); err != nil{
+		return false, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	_, ok := s.revokedTokens[id]
+	return ok, nil
+}
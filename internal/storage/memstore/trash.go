@@ -0,0 +1,112 @@
+package memstore
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/RomanKovalev007/mai_news/internal/models"
+	"github.com/RomanKovalev007/mai_news/internal/storage"
+)
+
+func (s *Storage) GetTrash(ctx context.Context) ([]models.OutputPost, error){
+	if err := ctx.Err(); err != nil{
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var trashed []*record
+	for _, rec := range s.posts{
+		if rec.trashed(){
+			trashed = append(trashed, rec)
+		}
+	}
+	sort.Slice(trashed, func(i, j int) bool { return trashed[i].deletedAt.After(trashed[j].deletedAt) })
+
+	posts := []models.OutputPost{}
+	for _, rec := range trashed{
+		post := rec.post
+		post.DeletedAt = rec.deletedAt
+		posts = append(posts, post)
+	}
+
+	return posts, nil
+}
+
+// GetTrashedPost returns a post in the trash. It fails with
+// storage.ErrPostNotFound for posts that are not there.
+func (s *Storage) GetTrashedPost(ctx context.Context, id int) (models.OutputPost, error){
+	if err := ctx.Err(); err != nil{
+		return models.OutputPost{}, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	rec, ok := s.posts[id]
+	if !ok || !rec.trashed(){
+		return models.OutputPost{}, storage.ErrPostNotFound
+	}
+	post := rec.post
+	post.DeletedAt = rec.deletedAt
+
+	return post, nil
+}
+
+func (s *Storage) RestorePost(ctx context.Context, id int) (models.OutputPost, error){
+	if err := ctx.Err(); err != nil{
+		return models.OutputPost{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rec, ok := s.posts[id]
+	if !ok || !rec.trashed(){
+		return models.OutputPost{}, storage.ErrPostNotFound
+	}
+	rec.deletedAt = time.Time{}
+	s.touch(rec)
+
+	return rec.post, nil
+}
+
+func (s *Storage) PurgePost(ctx context.Context, id int) error{
+	if err := ctx.Err(); err != nil{
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rec, ok := s.posts[id]
+	if !ok || !rec.trashed(){
+		return storage.ErrPostNotFound
+	}
+	delete(s.posts, id)
+	s.dropComments(id)
+
+	return nil
+}
+
+func (s *Storage) PurgeTrash(ctx context.Context, deletedBefore time.Time) (int, error){
+	if err := ctx.Err(); err != nil{
+		return 0, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	purged := 0
+	for id, rec := range s.posts{
+		if rec.trashed() && rec.deletedAt.Before(deletedBefore){
+			delete(s.posts, id)
+			s.dropComments(id)
+			purged++
+		}
+	}
+
+	return purged, nil
+}
@@ -0,0 +1,175 @@
+package memstore
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/RomanKovalev007/mai_news/internal/models"
+	"github.com/RomanKovalev007/mai_news/internal/storage"
+)
+
+// GetUsers returns every user, oldest first.
+func (s *Storage) GetUsers(ctx context.Context) ([]models.User, error){
+	if err := ctx.Err(); err != nil{
+		return []models.User{}, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var result []models.User
+	for _, user := range s.users{
+		result = append(result, *user)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ID < result[j].ID })
+
+	return result, nil
+}
+
+func (s *Storage) GetUser(ctx context.Context, id int) (models.User, error){
+	if err := ctx.Err(); err != nil{
+		return models.User{}, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	user, ok := s.users[id]
+	if !ok{
+		return models.User{}, storage.ErrUserNotFound
+	}
+
+	return *user, nil
+}
+
+// GetUserByEmail returns the user with the given email, which is expected
+// lowercased.
+func (s *Storage) GetUserByEmail(ctx context.Context, email string) (models.User, error){
+	if err := ctx.Err(); err != nil{
+		return models.User{}, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	user := s.userByEmail(email)
+	if user == nil{
+		return models.User{}, storage.ErrUserNotFound
+	}
+
+	return *user, nil
+}
+
+// SaveUser creates a user, a reader unless input sets the role. It fails
+// with storage.ErrUserExists if the email is in use.
+func (s *Storage) SaveUser(ctx context.Context, input models.InputUser) (models.User, error){
+	if err := ctx.Err(); err != nil{
+		return models.User{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.userByEmail(input.Email) != nil{
+		return models.User{}, storage.ErrUserExists
+	}
+
+	s.lastUserID++
+	user := &models.User{
+		ID: s.lastUserID,
+		Name: input.Name,
+		Email: input.Email,
+		PasswordHash: input.PasswordHash,
+		Role: input.Role,
+		CreatedAt: time.Now().UTC(),
+	}
+	if user.Role == ""{
+		user.Role = models.RoleReader
+	}
+	s.users[user.ID] = user
+
+	return *user, nil
+}
+
+// PatchUser updates the fields set in patch. A new name is a change of the
+// posts of the user, which show it.
+func (s *Storage) PatchUser(ctx context.Context, id int, patch models.UserPatch) (models.User, error){
+	if err := ctx.Err(); err != nil{
+		return models.User{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	user, ok := s.users[id]
+	if !ok{
+		return models.User{}, storage.ErrUserNotFound
+	}
+	if patch.Email != nil{
+		if other := s.userByEmail(*patch.Email); other != nil && other.ID != id{
+			return models.User{}, storage.ErrUserExists
+		}
+		user.Email = *patch.Email
+	}
+	if patch.PasswordHash != nil{
+		user.PasswordHash = *patch.PasswordHash
+	}
+	if patch.Role != nil{
+		user.Role = *patch.Role
+	}
+	if patch.Name != nil && *patch.Name != user.Name{
+		user.Name = *patch.Name
+		s.setAuthor(id, &models.Author{ID: id, Name: user.Name})
+	}
+
+	return *user, nil
+}
+
+// DeleteUser deletes the user and their API keys. Their posts stay, without
+// an author.
+func (s *Storage) DeleteUser(ctx context.Context, id int) error{
+	if err := ctx.Err(); err != nil{
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.users[id]; !ok{
+		return storage.ErrUserNotFound
+	}
+	delete(s.users, id)
+	s.setAuthor(id, nil)
+	s.dropReactions(models.ReactorID{UserID: id})
+	for keyID, key := range s.apiKeys{
+		if key.UserID == id{
+			delete(s.apiKeys, keyID)
+		}
+	}
+
+	return nil
+}
+
+// userByEmail returns the user with the given email, or nil. Callers must
+// hold s.mu.
+func (s *Storage) userByEmail(email string) *models.User{
+	for _, user := range s.users{
+		if user.Email == email{
+			return user
+		}
+	}
+	return nil
+}
+
+// setAuthor replaces the author of the posts of the user, trashed ones
+// included, like ON DELETE SET NULL does. Posts handed out before keep the
+// Author they had. Callers must hold s.mu.
+func (s *Storage) setAuthor(userID int, author *models.Author){
+	for _, rec := range s.posts{
+		if rec.post.Author != nil && rec.post.Author.ID == userID{
+			rec.post.Author = author
+			s.touch(rec)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package memstore
+
+import (
+	"context"
+	"sort"
+
+	"github.com/RomanKovalev007/mai_news/internal/models"
+	"github.com/RomanKovalev007/mai_news/internal/storage"
+)
+
+// AddViews adds the counts to the per-day views of the posts. Counts of
+// posts that no longer exist are dropped.
+func (s *Storage) AddViews(ctx context.Context, counts []models.ViewCount) error{
+	if err := ctx.Err(); err != nil{
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, count := range counts{
+		rec, ok := s.posts[count.PostID]
+		if !ok{
+			continue
+		}
+		if rec.views == nil{
+			rec.views = make(map[string]int)
+		}
+		rec.views[count.Day] += count.Views
+	}
+
+	return nil
+}
+
+// GetPostStats returns the views of the post by day. It fails with
+// storage.ErrPostNotFound if the post does not exist or is in the trash.
+func (s *Storage) GetPostStats(ctx context.Context, postID int) (models.PostStats, error){
+	if err := ctx.Err(); err != nil{
+		return models.PostStats{}, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	rec, ok := s.posts[postID]
+	if !ok || rec.trashed(){
+		return models.PostStats{}, storage.ErrPostNotFound
+	}
+
+	stats := models.PostStats{PostID: postID, Days: []models.DayViews{}}
+	for day, views := range rec.views{
+		stats.Views += views
+		stats.Days = append(stats.Days, models.DayViews{Day: day, Views: views})
+	}
+	sort.Slice(stats.Days, func(i, j int) bool { return stats.Days[i].Day < stats.Days[j].Day })
+
+	return stats, nil
+}
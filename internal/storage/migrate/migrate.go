@@ -0,0 +1,396 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrLocked       = errors.New("migrations are locked by another process")
+	ErrNoMigrations = errors.New("no migrations to apply")
+)
+
+// Migration is a single numbered schema change. Files are named
+// NNNN_name.up.sql and NNNN_name.down.sql.
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Status describes a migration and whether it has been applied.
+type Status struct {
+	Version   int
+	Name      string
+	Applied   bool
+	AppliedAt time.Time
+}
+
+// Dialect selects the bind parameter syntax used for the bookkeeping queries.
+type Dialect int
+
+const (
+	SQLite Dialect = iota
+	Postgres
+)
+
+// rebind rewrites ? placeholders into the dialect's syntax.
+func (d Dialect) rebind(query string) string {
+	if d != Postgres {
+		return query
+	}
+
+	var b strings.Builder
+	n := 0
+	for _, r := range query {
+		if r == '?' {
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+type Migrator struct {
+	db         *sql.DB
+	dialect    Dialect
+	migrations []Migration
+}
+
+// New loads all *.sql migrations from the root of fsys.
+func New(db *sql.DB, dialect Dialect, fsys fs.FS) (*Migrator, error) {
+	const fn = "storage.migrate.New"
+
+	migrations, err := load(fsys)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", fn, err)
+	}
+
+	return &Migrator{db: db, dialect: dialect, migrations: migrations}, nil
+}
+
+func load(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("read dir: %w", err)
+	}
+
+	byVersion := map[int]*Migration{}
+	for _, e := range entries {
+		if e.IsDir() || path.Ext(e.Name()) != ".sql" {
+			continue
+		}
+
+		name := strings.TrimSuffix(e.Name(), ".sql")
+		var direction string
+		switch {
+		case strings.HasSuffix(name, ".up"):
+			direction = "up"
+		case strings.HasSuffix(name, ".down"):
+			direction = "down"
+		default:
+			return nil, fmt.Errorf("migration %s: missing .up or .down suffix", e.Name())
+		}
+		name = strings.TrimSuffix(name, "."+direction)
+
+		num, title, ok := strings.Cut(name, "_")
+		if !ok {
+			return nil, fmt.Errorf("migration %s: expected NNNN_name", e.Name())
+		}
+		version, err := strconv.Atoi(num)
+		if err != nil || version <= 0 {
+			return nil, fmt.Errorf("migration %s: invalid version %q", e.Name(), num)
+		}
+
+		data, err := fs.ReadFile(fsys, e.Name())
+		if err != nil {
+			return nil, fmt.Errorf("migration %s: %w", e.Name(), err)
+		}
+
+		m, exists := byVersion[version]
+		if !exists {
+			m = &Migration{Version: version, Name: title}
+			byVersion[version] = m
+		}
+		if m.Name != title {
+			return nil, fmt.Errorf("migration %d: conflicting names %q and %q", version, m.Name, title)
+		}
+		if direction == "up" {
+			m.Up = string(data)
+		} else {
+			m.Down = string(data)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" {
+			return nil, fmt.Errorf("migration %d_%s: missing up script", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+
+	return migrations, nil
+}
+
+func (m *Migrator) init() error {
+	_, err := m.db.Exec(`
+	CREATE TABLE IF NOT EXISTS schema_migrations(
+		version INTEGER PRIMARY KEY,
+		name TEXT NOT NULL,
+		applied_at TIMESTAMP NOT NULL);
+	CREATE TABLE IF NOT EXISTS schema_migrations_lock(
+		id INTEGER PRIMARY KEY,
+		locked_at TIMESTAMP NOT NULL);
+	`)
+	return err
+}
+
+func (m *Migrator) lock() error {
+	_, err := m.db.Exec(m.dialect.rebind("INSERT INTO schema_migrations_lock(id, locked_at) VALUES(1, ?)"), time.Now().UTC())
+	if err != nil {
+		var held int
+		if m.db.QueryRow("SELECT COUNT(*) FROM schema_migrations_lock").Scan(&held) == nil && held > 0 {
+			return ErrLocked
+		}
+		return err
+	}
+	return nil
+}
+
+func (m *Migrator) unlock() error {
+	_, err := m.db.Exec("DELETE FROM schema_migrations_lock WHERE id = 1")
+	return err
+}
+
+// ForceUnlock removes a lock left behind by a migration process that crashed.
+func (m *Migrator) ForceUnlock() error {
+	const fn = "storage.migrate.ForceUnlock"
+
+	if err := m.init(); err != nil {
+		return fmt.Errorf("%s: init: %w", fn, err)
+	}
+	if err := m.unlock(); err != nil {
+		return fmt.Errorf("%s: %w", fn, err)
+	}
+	return nil
+}
+
+func (m *Migrator) applied() (map[int]time.Time, error) {
+	rows, err := m.db.Query("SELECT version, applied_at FROM schema_migrations")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := map[int]time.Time{}
+	for rows.Next() {
+		var version int
+		var at time.Time
+		if err := rows.Scan(&version, &at); err != nil {
+			return nil, err
+		}
+		applied[version] = at
+	}
+	return applied, rows.Err()
+}
+
+// withLock prepares the bookkeeping tables and runs f while holding the
+// migrations lock.
+func (m *Migrator) withLock(f func(applied map[int]time.Time) error) (err error) {
+	if err := m.init(); err != nil {
+		return fmt.Errorf("init: %w", err)
+	}
+	if err := m.lock(); err != nil {
+		return fmt.Errorf("lock: %w", err)
+	}
+	defer func() {
+		if uerr := m.unlock(); uerr != nil && err == nil {
+			err = fmt.Errorf("unlock: %w", uerr)
+		}
+	}()
+
+	applied, err := m.applied()
+	if err != nil {
+		return fmt.Errorf("read applied: %w", err)
+	}
+	return f(applied)
+}
+
+// Up applies every pending migration in version order and returns how many
+// were applied.
+func (m *Migrator) Up() (int, error) {
+	const fn = "storage.migrate.Up"
+
+	count := 0
+	err := m.withLock(func(applied map[int]time.Time) error {
+		for _, mig := range m.migrations {
+			if _, ok := applied[mig.Version]; ok {
+				continue
+			}
+			if err := m.apply(mig, mig.Up, true); err != nil {
+				return err
+			}
+			count++
+		}
+		return nil
+	})
+	if err != nil {
+		return count, fmt.Errorf("%s: %w", fn, err)
+	}
+
+	return count, nil
+}
+
+// Down rolls back the last steps applied migrations.
+func (m *Migrator) Down(steps int) (int, error) {
+	const fn = "storage.migrate.Down"
+
+	count := 0
+	err := m.withLock(func(applied map[int]time.Time) error {
+		for i := len(m.migrations) - 1; i >= 0 && count < steps; i-- {
+			mig := m.migrations[i]
+			if _, ok := applied[mig.Version]; !ok {
+				continue
+			}
+			if mig.Down == "" {
+				return fmt.Errorf("migration %d_%s: missing down script", mig.Version, mig.Name)
+			}
+			if err := m.apply(mig, mig.Down, false); err != nil {
+				return err
+			}
+			count++
+		}
+		if count == 0 {
+			return ErrNoMigrations
+		}
+		return nil
+	})
+	if err != nil {
+		return count, fmt.Errorf("%s: %w", fn, err)
+	}
+
+	return count, nil
+}
+
+func (m *Migrator) apply(mig Migration, script string, up bool) error {
+	ctx := context.Background()
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("migration %d_%s: get connection: %w", mig.Version, mig.Name, err)
+	}
+	defer conn.Close()
+
+	if m.dialect == SQLite {
+		restore, err := disableForeignKeys(ctx, conn)
+		if err != nil {
+			return fmt.Errorf("migration %d_%s: %w", mig.Version, mig.Name, err)
+		}
+		defer restore()
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("migration %d_%s: begin: %w", mig.Version, mig.Name, err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(script); err != nil {
+		return fmt.Errorf("migration %d_%s: %w", mig.Version, mig.Name, err)
+	}
+
+	if m.dialect == SQLite {
+		if err := checkForeignKeys(tx); err != nil {
+			return fmt.Errorf("migration %d_%s: %w", mig.Version, mig.Name, err)
+		}
+	}
+
+	if up {
+		_, err = tx.Exec(m.dialect.rebind("INSERT INTO schema_migrations(version, name, applied_at) VALUES(?, ?, ?)"),
+			mig.Version, mig.Name, time.Now().UTC())
+	} else {
+		_, err = tx.Exec(m.dialect.rebind("DELETE FROM schema_migrations WHERE version = ?"), mig.Version)
+	}
+	if err != nil {
+		return fmt.Errorf("migration %d_%s: record version: %w", mig.Version, mig.Name, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("migration %d_%s: commit: %w", mig.Version, mig.Name, err)
+	}
+	return nil
+}
+
+// disableForeignKeys turns off foreign key enforcement on conn until restore
+// is called. SQLite changes most of a table only by copying it into a new
+// one, and dropping the old table must neither cascade into the rows that
+// reference it nor rewrite their references. The setting cannot change
+// inside a transaction, so it is done before the script's.
+func disableForeignKeys(ctx context.Context, conn *sql.Conn) (restore func(), err error) {
+	var enabled bool
+	if err := conn.QueryRowContext(ctx, "PRAGMA foreign_keys").Scan(&enabled); err != nil {
+		return nil, fmt.Errorf("read foreign_keys: %w", err)
+	}
+	if !enabled {
+		return func() {}, nil
+	}
+	if _, err := conn.ExecContext(ctx, "PRAGMA foreign_keys = OFF"); err != nil {
+		return nil, fmt.Errorf("disable foreign keys: %w", err)
+	}
+	return func() { conn.ExecContext(ctx, "PRAGMA foreign_keys = ON") }, nil
+}
+
+// checkForeignKeys fails if a script left rows referencing missing ones,
+// which SQLite did not prevent while foreign keys were off.
+func checkForeignKeys(tx *sql.Tx) error {
+	rows, err := tx.Query("PRAGMA foreign_key_check")
+	if err != nil {
+		return fmt.Errorf("check foreign keys: %w", err)
+	}
+	defer rows.Close()
+
+	if rows.Next() {
+		var table, parent string
+		var rowid sql.NullInt64
+		var fkid int
+		if err := rows.Scan(&table, &rowid, &parent, &fkid); err != nil {
+			return fmt.Errorf("check foreign keys: %w", err)
+		}
+		return fmt.Errorf("row %d of %s references a missing row of %s", rowid.Int64, table, parent)
+	}
+	return rows.Err()
+}
+
+// Status reports every known migration and whether it has been applied.
+func (m *Migrator) Status() ([]Status, error) {
+	const fn = "storage.migrate.Status"
+
+	if err := m.init(); err != nil {
+		return nil, fmt.Errorf("%s: init: %w", fn, err)
+	}
+
+	applied, err := m.applied()
+	if err != nil {
+		return nil, fmt.Errorf("%s: read applied: %w", fn, err)
+	}
+
+	statuses := make([]Status, 0, len(m.migrations))
+	for _, mig := range m.migrations {
+		at, ok := applied[mig.Version]
+		statuses = append(statuses, Status{Version: mig.Version, Name: mig.Name, Applied: ok, AppliedAt: at})
+	}
+
+	return statuses, nil
+}
@@ -0,0 +1,179 @@
+package migrate
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestDB(t *testing.T) *sql.DB {
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "test.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+var testMigrations = fstest.MapFS{
+	"0001_create_a.up.sql":   {Data: []byte("CREATE TABLE a(id INTEGER PRIMARY KEY);")},
+	"0001_create_a.down.sql": {Data: []byte("DROP TABLE a;")},
+	"0002_create_b.up.sql":   {Data: []byte("CREATE TABLE b(id INTEGER PRIMARY KEY);")},
+	"0002_create_b.down.sql": {Data: []byte("DROP TABLE b;")},
+}
+
+func TestUpDownStatus(t *testing.T) {
+	db := newTestDB(t)
+	m, err := New(db, SQLite, testMigrations)
+	require.NoError(t, err)
+
+	n, err := m.Up()
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+
+	n, err = m.Up()
+	require.NoError(t, err)
+	assert.Equal(t, 0, n)
+
+	statuses, err := m.Status()
+	require.NoError(t, err)
+	require.Len(t, statuses, 2)
+	assert.True(t, statuses[0].Applied)
+	assert.True(t, statuses[1].Applied)
+
+	n, err = m.Down(1)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	_, err = db.Exec("SELECT id FROM b")
+	assert.Error(t, err)
+	_, err = db.Exec("SELECT id FROM a")
+	assert.NoError(t, err)
+
+	statuses, err = m.Status()
+	require.NoError(t, err)
+	assert.True(t, statuses[0].Applied)
+	assert.False(t, statuses[1].Applied)
+}
+
+func TestDownNothingApplied(t *testing.T) {
+	m, err := New(newTestDB(t), SQLite, testMigrations)
+	require.NoError(t, err)
+
+	_, err = m.Down(1)
+	assert.ErrorIs(t, err, ErrNoMigrations)
+}
+
+func TestFailedMigrationIsRolledBack(t *testing.T) {
+	db := newTestDB(t)
+	m, err := New(db, SQLite, fstest.MapFS{
+		"0001_ok.up.sql":     {Data: []byte("CREATE TABLE a(id INTEGER PRIMARY KEY);")},
+		"0002_broken.up.sql": {Data: []byte("CREATE TABLE b(id INTEGER PRIMARY KEY); NOT SQL;")},
+	})
+	require.NoError(t, err)
+
+	n, err := m.Up()
+	assert.Error(t, err)
+	assert.Equal(t, 1, n)
+
+	_, err = db.Exec("SELECT id FROM b")
+	assert.Error(t, err)
+
+	// the lock must be released after a failure
+	_, err = m.Up()
+	assert.NotErrorIs(t, err, ErrLocked)
+}
+
+func TestRebuildKeepsReferencingRows(t *testing.T) {
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "test.db")+"?_foreign_keys=on")
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	m, err := New(db, SQLite, fstest.MapFS{
+		"0001_create.up.sql": {Data: []byte(`
+		CREATE TABLE a(id INTEGER PRIMARY KEY, name TEXT NOT NULL UNIQUE);
+		CREATE TABLE b(a_id INTEGER NOT NULL REFERENCES a(id) ON DELETE CASCADE);
+		INSERT INTO a(id, name) VALUES(1, 'x');
+		INSERT INTO b(a_id) VALUES(1);`)},
+		"0002_rebuild.up.sql": {Data: []byte(`
+		CREATE TABLE a_new(id INTEGER PRIMARY KEY, name TEXT NOT NULL);
+		INSERT INTO a_new(id, name) SELECT id, name FROM a;
+		DROP TABLE a;
+		ALTER TABLE a_new RENAME TO a;`)},
+	})
+	require.NoError(t, err)
+
+	_, err = m.Up()
+	require.NoError(t, err)
+
+	var n int
+	require.NoError(t, db.QueryRow("SELECT COUNT(*) FROM b").Scan(&n))
+	assert.Equal(t, 1, n)
+
+	// enforcement is back on for the connections used afterwards
+	_, err = db.Exec("INSERT INTO b(a_id) VALUES(2)")
+	assert.Error(t, err)
+}
+
+func TestDanglingReferenceFailsMigration(t *testing.T) {
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "test.db")+"?_foreign_keys=on")
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	m, err := New(db, SQLite, fstest.MapFS{
+		"0001_create.up.sql": {Data: []byte(`
+		CREATE TABLE a(id INTEGER PRIMARY KEY);
+		CREATE TABLE b(a_id INTEGER NOT NULL REFERENCES a(id));
+		INSERT INTO a(id) VALUES(1);
+		INSERT INTO b(a_id) VALUES(1);`)},
+		"0002_drop.up.sql": {Data: []byte("DELETE FROM a;")},
+	})
+	require.NoError(t, err)
+
+	n, err := m.Up()
+	assert.Error(t, err)
+	assert.Equal(t, 1, n)
+
+	var count int
+	require.NoError(t, db.QueryRow("SELECT COUNT(*) FROM a").Scan(&count))
+	assert.Equal(t, 1, count)
+}
+
+func TestLocked(t *testing.T) {
+	db := newTestDB(t)
+	m, err := New(db, SQLite, testMigrations)
+	require.NoError(t, err)
+
+	require.NoError(t, m.init())
+	require.NoError(t, m.lock())
+
+	_, err = m.Up()
+	assert.ErrorIs(t, err, ErrLocked)
+
+	require.NoError(t, m.ForceUnlock())
+	_, err = m.Up()
+	assert.NoError(t, err)
+}
+
+func TestLoadInvalidNames(t *testing.T) {
+	for name, fsys := range map[string]fstest.MapFS{
+		"no direction": {"0001_a.sql": {Data: []byte("SELECT 1;")}},
+		"no version":   {"a.up.sql": {Data: []byte("SELECT 1;")}},
+		"bad version":  {"x_a.up.sql": {Data: []byte("SELECT 1;")}},
+		"down only":    {"0001_a.down.sql": {Data: []byte("SELECT 1;")}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := New(newTestDB(t), SQLite, fsys)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestRebind(t *testing.T) {
+	q := "INSERT INTO t(a, b) VALUES(?, ?)"
+	assert.Equal(t, q, SQLite.rebind(q))
+	assert.Equal(t, "INSERT INTO t(a, b) VALUES($1, $2)", Postgres.rebind(q))
+}
@@ -0,0 +1,155 @@
+package pgstore
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/RomanKovalev007/mai_news/internal/models"
+	"github.com/RomanKovalev007/mai_news/internal/storage"
+	"github.com/lib/pq"
+)
+
+// apiKeyColumns are the columns scanAPIKey reads, in its order.
+const apiKeyColumns = "id, name, prefix, key_hash, scopes, user_id, created_at, last_used_at"
+
+// GetAPIKeys returns every API key, oldest first.
+func (s *Storage) GetAPIKeys(ctx context.Context) ([]models.APIKey, error){
+	op := "storage.pgstore.GetAPIKeys"
+
+	rows, err := s.db.QueryContext(ctx, "SELECT " + apiKeyColumns + " FROM api_keys ORDER BY id")
+	if err != nil{
+		return []models.APIKey{}, fmt.Errorf("%s: failed to get api keys: %w", op, err)
+	}
+	defer rows.Close()
+
+	var keys []models.APIKey
+
+	for rows.Next(){
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return []models.APIKey{}, fmt.Errorf("%s: scan row: %w", op, err)
+		}
+		keys = append(keys, key)
+	}
+
+	if err = rows.Err(); err != nil{
+		return []models.APIKey{}, fmt.Errorf("%s: rows err: %w", op, err)
+	}
+
+	return keys, nil
+}
+
+func (s *Storage) GetAPIKey(ctx context.Context, id int) (models.APIKey, error){
+	op := "storage.pgstore.GetAPIKey"
+
+	key, err := scanAPIKey(s.db.QueryRowContext(ctx, "SELECT " + apiKeyColumns + " FROM api_keys WHERE id = $1", id))
+	if err != nil{
+		if errors.Is(err, sql.ErrNoRows){
+			return models.APIKey{}, storage.ErrAPIKeyNotFound
+		}
+		return models.APIKey{}, fmt.Errorf("%s: scan row: %w", op, err)
+	}
+
+	return key, nil
+}
+
+// GetAPIKeyByHash returns the API key with the given hash, see apikey.Hash.
+func (s *Storage) GetAPIKeyByHash(ctx context.Context, hash string) (models.APIKey, error){
+	op := "storage.pgstore.GetAPIKeyByHash"
+
+	key, err := scanAPIKey(s.db.QueryRowContext(ctx, "SELECT " + apiKeyColumns + " FROM api_keys WHERE key_hash = $1", hash))
+	if err != nil{
+		if errors.Is(err, sql.ErrNoRows){
+			return models.APIKey{}, storage.ErrAPIKeyNotFound
+		}
+		return models.APIKey{}, fmt.Errorf("%s: scan row: %w", op, err)
+	}
+
+	return key, nil
+}
+
+// SaveAPIKey creates an API key of the user. It fails with
+// storage.ErrUserNotFound if there is no such user.
+func (s *Storage) SaveAPIKey(ctx context.Context, input models.InputAPIKey) (models.APIKey, error){
+	op := "storage.pgstore.SaveAPIKey"
+
+	key, err := scanAPIKey(s.db.QueryRowContext(ctx, `
+	INSERT INTO api_keys(name, prefix, key_hash, scopes, user_id, created_at) VALUES($1, $2, $3, $4, $5, $6)
+	RETURNING ` + apiKeyColumns,
+		input.Name, input.Prefix, input.Hash, pq.Array(input.Scopes), input.UserID, time.Now().UTC()))
+	if err != nil{
+		if isForeignKeyViolation(err){
+			return models.APIKey{}, storage.ErrUserNotFound
+		}
+		return models.APIKey{}, fmt.Errorf("%s: scan row: %w", op, err)
+	}
+
+	return key, nil
+}
+
+// RotateAPIKey replaces the key of an API key, which keeps its id, name and
+// scopes. The old key stops working at once.
+func (s *Storage) RotateAPIKey(ctx context.Context, id int, prefix, hash string) (models.APIKey, error){
+	op := "storage.pgstore.RotateAPIKey"
+
+	key, err := scanAPIKey(s.db.QueryRowContext(ctx, `
+	UPDATE api_keys SET prefix = $1, key_hash = $2, last_used_at = NULL WHERE id = $3
+	RETURNING ` + apiKeyColumns, prefix, hash, id))
+	if err != nil{
+		if errors.Is(err, sql.ErrNoRows){
+			return models.APIKey{}, storage.ErrAPIKeyNotFound
+		}
+		return models.APIKey{}, fmt.Errorf("%s: scan row: %w", op, err)
+	}
+
+	return key, nil
+}
+
+// DeleteAPIKey revokes an API key for good.
+func (s *Storage) DeleteAPIKey(ctx context.Context, id int) error{
+	op := "storage.pgstore.DeleteAPIKey"
+
+	res, err := s.db.ExecContext(ctx, "DELETE FROM api_keys WHERE id = $1", id)
+	if err != nil {
+		return fmt.Errorf("%s: failed delete: %w", op, err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: rows affected: %w", op, err)
+	}
+	if n == 0 {
+		return storage.ErrAPIKeyNotFound
+	}
+
+	return nil
+}
+
+// TouchAPIKey records that the API key was used at usedAt. A key deleted
+// in the meantime is not an error.
+func (s *Storage) TouchAPIKey(ctx context.Context, id int, usedAt time.Time) error{
+	op := "storage.pgstore.TouchAPIKey"
+
+	if _, err := s.db.ExecContext(ctx, "UPDATE api_keys SET last_used_at = $1 WHERE id = $2", usedAt.UTC(), id); err != nil{
+		return fmt.Errorf("%s: exec statement: %w", op, err)
+	}
+
+	return nil
+}
+
+func scanAPIKey(row scanner) (models.APIKey, error){
+	var key models.APIKey
+	err := row.Scan(&key.ID, &key.Name, &key.Prefix, &key.Hash, pq.Array(&key.Scopes), &key.UserID, &key.CreatedAt, &key.LastUsedAt)
+	if err != nil{
+		return models.APIKey{}, err
+	}
+	key.CreatedAt = key.CreatedAt.UTC()
+	if key.LastUsedAt != nil{
+		lastUsedAt := key.LastUsedAt.UTC()
+		key.LastUsedAt = &lastUsedAt
+	}
+	return key, nil
+}
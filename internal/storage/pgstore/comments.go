@@ -0,0 +1,248 @@
+package pgstore
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/RomanKovalev007/mai_news/internal/models"
+	"github.com/RomanKovalev007/mai_news/internal/storage"
+	"github.com/lib/pq"
+)
+
+// commentColumns are the columns scanComment reads, in its order.
+const commentColumns = `id, post_id, parent_id, author_id, (SELECT name FROM users WHERE users.id = comments.author_id),
+	content, status, created_at, updated_at`
+
+const postExistsQuery = "SELECT EXISTS(SELECT 1 FROM post WHERE id = $1 AND deleted_at IS NULL)"
+
+// visibleComments is a recursive query of the comments shown under posts:
+// the approved comments whose parents are shown too. The posts are
+// selected by the condition filled in for %s.
+const visibleComments = `
+	WITH RECURSIVE visible(id, post_id) AS (
+		SELECT id, post_id FROM comments WHERE %s AND parent_id IS NULL AND status = 'approved'
+		UNION ALL
+		SELECT c.id, c.post_id FROM comments c JOIN visible v ON c.parent_id = v.id WHERE c.status = 'approved')`
+
+// GetComments returns the comments shown under the post, oldest first. It
+// fails with storage.ErrPostNotFound if the post does not exist or is in
+// the trash.
+func (s *Storage) GetComments(ctx context.Context, postID int) ([]models.Comment, error){
+	op := "storage.pgstore.GetComments"
+
+	var exists bool
+	if err := s.db.QueryRowContext(ctx, postExistsQuery, postID).Scan(&exists); err != nil{
+		return []models.Comment{}, fmt.Errorf("%s: find post: %w", op, err)
+	}
+	if !exists{
+		return []models.Comment{}, storage.ErrPostNotFound
+	}
+
+	return s.queryComments(ctx, op, fmt.Sprintf(visibleComments, "post_id = $1") + `
+	SELECT ` + commentColumns + ` FROM comments
+	WHERE id IN (SELECT id FROM visible) ORDER BY created_at, id`, postID)
+}
+
+// GetCommentQueue returns the comments awaiting moderation on posts outside
+// the trash, oldest first. Unless sections is nil, only those on posts of
+// the sections with these slugs.
+func (s *Storage) GetCommentQueue(ctx context.Context, sections []string) ([]models.Comment, error){
+	return s.queryComments(ctx, "storage.pgstore.GetCommentQueue", `
+	SELECT ` + commentColumns + ` FROM comments
+	WHERE status = 'pending' AND post_id IN (
+		SELECT id FROM post WHERE deleted_at IS NULL
+		AND ($1::text[] IS NULL OR section_id IN (SELECT id FROM sections WHERE slug = ANY($1))))
+	ORDER BY created_at, id`, pq.Array(sections))
+}
+
+func (s *Storage) queryComments(ctx context.Context, op, query string, args ...any) ([]models.Comment, error){
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil{
+		return []models.Comment{}, fmt.Errorf("%s: failed to get comments: %w", op, err)
+	}
+	defer rows.Close()
+
+	var comments []models.Comment
+
+	for rows.Next(){
+		comment, err := scanComment(rows)
+		if err != nil {
+			return []models.Comment{}, fmt.Errorf("%s: scan row: %w", op, err)
+		}
+		comments = append(comments, comment)
+	}
+
+	if err = rows.Err(); err != nil{
+		return []models.Comment{}, fmt.Errorf("%s: rows err: %w", op, err)
+	}
+
+	return comments, nil
+}
+
+// GetComment returns a comment in any status.
+func (s *Storage) GetComment(ctx context.Context, id int) (models.Comment, error){
+	op := "storage.pgstore.GetComment"
+
+	comment, err := scanComment(s.db.QueryRowContext(ctx, "SELECT " + commentColumns + " FROM comments WHERE id = $1", id))
+	if err != nil{
+		if errors.Is(err, sql.ErrNoRows){
+			return models.Comment{}, storage.ErrCommentNotFound
+		}
+		return models.Comment{}, fmt.Errorf("%s: scan row: %w", op, err)
+	}
+
+	return comment, nil
+}
+
+// SaveComment stores a new comment. It fails with storage.ErrPostNotFound if
+// the post does not exist or is in the trash, with
+// storage.ErrCommentNotFound if the parent is not a shown comment of the
+// same post and with storage.ErrUserNotFound if the author does not exist.
+func (s *Storage) SaveComment(ctx context.Context, input models.InputComment) (models.Comment, error){
+	op := "storage.pgstore.SaveComment"
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil{
+		return models.Comment{}, fmt.Errorf("%s: begin: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	var exists bool
+	if err = tx.QueryRowContext(ctx, postExistsQuery, input.PostID).Scan(&exists); err != nil{
+		return models.Comment{}, fmt.Errorf("%s: find post: %w", op, err)
+	}
+	if !exists{
+		return models.Comment{}, storage.ErrPostNotFound
+	}
+
+	var parentID, authorID sql.NullInt64
+	if input.ParentID != 0{
+		err = tx.QueryRowContext(ctx, fmt.Sprintf(visibleComments, "post_id = $2") + `
+		SELECT EXISTS(SELECT 1 FROM visible WHERE id = $1)`, input.ParentID, input.PostID).Scan(&exists)
+		if err != nil{
+			return models.Comment{}, fmt.Errorf("%s: find parent: %w", op, err)
+		}
+		if !exists{
+			return models.Comment{}, storage.ErrCommentNotFound
+		}
+		parentID = sql.NullInt64{Int64: int64(input.ParentID), Valid: true}
+	}
+	if input.AuthorID != 0{
+		authorID = sql.NullInt64{Int64: int64(input.AuthorID), Valid: true}
+	}
+
+	now := time.Now().UTC()
+	comment, err := scanComment(tx.QueryRowContext(ctx, `
+	INSERT INTO comments(post_id, parent_id, author_id, content, status, created_at, updated_at) VALUES($1, $2, $3, $4, $5, $6, $7)
+	RETURNING ` + commentColumns,
+		input.PostID, parentID, authorID, input.Content, input.Status, now, now))
+	if err != nil{
+		if isForeignKeyViolation(err){
+			return models.Comment{}, storage.ErrUserNotFound
+		}
+		return models.Comment{}, fmt.Errorf("%s: scan row: %w", op, err)
+	}
+
+	if err = tx.Commit(); err != nil{
+		return models.Comment{}, fmt.Errorf("%s: commit: %w", op, err)
+	}
+
+	return comment, nil
+}
+
+// PatchComment updates the fields set in patch. A new content changes the
+// update time of the comment, a new status does not.
+func (s *Storage) PatchComment(ctx context.Context, id int, patch models.CommentPatch) (models.Comment, error){
+	op := "storage.pgstore.PatchComment"
+
+	var updatedAt *time.Time
+	if patch.Content != nil{
+		now := time.Now().UTC()
+		updatedAt = &now
+	}
+
+	comment, err := scanComment(s.db.QueryRowContext(ctx, `
+	UPDATE comments SET content = COALESCE($1, content), status = COALESCE($2, status), updated_at = COALESCE($3, updated_at)
+	WHERE id = $4
+	RETURNING ` + commentColumns, patch.Content, patch.Status, updatedAt, id))
+	if err != nil{
+		if errors.Is(err, sql.ErrNoRows){
+			return models.Comment{}, storage.ErrCommentNotFound
+		}
+		return models.Comment{}, fmt.Errorf("%s: scan row: %w", op, err)
+	}
+
+	return comment, nil
+}
+
+// DeleteComment deletes a comment together with the replies to it.
+func (s *Storage) DeleteComment(ctx context.Context, id int) error{
+	op := "storage.pgstore.DeleteComment"
+
+	res, err := s.db.ExecContext(ctx, "DELETE FROM comments WHERE id = $1", id)
+	if err != nil {
+		return fmt.Errorf("%s: failed delete: %w", op, err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: rows affected: %w", op, err)
+	}
+	if n == 0 {
+		return storage.ErrCommentNotFound
+	}
+
+	return nil
+}
+
+// attachCommentCounts fills in the comment counts of posts with one query.
+func attachCommentCounts(ctx context.Context, q querier, posts ...*models.OutputPost) error{
+	if len(posts) == 0{
+		return nil
+	}
+
+	byID := make(map[int]*models.OutputPost, len(posts))
+	ids := make([]int64, 0, len(posts))
+	for _, post := range posts{
+		byID[post.ID] = post
+		ids = append(ids, int64(post.ID))
+	}
+
+	rows, err := q.QueryContext(ctx, fmt.Sprintf(visibleComments, "post_id = ANY($1)") + `
+	SELECT post_id, COUNT(*) FROM visible GROUP BY post_id`, pq.Array(ids))
+	if err != nil{
+		return fmt.Errorf("get comment counts: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next(){
+		var id, count int
+		if err := rows.Scan(&id, &count); err != nil{
+			return fmt.Errorf("scan comment count: %w", err)
+		}
+		byID[id].CommentCount = count
+	}
+
+	return rows.Err()
+}
+
+func scanComment(row scanner) (models.Comment, error){
+	var comment models.Comment
+	var parentID, authorID sql.NullInt64
+	var authorName sql.NullString
+	err := row.Scan(&comment.ID, &comment.PostID, &parentID, &authorID, &authorName,
+		&comment.Content, &comment.Status, &comment.CreatedAt, &comment.UpdatedAt)
+	if err != nil{
+		return models.Comment{}, err
+	}
+	comment.ParentID = int(parentID.Int64)
+	if authorID.Valid{
+		comment.Author = &models.Author{ID: int(authorID.Int64), Name: authorName.String}
+	}
+	comment.CreatedAt = comment.CreatedAt.UTC()
+	comment.UpdatedAt = comment.UpdatedAt.UTC()
+	return comment, nil
+}
@@ -0,0 +1,5 @@
+CREATE TABLE IF NOT EXISTS post(
+	id BIGSERIAL PRIMARY KEY,
+	title TEXT NOT NULL UNIQUE,
+	content TEXT NOT NULL,
+	created_at TIMESTAMPTZ);
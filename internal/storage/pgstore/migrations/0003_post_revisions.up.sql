@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS post_revisions(
+	post_id BIGINT NOT NULL REFERENCES post(id) ON DELETE CASCADE,
+	rev INTEGER NOT NULL,
+	title TEXT NOT NULL,
+	content TEXT NOT NULL,
+	revised_at TIMESTAMPTZ NOT NULL,
+	PRIMARY KEY (post_id, rev));
@@ -0,0 +1,4 @@
+ALTER TABLE post ADD COLUMN search tsvector GENERATED ALWAYS AS (
+	setweight(to_tsvector('russian', title), 'A') ||
+	setweight(to_tsvector('russian', content), 'B')) STORED;
+CREATE INDEX IF NOT EXISTS post_search_idx ON post USING GIN(search);
@@ -0,0 +1 @@
+ALTER TABLE post ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS post_updated_at_idx;
+ALTER TABLE post DROP COLUMN updated_at;
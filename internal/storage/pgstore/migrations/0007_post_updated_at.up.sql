@@ -0,0 +1,3 @@
+ALTER TABLE post ADD COLUMN updated_at TIMESTAMPTZ;
+UPDATE post SET updated_at = created_at;
+CREATE INDEX IF NOT EXISTS post_updated_at_idx ON post(updated_at);
@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS tags(
+	id BIGSERIAL PRIMARY KEY,
+	name TEXT NOT NULL UNIQUE);
+CREATE TABLE IF NOT EXISTS post_tags(
+	post_id BIGINT NOT NULL REFERENCES post(id) ON DELETE CASCADE,
+	tag_id BIGINT NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+	PRIMARY KEY (post_id, tag_id));
+CREATE INDEX IF NOT EXISTS post_tags_tag_id_idx ON post_tags(tag_id);
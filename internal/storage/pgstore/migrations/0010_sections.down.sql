@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS post_section_title_idx;
+ALTER TABLE post ADD CONSTRAINT post_title_key UNIQUE (title);
+ALTER TABLE post DROP COLUMN section_id;
+DROP TABLE IF EXISTS section_editors;
+DROP TABLE IF EXISTS sections;
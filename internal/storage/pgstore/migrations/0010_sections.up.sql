@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS sections(
+	id BIGSERIAL PRIMARY KEY,
+	slug TEXT NOT NULL UNIQUE,
+	title TEXT NOT NULL,
+	description TEXT NOT NULL DEFAULT '',
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now());
+CREATE TABLE IF NOT EXISTS section_editors(
+	section_id BIGINT NOT NULL REFERENCES sections(id) ON DELETE CASCADE,
+	email TEXT NOT NULL,
+	PRIMARY KEY (section_id, email));
+-- the posts from before sections go to the default one
+INSERT INTO sections(slug, title) VALUES('general', 'Общие новости');
+ALTER TABLE post ADD COLUMN section_id BIGINT REFERENCES sections(id);
+UPDATE post SET section_id = (SELECT id FROM sections WHERE slug = 'general');
+ALTER TABLE post ALTER COLUMN section_id SET NOT NULL;
+-- titles are unique per section rather than across all posts
+ALTER TABLE post DROP CONSTRAINT IF EXISTS post_title_key;
+CREATE UNIQUE INDEX IF NOT EXISTS post_section_title_idx ON post(section_id, title);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS post_author_id_idx;
+ALTER TABLE post DROP COLUMN IF EXISTS author_id;
+DROP TABLE IF EXISTS users;
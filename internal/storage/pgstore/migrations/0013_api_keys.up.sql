@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS api_keys(
+	id BIGSERIAL PRIMARY KEY,
+	name TEXT NOT NULL,
+	prefix TEXT NOT NULL,
+	key_hash TEXT NOT NULL UNIQUE,
+	scopes TEXT[] NOT NULL,
+	user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	last_used_at TIMESTAMPTZ);
+CREATE INDEX IF NOT EXISTS api_keys_user_id_idx ON api_keys(user_id);
@@ -0,0 +1,36 @@
+-- a user or an anonymous client has one reaction per post; reactions go
+-- away with the post and with the user
+CREATE TABLE IF NOT EXISTS post_reactions(
+	post_id BIGINT NOT NULL REFERENCES post(id) ON DELETE CASCADE,
+	reactor TEXT NOT NULL,
+	user_id BIGINT REFERENCES users(id) ON DELETE CASCADE,
+	kind TEXT NOT NULL,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	PRIMARY KEY (post_id, reactor));
+CREATE INDEX IF NOT EXISTS post_reactions_user_id_idx ON post_reactions(user_id);
+
+-- counts of reactions by kind, kept by the trigger below so that lists of
+-- posts read them without counting
+CREATE TABLE IF NOT EXISTS post_reaction_counts(
+	post_id BIGINT NOT NULL REFERENCES post(id) ON DELETE CASCADE,
+	kind TEXT NOT NULL,
+	count INTEGER NOT NULL,
+	PRIMARY KEY (post_id, kind));
+
+CREATE OR REPLACE FUNCTION count_post_reactions() RETURNS trigger AS $$
+BEGIN
+	IF TG_OP IN ('DELETE', 'UPDATE') THEN
+		UPDATE post_reaction_counts SET count = count - 1 WHERE post_id = OLD.post_id AND kind = OLD.kind;
+		DELETE FROM post_reaction_counts WHERE post_id = OLD.post_id AND kind = OLD.kind AND count <= 0;
+	END IF;
+	IF TG_OP IN ('INSERT', 'UPDATE') THEN
+		INSERT INTO post_reaction_counts(post_id, kind, count) VALUES (NEW.post_id, NEW.kind, 1)
+			ON CONFLICT (post_id, kind) DO UPDATE SET count = post_reaction_counts.count + 1;
+	END IF;
+	RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS post_reactions_count ON post_reactions;
+CREATE TRIGGER post_reactions_count AFTER INSERT OR DELETE OR UPDATE OF kind ON post_reactions
+	FOR EACH ROW EXECUTE FUNCTION count_post_reactions();
@@ -0,0 +1,6 @@
+-- views of posts per UTC day, written in batches by the view aggregator
+CREATE TABLE IF NOT EXISTS post_views(
+	post_id BIGINT NOT NULL REFERENCES post(id) ON DELETE CASCADE,
+	day DATE NOT NULL,
+	views BIGINT NOT NULL,
+	PRIMARY KEY (post_id, day));
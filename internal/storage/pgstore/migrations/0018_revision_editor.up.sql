@@ -0,0 +1,3 @@
+-- the user whose change replaced the revision; revisions from before this
+-- migration have none
+ALTER TABLE post_revisions ADD COLUMN revised_by BIGINT REFERENCES users(id) ON DELETE SET NULL;
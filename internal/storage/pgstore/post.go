@@ -0,0 +1,500 @@
+package pgstore
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/RomanKovalev007/mai_news/internal/lib/jsonpatch"
+	"github.com/RomanKovalev007/mai_news/internal/lib/tags"
+	"github.com/RomanKovalev007/mai_news/internal/models"
+	"github.com/RomanKovalev007/mai_news/internal/storage"
+	"github.com/lib/pq"
+)
+
+// sortColumns maps the sortable fields to their columns. Only these names
+// are ever put into the text of a query.
+var sortColumns = map[models.SortField]string{
+	models.SortByCreatedAt: "created_at",
+	models.SortByTitle: "title",
+}
+
+// GetAllPosts returns a page of the posts matching filter, in its order.
+func (s *Storage) GetAllPosts(ctx context.Context, filter models.PostFilter, page models.Page) (models.PostsPage, error){
+	op := "storage.pgstore.GetAllPosts"
+
+	// read before the posts, so that a change in between makes the page
+	// newer than its LastModified rather than the other way round
+	var result models.PostsPage
+	var lastModified sql.NullTime
+	err := s.db.QueryRowContext(ctx, "SELECT MAX(updated_at) FROM post").Scan(&lastModified)
+	if err != nil{
+		return models.PostsPage{}, fmt.Errorf("%s: failed to get last modified: %w", op, err)
+	}
+	result.LastModified = lastModified.Time
+
+	query, args := postsQuery(filter, page)
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil{
+		return models.PostsPage{}, fmt.Errorf("%s: failed to get all posts: %w", op, err)
+	}
+	defer rows.Close()
+
+	var last models.Cursor
+
+	for rows.Next(){
+		if len(result.Posts) == page.Limit{
+			result.Next = &last
+			break
+		}
+
+		var post models.OutputPost
+		err := rows.Scan(&post.ID, &post.Title, &post.Content, &post.Slug, &post.Section, &last.CreatedAt, &post.UpdatedAt, &post.Version)
+		if err != nil {
+			return models.PostsPage{}, fmt.Errorf("%s: scan row: %w", op, err)
+		}
+		last.ID, last.Title = post.ID, post.Title
+		post.CreatedAt = last.CreatedAt
+		inUTC(&post)
+		result.Posts = append(result.Posts, post)
+	}
+
+	if err = rows.Err(); err != nil{
+		return models.PostsPage{}, fmt.Errorf("%s: rows err: %w", op, err)
+	}
+	rows.Close()
+
+	posts := make([]*models.OutputPost, len(result.Posts))
+	for i := range result.Posts{
+		posts[i] = &result.Posts[i]
+	}
+	if err = attachTags(ctx, s.db, posts...); err != nil{
+		return models.PostsPage{}, fmt.Errorf("%s: %w", op, err)
+	}
+	if err = attachAuthors(ctx, s.db, posts...); err != nil{
+		return models.PostsPage{}, fmt.Errorf("%s: %w", op, err)
+	}
+	if err = attachCommentCounts(ctx, s.db, posts...); err != nil{
+		return models.PostsPage{}, fmt.Errorf("%s: %w", op, err)
+	}
+	if err = attachReactions(ctx, s.db, posts...); err != nil{
+		return models.PostsPage{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return result, nil
+}
+
+// postsQuery builds the query of a posts list page. It fetches one extra row
+// to tell whether there is a next page.
+func postsQuery(filter models.PostFilter, page models.Page) (string, []any){
+	conds := []string{"deleted_at IS NULL"}
+	var args []any
+	arg := func(v any) string{
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if !filter.From.IsZero(){
+		conds = append(conds, "created_at >= "+arg(filter.From))
+	}
+	if !filter.To.IsZero(){
+		conds = append(conds, "created_at < "+arg(filter.To))
+	}
+	if filter.TitlePrefix != ""{
+		conds = append(conds, "starts_with(title, "+arg(filter.TitlePrefix)+")")
+	}
+	if filter.Section != ""{
+		conds = append(conds, "section_id = (SELECT id FROM sections WHERE slug = "+arg(filter.Section)+")")
+	}
+	if filter.AuthorID != 0{
+		conds = append(conds, "author_id = "+arg(filter.AuthorID))
+	}
+	if names := tags.Set(filter.Tags); len(names) > 0{
+		tagged := `id IN (SELECT pt.post_id FROM post_tags pt JOIN tags t ON t.id = pt.tag_id
+		WHERE t.name = ANY(` + arg(pq.Array(names)) + `)`
+		if filter.AllTags{
+			tagged += " GROUP BY pt.post_id HAVING COUNT(*) = " + arg(len(names))
+		}
+		conds = append(conds, tagged + ")")
+	}
+
+	column, ok := sortColumns[filter.SortBy]
+	if !ok{
+		column = sortColumns[models.SortByCreatedAt]
+	}
+	cmp, dir := ">", "ASC"
+	if filter.Desc{
+		cmp, dir = "<", "DESC"
+	}
+
+	if page.After != nil{
+		var after any = page.After.CreatedAt
+		if filter.SortBy == models.SortByTitle{
+			after = page.After.Title
+		}
+		conds = append(conds, fmt.Sprintf("(%s, id) %s (%s, %s)", column, cmp, arg(after), arg(page.After.ID)))
+	}
+
+	query := fmt.Sprintf(`
+	SELECT id, title, content, slug, (SELECT slug FROM sections WHERE sections.id = post.section_id), created_at, updated_at, version FROM post WHERE %s
+	ORDER BY %s %s, id %s LIMIT %s`, strings.Join(conds, " AND "), column, dir, dir, arg(page.Limit+1))
+
+	return query, args
+}
+
+// SavePost stores a new post under inputPost.Slug, or under a slug made from
+// its title when that is empty. It fails with storage.ErrSectionNotFound if
+// the section of the post does not exist and with storage.ErrUserNotFound if
+// its author does not.
+func (s *Storage) SavePost(ctx context.Context, inputPost models.InputPost) (models.OutputPost, error){
+	op := "storage.pgstore.SavePost"
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil{
+		return models.OutputPost{}, fmt.Errorf("%s: begin: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	section := inputPost.Section
+	if section == ""{
+		section = models.DefaultSection
+	}
+	sectionID, err := lookupSection(ctx, tx, section)
+	if err != nil{
+		if errors.Is(err, storage.ErrSectionNotFound){
+			return models.OutputPost{}, err
+		}
+		return models.OutputPost{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	slug, err := newSlug(ctx, tx, inputPost.Slug, inputPost.Title, 0)
+	if err != nil{
+		if errors.Is(err, storage.ErrSlugTaken){
+			return models.OutputPost{}, err
+		}
+		return models.OutputPost{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	var author *models.Author
+	var authorID sql.NullInt64
+	if inputPost.AuthorID != 0{
+		author = &models.Author{ID: inputPost.AuthorID}
+		err = tx.QueryRowContext(ctx, "SELECT name FROM users WHERE id = $1 FOR SHARE", inputPost.AuthorID).Scan(&author.Name)
+		if err != nil{
+			if errors.Is(err, sql.ErrNoRows){
+				return models.OutputPost{}, storage.ErrUserNotFound
+			}
+			return models.OutputPost{}, fmt.Errorf("%s: get author: %w", op, err)
+		}
+		authorID = sql.NullInt64{Int64: int64(inputPost.AuthorID), Valid: true}
+	}
+
+	now := time.Now().UTC()
+	var id int
+	err = tx.QueryRowContext(ctx, `
+	INSERT INTO post(title, content, slug, section_id, author_id, created_at, updated_at) VALUES($1, $2, $3, $4, $5, $6, $7)
+	RETURNING id`, inputPost.Title, inputPost.Content, slug, sectionID, authorID, now, now).Scan(&id)
+	if err != nil {
+		if isSlugViolation(err){
+			return models.OutputPost{}, storage.ErrSlugTaken
+		}
+		if isUniqueViolation(err){
+			return models.OutputPost{}, storage.ErrPostExists
+		}
+		return models.OutputPost{}, fmt.Errorf("%s: exec statement: %w", op, err)
+	}
+
+	names := tags.Set(inputPost.Tags)
+	if err = setTags(ctx, tx, id, names); err != nil{
+		return models.OutputPost{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err = tx.Commit(); err != nil{
+		return models.OutputPost{}, fmt.Errorf("%s: commit: %w", op, err)
+	}
+
+	post := models.OutputPost{
+		ID: id,
+		Title: inputPost.Title,
+		Content: inputPost.Content,
+		Slug: slug,
+		Tags: names,
+		Section: section,
+		Author: author,
+		CreatedAt: now,
+		UpdatedAt: now,
+		Version: 1,
+	}
+
+	return post, nil
+}
+
+func (s *Storage) GetPost(ctx context.Context, id int) (models.OutputPost, error){
+	op := "storage.pgstore.GetPost"
+
+	var post models.OutputPost
+	err := s.db.QueryRowContext(ctx, `
+	SELECT id, title, content, slug, (SELECT slug FROM sections WHERE sections.id = post.section_id), created_at, updated_at, version FROM post
+	WHERE id = $1 AND deleted_at IS NULL`, id).
+		Scan(&post.ID, &post.Title, &post.Content, &post.Slug, &post.Section, &post.CreatedAt, &post.UpdatedAt, &post.Version)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows){
+			return models.OutputPost{}, storage.ErrPostNotFound
+		}
+		return models.OutputPost{}, fmt.Errorf("%s: scan row: %w", op, err)
+	}
+	inUTC(&post)
+	if err = attachTags(ctx, s.db, &post); err != nil{
+		return models.OutputPost{}, fmt.Errorf("%s: %w", op, err)
+	}
+	if err = attachAuthors(ctx, s.db, &post); err != nil{
+		return models.OutputPost{}, fmt.Errorf("%s: %w", op, err)
+	}
+	if err = attachCommentCounts(ctx, s.db, &post); err != nil{
+		return models.OutputPost{}, fmt.Errorf("%s: %w", op, err)
+	}
+	if err = attachReactions(ctx, s.db, &post); err != nil{
+		return models.OutputPost{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return post, nil
+}
+
+// PatchPost updates the fields set in patch and keeps the previous version of
+// the post as a revision. Unless version is 0 the post is only updated while
+// it is at that version.
+func (s *Storage) PatchPost(ctx context.Context, id, version int, patch models.PostPatch) (models.OutputPost, error){
+	op := "storage.pgstore.PatchPost"
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil{
+		return models.OutputPost{}, fmt.Errorf("%s: begin: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	post, err := patchPost(ctx, tx, id, version, patch)
+	if err != nil{
+		if errors.Is(err, storage.ErrPostNotFound) || errors.Is(err, storage.ErrPostExists) || errors.Is(err, storage.ErrSlugTaken) || errors.Is(err, storage.ErrSectionNotFound) || errors.Is(err, storage.ErrVersionMismatch){
+			return models.OutputPost{}, err
+		}
+		return models.OutputPost{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err = tx.Commit(); err != nil{
+		return models.OutputPost{}, fmt.Errorf("%s: commit: %w", op, err)
+	}
+
+	return post, nil
+}
+
+// ApplyPatch runs JSON Patch operations against the post within one
+// transaction, so test operations see the state the update replaces. Unless
+// version is 0 the post is only updated while it is at that version.
+func (s *Storage) ApplyPatch(ctx context.Context, id, version, editorID int, ops []jsonpatch.Operation) (models.OutputPost, error){
+	op := "storage.pgstore.ApplyPatch"
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil{
+		return models.OutputPost{}, fmt.Errorf("%s: begin: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	var current models.OutputPost
+	err = tx.QueryRowContext(ctx, `
+	SELECT id, title, content, slug, (SELECT slug FROM sections WHERE sections.id = post.section_id), version FROM post
+	WHERE id = $1 AND deleted_at IS NULL FOR UPDATE`, id).
+		Scan(&current.ID, &current.Title, &current.Content, &current.Slug, &current.Section, &current.Version)
+	if err != nil{
+		if errors.Is(err, sql.ErrNoRows){
+			return models.OutputPost{}, storage.ErrPostNotFound
+		}
+		return models.OutputPost{}, fmt.Errorf("%s: lock post: %w", op, err)
+	}
+	if version != 0 && version != current.Version{
+		return models.OutputPost{}, storage.ErrVersionMismatch
+	}
+	if err = attachTags(ctx, tx, &current); err != nil{
+		return models.OutputPost{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	patch, err := jsonpatch.Apply(ops, current)
+	if err != nil{
+		return models.OutputPost{}, err
+	}
+	patch.EditorID = editorID
+
+	post, err := patchPost(ctx, tx, id, current.Version, patch)
+	if err != nil{
+		if errors.Is(err, storage.ErrPostNotFound) || errors.Is(err, storage.ErrPostExists) || errors.Is(err, storage.ErrSlugTaken) || errors.Is(err, storage.ErrSectionNotFound) || errors.Is(err, storage.ErrVersionMismatch){
+			return models.OutputPost{}, err
+		}
+		return models.OutputPost{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err = tx.Commit(); err != nil{
+		return models.OutputPost{}, fmt.Errorf("%s: commit: %w", op, err)
+	}
+
+	return post, nil
+}
+
+// patchPost saves the current version of the post as a revision and updates
+// it within tx. The post row stays locked until tx ends, so concurrent
+// patches get consecutive revision numbers. Only the columns set in patch are
+// written; an empty patch leaves the post and its version as they are. Tags
+// and the section are not kept in revisions, but a change of them is a
+// change of the post.
+func patchPost(ctx context.Context, tx *sql.Tx, id, version int, patch models.PostPatch) (models.OutputPost, error){
+	var title, content string
+	var current int
+	err := tx.QueryRowContext(ctx, "SELECT title, content, version FROM post WHERE id = $1 AND deleted_at IS NULL FOR UPDATE", id).
+		Scan(&title, &content, &current)
+	if err != nil{
+		if errors.Is(err, sql.ErrNoRows){
+			return models.OutputPost{}, storage.ErrPostNotFound
+		}
+		return models.OutputPost{}, fmt.Errorf("lock post: %w", err)
+	}
+	if version != 0 && version != current{
+		return models.OutputPost{}, storage.ErrVersionMismatch
+	}
+
+	if (patch.Title != nil && *patch.Title != title) || (patch.Content != nil && *patch.Content != content){
+		_, err = tx.ExecContext(ctx, `
+		INSERT INTO post_revisions(post_id, rev, title, content, revised_at, revised_by)
+		SELECT $1, COALESCE(MAX(rev), 0) + 1, $2, $3, $4, (SELECT id FROM users WHERE id = $5) FROM post_revisions WHERE post_id = $1`,
+			id, title, content, time.Now().UTC(), patch.EditorID)
+		if err != nil{
+			return models.OutputPost{}, fmt.Errorf("save revision: %w", err)
+		}
+	}
+
+	slug, err := patchSlug(ctx, tx, id, patch)
+	if err != nil{
+		return models.OutputPost{}, err
+	}
+
+	tagged := models.OutputPost{ID: id}
+	if err = attachTags(ctx, tx, &tagged); err != nil{
+		return models.OutputPost{}, err
+	}
+	names, tagsChanged := tagged.Tags, false
+	if patch.Tags != nil && !slices.Equal(tags.Set(*patch.Tags), names){
+		names, tagsChanged = tags.Set(*patch.Tags), true
+	}
+
+	var sets []string
+	var args []any
+	if patch.Title != nil{
+		args = append(args, *patch.Title)
+		sets = append(sets, fmt.Sprintf("title = $%d", len(args)))
+	}
+	if patch.Content != nil{
+		args = append(args, *patch.Content)
+		sets = append(sets, fmt.Sprintf("content = $%d", len(args)))
+	}
+	if slug != ""{
+		args = append(args, slug)
+		sets = append(sets, fmt.Sprintf("slug = $%d", len(args)))
+	}
+	if patch.Section != nil{
+		sectionID, err := lookupSection(ctx, tx, *patch.Section)
+		if err != nil{
+			return models.OutputPost{}, err
+		}
+		args = append(args, sectionID)
+		sets = append(sets, fmt.Sprintf("section_id = $%d", len(args)))
+	}
+	if len(sets) == 0 && !tagsChanged{
+		sets = append(sets, "version = version")
+	} else {
+		args = append(args, time.Now().UTC())
+		sets = append(sets, fmt.Sprintf("updated_at = $%d", len(args)), "version = version + 1")
+	}
+	args = append(args, id)
+
+	query := fmt.Sprintf(`
+	UPDATE post SET %s WHERE id = $%d
+	RETURNING id, title, content, slug, (SELECT slug FROM sections WHERE sections.id = post.section_id), created_at, updated_at, version`, strings.Join(sets, ", "), len(args))
+
+	var post models.OutputPost
+	err = tx.QueryRowContext(ctx, query, args...).
+		Scan(&post.ID, &post.Title, &post.Content, &post.Slug, &post.Section, &post.CreatedAt, &post.UpdatedAt, &post.Version)
+	if err != nil {
+		if isSlugViolation(err){
+			return models.OutputPost{}, storage.ErrSlugTaken
+		}
+		if isUniqueViolation(err){
+			return models.OutputPost{}, storage.ErrPostExists
+		}
+		return models.OutputPost{}, fmt.Errorf("scan row: %w", err)
+	}
+	inUTC(&post)
+
+	if tagsChanged{
+		if err = setTags(ctx, tx, id, names); err != nil{
+			return models.OutputPost{}, err
+		}
+	}
+	post.Tags = names
+	if err = attachAuthors(ctx, tx, &post); err != nil{
+		return models.OutputPost{}, err
+	}
+	if err = attachCommentCounts(ctx, tx, &post); err != nil{
+		return models.OutputPost{}, err
+	}
+	if err = attachReactions(ctx, tx, &post); err != nil{
+		return models.OutputPost{}, err
+	}
+
+	return post, nil
+}
+
+// DeletePost moves the post to the trash. Unless version is 0 the post is
+// only deleted while it is at that version.
+func (s *Storage) DeletePost(ctx context.Context, id, version int) error{
+	op := "storage.pgstore.DeletePost"
+
+	res, err := s.db.ExecContext(ctx, `
+	UPDATE post SET deleted_at = $1, updated_at = $1, version = version + 1
+	WHERE id = $2 AND deleted_at IS NULL AND ($3 = 0 OR version = $3)`, time.Now().UTC(), id, version)
+	if err != nil {
+		return fmt.Errorf("%s: failed delete: %w", op, err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: rows affected: %w", op, err)
+	}
+	if n == 0 {
+		// tell a missing post from one at another version
+		if _, err = s.GetPost(ctx, id); err != nil{
+			return err
+		}
+		return storage.ErrVersionMismatch
+	}
+
+	return nil
+}
+
+// inUTC converts the times of post to UTC; lib/pq returns them in the time
+// zone of the database session.
+func inUTC(post *models.OutputPost){
+	post.CreatedAt = post.CreatedAt.UTC()
+	post.UpdatedAt = post.UpdatedAt.UTC()
+	post.DeletedAt = post.DeletedAt.UTC()
+}
+
+func isUniqueViolation(err error) bool{
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
+}
+
+func isForeignKeyViolation(err error) bool{
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23503"
+}
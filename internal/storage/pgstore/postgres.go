@@ -0,0 +1,71 @@
+package pgstore
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+
+	"github.com/RomanKovalev007/mai_news/internal/storage/migrate"
+	_ "github.com/lib/pq"
+)
+
+//go:embed migrations/*.sql
+var migrations embed.FS
+
+type Storage struct{
+	db *sql.DB
+}
+
+func New(dsn string) (*Storage, error) {
+	const fn = "storage.pgstore.New"
+
+	db, err := sql.Open("postgres", dsn)
+	if err != nil{
+		return nil, fmt.Errorf("%s: %w", fn, err)
+	}
+
+	if err = db.Ping(); err != nil{
+		db.Close()
+		return nil, fmt.Errorf("%s: %w", fn, err)
+	}
+
+	migrator, err := NewMigrator(db)
+	if err != nil{
+		db.Close()
+		return nil, fmt.Errorf("%s: %w", fn, err)
+	}
+
+	if _, err = migrator.Up(); err != nil{
+		db.Close()
+		return nil, fmt.Errorf("%s: %w", fn, err)
+	}
+
+	if err = backfillSlugs(context.Background(), db); err != nil{
+		db.Close()
+		return nil, fmt.Errorf("%s: %w", fn, err)
+	}
+
+	return &Storage{db: db}, nil
+}
+
+// Close closes the database connection pool.
+func (s *Storage) Close() error{
+	const fn = "storage.pgstore.Close"
+
+	if err := s.db.Close(); err != nil{
+		return fmt.Errorf("%s: %w", fn, err)
+	}
+	return nil
+}
+
+// NewMigrator returns a migrator over the schema migrations embedded in the binary.
+func NewMigrator(db *sql.DB) (*migrate.Migrator, error) {
+	sub, err := fs.Sub(migrations, "migrations")
+	if err != nil{
+		return nil, err
+	}
+
+	return migrate.New(db, migrate.Postgres, sub)
+}
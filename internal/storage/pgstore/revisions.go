@@ -0,0 +1,126 @@
+package pgstore
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/RomanKovalev007/mai_news/internal/models"
+	"github.com/RomanKovalev007/mai_news/internal/storage"
+)
+
+func (s *Storage) GetRevisions(ctx context.Context, postID int) ([]models.Revision, error){
+	op := "storage.pgstore.GetRevisions"
+
+	if _, err := s.GetPost(ctx, postID); err != nil{
+		return []models.Revision{}, err
+	}
+
+	rows, err := s.db.QueryContext(ctx, `
+	SELECT r.post_id, r.rev, r.title, r.content, r.revised_at, u.id, u.name FROM post_revisions r
+	LEFT JOIN users u ON u.id = r.revised_by
+	WHERE r.post_id = $1 ORDER BY r.rev DESC`, postID)
+	if err != nil{
+		return []models.Revision{}, fmt.Errorf("%s: failed to get revisions: %w", op, err)
+	}
+	defer rows.Close()
+
+	revisions := []models.Revision{}
+
+	for rows.Next(){
+		revision, err := scanRevision(rows)
+		if err != nil {
+			return []models.Revision{}, fmt.Errorf("%s: scan row: %w", op, err)
+		}
+		revisions = append(revisions, revision)
+	}
+
+	if err = rows.Err(); err != nil{
+		return []models.Revision{}, fmt.Errorf("%s: rows err: %w", op, err)
+	}
+
+	return revisions, nil
+}
+
+func (s *Storage) GetRevision(ctx context.Context, postID, rev int) (models.Revision, error){
+	op := "storage.pgstore.GetRevision"
+
+	if _, err := s.GetPost(ctx, postID); err != nil{
+		return models.Revision{}, err
+	}
+
+	revision, err := scanRevision(s.db.QueryRowContext(ctx, selectRevision, postID, rev))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows){
+			return models.Revision{}, storage.ErrRevisionNotFound
+		}
+		return models.Revision{}, fmt.Errorf("%s: scan row: %w", op, err)
+	}
+
+	return revision, nil
+}
+
+// RevertPost restores the title and content of the given revision. The
+// version being replaced is kept as a new revision, so a revert can itself
+// be reverted.
+func (s *Storage) RevertPost(ctx context.Context, postID, rev, editorID int) (models.OutputPost, error){
+	op := "storage.pgstore.RevertPost"
+
+	if _, err := s.GetPost(ctx, postID); err != nil{
+		return models.OutputPost{}, err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil{
+		return models.OutputPost{}, fmt.Errorf("%s: begin: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	revision, err := scanRevision(tx.QueryRowContext(ctx, selectRevision, postID, rev))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows){
+			return models.OutputPost{}, storage.ErrRevisionNotFound
+		}
+		return models.OutputPost{}, fmt.Errorf("%s: scan row: %w", op, err)
+	}
+
+	post, err := patchPost(ctx, tx, postID, 0, models.PostPatch{Title: &revision.Title, Content: &revision.Content, EditorID: editorID})
+	if err != nil{
+		if errors.Is(err, storage.ErrPostNotFound) || errors.Is(err, storage.ErrPostExists) || errors.Is(err, storage.ErrSlugTaken){
+			return models.OutputPost{}, err
+		}
+		return models.OutputPost{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err = tx.Commit(); err != nil{
+		return models.OutputPost{}, fmt.Errorf("%s: commit: %w", op, err)
+	}
+
+	return post, nil
+}
+
+const selectRevision = `
+	SELECT r.post_id, r.rev, r.title, r.content, r.revised_at, u.id, u.name FROM post_revisions r
+	LEFT JOIN users u ON u.id = r.revised_by
+	WHERE r.post_id = $1 AND r.rev = $2`
+
+type scanner interface{
+	Scan(dest ...any) error
+}
+
+func scanRevision(row scanner) (models.Revision, error){
+	var revision models.Revision
+	var revisedAt time.Time
+	var editorID sql.NullInt64
+	var editorName sql.NullString
+	if err := row.Scan(&revision.PostID, &revision.Rev, &revision.Title, &revision.Content, &revisedAt, &editorID, &editorName); err != nil{
+		return models.Revision{}, err
+	}
+	revision.RevisedAt = revisedAt.UTC()
+	if editorID.Valid{
+		revision.RevisedBy = &models.Author{ID: int(editorID.Int64), Name: editorName.String}
+	}
+	return revision, nil
+}
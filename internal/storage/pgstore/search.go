@@ -0,0 +1,78 @@
+package pgstore
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/RomanKovalev007/mai_news/internal/lib/search"
+	"github.com/RomanKovalev007/mai_news/internal/models"
+)
+
+// SearchPosts returns up to limit posts matching every word of query, best
+// matches first. Words are stemmed with the russian configuration and also
+// match longer words starting with them.
+func (s *Storage) SearchPosts(ctx context.Context, query string, limit int) ([]models.SearchResult, error){
+	op := "storage.pgstore.SearchPosts"
+
+	terms := search.Terms(query)
+	if len(terms) == 0{
+		return []models.SearchResult{}, nil
+	}
+	for i, term := range terms{
+		spellings := search.Spellings(term)
+		for j, spelling := range spellings{
+			spellings[j] = spelling + ":*"
+		}
+		terms[i] = "(" + strings.Join(spellings, " | ") + ")"
+	}
+
+	rows, err := s.db.QueryContext(ctx, `
+	SELECT id, title, content, slug, (SELECT slug FROM sections WHERE sections.id = post.section_id), created_at, ts_rank(search, q),
+		ts_headline('russian', title, q, 'StartSel=<mark>, StopSel=</mark>, HighlightAll=true'),
+		ts_headline('russian', content, q, 'StartSel=<mark>, StopSel=</mark>, MaxWords=24, MinWords=12, FragmentDelimiter=…')
+	FROM post, to_tsquery('russian', $1) q
+	WHERE deleted_at IS NULL AND search @@ q
+	ORDER BY ts_rank(search, q) DESC, id DESC
+	LIMIT $2`, strings.Join(terms, " & "), limit)
+	if err != nil{
+		return []models.SearchResult{}, fmt.Errorf("%s: failed to search posts: %w", op, err)
+	}
+	defer rows.Close()
+
+	var results []models.SearchResult
+
+	for rows.Next(){
+		var r models.SearchResult
+		err := rows.Scan(&r.ID, &r.Title, &r.Content, &r.Slug, &r.Section, &r.CreatedAt, &r.Score, &r.TitleHighlight, &r.Snippet)
+		if err != nil {
+			return []models.SearchResult{}, fmt.Errorf("%s: scan row: %w", op, err)
+		}
+		inUTC(&r.OutputPost)
+		results = append(results, r)
+	}
+
+	if err = rows.Err(); err != nil{
+		return []models.SearchResult{}, fmt.Errorf("%s: rows err: %w", op, err)
+	}
+	rows.Close()
+
+	posts := make([]*models.OutputPost, len(results))
+	for i := range results{
+		posts[i] = &results[i].OutputPost
+	}
+	if err = attachTags(ctx, s.db, posts...); err != nil{
+		return []models.SearchResult{}, fmt.Errorf("%s: %w", op, err)
+	}
+	if err = attachAuthors(ctx, s.db, posts...); err != nil{
+		return []models.SearchResult{}, fmt.Errorf("%s: %w", op, err)
+	}
+	if err = attachCommentCounts(ctx, s.db, posts...); err != nil{
+		return []models.SearchResult{}, fmt.Errorf("%s: %w", op, err)
+	}
+	if err = attachReactions(ctx, s.db, posts...); err != nil{
+		return []models.SearchResult{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return results, nil
+}
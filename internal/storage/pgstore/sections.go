@@ -0,0 +1,207 @@
+package pgstore
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/RomanKovalev007/mai_news/internal/models"
+	"github.com/RomanKovalev007/mai_news/internal/storage"
+	"github.com/lib/pq"
+)
+
+// GetSections returns every section, ordered by title.
+func (s *Storage) GetSections(ctx context.Context) ([]models.Section, error){
+	op := "storage.pgstore.GetSections"
+
+	rows, err := s.db.QueryContext(ctx, "SELECT id, slug, title, description, created_at FROM sections ORDER BY title, id")
+	if err != nil{
+		return []models.Section{}, fmt.Errorf("%s: failed to get sections: %w", op, err)
+	}
+	defer rows.Close()
+
+	var sections []models.Section
+
+	for rows.Next(){
+		var section models.Section
+		if err := rows.Scan(&section.ID, &section.Slug, &section.Title, &section.Description, &section.CreatedAt); err != nil {
+			return []models.Section{}, fmt.Errorf("%s: scan row: %w", op, err)
+		}
+		section.CreatedAt = section.CreatedAt.UTC()
+		sections = append(sections, section)
+	}
+
+	if err = rows.Err(); err != nil{
+		return []models.Section{}, fmt.Errorf("%s: rows err: %w", op, err)
+	}
+	rows.Close()
+
+	ptrs := make([]*models.Section, len(sections))
+	for i := range sections{
+		ptrs[i] = &sections[i]
+	}
+	if err = attachEditors(ctx, s.db, ptrs...); err != nil{
+		return []models.Section{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return sections, nil
+}
+
+func (s *Storage) GetSection(ctx context.Context, slug string) (models.Section, error){
+	op := "storage.pgstore.GetSection"
+
+	var section models.Section
+	err := s.db.QueryRowContext(ctx, "SELECT id, slug, title, description, created_at FROM sections WHERE slug = $1", slug).
+		Scan(&section.ID, &section.Slug, &section.Title, &section.Description, &section.CreatedAt)
+	if err != nil{
+		if errors.Is(err, sql.ErrNoRows){
+			return models.Section{}, storage.ErrSectionNotFound
+		}
+		return models.Section{}, fmt.Errorf("%s: scan row: %w", op, err)
+	}
+	section.CreatedAt = section.CreatedAt.UTC()
+	if err = attachEditors(ctx, s.db, &section); err != nil{
+		return models.Section{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return section, nil
+}
+
+// SaveSection creates a section. It fails with storage.ErrSectionExists if
+// the slug is in use.
+func (s *Storage) SaveSection(ctx context.Context, input models.InputSection) (models.Section, error){
+	op := "storage.pgstore.SaveSection"
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil{
+		return models.Section{}, fmt.Errorf("%s: begin: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	var section models.Section
+	err = tx.QueryRowContext(ctx, `
+	INSERT INTO sections(slug, title, description) VALUES($1, $2, $3)
+	RETURNING id, slug, title, description, created_at`, input.Slug, input.Title, input.Description).
+		Scan(&section.ID, &section.Slug, &section.Title, &section.Description, &section.CreatedAt)
+	if err != nil{
+		if isUniqueViolation(err){
+			return models.Section{}, storage.ErrSectionExists
+		}
+		return models.Section{}, fmt.Errorf("%s: exec statement: %w", op, err)
+	}
+	section.CreatedAt = section.CreatedAt.UTC()
+
+	if err = setEditors(ctx, tx, &section, input.Editors); err != nil{
+		return models.Section{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err = tx.Commit(); err != nil{
+		return models.Section{}, fmt.Errorf("%s: commit: %w", op, err)
+	}
+
+	return section, nil
+}
+
+// PatchSection updates the fields set in patch. Editors, if set, replace
+// the editors of the section.
+func (s *Storage) PatchSection(ctx context.Context, slug string, patch models.SectionPatch) (models.Section, error){
+	op := "storage.pgstore.PatchSection"
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil{
+		return models.Section{}, fmt.Errorf("%s: begin: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	var section models.Section
+	err = tx.QueryRowContext(ctx, `
+	UPDATE sections SET title = COALESCE($1, title), description = COALESCE($2, description)
+	WHERE slug = $3
+	RETURNING id, slug, title, description, created_at`, patch.Title, patch.Description, slug).
+		Scan(&section.ID, &section.Slug, &section.Title, &section.Description, &section.CreatedAt)
+	if err != nil{
+		if errors.Is(err, sql.ErrNoRows){
+			return models.Section{}, storage.ErrSectionNotFound
+		}
+		return models.Section{}, fmt.Errorf("%s: scan row: %w", op, err)
+	}
+	section.CreatedAt = section.CreatedAt.UTC()
+
+	if patch.Editors != nil{
+		err = setEditors(ctx, tx, &section, *patch.Editors)
+	} else {
+		err = attachEditors(ctx, tx, &section)
+	}
+	if err != nil{
+		return models.Section{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err = tx.Commit(); err != nil{
+		return models.Section{}, fmt.Errorf("%s: commit: %w", op, err)
+	}
+
+	return section, nil
+}
+
+// lookupSection returns the id of the section with the given slug.
+func lookupSection(ctx context.Context, tx *sql.Tx, slug string) (int, error){
+	var id int
+	if err := tx.QueryRowContext(ctx, "SELECT id FROM sections WHERE slug = $1", slug).Scan(&id); err != nil{
+		if errors.Is(err, sql.ErrNoRows){
+			return 0, storage.ErrSectionNotFound
+		}
+		return 0, fmt.Errorf("get section: %w", err)
+	}
+	return id, nil
+}
+
+// setEditors replaces the editors of the section within tx and fills them in.
+func setEditors(ctx context.Context, tx *sql.Tx, section *models.Section, emails []string) error{
+	if _, err := tx.ExecContext(ctx, "DELETE FROM section_editors WHERE section_id = $1", section.ID); err != nil{
+		return fmt.Errorf("remove editors: %w", err)
+	}
+	if len(emails) > 0{
+		_, err := tx.ExecContext(ctx, `
+		INSERT INTO section_editors(section_id, email) SELECT $1, unnest($2::text[])
+		ON CONFLICT DO NOTHING`, section.ID, pq.Array(emails))
+		if err != nil{
+			return fmt.Errorf("add editors: %w", err)
+		}
+	}
+	return attachEditors(ctx, tx, section)
+}
+
+// attachEditors fills in the editors of sections with one query.
+func attachEditors(ctx context.Context, q querier, sections ...*models.Section) error{
+	if len(sections) == 0{
+		return nil
+	}
+
+	byID := make(map[int]*models.Section, len(sections))
+	ids := make([]int64, 0, len(sections))
+	for _, section := range sections{
+		section.Editors = []string{}
+		byID[section.ID] = section
+		ids = append(ids, int64(section.ID))
+	}
+
+	rows, err := q.QueryContext(ctx, `
+	SELECT section_id, email FROM section_editors
+	WHERE section_id = ANY($1) ORDER BY email COLLATE "C"`, pq.Array(ids))
+	if err != nil{
+		return fmt.Errorf("get editors: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next(){
+		var id int
+		var email string
+		if err := rows.Scan(&id, &email); err != nil{
+			return fmt.Errorf("scan editor: %w", err)
+		}
+		byID[id].Editors = append(byID[id].Editors, email)
+	}
+
+	return rows.Err()
+}
@@ -0,0 +1,169 @@
+package pgstore
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/RomanKovalev007/mai_news/internal/lib/slug"
+	"github.com/RomanKovalev007/mai_news/internal/models"
+	"github.com/RomanKovalev007/mai_news/internal/storage"
+	"github.com/lib/pq"
+)
+
+const slugTakenQuery = `
+SELECT EXISTS(SELECT 1 FROM post WHERE slug = $1 AND id <> $2)
+	OR EXISTS(SELECT 1 FROM post_slugs WHERE slug = $1 AND post_id <> $2)`
+
+// GetPostBySlug returns the post addressed by slug, now or formerly. A post
+// found by a former slug has a different Slug, which callers redirect to.
+func (s *Storage) GetPostBySlug(ctx context.Context, slug string) (models.OutputPost, error){
+	op := "storage.pgstore.GetPostBySlug"
+
+	var post models.OutputPost
+	err := s.db.QueryRowContext(ctx, `
+	SELECT id, title, content, slug, (SELECT slug FROM sections WHERE sections.id = post.section_id), created_at, updated_at, version FROM post
+	WHERE deleted_at IS NULL AND (slug = $1 OR id = (SELECT post_id FROM post_slugs WHERE slug = $1))`, slug).
+		Scan(&post.ID, &post.Title, &post.Content, &post.Slug, &post.Section, &post.CreatedAt, &post.UpdatedAt, &post.Version)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows){
+			return models.OutputPost{}, storage.ErrPostNotFound
+		}
+		return models.OutputPost{}, fmt.Errorf("%s: scan row: %w", op, err)
+	}
+	inUTC(&post)
+	if err = attachTags(ctx, s.db, &post); err != nil{
+		return models.OutputPost{}, fmt.Errorf("%s: %w", op, err)
+	}
+	if err = attachAuthors(ctx, s.db, &post); err != nil{
+		return models.OutputPost{}, fmt.Errorf("%s: %w", op, err)
+	}
+	if err = attachCommentCounts(ctx, s.db, &post); err != nil{
+		return models.OutputPost{}, fmt.Errorf("%s: %w", op, err)
+	}
+	if err = attachReactions(ctx, s.db, &post); err != nil{
+		return models.OutputPost{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return post, nil
+}
+
+// newSlug returns the slug for a post with the given title: requested if it
+// is set and free, otherwise the first free candidate made from title. Slugs
+// of postID itself, current or former, count as free.
+func newSlug(ctx context.Context, tx *sql.Tx, requested, title string, postID int) (string, error){
+	if requested != ""{
+		var isTaken bool
+		if err := tx.QueryRowContext(ctx, slugTakenQuery, requested, postID).Scan(&isTaken); err != nil{
+			return "", fmt.Errorf("check slug: %w", err)
+		}
+		if isTaken{
+			return "", storage.ErrSlugTaken
+		}
+		return requested, nil
+	}
+
+	base := slug.Make(title)
+	for n := 1; ; n++{
+		candidate := slug.WithSuffix(base, n)
+		var isTaken bool
+		if err := tx.QueryRowContext(ctx, slugTakenQuery, candidate, postID).Scan(&isTaken); err != nil{
+			return "", fmt.Errorf("check slug: %w", err)
+		}
+		if !isTaken{
+			return candidate, nil
+		}
+	}
+}
+
+// patchSlug works out the slug of the post after patch and, if it changes,
+// keeps the current one as a former slug within tx. It returns "" when the
+// slug stays as it is.
+func patchSlug(ctx context.Context, tx *sql.Tx, id int, patch models.PostPatch) (string, error){
+	if patch.Slug == nil && patch.Title == nil{
+		return "", nil
+	}
+
+	var title, current string
+	err := tx.QueryRowContext(ctx, "SELECT title, slug FROM post WHERE id = $1 AND deleted_at IS NULL", id).Scan(&title, &current)
+	if err != nil{
+		if errors.Is(err, sql.ErrNoRows){
+			// the update that follows tells why
+			return "", nil
+		}
+		return "", fmt.Errorf("get slug: %w", err)
+	}
+
+	next := current
+	switch {
+	case patch.Slug != nil:
+		next, err = newSlug(ctx, tx, *patch.Slug, "", id)
+	case *patch.Title != title:
+		next, err = newSlug(ctx, tx, "", *patch.Title, id)
+	}
+	if err != nil || next == current{
+		return "", err
+	}
+
+	if _, err = tx.ExecContext(ctx, "DELETE FROM post_slugs WHERE slug = $1 AND post_id = $2", next, id); err != nil{
+		return "", fmt.Errorf("reclaim slug: %w", err)
+	}
+	if _, err = tx.ExecContext(ctx, "INSERT INTO post_slugs(slug, post_id) VALUES($1, $2)", current, id); err != nil{
+		return "", fmt.Errorf("keep former slug: %w", err)
+	}
+
+	return next, nil
+}
+
+// backfillSlugs gives slugs to the posts created before posts had them.
+func backfillSlugs(ctx context.Context, db *sql.DB) error{
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil{
+		return fmt.Errorf("begin: %w", err)
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, "SELECT id, title FROM post WHERE slug IS NULL ORDER BY id FOR UPDATE")
+	if err != nil{
+		return fmt.Errorf("get posts without slug: %w", err)
+	}
+	var posts []models.OutputPost
+	for rows.Next(){
+		var post models.OutputPost
+		if err = rows.Scan(&post.ID, &post.Title); err != nil{
+			rows.Close()
+			return fmt.Errorf("scan row: %w", err)
+		}
+		posts = append(posts, post)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil{
+		return fmt.Errorf("rows err: %w", err)
+	}
+	if len(posts) == 0{
+		return nil
+	}
+
+	for _, post := range posts{
+		slug, err := newSlug(ctx, tx, "", post.Title, post.ID)
+		if err != nil{
+			return err
+		}
+		if _, err = tx.ExecContext(ctx, "UPDATE post SET slug = $1 WHERE id = $2", slug, post.ID); err != nil{
+			return fmt.Errorf("set slug: %w", err)
+		}
+	}
+
+	if err = tx.Commit(); err != nil{
+		return fmt.Errorf("commit: %w", err)
+	}
+	return nil
+}
+
+// isSlugViolation tells a clash of slugs, which newSlug checks for but a
+// concurrent insert can still cause, from other unique violations.
+func isSlugViolation(err error) bool{
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505" && pqErr.Constraint == "post_slug_idx"
+}
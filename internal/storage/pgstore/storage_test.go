@@ -0,0 +1,109 @@
+package pgstore
+
+import (
+	"database/sql"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/RomanKovalev007/mai_news/internal/storage/storagetest"
+	"github.com/stretchr/testify/require"
+)
+
+// testDSN points at the Postgres used by the tests. It is taken from
+// PGSTORE_TEST_DSN or, when initdb and pg_ctl are on PATH, from a throwaway
+// cluster started in TestMain. Tests are skipped when neither is available.
+var (
+	testDSN    string
+	skipReason string
+)
+
+func TestMain(m *testing.M) {
+	os.Exit(runTests(m))
+}
+
+func runTests(m *testing.M) int {
+	if dsn := os.Getenv("PGSTORE_TEST_DSN"); dsn != "" {
+		testDSN = dsn
+		return m.Run()
+	}
+
+	dir, err := os.MkdirTemp("", "pgstore")
+	if err != nil {
+		skipReason = err.Error()
+		return m.Run()
+	}
+	defer os.RemoveAll(dir)
+
+	stop, dsn, err := startPostgres(dir)
+	if err != nil {
+		skipReason = "no postgres available: " + err.Error()
+		return m.Run()
+	}
+	defer stop()
+
+	testDSN = dsn
+	return m.Run()
+}
+
+func startPostgres(dir string) (stop func(), dsn string, err error) {
+	initdb, err := exec.LookPath("initdb")
+	if err != nil {
+		return nil, "", err
+	}
+	pgctl, err := exec.LookPath("pg_ctl")
+	if err != nil {
+		return nil, "", err
+	}
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, "", err
+	}
+	port := l.Addr().(*net.TCPAddr).Port
+	l.Close()
+
+	data := filepath.Join(dir, "data")
+	if out, err := exec.Command(initdb, "-D", data, "-U", "postgres", "-A", "trust", "--no-sync").CombinedOutput(); err != nil {
+		return nil, "", fmt.Errorf("initdb: %w: %s", err, out)
+	}
+
+	opts := fmt.Sprintf("-p %d -k %s -c listen_addresses=127.0.0.1 -F", port, dir)
+	if out, err := exec.Command(pgctl, "-D", data, "-o", opts, "-l", filepath.Join(dir, "log"), "-w", "start").CombinedOutput(); err != nil {
+		return nil, "", fmt.Errorf("pg_ctl start: %w: %s", err, out)
+	}
+
+	stop = func() {
+		exec.Command(pgctl, "-D", data, "-m", "immediate", "stop").Run()
+	}
+	dsn = fmt.Sprintf("host=127.0.0.1 port=%d user=postgres dbname=postgres sslmode=disable", port)
+	return stop, dsn, nil
+}
+
+// newTestStorage returns a Storage over an empty public schema.
+func newTestStorage(t *testing.T) *Storage {
+	t.Helper()
+	if testDSN == "" {
+		t.Skip(skipReason)
+	}
+
+	db, err := sql.Open("postgres", testDSN)
+	require.NoError(t, err)
+	_, err = db.Exec("DROP SCHEMA public CASCADE; CREATE SCHEMA public;")
+	require.NoError(t, err)
+	require.NoError(t, db.Close())
+
+	s, err := New(testDSN)
+	require.NoError(t, err)
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+func TestStorage(t *testing.T) {
+	storagetest.Run(t, func(t *testing.T) storagetest.Storage {
+		return newTestStorage(t)
+	})
+}
@@ -0,0 +1,55 @@
+package pgstore
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/RomanKovalev007/mai_news/internal/storage"
+)
+
+// RevokeToken records the token id as revoked until expiresAt, after which
+// the token is rejected anyway. It fails with storage.ErrTokenRevoked if the
+// token was revoked before. Revocations past their expiry are dropped.
+func (s *Storage) RevokeToken(ctx context.Context, id string, expiresAt time.Time) error{
+	op := "storage.pgstore.RevokeToken"
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil{
+		return fmt.Errorf("%s: begin: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	if _, err = tx.ExecContext(ctx, "DELETE FROM revoked_tokens WHERE expires_at < $1", time.Now().UTC()); err != nil{
+		return fmt.Errorf("%s: purge expired: %w", op, err)
+	}
+	res, err := tx.ExecContext(ctx, "INSERT INTO revoked_tokens(id, expires_at) VALUES($1, $2) ON CONFLICT DO NOTHING", id, expiresAt.UTC())
+	if err != nil{
+		return fmt.Errorf("%s: exec statement: %w", op, err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: rows affected: %w", op, err)
+	}
+	if n == 0 {
+		return storage.ErrTokenRevoked
+	}
+
+	if err = tx.Commit(); err != nil{
+		return fmt.Errorf("%s: commit: %w", op, err)
+	}
+
+	return nil
+}
+
+func (s *Storage) TokenRevoked(ctx context.Context, id string) (bool, error){
+	op := "storage.pgstore.TokenRevoked"
+
+	var revoked bool
+	if err := s.db.QueryRowContext(ctx, "SELECT EXISTS(SELECT 1 FROM revoked_tokens WHERE id = $1)", id).Scan(&revoked); err != nil{
+		return false, fmt.Errorf("%s: scan row: %w", op, err)
+	}
+
+	return revoked, nil
+}
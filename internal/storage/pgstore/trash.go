@@ -0,0 +1,163 @@
+package pgstore
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/RomanKovalev007/mai_news/internal/models"
+	"github.com/RomanKovalev007/mai_news/internal/storage"
+)
+
+func (s *Storage) GetTrash(ctx context.Context) ([]models.OutputPost, error){
+	op := "storage.pgstore.GetTrash"
+
+	rows, err := s.db.QueryContext(ctx, `
+	SELECT id, title, content, slug, (SELECT slug FROM sections WHERE sections.id = post.section_id), created_at, deleted_at FROM post
+	WHERE deleted_at IS NOT NULL ORDER BY deleted_at DESC`)
+	if err != nil{
+		return []models.OutputPost{}, fmt.Errorf("%s: failed to get trash: %w", op, err)
+	}
+	defer rows.Close()
+
+	posts := []models.OutputPost{}
+
+	for rows.Next(){
+		var post models.OutputPost
+		err := rows.Scan(&post.ID, &post.Title, &post.Content, &post.Slug, &post.Section, &post.CreatedAt, &post.DeletedAt)
+		if err != nil {
+			return []models.OutputPost{}, fmt.Errorf("%s: scan row: %w", op, err)
+		}
+		inUTC(&post)
+		posts = append(posts, post)
+	}
+
+	if err = rows.Err(); err != nil{
+		return []models.OutputPost{}, fmt.Errorf("%s: rows err: %w", op, err)
+	}
+	rows.Close()
+
+	tagged := make([]*models.OutputPost, len(posts))
+	for i := range posts{
+		tagged[i] = &posts[i]
+	}
+	if err = attachTags(ctx, s.db, tagged...); err != nil{
+		return []models.OutputPost{}, fmt.Errorf("%s: %w", op, err)
+	}
+	if err = attachAuthors(ctx, s.db, tagged...); err != nil{
+		return []models.OutputPost{}, fmt.Errorf("%s: %w", op, err)
+	}
+	if err = attachCommentCounts(ctx, s.db, tagged...); err != nil{
+		return []models.OutputPost{}, fmt.Errorf("%s: %w", op, err)
+	}
+	if err = attachReactions(ctx, s.db, tagged...); err != nil{
+		return []models.OutputPost{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return posts, nil
+}
+
+// GetTrashedPost returns a post in the trash. It fails with
+// storage.ErrPostNotFound for posts that are not there.
+func (s *Storage) GetTrashedPost(ctx context.Context, id int) (models.OutputPost, error){
+	op := "storage.pgstore.GetTrashedPost"
+
+	var post models.OutputPost
+	err := s.db.QueryRowContext(ctx, `
+	SELECT id, title, content, slug, (SELECT slug FROM sections WHERE sections.id = post.section_id), created_at, deleted_at FROM post
+	WHERE id = $1 AND deleted_at IS NOT NULL`, id).
+		Scan(&post.ID, &post.Title, &post.Content, &post.Slug, &post.Section, &post.CreatedAt, &post.DeletedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows){
+			return models.OutputPost{}, storage.ErrPostNotFound
+		}
+		return models.OutputPost{}, fmt.Errorf("%s: scan row: %w", op, err)
+	}
+	inUTC(&post)
+
+	if err = attachTags(ctx, s.db, &post); err != nil{
+		return models.OutputPost{}, fmt.Errorf("%s: %w", op, err)
+	}
+	if err = attachAuthors(ctx, s.db, &post); err != nil{
+		return models.OutputPost{}, fmt.Errorf("%s: %w", op, err)
+	}
+	if err = attachCommentCounts(ctx, s.db, &post); err != nil{
+		return models.OutputPost{}, fmt.Errorf("%s: %w", op, err)
+	}
+	if err = attachReactions(ctx, s.db, &post); err != nil{
+		return models.OutputPost{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return post, nil
+}
+
+func (s *Storage) RestorePost(ctx context.Context, id int) (models.OutputPost, error){
+	op := "storage.pgstore.RestorePost"
+
+	var post models.OutputPost
+	err := s.db.QueryRowContext(ctx, `
+	UPDATE post SET deleted_at = NULL, updated_at = $1, version = version + 1
+	WHERE id = $2 AND deleted_at IS NOT NULL
+	RETURNING id, title, content, slug, (SELECT slug FROM sections WHERE sections.id = post.section_id), created_at, updated_at, version`, time.Now().UTC(), id).
+		Scan(&post.ID, &post.Title, &post.Content, &post.Slug, &post.Section, &post.CreatedAt, &post.UpdatedAt, &post.Version)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows){
+			return models.OutputPost{}, storage.ErrPostNotFound
+		}
+		return models.OutputPost{}, fmt.Errorf("%s: scan row: %w", op, err)
+	}
+	inUTC(&post)
+	if err = attachTags(ctx, s.db, &post); err != nil{
+		return models.OutputPost{}, fmt.Errorf("%s: %w", op, err)
+	}
+	if err = attachAuthors(ctx, s.db, &post); err != nil{
+		return models.OutputPost{}, fmt.Errorf("%s: %w", op, err)
+	}
+	if err = attachCommentCounts(ctx, s.db, &post); err != nil{
+		return models.OutputPost{}, fmt.Errorf("%s: %w", op, err)
+	}
+	if err = attachReactions(ctx, s.db, &post); err != nil{
+		return models.OutputPost{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return post, nil
+}
+
+func (s *Storage) PurgePost(ctx context.Context, id int) error{
+	op := "storage.pgstore.PurgePost"
+
+	res, err := s.db.ExecContext(ctx, "DELETE FROM post WHERE id = $1 AND deleted_at IS NOT NULL", id)
+	if err != nil {
+		return fmt.Errorf("%s: failed purge: %w", op, err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: rows affected: %w", op, err)
+	}
+	if n == 0 {
+		return storage.ErrPostNotFound
+	}
+
+	return nil
+}
+
+// PurgeTrash permanently removes posts trashed before deletedBefore and
+// returns how many were removed.
+func (s *Storage) PurgeTrash(ctx context.Context, deletedBefore time.Time) (int, error){
+	op := "storage.pgstore.PurgeTrash"
+
+	res, err := s.db.ExecContext(ctx, "DELETE FROM post WHERE deleted_at IS NOT NULL AND deleted_at < $1", deletedBefore)
+	if err != nil {
+		return 0, fmt.Errorf("%s: failed purge: %w", op, err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("%s: rows affected: %w", op, err)
+	}
+
+	return int(n), nil
+}
@@ -0,0 +1,222 @@
+package pgstore
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/RomanKovalev007/mai_news/internal/models"
+	"github.com/RomanKovalev007/mai_news/internal/storage"
+	"github.com/lib/pq"
+)
+
+// GetUsers returns every user, oldest first.
+func (s *Storage) GetUsers(ctx context.Context) ([]models.User, error){
+	op := "storage.pgstore.GetUsers"
+
+	rows, err := s.db.QueryContext(ctx, "SELECT id, name, email, password_hash, role, created_at FROM users ORDER BY id")
+	if err != nil{
+		return []models.User{}, fmt.Errorf("%s: failed to get users: %w", op, err)
+	}
+	defer rows.Close()
+
+	var users []models.User
+
+	for rows.Next(){
+		user, err := scanUser(rows)
+		if err != nil {
+			return []models.User{}, fmt.Errorf("%s: scan row: %w", op, err)
+		}
+		users = append(users, user)
+	}
+
+	if err = rows.Err(); err != nil{
+		return []models.User{}, fmt.Errorf("%s: rows err: %w", op, err)
+	}
+
+	return users, nil
+}
+
+func (s *Storage) GetUser(ctx context.Context, id int) (models.User, error){
+	op := "storage.pgstore.GetUser"
+
+	user, err := scanUser(s.db.QueryRowContext(ctx, "SELECT id, name, email, password_hash, role, created_at FROM users WHERE id = $1", id))
+	if err != nil{
+		if errors.Is(err, sql.ErrNoRows){
+			return models.User{}, storage.ErrUserNotFound
+		}
+		return models.User{}, fmt.Errorf("%s: scan row: %w", op, err)
+	}
+
+	return user, nil
+}
+
+// GetUserByEmail returns the user with the given email, which is expected
+// lowercased.
+func (s *Storage) GetUserByEmail(ctx context.Context, email string) (models.User, error){
+	op := "storage.pgstore.GetUserByEmail"
+
+	user, err := scanUser(s.db.QueryRowContext(ctx, "SELECT id, name, email, password_hash, role, created_at FROM users WHERE email = $1", email))
+	if err != nil{
+		if errors.Is(err, sql.ErrNoRows){
+			return models.User{}, storage.ErrUserNotFound
+		}
+		return models.User{}, fmt.Errorf("%s: scan row: %w", op, err)
+	}
+
+	return user, nil
+}
+
+// SaveUser creates a user, a reader unless input sets the role. It fails
+// with storage.ErrUserExists if the email is in use.
+func (s *Storage) SaveUser(ctx context.Context, input models.InputUser) (models.User, error){
+	op := "storage.pgstore.SaveUser"
+
+	user, err := scanUser(s.db.QueryRowContext(ctx, `
+	INSERT INTO users(name, email, password_hash, created_at) VALUES($1, $2, $3, $4)
+	RETURNING id, name, email, password_hash, role, created_at`, input.Name, input.Email, input.PasswordHash, input.Role, time.Now().UTC()))
+	if err != nil{
+		if isUniqueViolation(err){
+			return models.User{}, storage.ErrUserExists
+		}
+		return models.User{}, fmt.Errorf("%s: exec statement: %w", op, err)
+	}
+
+	return user, nil
+}
+
+// PatchUser updates the fields set in patch. A new name is a change of the
+// posts of the user, which show it.
+func (s *Storage) PatchUser(ctx context.Context, id int, patch models.UserPatch) (models.User, error){
+	op := "storage.pgstore.PatchUser"
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil{
+		return models.User{}, fmt.Errorf("%s: begin: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	var name string
+	err = tx.QueryRowContext(ctx, "SELECT name FROM users WHERE id = $1 FOR UPDATE", id).Scan(&name)
+	if err != nil{
+		if errors.Is(err, sql.ErrNoRows){
+			return models.User{}, storage.ErrUserNotFound
+		}
+		return models.User{}, fmt.Errorf("%s: lock user: %w", op, err)
+	}
+
+	user, err := scanUser(tx.QueryRowContext(ctx, `
+	UPDATE users SET name = COALESCE($1, name), email = COALESCE($2, email), password_hash = COALESCE($3, password_hash),
+	role = COALESCE($4, role)
+	WHERE id = $5
+	RETURNING id, name, email, password_hash, role, created_at`, patch.Name, patch.Email, patch.PasswordHash, patch.Role, id))
+	if err != nil{
+		if isUniqueViolation(err){
+			return models.User{}, storage.ErrUserExists
+		}
+		return models.User{}, fmt.Errorf("%s: scan row: %w", op, err)
+	}
+
+	if user.Name != name{
+		if err = touchAuthored(ctx, tx, id); err != nil{
+			return models.User{}, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	if err = tx.Commit(); err != nil{
+		return models.User{}, fmt.Errorf("%s: commit: %w", op, err)
+	}
+
+	return user, nil
+}
+
+// DeleteUser deletes the user and their API keys. Their posts stay, without
+// an author.
+func (s *Storage) DeleteUser(ctx context.Context, id int) error{
+	op := "storage.pgstore.DeleteUser"
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil{
+		return fmt.Errorf("%s: begin: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	if err = touchAuthored(ctx, tx, id); err != nil{
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	res, err := tx.ExecContext(ctx, "DELETE FROM users WHERE id = $1", id)
+	if err != nil {
+		return fmt.Errorf("%s: failed delete: %w", op, err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: rows affected: %w", op, err)
+	}
+	if n == 0 {
+		return storage.ErrUserNotFound
+	}
+
+	if err = tx.Commit(); err != nil{
+		return fmt.Errorf("%s: commit: %w", op, err)
+	}
+
+	return nil
+}
+
+func scanUser(row scanner) (models.User, error){
+	var user models.User
+	if err := row.Scan(&user.ID, &user.Name, &user.Email, &user.PasswordHash, &user.Role, &user.CreatedAt); err != nil{
+		return models.User{}, err
+	}
+	user.CreatedAt = user.CreatedAt.UTC()
+	return user, nil
+}
+
+// touchAuthored records a change of the posts of the user, whose author is
+// about to change.
+func touchAuthored(ctx context.Context, tx *sql.Tx, userID int) error{
+	_, err := tx.ExecContext(ctx, `
+	UPDATE post SET updated_at = $1, version = version + 1
+	WHERE author_id = $2`, time.Now().UTC(), userID)
+	if err != nil{
+		return fmt.Errorf("touch authored posts: %w", err)
+	}
+	return nil
+}
+
+// attachAuthors fills in the authors of posts with one query.
+func attachAuthors(ctx context.Context, q querier, posts ...*models.OutputPost) error{
+	if len(posts) == 0{
+		return nil
+	}
+
+	byID := make(map[int]*models.OutputPost, len(posts))
+	ids := make([]int64, 0, len(posts))
+	for _, post := range posts{
+		byID[post.ID] = post
+		ids = append(ids, int64(post.ID))
+	}
+
+	rows, err := q.QueryContext(ctx, `
+	SELECT p.id, u.id, u.name FROM post p JOIN users u ON u.id = p.author_id
+	WHERE p.id = ANY($1)`, pq.Array(ids))
+	if err != nil{
+		return fmt.Errorf("get authors: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next(){
+		var id int
+		var author models.Author
+		if err := rows.Scan(&id, &author.ID, &author.Name); err != nil{
+			return fmt.Errorf("scan author: %w", err)
+		}
+		byID[id].Author = &author
+	}
+
+	return rows.Err()
+}
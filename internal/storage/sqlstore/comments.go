@@ -0,0 +1,246 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/RomanKovalev007/mai_news/internal/models"
+	"github.com/RomanKovalev007/mai_news/internal/storage"
+)
+
+// commentColumns are the columns scanComment reads, in its order.
+const commentColumns = `id, post_id, parent_id, author_id, (SELECT name FROM users WHERE users.id = comments.author_id),
+	content, status, created_at, updated_at`
+
+// visibleComments is a recursive query of the comments shown under posts:
+// the approved comments whose parents are shown too. The posts are
+// selected by the condition filled in for %s.
+const visibleComments = `
+	WITH RECURSIVE visible(id, post_id) AS (
+		SELECT id, post_id FROM comments WHERE %s AND parent_id IS NULL AND status = 'approved'
+		UNION ALL
+		SELECT c.id, c.post_id FROM comments c JOIN visible v ON c.parent_id = v.id WHERE c.status = 'approved')`
+
+// GetComments returns the comments shown under the post, oldest first. It
+// fails with storage.ErrPostNotFound if the post does not exist or is in
+// the trash.
+func (s *Storage) GetComments(ctx context.Context, postID int) ([]models.Comment, error){
+	op := "storage.sqlstore.GetComments"
+
+	var exists bool
+	if err := s.stmts.postExists.QueryRowContext(ctx, postID).Scan(&exists); err != nil{
+		return []models.Comment{}, fmt.Errorf("%s: find post: %w", op, err)
+	}
+	if !exists{
+		return []models.Comment{}, storage.ErrPostNotFound
+	}
+
+	return s.queryComments(ctx, op, s.stmts.getComments, postID)
+}
+
+// GetCommentQueue returns the comments awaiting moderation on posts outside
+// the trash, oldest first. Unless sections is nil, only those on posts of
+// the sections with these slugs.
+func (s *Storage) GetCommentQueue(ctx context.Context, sections []string) ([]models.Comment, error){
+	op := "storage.sqlstore.GetCommentQueue"
+
+	var slugList any
+	if sections != nil{
+		list, err := json.Marshal(sections)
+		if err != nil{
+			return []models.Comment{}, fmt.Errorf("%s: encode sections: %w", op, err)
+		}
+		slugList = string(list)
+	}
+
+	return s.queryComments(ctx, op, s.stmts.getCommentQueue, slugList)
+}
+
+func (s *Storage) queryComments(ctx context.Context, op string, stmt *sql.Stmt, args ...any) ([]models.Comment, error){
+	rows, err := stmt.QueryContext(ctx, args...)
+	if err != nil{
+		return []models.Comment{}, fmt.Errorf("%s: failed to get comments: %w", op, err)
+	}
+	defer rows.Close()
+
+	var comments []models.Comment
+
+	for rows.Next(){
+		comment, err := scanComment(rows)
+		if err != nil {
+			return []models.Comment{}, fmt.Errorf("%s: scan row: %w", op, err)
+		}
+		comments = append(comments, comment)
+	}
+
+	if err = rows.Err(); err != nil{
+		return []models.Comment{}, fmt.Errorf("%s: rows err: %w", op, err)
+	}
+
+	return comments, nil
+}
+
+// GetComment returns a comment in any status.
+func (s *Storage) GetComment(ctx context.Context, id int) (models.Comment, error){
+	op := "storage.sqlstore.GetComment"
+
+	comment, err := scanComment(s.stmts.getComment.QueryRowContext(ctx, id))
+	if err != nil{
+		if errors.Is(err, sql.ErrNoRows){
+			return models.Comment{}, storage.ErrCommentNotFound
+		}
+		return models.Comment{}, fmt.Errorf("%s: scan row: %w", op, err)
+	}
+
+	return comment, nil
+}
+
+// SaveComment stores a new comment. It fails with storage.ErrPostNotFound if
+// the post does not exist or is in the trash, with
+// storage.ErrCommentNotFound if the parent is not a shown comment of the
+// same post and with storage.ErrUserNotFound if the author does not exist.
+func (s *Storage) SaveComment(ctx context.Context, input models.InputComment) (models.Comment, error){
+	op := "storage.sqlstore.SaveComment"
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil{
+		return models.Comment{}, fmt.Errorf("%s: begin: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	var exists bool
+	if err = tx.StmtContext(ctx, s.stmts.postExists).QueryRowContext(ctx, input.PostID).Scan(&exists); err != nil{
+		return models.Comment{}, fmt.Errorf("%s: find post: %w", op, err)
+	}
+	if !exists{
+		return models.Comment{}, storage.ErrPostNotFound
+	}
+
+	var parentID, authorID sql.NullInt64
+	if input.ParentID != 0{
+		err = tx.StmtContext(ctx, s.stmts.commentShown).QueryRowContext(ctx, input.ParentID, input.PostID).Scan(&exists)
+		if err != nil{
+			return models.Comment{}, fmt.Errorf("%s: find parent: %w", op, err)
+		}
+		if !exists{
+			return models.Comment{}, storage.ErrCommentNotFound
+		}
+		parentID = sql.NullInt64{Int64: int64(input.ParentID), Valid: true}
+	}
+	if input.AuthorID != 0{
+		authorID = sql.NullInt64{Int64: int64(input.AuthorID), Valid: true}
+	}
+
+	now := time.Now().UTC()
+	comment, err := scanComment(tx.StmtContext(ctx, s.stmts.saveComment).QueryRowContext(ctx,
+		input.PostID, parentID, authorID, input.Content, input.Status, now, now))
+	if err != nil{
+		if isForeignKeyViolation(err){
+			return models.Comment{}, storage.ErrUserNotFound
+		}
+		return models.Comment{}, fmt.Errorf("%s: scan row: %w", op, err)
+	}
+
+	if err = tx.Commit(); err != nil{
+		return models.Comment{}, fmt.Errorf("%s: commit: %w", op, err)
+	}
+
+	return comment, nil
+}
+
+// PatchComment updates the fields set in patch. A new content changes the
+// update time of the comment, a new status does not.
+func (s *Storage) PatchComment(ctx context.Context, id int, patch models.CommentPatch) (models.Comment, error){
+	op := "storage.sqlstore.PatchComment"
+
+	var updatedAt *time.Time
+	if patch.Content != nil{
+		now := time.Now().UTC()
+		updatedAt = &now
+	}
+
+	comment, err := scanComment(s.stmts.patchComment.QueryRowContext(ctx, patch.Content, patch.Status, updatedAt, id))
+	if err != nil{
+		if errors.Is(err, sql.ErrNoRows){
+			return models.Comment{}, storage.ErrCommentNotFound
+		}
+		return models.Comment{}, fmt.Errorf("%s: scan row: %w", op, err)
+	}
+
+	return comment, nil
+}
+
+// DeleteComment deletes a comment together with the replies to it.
+func (s *Storage) DeleteComment(ctx context.Context, id int) error{
+	op := "storage.sqlstore.DeleteComment"
+
+	res, err := s.stmts.deleteComment.ExecContext(ctx, id)
+	if err != nil {
+		return fmt.Errorf("%s: failed delete: %w", op, err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: rows affected: %w", op, err)
+	}
+	if n == 0 {
+		return storage.ErrCommentNotFound
+	}
+
+	return nil
+}
+
+// attachCommentCounts fills in the comment counts of posts with one query
+// of commentCounts, which may be bound to a transaction.
+func attachCommentCounts(ctx context.Context, commentCounts *sql.Stmt, posts ...*models.OutputPost) error{
+	if len(posts) == 0{
+		return nil
+	}
+
+	byID := make(map[int]*models.OutputPost, len(posts))
+	ids := make([]int, 0, len(posts))
+	for _, post := range posts{
+		byID[post.ID] = post
+		ids = append(ids, post.ID)
+	}
+	idList, err := json.Marshal(ids)
+	if err != nil{
+		return fmt.Errorf("encode post ids: %w", err)
+	}
+
+	rows, err := commentCounts.QueryContext(ctx, string(idList))
+	if err != nil{
+		return fmt.Errorf("get comment counts: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next(){
+		var id, count int
+		if err := rows.Scan(&id, &count); err != nil{
+			return fmt.Errorf("scan comment count: %w", err)
+		}
+		byID[id].CommentCount = count
+	}
+
+	return rows.Err()
+}
+
+func scanComment(row scanner) (models.Comment, error){
+	var comment models.Comment
+	var parentID, authorID sql.NullInt64
+	var authorName sql.NullString
+	err := row.Scan(&comment.ID, &comment.PostID, &parentID, &authorID, &authorName,
+		&comment.Content, &comment.Status, &comment.CreatedAt, &comment.UpdatedAt)
+	if err != nil{
+		return models.Comment{}, err
+	}
+	comment.ParentID = int(parentID.Int64)
+	if authorID.Valid{
+		comment.Author = &models.Author{ID: int(authorID.Int64), Name: authorName.String}
+	}
+	return comment, nil
+}
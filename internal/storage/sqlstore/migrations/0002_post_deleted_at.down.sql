@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS post_deleted_at_idx;
+ALTER TABLE post DROP COLUMN deleted_at;
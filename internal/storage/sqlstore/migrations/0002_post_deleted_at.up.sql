@@ -0,0 +1,2 @@
+ALTER TABLE post ADD COLUMN deleted_at DATETIME;
+CREATE INDEX IF NOT EXISTS post_deleted_at_idx ON post(deleted_at);
@@ -0,0 +1 @@
+CREATE INDEX IF NOT EXISTS post_created_at_id ON post(created_at, id);
@@ -0,0 +1,3 @@
+ALTER TABLE post ADD COLUMN updated_at DATETIME;
+UPDATE post SET updated_at = strftime('%Y-%m-%d %H:%M:%f+00:00', created_at);
+CREATE INDEX IF NOT EXISTS post_updated_at_idx ON post(updated_at);
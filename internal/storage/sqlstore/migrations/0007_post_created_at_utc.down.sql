@@ -0,0 +1,2 @@
+-- UTC times are read correctly by every version, so they are kept as they are
+SELECT 1;
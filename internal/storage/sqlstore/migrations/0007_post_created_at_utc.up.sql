@@ -0,0 +1,2 @@
+-- created_at used to be written in the local time zone of the server
+UPDATE post SET created_at = strftime('%Y-%m-%d %H:%M:%f+00:00', created_at) WHERE created_at IS NOT NULL;
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS post_slugs;
+DROP INDEX IF EXISTS post_slug_idx;
+ALTER TABLE post DROP COLUMN slug;
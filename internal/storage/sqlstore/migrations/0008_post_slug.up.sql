@@ -0,0 +1,6 @@
+ALTER TABLE post ADD COLUMN slug TEXT;
+CREATE UNIQUE INDEX IF NOT EXISTS post_slug_idx ON post(slug);
+CREATE TABLE IF NOT EXISTS post_slugs(
+	slug TEXT PRIMARY KEY,
+	post_id INTEGER NOT NULL REFERENCES post(id) ON DELETE CASCADE);
+CREATE INDEX IF NOT EXISTS post_slugs_post_id_idx ON post_slugs(post_id);
@@ -0,0 +1,19 @@
+CREATE TABLE post_old(
+	id INTEGER PRIMARY KEY,
+	title TEXT NOT NULL UNIQUE,
+	content TEXT NOT NULL,
+	created_at DATETIME,
+	deleted_at DATETIME,
+	version INTEGER NOT NULL DEFAULT 1,
+	updated_at DATETIME,
+	slug TEXT);
+INSERT INTO post_old(id, title, content, created_at, deleted_at, version, updated_at, slug)
+SELECT id, title, content, created_at, deleted_at, version, updated_at, slug FROM post;
+DROP TABLE post;
+ALTER TABLE post_old RENAME TO post;
+CREATE INDEX IF NOT EXISTS post_deleted_at_idx ON post(deleted_at);
+CREATE INDEX IF NOT EXISTS post_created_at_id ON post(created_at, id);
+CREATE INDEX IF NOT EXISTS post_updated_at_idx ON post(updated_at);
+CREATE UNIQUE INDEX IF NOT EXISTS post_slug_idx ON post(slug);
+DROP TABLE IF EXISTS section_editors;
+DROP TABLE IF EXISTS sections;
@@ -0,0 +1,35 @@
+CREATE TABLE IF NOT EXISTS sections(
+	id INTEGER PRIMARY KEY,
+	slug TEXT NOT NULL UNIQUE,
+	title TEXT NOT NULL,
+	description TEXT NOT NULL DEFAULT '',
+	created_at DATETIME NOT NULL);
+CREATE TABLE IF NOT EXISTS section_editors(
+	section_id INTEGER NOT NULL REFERENCES sections(id) ON DELETE CASCADE,
+	email TEXT NOT NULL,
+	PRIMARY KEY (section_id, email));
+-- the posts from before sections go to the default one
+INSERT INTO sections(id, slug, title, created_at)
+VALUES(1, 'general', 'Общие новости', strftime('%Y-%m-%d %H:%M:%f+00:00', 'now'));
+
+-- titles become unique per section; SQLite cannot drop a constraint, so post
+-- is copied into a table without it
+CREATE TABLE post_new(
+	id INTEGER PRIMARY KEY,
+	title TEXT NOT NULL,
+	content TEXT NOT NULL,
+	created_at DATETIME,
+	deleted_at DATETIME,
+	version INTEGER NOT NULL DEFAULT 1,
+	updated_at DATETIME,
+	slug TEXT,
+	section_id INTEGER NOT NULL REFERENCES sections(id));
+INSERT INTO post_new(id, title, content, created_at, deleted_at, version, updated_at, slug, section_id)
+SELECT id, title, content, created_at, deleted_at, version, updated_at, slug, 1 FROM post;
+DROP TABLE post;
+ALTER TABLE post_new RENAME TO post;
+CREATE INDEX IF NOT EXISTS post_deleted_at_idx ON post(deleted_at);
+CREATE INDEX IF NOT EXISTS post_created_at_id ON post(created_at, id);
+CREATE INDEX IF NOT EXISTS post_updated_at_idx ON post(updated_at);
+CREATE UNIQUE INDEX IF NOT EXISTS post_slug_idx ON post(slug);
+CREATE UNIQUE INDEX IF NOT EXISTS post_section_title_idx ON post(section_id, title);
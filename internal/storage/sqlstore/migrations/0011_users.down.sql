@@ -0,0 +1,22 @@
+-- SQLite cannot drop a column that references another table, so post is
+-- copied into a table without author_id
+CREATE TABLE post_old(
+	id INTEGER PRIMARY KEY,
+	title TEXT NOT NULL,
+	content TEXT NOT NULL,
+	created_at DATETIME,
+	deleted_at DATETIME,
+	version INTEGER NOT NULL DEFAULT 1,
+	updated_at DATETIME,
+	slug TEXT,
+	section_id INTEGER NOT NULL REFERENCES sections(id));
+INSERT INTO post_old(id, title, content, created_at, deleted_at, version, updated_at, slug, section_id)
+SELECT id, title, content, created_at, deleted_at, version, updated_at, slug, section_id FROM post;
+DROP TABLE post;
+ALTER TABLE post_old RENAME TO post;
+CREATE INDEX IF NOT EXISTS post_deleted_at_idx ON post(deleted_at);
+CREATE INDEX IF NOT EXISTS post_created_at_id ON post(created_at, id);
+CREATE INDEX IF NOT EXISTS post_updated_at_idx ON post(updated_at);
+CREATE UNIQUE INDEX IF NOT EXISTS post_slug_idx ON post(slug);
+CREATE UNIQUE INDEX IF NOT EXISTS post_section_title_idx ON post(section_id, title);
+DROP TABLE IF EXISTS users;
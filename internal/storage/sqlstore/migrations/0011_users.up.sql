@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS users(
+	id INTEGER PRIMARY KEY,
+	name TEXT NOT NULL,
+	email TEXT NOT NULL UNIQUE,
+	password_hash TEXT NOT NULL,
+	created_at DATETIME NOT NULL);
+-- the posts from before users have no author
+ALTER TABLE post ADD COLUMN author_id INTEGER REFERENCES users(id) ON DELETE SET NULL;
+CREATE INDEX IF NOT EXISTS post_author_id_idx ON post(author_id);
@@ -0,0 +1,5 @@
+-- ids of revoked tokens, kept until the tokens expire anyway
+CREATE TABLE IF NOT EXISTS revoked_tokens(
+	id TEXT PRIMARY KEY,
+	expires_at DATETIME NOT NULL);
+CREATE INDEX IF NOT EXISTS revoked_tokens_expires_at_idx ON revoked_tokens(expires_at);
@@ -0,0 +1,11 @@
+-- scopes are space-separated, like the scope of OAuth 2.0
+CREATE TABLE IF NOT EXISTS api_keys(
+	id INTEGER PRIMARY KEY,
+	name TEXT NOT NULL,
+	prefix TEXT NOT NULL,
+	key_hash TEXT NOT NULL UNIQUE,
+	scopes TEXT NOT NULL,
+	user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	created_at DATETIME NOT NULL,
+	last_used_at DATETIME);
+CREATE INDEX IF NOT EXISTS api_keys_user_id_idx ON api_keys(user_id);
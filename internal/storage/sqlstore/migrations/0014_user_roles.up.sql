@@ -0,0 +1,4 @@
+ALTER TABLE users ADD COLUMN role TEXT NOT NULL DEFAULT 'reader'
+	CHECK (role IN ('reader', 'author', 'editor', 'admin'));
+-- users from before roles could do everything
+UPDATE users SET role = 'admin';
@@ -0,0 +1,14 @@
+-- replies go away with the comment they answer, and comments with their
+-- post; comments of deleted users stay, without an author
+CREATE TABLE IF NOT EXISTS comments(
+	id INTEGER PRIMARY KEY,
+	post_id INTEGER NOT NULL REFERENCES post(id) ON DELETE CASCADE,
+	parent_id INTEGER REFERENCES comments(id) ON DELETE CASCADE,
+	author_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+	content TEXT NOT NULL,
+	status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'approved', 'rejected')),
+	created_at DATETIME NOT NULL,
+	updated_at DATETIME NOT NULL);
+CREATE INDEX IF NOT EXISTS comments_post_id_idx ON comments(post_id, status);
+CREATE INDEX IF NOT EXISTS comments_parent_id_idx ON comments(parent_id);
+CREATE INDEX IF NOT EXISTS comments_status_idx ON comments(status, created_at);
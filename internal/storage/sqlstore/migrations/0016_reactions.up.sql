@@ -0,0 +1,33 @@
+-- a user or an anonymous client has one reaction per post; reactions go
+-- away with the post and with the user
+CREATE TABLE IF NOT EXISTS post_reactions(
+	post_id INTEGER NOT NULL REFERENCES post(id) ON DELETE CASCADE,
+	reactor TEXT NOT NULL,
+	user_id INTEGER REFERENCES users(id) ON DELETE CASCADE,
+	kind TEXT NOT NULL,
+	created_at DATETIME NOT NULL,
+	PRIMARY KEY (post_id, reactor));
+CREATE INDEX IF NOT EXISTS post_reactions_user_id_idx ON post_reactions(user_id);
+
+-- counts of reactions by kind, kept by the triggers below so that lists of
+-- posts read them without counting
+CREATE TABLE IF NOT EXISTS post_reaction_counts(
+	post_id INTEGER NOT NULL REFERENCES post(id) ON DELETE CASCADE,
+	kind TEXT NOT NULL,
+	count INTEGER NOT NULL,
+	PRIMARY KEY (post_id, kind));
+
+CREATE TRIGGER IF NOT EXISTS post_reactions_ai AFTER INSERT ON post_reactions BEGIN
+	INSERT INTO post_reaction_counts(post_id, kind, count) VALUES (new.post_id, new.kind, 1)
+		ON CONFLICT(post_id, kind) DO UPDATE SET count = count + 1;
+END;
+CREATE TRIGGER IF NOT EXISTS post_reactions_ad AFTER DELETE ON post_reactions BEGIN
+	UPDATE post_reaction_counts SET count = count - 1 WHERE post_id = old.post_id AND kind = old.kind;
+	DELETE FROM post_reaction_counts WHERE post_id = old.post_id AND kind = old.kind AND count <= 0;
+END;
+CREATE TRIGGER IF NOT EXISTS post_reactions_au AFTER UPDATE OF kind ON post_reactions BEGIN
+	UPDATE post_reaction_counts SET count = count - 1 WHERE post_id = old.post_id AND kind = old.kind;
+	DELETE FROM post_reaction_counts WHERE post_id = old.post_id AND kind = old.kind AND count <= 0;
+	INSERT INTO post_reaction_counts(post_id, kind, count) VALUES (new.post_id, new.kind, 1)
+		ON CONFLICT(post_id, kind) DO UPDATE SET count = count + 1;
+END;
@@ -0,0 +1,7 @@
+-- views of posts per UTC day (YYYY-MM-DD), written in batches by the view
+-- aggregator
+CREATE TABLE IF NOT EXISTS post_views(
+	post_id INTEGER NOT NULL REFERENCES post(id) ON DELETE CASCADE,
+	day TEXT NOT NULL,
+	views INTEGER NOT NULL,
+	PRIMARY KEY (post_id, day));
@@ -0,0 +1,13 @@
+-- SQLite cannot drop a column that references another table, so
+-- post_revisions is copied into a table without revised_by
+CREATE TABLE post_revisions_old(
+	post_id INTEGER NOT NULL REFERENCES post(id) ON DELETE CASCADE,
+	rev INTEGER NOT NULL,
+	title TEXT NOT NULL,
+	content TEXT NOT NULL,
+	revised_at DATETIME NOT NULL,
+	PRIMARY KEY (post_id, rev));
+INSERT INTO post_revisions_old(post_id, rev, title, content, revised_at)
+SELECT post_id, rev, title, content, revised_at FROM post_revisions;
+DROP TABLE post_revisions;
+ALTER TABLE post_revisions_old RENAME TO post_revisions;
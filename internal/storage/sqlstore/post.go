@@ -1,58 +1,212 @@
 package sqlstore
 
 import (
+	"context"
 	"database/sql"
 	"errors"
+	"encoding/json"
 	"fmt"
+	"slices"
+	"strings"
 	"time"
 
+	"github.com/RomanKovalev007/mai_news/internal/lib/jsonpatch"
+	"github.com/RomanKovalev007/mai_news/internal/lib/tags"
 	"github.com/RomanKovalev007/mai_news/internal/models"
 	"github.com/RomanKovalev007/mai_news/internal/storage"
-	_ "github.com/mattn/go-sqlite3"
+	"github.com/mattn/go-sqlite3"
 )
 
-func (s *Storage) GetAllPosts() ([]models.OutputPost, error){
+// sortColumns maps the sortable fields to their columns. Only these names
+// are ever put into the text of a query.
+var sortColumns = map[models.SortField]string{
+	models.SortByCreatedAt: "created_at",
+	models.SortByTitle: "title",
+}
+
+// GetAllPosts returns a page of the posts matching filter, in its order.
+func (s *Storage) GetAllPosts(ctx context.Context, filter models.PostFilter, page models.Page) (models.PostsPage, error){
 	op := "storage.sqlstore.GetAllPosts"
 
-	stmt, err := s.db.Prepare("SELECT id, title, content, created_at FROM post")
-		if err != nil {
-			return []models.OutputPost{}, fmt.Errorf("%s: prepare statement: %w", op, err)
-		}
+	// read before the posts, so that a change in between makes the page
+	// newer than its LastModified rather than the other way round
+	var result models.PostsPage
+	err := s.stmts.lastModified.QueryRowContext(ctx).Scan(&result.LastModified)
+	if err != nil && !errors.Is(err, sql.ErrNoRows){
+		return models.PostsPage{}, fmt.Errorf("%s: failed to get last modified: %w", op, err)
+	}
 
-	rows, err := stmt.Query()
+	query, args := postsQuery(filter, page)
+	rows, err := s.db.QueryContext(ctx, query, args...)
 	if err != nil{
-		return []models.OutputPost{}, fmt.Errorf("%s: failed to get all posts: %w", op, err)
+		return models.PostsPage{}, fmt.Errorf("%s: failed to get all posts: %w", op, err)
 	}
+	defer rows.Close()
 
-	var posts []models.OutputPost
+	var last models.Cursor
 
 	for rows.Next(){
+		if len(result.Posts) == page.Limit{
+			result.Next = &last
+			break
+		}
+
 		var post models.OutputPost
-		err := rows.Scan(&post.ID, &post.Title, &post.Content, &post.CreatedAt)
+		err := rows.Scan(&post.ID, &post.Title, &post.Content, &post.Slug, &post.Section, &last.CreatedAt, &post.UpdatedAt, &post.Version)
 		if err != nil {
-			return []models.OutputPost{}, fmt.Errorf("%s: scan row: %w", op, err)
+			return models.PostsPage{}, fmt.Errorf("%s: scan row: %w", op, err)
 		}
-		posts = append(posts, post)
+		last.ID, last.Title = post.ID, post.Title
+		post.CreatedAt = last.CreatedAt
+		result.Posts = append(result.Posts, post)
 	}
 
 	if err = rows.Err(); err != nil{
-		return []models.OutputPost{}, fmt.Errorf("%s: rows err: %w", op, err)
+		return models.PostsPage{}, fmt.Errorf("%s: rows err: %w", op, err)
+	}
+	rows.Close()
+
+	posts := make([]*models.OutputPost, len(result.Posts))
+	for i := range result.Posts{
+		posts[i] = &result.Posts[i]
+	}
+	if err = attachTags(ctx, s.stmts.tagsOfPosts, posts...); err != nil{
+		return models.PostsPage{}, fmt.Errorf("%s: %w", op, err)
+	}
+	if err = attachAuthors(ctx, s.stmts.authorsOfPosts, posts...); err != nil{
+		return models.PostsPage{}, fmt.Errorf("%s: %w", op, err)
+	}
+	if err = attachCommentCounts(ctx, s.stmts.commentCounts, posts...); err != nil{
+		return models.PostsPage{}, fmt.Errorf("%s: %w", op, err)
+	}
+	if err = attachReactions(ctx, s.stmts.reactionCounts, posts...); err != nil{
+		return models.PostsPage{}, fmt.Errorf("%s: %w", op, err)
 	}
 	
-	return posts, nil
+	return result, nil
+}
+
+// postsQuery builds the query of a posts list page. It fetches one extra row
+// to tell whether there is a next page.
+func postsQuery(filter models.PostFilter, page models.Page) (string, []any){
+	// times are stored as text in UTC, so they must be bound in UTC to
+	// compare correctly
+	conds := []string{"deleted_at IS NULL"}
+	var args []any
+	if !filter.From.IsZero(){
+		conds = append(conds, "created_at >= ?")
+		args = append(args, filter.From.UTC())
+	}
+	if !filter.To.IsZero(){
+		conds = append(conds, "created_at < ?")
+		args = append(args, filter.To.UTC())
+	}
+	if filter.TitlePrefix != ""{
+		conds = append(conds, "substr(title, 1, length(?)) = ?")
+		args = append(args, filter.TitlePrefix, filter.TitlePrefix)
+	}
+	if filter.Section != ""{
+		conds = append(conds, "section_id = (SELECT id FROM sections WHERE slug = ?)")
+		args = append(args, filter.Section)
+	}
+	if filter.AuthorID != 0{
+		conds = append(conds, "author_id = ?")
+		args = append(args, filter.AuthorID)
+	}
+	if names := tags.Set(filter.Tags); len(names) > 0{
+		tagged := `id IN (SELECT pt.post_id FROM post_tags pt JOIN tags t ON t.id = pt.tag_id
+		WHERE t.name IN (SELECT value FROM json_each(?))`
+		list, _ := json.Marshal(names)
+		args = append(args, string(list))
+		if filter.AllTags{
+			tagged += " GROUP BY pt.post_id HAVING COUNT(*) = ?"
+			args = append(args, len(names))
+		}
+		conds = append(conds, tagged + ")")
+	}
+
+	column, ok := sortColumns[filter.SortBy]
+	if !ok{
+		column = sortColumns[models.SortByCreatedAt]
+	}
+	cmp, dir := ">", "ASC"
+	if filter.Desc{
+		cmp, dir = "<", "DESC"
+	}
+
+	if page.After != nil{
+		var after any = page.After.CreatedAt.UTC()
+		if filter.SortBy == models.SortByTitle{
+			after = page.After.Title
+		}
+		conds = append(conds, fmt.Sprintf("(%s, id) %s (?, ?)", column, cmp))
+		args = append(args, after, page.After.ID)
+	}
+
+	query := fmt.Sprintf(`
+	SELECT id, title, content, slug, (SELECT slug FROM sections WHERE sections.id = post.section_id), created_at, updated_at, version FROM post WHERE %s
+	ORDER BY %s %s, id %s LIMIT ?`, strings.Join(conds, " AND "), column, dir, dir)
+	args = append(args, page.Limit+1)
+
+	return query, args
 }
 
-func (s *Storage) SavePost(inputPost models.InputPost) (models.OutputPost, error){
+// SavePost stores a new post under inputPost.Slug, or under a slug made from
+// its title when that is empty. It fails with storage.ErrSectionNotFound if
+// the section of the post does not exist and with storage.ErrUserNotFound if
+// its author does not.
+func (s *Storage) SavePost(ctx context.Context, inputPost models.InputPost) (models.OutputPost, error){
 	op := "storage.sqlstore.SavePost"
 
-	stmt, err := s.db.Prepare("INSERT INTO post(title, content, created_at) VALUES(?, ?, ?)")
-	if err != nil {
-		return models.OutputPost{}, fmt.Errorf("%s: prepare statement: %w", op, err)
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil{
+		return models.OutputPost{}, fmt.Errorf("%s: begin: %w", op, err)
 	}
+	defer tx.Rollback()
 
-	now := time.Now()
-	res, err := stmt.Exec(inputPost.Title, inputPost.Content, now)
+	section := inputPost.Section
+	if section == ""{
+		section = models.DefaultSection
+	}
+	sectionID, err := s.sectionID(ctx, tx, section)
+	if err != nil{
+		if errors.Is(err, storage.ErrSectionNotFound){
+			return models.OutputPost{}, err
+		}
+		return models.OutputPost{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	slug, err := s.newSlug(ctx, tx, inputPost.Slug, inputPost.Title, 0)
+	if err != nil{
+		if errors.Is(err, storage.ErrSlugTaken){
+			return models.OutputPost{}, err
+		}
+		return models.OutputPost{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	var author *models.Author
+	var authorID sql.NullInt64
+	if inputPost.AuthorID != 0{
+		user, err := scanUser(tx.StmtContext(ctx, s.stmts.getUser).QueryRowContext(ctx, inputPost.AuthorID))
+		if err != nil{
+			if errors.Is(err, sql.ErrNoRows){
+				return models.OutputPost{}, storage.ErrUserNotFound
+			}
+			return models.OutputPost{}, fmt.Errorf("%s: get author: %w", op, err)
+		}
+		author = &models.Author{ID: user.ID, Name: user.Name}
+		authorID = sql.NullInt64{Int64: int64(user.ID), Valid: true}
+	}
+
+	now := time.Now().UTC()
+	res, err := tx.StmtContext(ctx, s.stmts.savePost).ExecContext(ctx, inputPost.Title, inputPost.Content, slug, sectionID, authorID, now, now)
 	if err != nil {
+		if isSlugViolation(err){
+			return models.OutputPost{}, storage.ErrSlugTaken
+		}
+		if isUniqueViolation(err){
+			return models.OutputPost{}, storage.ErrPostExists
+		}
 		return models.OutputPost{}, fmt.Errorf("%s: exec statement: %w", op, err)
 	}
 
@@ -61,74 +215,278 @@ func (s *Storage) SavePost(inputPost models.InputPost) (models.OutputPost, error
 		return models.OutputPost{}, fmt.Errorf("%s: get last insert id: %w", op, err)
 	}
 
+	names := tags.Set(inputPost.Tags)
+	if err = s.setTags(ctx, tx, int(id), names); err != nil{
+		return models.OutputPost{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err = tx.Commit(); err != nil{
+		return models.OutputPost{}, fmt.Errorf("%s: commit: %w", op, err)
+	}
+
 	post := models.OutputPost{
 		ID: int(id),
 		Title: inputPost.Title,
 		Content: inputPost.Content,
-		CreatedAt: now.Format("2006-01-02 15:04:05.999999999 -0700 MST"),
+		Slug: slug,
+		Tags: names,
+		Section: section,
+		Author: author,
+		CreatedAt: now,
+		UpdatedAt: now,
+		Version: 1,
 	}
 
 	return post, nil
 }
 
-func (s *Storage) GetPost(id int) (models.OutputPost, error){
+func (s *Storage) GetPost(ctx context.Context, id int) (models.OutputPost, error){
 	op := "storage.sqlstore.GetPost"
 
-	stmt, err := s.db.Prepare("SELECT id, title, content, created_at FROM post WHERE id = ?")
-	if err != nil{
-		return models.OutputPost{}, fmt.Errorf("%s: prepare statement: %w", op, err)
-	}
-
-	row := stmt.QueryRow(id)
+	row := s.stmts.getPost.QueryRowContext(ctx, id)
 	var post models.OutputPost
-	err = row.Scan(&post.ID, &post.Title, &post.Content, &post.CreatedAt)
+	err := row.Scan(&post.ID, &post.Title, &post.Content, &post.Slug, &post.Section, &post.CreatedAt, &post.UpdatedAt, &post.Version)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows){
 			return models.OutputPost{}, storage.ErrPostNotFound
 		}
 		return models.OutputPost{}, fmt.Errorf("%s: scan row: %w", op, err)
 	}
+	if err = attachTags(ctx, s.stmts.tagsOfPosts, &post); err != nil{
+		return models.OutputPost{}, fmt.Errorf("%s: %w", op, err)
+	}
+	if err = attachAuthors(ctx, s.stmts.authorsOfPosts, &post); err != nil{
+		return models.OutputPost{}, fmt.Errorf("%s: %w", op, err)
+	}
+	if err = attachCommentCounts(ctx, s.stmts.commentCounts, &post); err != nil{
+		return models.OutputPost{}, fmt.Errorf("%s: %w", op, err)
+	}
+	if err = attachReactions(ctx, s.stmts.reactionCounts, &post); err != nil{
+		return models.OutputPost{}, fmt.Errorf("%s: %w", op, err)
+	}
 
 	return post, nil
 }
 
-func (s *Storage) PatchPost(id int, inputPost models.InputPost) (models.OutputPost, error){
-	op := "storage.sqlstore.PutchPost"
+// PatchPost updates the fields set in patch and keeps the previous version of
+// the post as a revision. Unless version is 0 the post is only updated while
+// it is at that version.
+func (s *Storage) PatchPost(ctx context.Context, id, version int, patch models.PostPatch) (models.OutputPost, error){
+	op := "storage.sqlstore.PatchPost"
 
-	stmt, err := s.db.Prepare(`
-	UPDATE post SET title = ?, content = ? WHERE id = ?
-	RETURNING id, title, content, created_at`)
+	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil{
-		return models.OutputPost{}, fmt.Errorf("%s: prepare statement: %w", op, err)
+		return models.OutputPost{}, fmt.Errorf("%s: begin: %w", op, err)
 	}
+	defer tx.Rollback()
 
-	var post models.OutputPost
-	err = stmt.QueryRow(inputPost.Title, inputPost.Content, id).Scan(&post.ID, &post.Title, &post.Content, &post.CreatedAt)
-	if err != nil {
+	post, err := s.patchPost(ctx, tx, id, version, patch)
+	if err != nil{
+		if errors.Is(err, storage.ErrPostNotFound) || errors.Is(err, storage.ErrPostExists) || errors.Is(err, storage.ErrSlugTaken) || errors.Is(err, storage.ErrSectionNotFound) || errors.Is(err, storage.ErrVersionMismatch){
+			return models.OutputPost{}, err
+		}
+		return models.OutputPost{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err = tx.Commit(); err != nil{
+		return models.OutputPost{}, fmt.Errorf("%s: commit: %w", op, err)
+	}
+
+	return post, nil
+}
+
+// ApplyPatch runs JSON Patch operations against the post within one
+// transaction, so test operations see the state the update replaces. Unless
+// version is 0 the post is only updated while it is at that version.
+func (s *Storage) ApplyPatch(ctx context.Context, id, version, editorID int, ops []jsonpatch.Operation) (models.OutputPost, error){
+	op := "storage.sqlstore.ApplyPatch"
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil{
+		return models.OutputPost{}, fmt.Errorf("%s: begin: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	var current models.OutputPost
+	err = tx.StmtContext(ctx, s.stmts.getPost).QueryRowContext(ctx, id).
+		Scan(&current.ID, &current.Title, &current.Content, &current.Slug, &current.Section, &current.CreatedAt, &current.UpdatedAt, &current.Version)
+	if err != nil{
 		if errors.Is(err, sql.ErrNoRows){
 			return models.OutputPost{}, storage.ErrPostNotFound
 		}
 		return models.OutputPost{}, fmt.Errorf("%s: scan row: %w", op, err)
 	}
+	if version != 0 && version != current.Version{
+		return models.OutputPost{}, storage.ErrVersionMismatch
+	}
+
+	if err = attachTags(ctx, tx.StmtContext(ctx, s.stmts.tagsOfPosts), &current); err != nil{
+		return models.OutputPost{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	patch, err := jsonpatch.Apply(ops, current)
+	if err != nil{
+		return models.OutputPost{}, err
+	}
+	patch.EditorID = editorID
+
+	// the version read above guards against writes since then
+	post, err := s.patchPost(ctx, tx, id, current.Version, patch)
+	if err != nil{
+		if errors.Is(err, storage.ErrPostNotFound) || errors.Is(err, storage.ErrPostExists) || errors.Is(err, storage.ErrSlugTaken) || errors.Is(err, storage.ErrSectionNotFound) || errors.Is(err, storage.ErrVersionMismatch){
+			return models.OutputPost{}, err
+		}
+		return models.OutputPost{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err = tx.Commit(); err != nil{
+		return models.OutputPost{}, fmt.Errorf("%s: commit: %w", op, err)
+	}
 
 	return post, nil
 }
 
-func (s *Storage) DeletePost(id int) error{
-	op := "storage.sqlstore.DeletePost"
+// patchPost saves the current version of the post as a revision and updates
+// it within tx. Only the columns set in patch are written; an empty patch
+// leaves the post and its version as they are. Tags and the section are not
+// kept in revisions, but a change of them is a change of the post.
+func (s *Storage) patchPost(ctx context.Context, tx *sql.Tx, id, version int, patch models.PostPatch) (models.OutputPost, error){
+	_, err := tx.StmtContext(ctx, s.stmts.saveRevision).ExecContext(ctx,
+		time.Now().UTC(), patch.EditorID, id, patch.Title, patch.Content)
+	if err != nil{
+		return models.OutputPost{}, fmt.Errorf("save revision: %w", err)
+	}
 
-	stmt, err := s.db.Prepare("DELETE FROM post WHERE id = ?")
+	slug, err := s.patchSlug(ctx, tx, id, patch)
 	if err != nil{
-		return fmt.Errorf("%s: prepare statement: %w", op, err)
+		return models.OutputPost{}, err
 	}
 
-	_, err = stmt.Exec(id)
+	current := models.OutputPost{ID: id}
+	if err = attachTags(ctx, tx.StmtContext(ctx, s.stmts.tagsOfPosts), &current); err != nil{
+		return models.OutputPost{}, err
+	}
+	names, tagsChanged := current.Tags, false
+	if patch.Tags != nil && !slices.Equal(tags.Set(*patch.Tags), names){
+		names, tagsChanged = tags.Set(*patch.Tags), true
+	}
+
+	var sets []string
+	var args []any
+	if patch.Title != nil{
+		sets = append(sets, "title = ?")
+		args = append(args, *patch.Title)
+	}
+	if patch.Content != nil{
+		sets = append(sets, "content = ?")
+		args = append(args, *patch.Content)
+	}
+	if slug != ""{
+		sets = append(sets, "slug = ?")
+		args = append(args, slug)
+	}
+	if patch.Section != nil{
+		sectionID, err := s.sectionID(ctx, tx, *patch.Section)
+		if err != nil{
+			return models.OutputPost{}, err
+		}
+		sets = append(sets, "section_id = ?")
+		args = append(args, sectionID)
+	}
+	if len(sets) == 0 && !tagsChanged{
+		sets = append(sets, "version = version")
+	} else {
+		sets = append(sets, "updated_at = ?", "version = version + 1")
+		args = append(args, time.Now().UTC())
+	}
+	args = append(args, id, version, version)
+
+	query := fmt.Sprintf(`
+	UPDATE post SET %s
+	WHERE id = ? AND deleted_at IS NULL AND (? = 0 OR version = ?)
+	RETURNING id, title, content, slug, (SELECT slug FROM sections WHERE sections.id = post.section_id), created_at, updated_at, version`, strings.Join(sets, ", "))
+
+	var post models.OutputPost
+	err = tx.QueryRowContext(ctx, query, args...).
+		Scan(&post.ID, &post.Title, &post.Content, &post.Slug, &post.Section, &post.CreatedAt, &post.UpdatedAt, &post.Version)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows){
-			return storage.ErrPostNotFound
+			return models.OutputPost{}, s.missedUpdate(ctx, tx.StmtContext(ctx, s.stmts.getPost), id)
 		}
+		if isSlugViolation(err){
+			return models.OutputPost{}, storage.ErrSlugTaken
+		}
+		if isUniqueViolation(err){
+			return models.OutputPost{}, storage.ErrPostExists
+		}
+		return models.OutputPost{}, fmt.Errorf("scan row: %w", err)
+	}
+
+	if tagsChanged{
+		if err = s.setTags(ctx, tx, id, names); err != nil{
+			return models.OutputPost{}, err
+		}
+	}
+	post.Tags = names
+	if err = attachAuthors(ctx, tx.StmtContext(ctx, s.stmts.authorsOfPosts), &post); err != nil{
+		return models.OutputPost{}, err
+	}
+	if err = attachCommentCounts(ctx, tx.StmtContext(ctx, s.stmts.commentCounts), &post); err != nil{
+		return models.OutputPost{}, err
+	}
+	if err = attachReactions(ctx, tx.StmtContext(ctx, s.stmts.reactionCounts), &post); err != nil{
+		return models.OutputPost{}, err
+	}
+
+	return post, nil
+}
+
+// DeletePost moves the post to the trash. Unless version is 0 the post is
+// only deleted while it is at that version.
+func (s *Storage) DeletePost(ctx context.Context, id, version int) error{
+	op := "storage.sqlstore.DeletePost"
+
+	now := time.Now().UTC()
+	res, err := s.stmts.deletePost.ExecContext(ctx, now, now, id, version, version)
+	if err != nil {
 		return fmt.Errorf("%s: failed delete: %w", op, err)
 	}
 
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: rows affected: %w", op, err)
+	}
+	if n == 0 {
+		err = s.missedUpdate(ctx, s.stmts.getPost, id)
+		if errors.Is(err, storage.ErrPostNotFound) || errors.Is(err, storage.ErrVersionMismatch){
+			return err
+		}
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
 	return nil
+}
+
+// missedUpdate tells why a conditional update of the post touched no rows:
+// ErrVersionMismatch if the post exists, ErrPostNotFound otherwise.
+func (s *Storage) missedUpdate(ctx context.Context, getPost *sql.Stmt, id int) error{
+	var post models.OutputPost
+	err := getPost.QueryRowContext(ctx, id).Scan(&post.ID, &post.Title, &post.Content, &post.Slug, &post.Section, &post.CreatedAt, &post.UpdatedAt, &post.Version)
+	if err != nil{
+		if errors.Is(err, sql.ErrNoRows){
+			return storage.ErrPostNotFound
+		}
+		return fmt.Errorf("get post: %w", err)
+	}
+	return storage.ErrVersionMismatch
+}
+
+func isUniqueViolation(err error) bool{
+	var sqliteErr sqlite3.Error
+	return errors.As(err, &sqliteErr) && sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique
+}
+
+func isForeignKeyViolation(err error) bool{
+	var sqliteErr sqlite3.Error
+	return errors.As(err, &sqliteErr) && sqliteErr.ExtendedCode == sqlite3.ErrConstraintForeignKey
 }
\ No newline at end of file
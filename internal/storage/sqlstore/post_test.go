@@ -0,0 +1,73 @@
+package sqlstore
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/RomanKovalev007/mai_news/internal/models"
+	"github.com/RomanKovalev007/mai_news/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatementsAreReused(t *testing.T) {
+	ctx := context.Background()
+	s := newTestStorage(t)
+
+	stmt := s.stmts.getPost
+	for i := 0; i < 3; i++ {
+		_, err := s.GetPost(ctx, 1)
+		assert.ErrorIs(t, err, storage.ErrPostNotFound)
+	}
+	assert.Same(t, stmt, s.stmts.getPost)
+}
+
+func TestClose(t *testing.T) {
+	s, err := New(filepath.Join(t.TempDir(), "storage.db"))
+	require.NoError(t, err)
+
+	require.NoError(t, s.Close())
+
+	_, err = s.GetAllPosts(context.Background(), models.PostFilter{}, models.Page{Limit: 10})
+	assert.Error(t, err)
+}
+
+func TestCreatedAtMigratedToUTC(t *testing.T) {
+	ctx := context.Background()
+	s := newTestStorage(t)
+
+	m, err := NewMigrator(s.db)
+	require.NoError(t, err)
+	// back to before 0007_post_created_at_utc
+	status, err := m.Status()
+	require.NoError(t, err)
+	steps := 0
+	for _, st := range status {
+		if st.Applied && st.Version >= 7 {
+			steps++
+		}
+	}
+	_, err = m.Down(steps)
+	require.NoError(t, err)
+
+	// rows written before the migration carry the zone of the server
+	moscow := time.FixedZone("MSK", 3*60*60)
+	createdAt := time.Date(2025, 9, 1, 13, 0, 0, 0, moscow)
+	_, err = s.db.Exec("INSERT INTO post(title, content, created_at, updated_at) VALUES('Old', '', ?, ?)", createdAt, createdAt.UTC())
+	require.NoError(t, err)
+	_, err = m.Up()
+	require.NoError(t, err)
+	require.NoError(t, s.backfillSlugs(ctx))
+
+	var stored string
+	require.NoError(t, s.db.QueryRow("SELECT CAST(created_at AS TEXT) FROM post").Scan(&stored))
+	assert.Equal(t, "2025-09-01 10:00:00.000+00:00", stored)
+
+	page, err := s.GetAllPosts(ctx, models.PostFilter{From: time.Date(2025, 9, 1, 10, 0, 0, 0, time.UTC)}, models.Page{Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, page.Posts, 1)
+	assert.True(t, createdAt.Equal(page.Posts[0].CreatedAt))
+	assert.Equal(t, time.UTC, page.Posts[0].CreatedAt.Location())
+}
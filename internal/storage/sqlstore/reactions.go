@@ -0,0 +1,134 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/RomanKovalev007/mai_news/internal/models"
+	"github.com/RomanKovalev007/mai_news/internal/storage"
+)
+
+// SetReaction makes kind the reaction of the reactor to the post, replacing
+// the reactor's previous reaction, and returns the counts after it. It fails
+// with storage.ErrPostNotFound if the post does not exist or is in the
+// trash and with storage.ErrUserNotFound if the reacting user does not
+// exist.
+func (s *Storage) SetReaction(ctx context.Context, postID int, reactor models.ReactorID, kind models.ReactionKind) (models.PostReactions, error){
+	op := "storage.sqlstore.SetReaction"
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil{
+		return models.PostReactions{}, fmt.Errorf("%s: begin: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	var exists bool
+	if err = tx.StmtContext(ctx, s.stmts.postExists).QueryRowContext(ctx, postID).Scan(&exists); err != nil{
+		return models.PostReactions{}, fmt.Errorf("%s: find post: %w", op, err)
+	}
+	if !exists{
+		return models.PostReactions{}, storage.ErrPostNotFound
+	}
+
+	var userID sql.NullInt64
+	if reactor.UserID != 0{
+		userID = sql.NullInt64{Int64: int64(reactor.UserID), Valid: true}
+	}
+	_, err = tx.StmtContext(ctx, s.stmts.setReaction).ExecContext(ctx,
+		postID, reactor.Key(), userID, kind, time.Now().UTC())
+	if err != nil{
+		if isForeignKeyViolation(err){
+			return models.PostReactions{}, storage.ErrUserNotFound
+		}
+		return models.PostReactions{}, fmt.Errorf("%s: failed to save: %w", op, err)
+	}
+
+	counts, err := reactionCounts(ctx, tx.StmtContext(ctx, s.stmts.reactionCounts), postID)
+	if err != nil{
+		return models.PostReactions{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err = tx.Commit(); err != nil{
+		return models.PostReactions{}, fmt.Errorf("%s: commit: %w", op, err)
+	}
+
+	return models.PostReactions{PostID: postID, Kind: kind, Reactions: counts[postID]}, nil
+}
+
+// DeleteReaction takes back the reaction of the reactor to the post. It
+// fails with storage.ErrReactionNotFound unless the reactor's reaction is
+// of the given kind.
+func (s *Storage) DeleteReaction(ctx context.Context, postID int, reactor models.ReactorID, kind models.ReactionKind) error{
+	op := "storage.sqlstore.DeleteReaction"
+
+	res, err := s.stmts.deleteReaction.ExecContext(ctx, postID, reactor.Key(), kind)
+	if err != nil {
+		return fmt.Errorf("%s: failed delete: %w", op, err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: rows affected: %w", op, err)
+	}
+	if n == 0 {
+		return storage.ErrReactionNotFound
+	}
+
+	return nil
+}
+
+// attachReactions fills in the reaction counts of posts with one query of
+// reactionCounts, which may be bound to a transaction.
+func attachReactions(ctx context.Context, stmt *sql.Stmt, posts ...*models.OutputPost) error{
+	if len(posts) == 0{
+		return nil
+	}
+
+	ids := make([]int, 0, len(posts))
+	for _, post := range posts{
+		ids = append(ids, post.ID)
+	}
+
+	counts, err := reactionCounts(ctx, stmt, ids...)
+	if err != nil{
+		return err
+	}
+	for _, post := range posts{
+		post.Reactions = counts[post.ID]
+	}
+
+	return nil
+}
+
+// reactionCounts returns the reaction counts of the posts by post id. Posts
+// without reactions are left out.
+func reactionCounts(ctx context.Context, stmt *sql.Stmt, ids ...int) (map[int]map[models.ReactionKind]int, error){
+	idList, err := json.Marshal(ids)
+	if err != nil{
+		return nil, fmt.Errorf("encode post ids: %w", err)
+	}
+
+	rows, err := stmt.QueryContext(ctx, string(idList))
+	if err != nil{
+		return nil, fmt.Errorf("get reaction counts: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[int]map[models.ReactionKind]int)
+	for rows.Next(){
+		var id, count int
+		var kind models.ReactionKind
+		if err := rows.Scan(&id, &kind, &count); err != nil{
+			return nil, fmt.Errorf("scan reaction count: %w", err)
+		}
+		if counts[id] == nil{
+			counts[id] = make(map[models.ReactionKind]int)
+		}
+		counts[id][kind] = count
+	}
+
+	return counts, rows.Err()
+}
@@ -0,0 +1,151 @@
+package sqlstore
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/RomanKovalev007/mai_news/internal/lib/search"
+	"github.com/RomanKovalev007/mai_news/internal/models"
+	"github.com/RomanKovalev007/mai_news/internal/storage"
+)
+
+// The search index is an FTS5 table over post kept in sync by triggers. It is
+// derived data rather than schema, so instead of a migration it is created on
+// startup, and only when SQLite was built with FTS5 (go build -tags sqlite_fts5).
+const searchSchema = `
+CREATE VIRTUAL TABLE post_fts USING fts5(
+	title, content,
+	content='post', content_rowid='id',
+	tokenize='unicode61 remove_diacritics 2',
+	prefix='2 3');
+CREATE TRIGGER post_fts_insert AFTER INSERT ON post BEGIN
+	INSERT INTO post_fts(rowid, title, content) VALUES(new.id, new.title, new.content);
+END;
+CREATE TRIGGER post_fts_delete AFTER DELETE ON post BEGIN
+	INSERT INTO post_fts(post_fts, rowid, title, content) VALUES('delete', old.id, old.title, old.content);
+END;
+CREATE TRIGGER post_fts_update AFTER UPDATE OF title, content ON post BEGIN
+	INSERT INTO post_fts(post_fts, rowid, title, content) VALUES('delete', old.id, old.title, old.content);
+	INSERT INTO post_fts(rowid, title, content) VALUES(new.id, new.title, new.content);
+END;
+INSERT INTO post_fts(post_fts) VALUES('rebuild');
+`
+
+// title matches weigh ten times more than content matches
+const searchQuery = `
+SELECT p.id, p.title, p.content, p.slug, (SELECT slug FROM sections WHERE sections.id = p.section_id), p.created_at,
+	-bm25(post_fts, 10.0, 1.0),
+	highlight(post_fts, 0, '<mark>', '</mark>'),
+	snippet(post_fts, 1, '<mark>', '</mark>', '…', 24)
+FROM post_fts JOIN post p ON p.id = post_fts.rowid
+WHERE post_fts MATCH ? AND p.deleted_at IS NULL
+ORDER BY bm25(post_fts, 10.0, 1.0)
+LIMIT ?`
+
+func (s *Storage) setupSearch(ctx context.Context) error{
+	var enabled bool
+	if err := s.db.QueryRowContext(ctx, "SELECT sqlite_compileoption_used('ENABLE_FTS5')").Scan(&enabled); err != nil{
+		return fmt.Errorf("check fts5: %w", err)
+	}
+	if !enabled{
+		return nil
+	}
+
+	// migrations that copy post into a new table drop the triggers with the
+	// old one, and the index is rebuilt from scratch then
+	var exists int
+	err := s.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM sqlite_master WHERE type = 'trigger' AND name = 'post_fts_insert'").Scan(&exists)
+	if err != nil{
+		return fmt.Errorf("check search index: %w", err)
+	}
+	if exists == 0{
+		tx, err := s.db.BeginTx(ctx, nil)
+		if err != nil{
+			return fmt.Errorf("begin: %w", err)
+		}
+		defer tx.Rollback()
+
+		if _, err = tx.ExecContext(ctx, "DROP TABLE IF EXISTS post_fts"); err != nil{
+			return fmt.Errorf("drop search index: %w", err)
+		}
+		if _, err = tx.ExecContext(ctx, searchSchema); err != nil{
+			return fmt.Errorf("create search index: %w", err)
+		}
+		if err = tx.Commit(); err != nil{
+			return fmt.Errorf("commit: %w", err)
+		}
+	}
+
+	stmt, err := s.db.PrepareContext(ctx, searchQuery)
+	if err != nil{
+		return fmt.Errorf("prepare %q: %w", searchQuery, err)
+	}
+	s.stmts.searchPosts = stmt
+	s.stmts.all = append(s.stmts.all, stmt)
+
+	return nil
+}
+
+// SearchPosts returns up to limit posts matching every word of query, best
+// matches first. Each word also matches longer words starting with it.
+func (s *Storage) SearchPosts(ctx context.Context, query string, limit int) ([]models.SearchResult, error){
+	op := "storage.sqlstore.SearchPosts"
+
+	if s.stmts.searchPosts == nil{
+		return []models.SearchResult{}, storage.ErrSearchUnavailable
+	}
+
+	terms := search.Terms(query)
+	if len(terms) == 0{
+		return []models.SearchResult{}, nil
+	}
+	for i, term := range terms{
+		spellings := search.Spellings(term)
+		for j, spelling := range spellings{
+			spellings[j] = `"` + spelling + `"*`
+		}
+		terms[i] = "(" + strings.Join(spellings, " OR ") + ")"
+	}
+
+	rows, err := s.stmts.searchPosts.QueryContext(ctx, strings.Join(terms, " AND "), limit)
+	if err != nil{
+		return []models.SearchResult{}, fmt.Errorf("%s: failed to search posts: %w", op, err)
+	}
+	defer rows.Close()
+
+	var results []models.SearchResult
+
+	for rows.Next(){
+		var r models.SearchResult
+		err := rows.Scan(&r.ID, &r.Title, &r.Content, &r.Slug, &r.Section, &r.CreatedAt, &r.Score, &r.TitleHighlight, &r.Snippet)
+		if err != nil {
+			return []models.SearchResult{}, fmt.Errorf("%s: scan row: %w", op, err)
+		}
+		results = append(results, r)
+	}
+
+	if err = rows.Err(); err != nil{
+		return []models.SearchResult{}, fmt.Errorf("%s: rows err: %w", op, err)
+	}
+	rows.Close()
+
+	posts := make([]*models.OutputPost, len(results))
+	for i := range results{
+		posts[i] = &results[i].OutputPost
+	}
+	if err = attachTags(ctx, s.stmts.tagsOfPosts, posts...); err != nil{
+		return []models.SearchResult{}, fmt.Errorf("%s: %w", op, err)
+	}
+	if err = attachAuthors(ctx, s.stmts.authorsOfPosts, posts...); err != nil{
+		return []models.SearchResult{}, fmt.Errorf("%s: %w", op, err)
+	}
+	if err = attachCommentCounts(ctx, s.stmts.commentCounts, posts...); err != nil{
+		return []models.SearchResult{}, fmt.Errorf("%s: %w", op, err)
+	}
+	if err = attachReactions(ctx, s.stmts.reactionCounts, posts...); err != nil{
+		return []models.SearchResult{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return results, nil
+}
@@ -0,0 +1,49 @@
+package sqlstore
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/RomanKovalev007/mai_news/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearchAfterSectionsMigration(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "storage.db") + "?_parseTime=true"
+	s, err := New(path)
+	require.NoError(t, err)
+	post, err := s.SavePost(ctx, models.InputPost{Title: "Приём документов", Content: "Content", Tags: []string{"admissions"}})
+	require.NoError(t, err)
+
+	// 0010_sections copies post into a new table, which drops its triggers;
+	// 0011_users is undone first
+	m, err := NewMigrator(s.db)
+	require.NoError(t, err)
+	_, err = m.Down(2)
+	require.NoError(t, err)
+	_, err = m.Up()
+	require.NoError(t, err)
+	require.NoError(t, s.Close())
+
+	s, err = New(path)
+	require.NoError(t, err)
+	t.Cleanup(func() { s.Close() })
+
+	got, err := s.GetPost(ctx, post.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.DefaultSection, got.Section)
+	assert.Equal(t, []string{"admissions"}, got.Tags)
+
+	if s.stmts.searchPosts == nil {
+		t.Skip("SQLite is built without FTS5, run the tests with -tags sqlite_fts5")
+	}
+
+	_, err = s.SavePost(ctx, models.InputPost{Title: "Приём в аспирантуру", Content: "Content"})
+	require.NoError(t, err)
+	results, err := s.SearchPosts(ctx, "приём", 10)
+	require.NoError(t, err)
+	assert.Len(t, results, 2)
+}
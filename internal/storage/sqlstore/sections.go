@@ -0,0 +1,213 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/RomanKovalev007/mai_news/internal/models"
+	"github.com/RomanKovalev007/mai_news/internal/storage"
+)
+
+// GetSections returns every section, ordered by title.
+func (s *Storage) GetSections(ctx context.Context) ([]models.Section, error){
+	op := "storage.sqlstore.GetSections"
+
+	rows, err := s.stmts.getSections.QueryContext(ctx)
+	if err != nil{
+		return []models.Section{}, fmt.Errorf("%s: failed to get sections: %w", op, err)
+	}
+	defer rows.Close()
+
+	var sections []models.Section
+
+	for rows.Next(){
+		var section models.Section
+		if err := rows.Scan(&section.ID, &section.Slug, &section.Title, &section.Description, &section.CreatedAt); err != nil {
+			return []models.Section{}, fmt.Errorf("%s: scan row: %w", op, err)
+		}
+		sections = append(sections, section)
+	}
+
+	if err = rows.Err(); err != nil{
+		return []models.Section{}, fmt.Errorf("%s: rows err: %w", op, err)
+	}
+	rows.Close()
+
+	ptrs := make([]*models.Section, len(sections))
+	for i := range sections{
+		ptrs[i] = &sections[i]
+	}
+	if err = attachEditors(ctx, s.stmts.editorsOf, ptrs...); err != nil{
+		return []models.Section{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return sections, nil
+}
+
+func (s *Storage) GetSection(ctx context.Context, slug string) (models.Section, error){
+	op := "storage.sqlstore.GetSection"
+
+	var section models.Section
+	err := s.stmts.getSection.QueryRowContext(ctx, slug).
+		Scan(&section.ID, &section.Slug, &section.Title, &section.Description, &section.CreatedAt)
+	if err != nil{
+		if errors.Is(err, sql.ErrNoRows){
+			return models.Section{}, storage.ErrSectionNotFound
+		}
+		return models.Section{}, fmt.Errorf("%s: scan row: %w", op, err)
+	}
+	if err = attachEditors(ctx, s.stmts.editorsOf, &section); err != nil{
+		return models.Section{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return section, nil
+}
+
+// SaveSection creates a section. It fails with storage.ErrSectionExists if
+// the slug is in use.
+func (s *Storage) SaveSection(ctx context.Context, input models.InputSection) (models.Section, error){
+	op := "storage.sqlstore.SaveSection"
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil{
+		return models.Section{}, fmt.Errorf("%s: begin: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now().UTC()
+	res, err := tx.StmtContext(ctx, s.stmts.saveSection).ExecContext(ctx, input.Slug, input.Title, input.Description, now)
+	if err != nil{
+		if isUniqueViolation(err){
+			return models.Section{}, storage.ErrSectionExists
+		}
+		return models.Section{}, fmt.Errorf("%s: exec statement: %w", op, err)
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return models.Section{}, fmt.Errorf("%s: get last insert id: %w", op, err)
+	}
+
+	section := models.Section{
+		ID: int(id),
+		Slug: input.Slug,
+		Title: input.Title,
+		Description: input.Description,
+		CreatedAt: now,
+	}
+	if err = s.setEditors(ctx, tx, &section, input.Editors); err != nil{
+		return models.Section{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err = tx.Commit(); err != nil{
+		return models.Section{}, fmt.Errorf("%s: commit: %w", op, err)
+	}
+
+	return section, nil
+}
+
+// PatchSection updates the fields set in patch. Editors, if set, replace
+// the editors of the section.
+func (s *Storage) PatchSection(ctx context.Context, slug string, patch models.SectionPatch) (models.Section, error){
+	op := "storage.sqlstore.PatchSection"
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil{
+		return models.Section{}, fmt.Errorf("%s: begin: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	var section models.Section
+	err = tx.StmtContext(ctx, s.stmts.patchSection).QueryRowContext(ctx, patch.Title, patch.Description, slug).
+		Scan(&section.ID, &section.Slug, &section.Title, &section.Description, &section.CreatedAt)
+	if err != nil{
+		if errors.Is(err, sql.ErrNoRows){
+			return models.Section{}, storage.ErrSectionNotFound
+		}
+		return models.Section{}, fmt.Errorf("%s: scan row: %w", op, err)
+	}
+
+	if patch.Editors != nil{
+		err = s.setEditors(ctx, tx, &section, *patch.Editors)
+	} else {
+		err = attachEditors(ctx, tx.StmtContext(ctx, s.stmts.editorsOf), &section)
+	}
+	if err != nil{
+		return models.Section{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err = tx.Commit(); err != nil{
+		return models.Section{}, fmt.Errorf("%s: commit: %w", op, err)
+	}
+
+	return section, nil
+}
+
+// sectionID returns the id of the section with the given slug.
+func (s *Storage) sectionID(ctx context.Context, tx *sql.Tx, slug string) (int, error){
+	var id int
+	if err := tx.StmtContext(ctx, s.stmts.sectionID).QueryRowContext(ctx, slug).Scan(&id); err != nil{
+		if errors.Is(err, sql.ErrNoRows){
+			return 0, storage.ErrSectionNotFound
+		}
+		return 0, fmt.Errorf("get section: %w", err)
+	}
+	return id, nil
+}
+
+// setEditors replaces the editors of the section within tx and fills them in.
+func (s *Storage) setEditors(ctx context.Context, tx *sql.Tx, section *models.Section, emails []string) error{
+	if _, err := tx.StmtContext(ctx, s.stmts.removeEditors).ExecContext(ctx, section.ID); err != nil{
+		return fmt.Errorf("remove editors: %w", err)
+	}
+
+	addEditor := tx.StmtContext(ctx, s.stmts.addEditor)
+	for _, email := range emails{
+		if _, err := addEditor.ExecContext(ctx, section.ID, email); err != nil{
+			return fmt.Errorf("add editor: %w", err)
+		}
+	}
+
+	return attachEditors(ctx, tx.StmtContext(ctx, s.stmts.editorsOf), section)
+}
+
+// attachEditors fills in the editors of sections with one query of
+// editorsOf, which may be bound to a transaction.
+func attachEditors(ctx context.Context, editorsOf *sql.Stmt, sections ...*models.Section) error{
+	if len(sections) == 0{
+		return nil
+	}
+
+	byID := make(map[int]*models.Section, len(sections))
+	ids := make([]int, 0, len(sections))
+	for _, section := range sections{
+		section.Editors = []string{}
+		byID[section.ID] = section
+		ids = append(ids, section.ID)
+	}
+	idList, err := json.Marshal(ids)
+	if err != nil{
+		return fmt.Errorf("encode section ids: %w", err)
+	}
+
+	rows, err := editorsOf.QueryContext(ctx, string(idList))
+	if err != nil{
+		return fmt.Errorf("get editors: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next(){
+		var id int
+		var email string
+		if err := rows.Scan(&id, &email); err != nil{
+			return fmt.Errorf("scan editor: %w", err)
+		}
+		byID[id].Editors = append(byID[id].Editors, email)
+	}
+
+	return rows.Err()
+}
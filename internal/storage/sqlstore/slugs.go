@@ -0,0 +1,166 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/RomanKovalev007/mai_news/internal/lib/slug"
+	"github.com/RomanKovalev007/mai_news/internal/models"
+	"github.com/RomanKovalev007/mai_news/internal/storage"
+	"github.com/mattn/go-sqlite3"
+)
+
+// GetPostBySlug returns the post addressed by slug, now or formerly. A post
+// found by a former slug has a different Slug, which callers redirect to.
+func (s *Storage) GetPostBySlug(ctx context.Context, slug string) (models.OutputPost, error){
+	op := "storage.sqlstore.GetPostBySlug"
+
+	var post models.OutputPost
+	err := s.stmts.getPostBySlug.QueryRowContext(ctx, slug, slug).
+		Scan(&post.ID, &post.Title, &post.Content, &post.Slug, &post.Section, &post.CreatedAt, &post.UpdatedAt, &post.Version)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows){
+			return models.OutputPost{}, storage.ErrPostNotFound
+		}
+		return models.OutputPost{}, fmt.Errorf("%s: scan row: %w", op, err)
+	}
+	if err = attachTags(ctx, s.stmts.tagsOfPosts, &post); err != nil{
+		return models.OutputPost{}, fmt.Errorf("%s: %w", op, err)
+	}
+	if err = attachAuthors(ctx, s.stmts.authorsOfPosts, &post); err != nil{
+		return models.OutputPost{}, fmt.Errorf("%s: %w", op, err)
+	}
+	if err = attachCommentCounts(ctx, s.stmts.commentCounts, &post); err != nil{
+		return models.OutputPost{}, fmt.Errorf("%s: %w", op, err)
+	}
+	if err = attachReactions(ctx, s.stmts.reactionCounts, &post); err != nil{
+		return models.OutputPost{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return post, nil
+}
+
+// newSlug returns the slug for a post with the given title: requested if it
+// is set and free, otherwise the first free candidate made from title. Slugs
+// of postID itself, current or former, count as free.
+func (s *Storage) newSlug(ctx context.Context, tx *sql.Tx, requested, title string, postID int) (string, error){
+	taken := tx.StmtContext(ctx, s.stmts.slugTaken)
+
+	if requested != ""{
+		var isTaken bool
+		if err := taken.QueryRowContext(ctx, requested, postID, requested, postID).Scan(&isTaken); err != nil{
+			return "", fmt.Errorf("check slug: %w", err)
+		}
+		if isTaken{
+			return "", storage.ErrSlugTaken
+		}
+		return requested, nil
+	}
+
+	base := slug.Make(title)
+	for n := 1; ; n++{
+		candidate := slug.WithSuffix(base, n)
+		var isTaken bool
+		if err := taken.QueryRowContext(ctx, candidate, postID, candidate, postID).Scan(&isTaken); err != nil{
+			return "", fmt.Errorf("check slug: %w", err)
+		}
+		if !isTaken{
+			return candidate, nil
+		}
+	}
+}
+
+// patchSlug works out the slug of the post after patch and, if it changes,
+// keeps the current one as a former slug within tx. It returns "" when the
+// slug stays as it is.
+func (s *Storage) patchSlug(ctx context.Context, tx *sql.Tx, id int, patch models.PostPatch) (string, error){
+	if patch.Slug == nil && patch.Title == nil{
+		return "", nil
+	}
+
+	var title, current string
+	err := tx.QueryRowContext(ctx, "SELECT title, slug FROM post WHERE id = ? AND deleted_at IS NULL", id).Scan(&title, &current)
+	if err != nil{
+		if errors.Is(err, sql.ErrNoRows){
+			// the update that follows tells why
+			return "", nil
+		}
+		return "", fmt.Errorf("get slug: %w", err)
+	}
+
+	next := current
+	switch {
+	case patch.Slug != nil:
+		next, err = s.newSlug(ctx, tx, *patch.Slug, "", id)
+	case *patch.Title != title:
+		next, err = s.newSlug(ctx, tx, "", *patch.Title, id)
+	}
+	if err != nil || next == current{
+		return "", err
+	}
+
+	if _, err = tx.StmtContext(ctx, s.stmts.reclaimSlug).ExecContext(ctx, next, id); err != nil{
+		return "", fmt.Errorf("reclaim slug: %w", err)
+	}
+	if _, err = tx.StmtContext(ctx, s.stmts.keepSlug).ExecContext(ctx, current, id); err != nil{
+		return "", fmt.Errorf("keep former slug: %w", err)
+	}
+
+	return next, nil
+}
+
+// backfillSlugs gives slugs to the posts created before posts had them.
+func (s *Storage) backfillSlugs(ctx context.Context) error{
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil{
+		return fmt.Errorf("begin: %w", err)
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, "SELECT id, title FROM post WHERE slug IS NULL ORDER BY id")
+	if err != nil{
+		return fmt.Errorf("get posts without slug: %w", err)
+	}
+	var posts []models.OutputPost
+	for rows.Next(){
+		var post models.OutputPost
+		if err = rows.Scan(&post.ID, &post.Title); err != nil{
+			rows.Close()
+			return fmt.Errorf("scan row: %w", err)
+		}
+		posts = append(posts, post)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil{
+		return fmt.Errorf("rows err: %w", err)
+	}
+	if len(posts) == 0{
+		return nil
+	}
+
+	for _, post := range posts{
+		slug, err := s.newSlug(ctx, tx, "", post.Title, post.ID)
+		if err != nil{
+			return err
+		}
+		if _, err = tx.ExecContext(ctx, "UPDATE post SET slug = ? WHERE id = ?", slug, post.ID); err != nil{
+			return fmt.Errorf("set slug: %w", err)
+		}
+	}
+
+	if err = tx.Commit(); err != nil{
+		return fmt.Errorf("commit: %w", err)
+	}
+	return nil
+}
+
+// isSlugViolation tells a clash of slugs, which newSlug checks for but a
+// concurrent insert can still cause, from other unique violations.
+func isSlugViolation(err error) bool{
+	var sqliteErr sqlite3.Error
+	return errors.As(err, &sqliteErr) && sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique &&
+		strings.Contains(sqliteErr.Error(), "post.slug")
+}
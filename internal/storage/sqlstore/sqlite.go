@@ -1,40 +1,323 @@
 package sqlstore
 
 import (
+	"context"
 	"database/sql"
+	"embed"
+	"errors"
 	"fmt"
+	"io/fs"
+	"strings"
 
+	"github.com/RomanKovalev007/mai_news/internal/storage/migrate"
 	_ "github.com/mattn/go-sqlite3"
 )
 
+//go:embed migrations/*.sql
+var migrations embed.FS
+
 type Storage struct{
 	db *sql.DB
+	stmts statements
+}
+
+// statements are prepared once in New and closed by Close.
+type statements struct{
+	getPost *sql.Stmt
+	getPostBySlug *sql.Stmt
+	slugTaken *sql.Stmt
+	reclaimSlug *sql.Stmt
+	keepSlug *sql.Stmt
+	lastModified *sql.Stmt
+	savePost *sql.Stmt
+	deletePost *sql.Stmt
+	getTrash *sql.Stmt
+	getTrashedPost *sql.Stmt
+	restorePost *sql.Stmt
+	purgePost *sql.Stmt
+	purgeTrash *sql.Stmt
+	saveRevision *sql.Stmt
+	getRevisions *sql.Stmt
+	getRevision *sql.Stmt
+	getTags *sql.Stmt
+	getTag *sql.Stmt
+	saveTag *sql.Stmt
+	tagPost *sql.Stmt
+	untagPost *sql.Stmt
+	tagsOfPosts *sql.Stmt
+	getSections *sql.Stmt
+	getSection *sql.Stmt
+	sectionID *sql.Stmt
+	saveSection *sql.Stmt
+	patchSection *sql.Stmt
+	addEditor *sql.Stmt
+	removeEditors *sql.Stmt
+	editorsOf *sql.Stmt
+	getUsers *sql.Stmt
+	getUser *sql.Stmt
+	getUserByEmail *sql.Stmt
+	saveUser *sql.Stmt
+	patchUser *sql.Stmt
+	deleteUser *sql.Stmt
+	authorsOfPosts *sql.Stmt
+	revokeToken *sql.Stmt
+	tokenRevoked *sql.Stmt
+	purgeTokens *sql.Stmt
+	getAPIKeys *sql.Stmt
+	getAPIKey *sql.Stmt
+	getAPIKeyByHash *sql.Stmt
+	saveAPIKey *sql.Stmt
+	rotateAPIKey *sql.Stmt
+	deleteAPIKey *sql.Stmt
+	touchAPIKey *sql.Stmt
+	postExists *sql.Stmt
+	getComments *sql.Stmt
+	getCommentQueue *sql.Stmt
+	getComment *sql.Stmt
+	commentShown *sql.Stmt
+	saveComment *sql.Stmt
+	patchComment *sql.Stmt
+	deleteComment *sql.Stmt
+	commentCounts *sql.Stmt
+	setReaction *sql.Stmt
+	deleteReaction *sql.Stmt
+	reactionCounts *sql.Stmt
+	addViews *sql.Stmt
+	getPostViews *sql.Stmt
+	// searchPosts is nil when SQLite was built without FTS5
+	searchPosts *sql.Stmt
+
+	// all holds every prepared statement so Close can release them
+	all []*sql.Stmt
 }
 
 func New(storagePath string) (*Storage, error) {
 	const fn = "storage.sqlstore.New"
 
-	db, err := sql.Open("sqlite3", storagePath)
+	db, err := sql.Open("sqlite3", withForeignKeys(storagePath))
 	if err != nil{
 		return nil, fmt.Errorf("%s: %w", fn, err)
 	}
 
-	stmt, err := db.Prepare(`
-	CREATE TABLE IF NOT EXISTS post(
-		id INTEGER PRIMARY KEY,
-		title TEXT NOT NULL UNIQUE,
-		content TEXT NOT NULL,
-		created_at DATETIME);
-	`)
-
+	migrator, err := NewMigrator(db)
 	if err != nil{
+		db.Close()
 		return nil, fmt.Errorf("%s: %w", fn, err)
 	}
 
-	if _, err = stmt.Exec(); err != nil{
+	if _, err = migrator.Up(); err != nil{
+		db.Close()
 		return nil, fmt.Errorf("%s: %w", fn, err)
 	}
 
-	return &Storage{db: db}, nil
+	s := &Storage{db: db}
+	if err = s.prepare(context.Background()); err != nil{
+		s.Close()
+		return nil, fmt.Errorf("%s: %w", fn, err)
+	}
+
+	if err = s.backfillSlugs(context.Background()); err != nil{
+		s.Close()
+		return nil, fmt.Errorf("%s: %w", fn, err)
+	}
+
+	if err = s.setupSearch(context.Background()); err != nil{
+		s.Close()
+		return nil, fmt.Errorf("%s: %w", fn, err)
+	}
+
+	return s, nil
+}
+
+// withForeignKeys turns on foreign key enforcement, which SQLite leaves off
+// by default, so that ON DELETE CASCADE cleans up rows owned by a post.
+func withForeignKeys(storagePath string) string{
+	if strings.Contains(storagePath, "_fk=") || strings.Contains(storagePath, "_foreign_keys="){
+		return storagePath
+	}
+	if strings.Contains(storagePath, "?"){
+		return storagePath + "&_foreign_keys=on"
+	}
+	return storagePath + "?_foreign_keys=on"
+}
+
+func (s *Storage) prepare(ctx context.Context) error{
+	queries := []struct{
+		stmt **sql.Stmt
+		query string
+	}{
+		{&s.stmts.getPost, `
+		SELECT id, title, content, slug, (SELECT slug FROM sections WHERE sections.id = post.section_id), created_at, updated_at, version FROM post
+		WHERE id = ? AND deleted_at IS NULL`},
+		{&s.stmts.getPostBySlug, `
+		SELECT id, title, content, slug, (SELECT slug FROM sections WHERE sections.id = post.section_id), created_at, updated_at, version FROM post
+		WHERE deleted_at IS NULL AND (slug = ? OR id = (SELECT post_id FROM post_slugs WHERE slug = ?))`},
+		{&s.stmts.slugTaken, `
+		SELECT EXISTS(SELECT 1 FROM post WHERE slug = ? AND id <> ?)
+			OR EXISTS(SELECT 1 FROM post_slugs WHERE slug = ? AND post_id <> ?)`},
+		{&s.stmts.reclaimSlug, "DELETE FROM post_slugs WHERE slug = ? AND post_id = ?"},
+		{&s.stmts.keepSlug, "INSERT INTO post_slugs(slug, post_id) VALUES(?, ?)"},
+		{&s.stmts.lastModified, "SELECT updated_at FROM post ORDER BY updated_at DESC LIMIT 1"},
+		{&s.stmts.savePost, "INSERT INTO post(title, content, slug, section_id, author_id, created_at, updated_at) VALUES(?, ?, ?, ?, ?, ?, ?)"},
+		{&s.stmts.deletePost, `
+		UPDATE post SET deleted_at = ?, updated_at = ?, version = version + 1
+		WHERE id = ? AND deleted_at IS NULL AND (? = 0 OR version = ?)`},
+		{&s.stmts.getTrash, `
+		SELECT id, title, content, slug, (SELECT slug FROM sections WHERE sections.id = post.section_id), created_at, deleted_at FROM post
+		WHERE deleted_at IS NOT NULL ORDER BY deleted_at DESC`},
+		{&s.stmts.getTrashedPost, `
+		SELECT id, title, content, slug, (SELECT slug FROM sections WHERE sections.id = post.section_id), created_at, deleted_at FROM post
+		WHERE id = ? AND deleted_at IS NOT NULL`},
+		{&s.stmts.restorePost, `
+		UPDATE post SET deleted_at = NULL, updated_at = ?, version = version + 1
+		WHERE id = ? AND deleted_at IS NOT NULL
+		RETURNING id, title, content, slug, (SELECT slug FROM sections WHERE sections.id = post.section_id), created_at, updated_at, version`},
+		{&s.stmts.purgePost, "DELETE FROM post WHERE id = ? AND deleted_at IS NOT NULL"},
+		{&s.stmts.purgeTrash, "DELETE FROM post WHERE deleted_at IS NOT NULL AND deleted_at < ?"},
+		{&s.stmts.saveRevision, `
+		INSERT INTO post_revisions(post_id, rev, title, content, revised_at, revised_by)
+		SELECT id, (SELECT COALESCE(MAX(rev), 0) + 1 FROM post_revisions WHERE post_id = post.id), title, content, ?, (SELECT id FROM users WHERE id = ?)
+		FROM post WHERE id = ? AND deleted_at IS NULL AND (title <> COALESCE(?, title) OR content <> COALESCE(?, content))`},
+		{&s.stmts.getRevisions, `
+		SELECT r.post_id, r.rev, r.title, r.content, r.revised_at, u.id, u.name FROM post_revisions r
+		LEFT JOIN users u ON u.id = r.revised_by
+		WHERE r.post_id = ? ORDER BY r.rev DESC`},
+		{&s.stmts.getRevision, `
+		SELECT r.post_id, r.rev, r.title, r.content, r.revised_at, u.id, u.name FROM post_revisions r
+		LEFT JOIN users u ON u.id = r.revised_by
+		WHERE r.post_id = ? AND r.rev = ?`},
+		{&s.stmts.getTags, `
+		SELECT t.name, COUNT(p.id) FROM tags t
+		JOIN post_tags pt ON pt.tag_id = t.id
+		LEFT JOIN post p ON p.id = pt.post_id AND p.deleted_at IS NULL
+		GROUP BY t.id ORDER BY COUNT(p.id) DESC, t.name`},
+		{&s.stmts.getTag, `
+		SELECT t.name, COUNT(p.id) FROM tags t
+		JOIN post_tags pt ON pt.tag_id = t.id
+		LEFT JOIN post p ON p.id = pt.post_id AND p.deleted_at IS NULL
+		WHERE t.id = ? GROUP BY t.id`},
+		{&s.stmts.saveTag, "INSERT INTO tags(name) VALUES(?) ON CONFLICT(name) DO NOTHING"},
+		{&s.stmts.tagPost, "INSERT INTO post_tags(post_id, tag_id) SELECT ?, id FROM tags WHERE name = ?"},
+		{&s.stmts.untagPost, "DELETE FROM post_tags WHERE post_id = ?"},
+		// the ids come as a JSON array, so one statement serves any number
+		{&s.stmts.tagsOfPosts, `
+		SELECT pt.post_id, t.name FROM post_tags pt JOIN tags t ON t.id = pt.tag_id
+		WHERE pt.post_id IN (SELECT value FROM json_each(?)) ORDER BY t.name`},
+		{&s.stmts.getSections, "SELECT id, slug, title, description, created_at FROM sections ORDER BY title, id"},
+		{&s.stmts.getSection, "SELECT id, slug, title, description, created_at FROM sections WHERE slug = ?"},
+		{&s.stmts.sectionID, "SELECT id FROM sections WHERE slug = ?"},
+		{&s.stmts.saveSection, "INSERT INTO sections(slug, title, description, created_at) VALUES(?, ?, ?, ?)"},
+		{&s.stmts.patchSection, `
+		UPDATE sections SET title = COALESCE(?, title), description = COALESCE(?, description)
+		WHERE slug = ?
+		RETURNING id, slug, title, description, created_at`},
+		{&s.stmts.addEditor, "INSERT INTO section_editors(section_id, email) VALUES(?, ?) ON CONFLICT DO NOTHING"},
+		{&s.stmts.removeEditors, "DELETE FROM section_editors WHERE section_id = ?"},
+		{&s.stmts.editorsOf, `
+		SELECT section_id, email FROM section_editors
+		WHERE section_id IN (SELECT value FROM json_each(?)) ORDER BY email`},
+		{&s.stmts.getUsers, "SELECT id, name, email, password_hash, role, created_at FROM users ORDER BY id"},
+		{&s.stmts.getUser, "SELECT id, name, email, password_hash, role, created_at FROM users WHERE id = ?"},
+		{&s.stmts.getUserByEmail, "SELECT id, name, email, password_hash, role, created_at FROM users WHERE email = ?"},
+		{&s.stmts.saveUser, "INSERT INTO users(name, email, password_hash, role, created_at) VALUES(?, ?, ?, COALESCE(NULLIF(?, ''), 'reader'), ?)"},
+		{&s.stmts.patchUser, `
+		UPDATE users SET name = COALESCE(?, name), email = COALESCE(?, email), password_hash = COALESCE(?, password_hash),
+		role = COALESCE(?, role)
+		WHERE id = ?
+		RETURNING id, name, email, password_hash, role, created_at`},
+		{&s.stmts.deleteUser, "DELETE FROM users WHERE id = ?"},
+		{&s.stmts.authorsOfPosts, `
+		SELECT p.id, u.id, u.name FROM post p JOIN users u ON u.id = p.author_id
+		WHERE p.id IN (SELECT value FROM json_each(?))`},
+		{&s.stmts.revokeToken, "INSERT INTO revoked_tokens(id, expires_at) VALUES(?, ?) ON CONFLICT DO NOTHING"},
+		{&s.stmts.tokenRevoked, "SELECT EXISTS(SELECT 1 FROM revoked_tokens WHERE id = ?)"},
+		{&s.stmts.purgeTokens, "DELETE FROM revoked_tokens WHERE expires_at < ?"},
+		{&s.stmts.getAPIKeys, "SELECT " + apiKeyColumns + " FROM api_keys ORDER BY id"},
+		{&s.stmts.getAPIKey, "SELECT " + apiKeyColumns + " FROM api_keys WHERE id = ?"},
+		{&s.stmts.getAPIKeyByHash, "SELECT " + apiKeyColumns + " FROM api_keys WHERE key_hash = ?"},
+		{&s.stmts.saveAPIKey, `
+		INSERT INTO api_keys(name, prefix, key_hash, scopes, user_id, created_at) VALUES(?, ?, ?, ?, ?, ?)
+		RETURNING ` + apiKeyColumns},
+		{&s.stmts.rotateAPIKey, `
+		UPDATE api_keys SET prefix = ?, key_hash = ?, last_used_at = NULL WHERE id = ?
+		RETURNING ` + apiKeyColumns},
+		{&s.stmts.deleteAPIKey, "DELETE FROM api_keys WHERE id = ?"},
+		{&s.stmts.touchAPIKey, "UPDATE api_keys SET last_used_at = ? WHERE id = ?"},
+		{&s.stmts.postExists, "SELECT EXISTS(SELECT 1 FROM post WHERE id = ? AND deleted_at IS NULL)"},
+		{&s.stmts.getComments, fmt.Sprintf(visibleComments, "post_id = ?") + `
+		SELECT ` + commentColumns + ` FROM comments
+		WHERE id IN (SELECT id FROM visible) ORDER BY created_at, id`},
+		{&s.stmts.getCommentQueue, `
+		SELECT ` + commentColumns + ` FROM comments
+		WHERE status = 'pending' AND post_id IN (
+			SELECT id FROM post WHERE deleted_at IS NULL
+			AND (?1 IS NULL OR section_id IN (SELECT id FROM sections WHERE slug IN (SELECT value FROM json_each(?1)))))
+		ORDER BY created_at, id`},
+		{&s.stmts.getComment, "SELECT " + commentColumns + " FROM comments WHERE id = ?"},
+		{&s.stmts.commentShown, fmt.Sprintf(visibleComments, "post_id = ?2") + `
+		SELECT EXISTS(SELECT 1 FROM visible WHERE id = ?1)`},
+		{&s.stmts.saveComment, `
+		INSERT INTO comments(post_id, parent_id, author_id, content, status, created_at, updated_at) VALUES(?, ?, ?, ?, ?, ?, ?)
+		RETURNING ` + commentColumns},
+		{&s.stmts.patchComment, `
+		UPDATE comments SET content = COALESCE(?, content), status = COALESCE(?, status), updated_at = COALESCE(?, updated_at)
+		WHERE id = ?
+		RETURNING ` + commentColumns},
+		{&s.stmts.deleteComment, "DELETE FROM comments WHERE id = ?"},
+		// the ids come as a JSON array, like in tagsOfPosts
+		{&s.stmts.commentCounts, fmt.Sprintf(visibleComments, "post_id IN (SELECT value FROM json_each(?))") + `
+		SELECT post_id, COUNT(*) FROM visible GROUP BY post_id`},
+		// the triggers of post_reactions keep the counts, so the upsert
+		// leaves a reaction of the same kind alone
+		{&s.stmts.setReaction, `
+		INSERT INTO post_reactions(post_id, reactor, user_id, kind, created_at) VALUES(?, ?, ?, ?, ?)
+		ON CONFLICT(post_id, reactor) DO UPDATE SET kind = excluded.kind, created_at = excluded.created_at
+		WHERE kind <> excluded.kind`},
+		{&s.stmts.deleteReaction, "DELETE FROM post_reactions WHERE post_id = ? AND reactor = ? AND kind = ?"},
+		{&s.stmts.reactionCounts, `
+		SELECT post_id, kind, count FROM post_reaction_counts
+		WHERE post_id IN (SELECT value FROM json_each(?))`},
+		// views of posts deleted since they were counted are dropped
+		{&s.stmts.addViews, `
+		INSERT INTO post_views(post_id, day, views) SELECT ?1, ?2, ?3 WHERE EXISTS(SELECT 1 FROM post WHERE id = ?1)
+		ON CONFLICT(post_id, day) DO UPDATE SET views = views + excluded.views`},
+		{&s.stmts.getPostViews, "SELECT day, views FROM post_views WHERE post_id = ? ORDER BY day"},
+	}
+
+	for _, q := range queries{
+		stmt, err := s.db.PrepareContext(ctx, q.query)
+		if err != nil{
+			return fmt.Errorf("prepare %q: %w", q.query, err)
+		}
+		*q.stmt = stmt
+		s.stmts.all = append(s.stmts.all, stmt)
+	}
+
+	return nil
 }
 
+// Close releases the prepared statements and closes the database.
+func (s *Storage) Close() error{
+	const fn = "storage.sqlstore.Close"
+
+	var errs []error
+	for _, stmt := range s.stmts.all{
+		errs = append(errs, stmt.Close())
+	}
+	errs = append(errs, s.db.Close())
+
+	if err := errors.Join(errs...); err != nil{
+		return fmt.Errorf("%s: %w", fn, err)
+	}
+	return nil
+}
+
+// NewMigrator returns a migrator over the schema migrations embedded in the binary.
+func NewMigrator(db *sql.DB) (*migrate.Migrator, error) {
+	sub, err := fs.Sub(migrations, "migrations")
+	if err != nil{
+		return nil, err
+	}
+
+	return migrate.New(db, migrate.SQLite, sub)
+}
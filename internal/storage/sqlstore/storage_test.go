@@ -0,0 +1,24 @@
+package sqlstore
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/RomanKovalev007/mai_news/internal/storage/storagetest"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestStorage(t *testing.T) *Storage {
+	t.Helper()
+
+	s, err := New(filepath.Join(t.TempDir(), "storage.db") + "?_parseTime=true")
+	require.NoError(t, err)
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+func TestStorage(t *testing.T) {
+	storagetest.Run(t, func(t *testing.T) storagetest.Storage {
+		return newTestStorage(t)
+	})
+}
@@ -0,0 +1,235 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/RomanKovalev007/mai_news/internal/models"
+	"github.com/RomanKovalev007/mai_news/internal/storage"
+)
+
+// Tags are rows of the tags table linked to posts through post_tags. A tag
+// whose last post was untagged or purged keeps its row, but counts as
+// missing: it is not listed and can be neither renamed nor merged.
+
+// GetTags returns the tags in use, most used first.
+func (s *Storage) GetTags(ctx context.Context) ([]models.Tag, error){
+	op := "storage.sqlstore.GetTags"
+
+	rows, err := s.stmts.getTags.QueryContext(ctx)
+	if err != nil{
+		return []models.Tag{}, fmt.Errorf("%s: failed to get tags: %w", op, err)
+	}
+	defer rows.Close()
+
+	var tags []models.Tag
+
+	for rows.Next(){
+		var tag models.Tag
+		if err := rows.Scan(&tag.Name, &tag.Count); err != nil {
+			return []models.Tag{}, fmt.Errorf("%s: scan row: %w", op, err)
+		}
+		tags = append(tags, tag)
+	}
+
+	if err = rows.Err(); err != nil{
+		return []models.Tag{}, fmt.Errorf("%s: rows err: %w", op, err)
+	}
+
+	return tags, nil
+}
+
+// RenameTag renames the tag on every post that carries it. It fails with
+// storage.ErrTagExists if newName is in use; MergeTags joins two tags.
+func (s *Storage) RenameTag(ctx context.Context, name, newName string) (models.Tag, error){
+	op := "storage.sqlstore.RenameTag"
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil{
+		return models.Tag{}, fmt.Errorf("%s: begin: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	id, err := tagID(ctx, tx, name)
+	if err != nil{
+		if errors.Is(err, storage.ErrTagNotFound){
+			return models.Tag{}, err
+		}
+		return models.Tag{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if newName != name{
+		// a row left by a tag no post carries any more would block the name
+		_, err = tx.ExecContext(ctx, `
+		DELETE FROM tags WHERE name = ? AND NOT EXISTS(SELECT 1 FROM post_tags WHERE tag_id = tags.id)`, newName)
+		if err != nil{
+			return models.Tag{}, fmt.Errorf("%s: delete unused tag: %w", op, err)
+		}
+		if _, err = tx.ExecContext(ctx, "UPDATE tags SET name = ? WHERE id = ?", newName, id); err != nil{
+			if isUniqueViolation(err){
+				return models.Tag{}, storage.ErrTagExists
+			}
+			return models.Tag{}, fmt.Errorf("%s: rename: %w", op, err)
+		}
+		if err = touchTagged(ctx, tx, id); err != nil{
+			return models.Tag{}, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	tag, err := s.getTag(ctx, tx, id)
+	if err != nil{
+		return models.Tag{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err = tx.Commit(); err != nil{
+		return models.Tag{}, fmt.Errorf("%s: commit: %w", op, err)
+	}
+
+	return tag, nil
+}
+
+// MergeTags replaces the tag from with the tag into on every post and
+// removes from.
+func (s *Storage) MergeTags(ctx context.Context, from, into string) (models.Tag, error){
+	op := "storage.sqlstore.MergeTags"
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil{
+		return models.Tag{}, fmt.Errorf("%s: begin: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	fromID, err := tagID(ctx, tx, from)
+	if err != nil{
+		if errors.Is(err, storage.ErrTagNotFound){
+			return models.Tag{}, err
+		}
+		return models.Tag{}, fmt.Errorf("%s: %w", op, err)
+	}
+	intoID, err := tagID(ctx, tx, into)
+	if err != nil{
+		if errors.Is(err, storage.ErrTagNotFound){
+			return models.Tag{}, err
+		}
+		return models.Tag{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if fromID != intoID{
+		if err = touchTagged(ctx, tx, fromID); err != nil{
+			return models.Tag{}, fmt.Errorf("%s: %w", op, err)
+		}
+		_, err = tx.ExecContext(ctx, `
+		INSERT INTO post_tags(post_id, tag_id) SELECT post_id, ? FROM post_tags WHERE tag_id = ?
+		ON CONFLICT DO NOTHING`, intoID, fromID)
+		if err != nil{
+			return models.Tag{}, fmt.Errorf("%s: move posts: %w", op, err)
+		}
+		if _, err = tx.ExecContext(ctx, "DELETE FROM tags WHERE id = ?", fromID); err != nil{
+			return models.Tag{}, fmt.Errorf("%s: delete tag: %w", op, err)
+		}
+	}
+
+	tag, err := s.getTag(ctx, tx, intoID)
+	if err != nil{
+		return models.Tag{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err = tx.Commit(); err != nil{
+		return models.Tag{}, fmt.Errorf("%s: commit: %w", op, err)
+	}
+
+	return tag, nil
+}
+
+// tagID returns the id of the tag called name if some post carries it.
+func tagID(ctx context.Context, tx *sql.Tx, name string) (int, error){
+	var id int
+	err := tx.QueryRowContext(ctx, `
+	SELECT id FROM tags WHERE name = ? AND EXISTS(SELECT 1 FROM post_tags WHERE tag_id = tags.id)`, name).Scan(&id)
+	if err != nil{
+		if errors.Is(err, sql.ErrNoRows){
+			return 0, storage.ErrTagNotFound
+		}
+		return 0, fmt.Errorf("get tag: %w", err)
+	}
+	return id, nil
+}
+
+func (s *Storage) getTag(ctx context.Context, tx *sql.Tx, id int) (models.Tag, error){
+	var tag models.Tag
+	if err := tx.StmtContext(ctx, s.stmts.getTag).QueryRowContext(ctx, id).Scan(&tag.Name, &tag.Count); err != nil{
+		return models.Tag{}, fmt.Errorf("get tag: %w", err)
+	}
+	return tag, nil
+}
+
+// touchTagged records a change of the posts carrying the tag, whose tag
+// lists are about to change.
+func touchTagged(ctx context.Context, tx *sql.Tx, id int) error{
+	_, err := tx.ExecContext(ctx, `
+	UPDATE post SET updated_at = ?, version = version + 1
+	WHERE id IN (SELECT post_id FROM post_tags WHERE tag_id = ?)`, time.Now().UTC(), id)
+	if err != nil{
+		return fmt.Errorf("touch tagged posts: %w", err)
+	}
+	return nil
+}
+
+// setTags replaces the tags of the post with names, a tags.Set, within tx.
+func (s *Storage) setTags(ctx context.Context, tx *sql.Tx, postID int, names []string) error{
+	if _, err := tx.StmtContext(ctx, s.stmts.untagPost).ExecContext(ctx, postID); err != nil{
+		return fmt.Errorf("untag post: %w", err)
+	}
+
+	saveTag := tx.StmtContext(ctx, s.stmts.saveTag)
+	tagPost := tx.StmtContext(ctx, s.stmts.tagPost)
+	for _, name := range names{
+		if _, err := saveTag.ExecContext(ctx, name); err != nil{
+			return fmt.Errorf("save tag: %w", err)
+		}
+		if _, err := tagPost.ExecContext(ctx, postID, name); err != nil{
+			return fmt.Errorf("tag post: %w", err)
+		}
+	}
+	return nil
+}
+
+// attachTags fills in the tags of posts with one query of tagsOfPosts, which
+// may be bound to a transaction.
+func attachTags(ctx context.Context, tagsOfPosts *sql.Stmt, posts ...*models.OutputPost) error{
+	if len(posts) == 0{
+		return nil
+	}
+
+	byID := make(map[int]*models.OutputPost, len(posts))
+	ids := make([]int, 0, len(posts))
+	for _, post := range posts{
+		byID[post.ID] = post
+		ids = append(ids, post.ID)
+	}
+	idList, err := json.Marshal(ids)
+	if err != nil{
+		return fmt.Errorf("encode post ids: %w", err)
+	}
+
+	rows, err := tagsOfPosts.QueryContext(ctx, string(idList))
+	if err != nil{
+		return fmt.Errorf("get tags: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next(){
+		var id int
+		var name string
+		if err := rows.Scan(&id, &name); err != nil{
+			return fmt.Errorf("scan tag: %w", err)
+		}
+		byID[id].Tags = append(byID[id].Tags, name)
+	}
+
+	return rows.Err()
+}
@@ -0,0 +1,55 @@
+package sqlstore
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/RomanKovalev007/mai_news/internal/storage"
+)
+
+// RevokeToken records the token id as revoked until expiresAt, after which
+// the token is rejected anyway. It fails with storage.ErrTokenRevoked if the
+// token was revoked before. Revocations past their expiry are dropped.
+func (s *Storage) RevokeToken(ctx context.Context, id string, expiresAt time.Time) error{
+	op := "storage.sqlstore.RevokeToken"
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil{
+		return fmt.Errorf("%s: begin: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	if _, err = tx.StmtContext(ctx, s.stmts.purgeTokens).ExecContext(ctx, time.Now().UTC()); err != nil{
+		return fmt.Errorf("%s: purge expired: %w", op, err)
+	}
+	res, err := tx.StmtContext(ctx, s.stmts.revokeToken).ExecContext(ctx, id, expiresAt.UTC())
+	if err != nil{
+		return fmt.Errorf("%s: exec statement: %w", op, err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: rows affected: %w", op, err)
+	}
+	if n == 0 {
+		return storage.ErrTokenRevoked
+	}
+
+	if err = tx.Commit(); err != nil{
+		return fmt.Errorf("%s: commit: %w", op, err)
+	}
+
+	return nil
+}
+
+func (s *Storage) TokenRevoked(ctx context.Context, id string) (bool, error){
+	op := "storage.sqlstore.TokenRevoked"
+
+	var revoked bool
+	if err := s.stmts.tokenRevoked.QueryRowContext(ctx, id).Scan(&revoked); err != nil{
+		return false, fmt.Errorf("%s: scan row: %w", op, err)
+	}
+
+	return revoked, nil
+}
@@ -0,0 +1,153 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/RomanKovalev007/mai_news/internal/models"
+	"github.com/RomanKovalev007/mai_news/internal/storage"
+)
+
+func (s *Storage) GetTrash(ctx context.Context) ([]models.OutputPost, error){
+	op := "storage.sqlstore.GetTrash"
+
+	rows, err := s.stmts.getTrash.QueryContext(ctx)
+	if err != nil{
+		return []models.OutputPost{}, fmt.Errorf("%s: failed to get trash: %w", op, err)
+	}
+	defer rows.Close()
+
+	posts := []models.OutputPost{}
+
+	for rows.Next(){
+		var post models.OutputPost
+		err := rows.Scan(&post.ID, &post.Title, &post.Content, &post.Slug, &post.Section, &post.CreatedAt, &post.DeletedAt)
+		if err != nil {
+			return []models.OutputPost{}, fmt.Errorf("%s: scan row: %w", op, err)
+		}
+		posts = append(posts, post)
+	}
+
+	if err = rows.Err(); err != nil{
+		return []models.OutputPost{}, fmt.Errorf("%s: rows err: %w", op, err)
+	}
+	rows.Close()
+
+	tagged := make([]*models.OutputPost, len(posts))
+	for i := range posts{
+		tagged[i] = &posts[i]
+	}
+	if err = attachTags(ctx, s.stmts.tagsOfPosts, tagged...); err != nil{
+		return []models.OutputPost{}, fmt.Errorf("%s: %w", op, err)
+	}
+	if err = attachAuthors(ctx, s.stmts.authorsOfPosts, tagged...); err != nil{
+		return []models.OutputPost{}, fmt.Errorf("%s: %w", op, err)
+	}
+	if err = attachCommentCounts(ctx, s.stmts.commentCounts, tagged...); err != nil{
+		return []models.OutputPost{}, fmt.Errorf("%s: %w", op, err)
+	}
+	if err = attachReactions(ctx, s.stmts.reactionCounts, tagged...); err != nil{
+		return []models.OutputPost{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return posts, nil
+}
+
+// GetTrashedPost returns a post in the trash. It fails with
+// storage.ErrPostNotFound for posts that are not there.
+func (s *Storage) GetTrashedPost(ctx context.Context, id int) (models.OutputPost, error){
+	op := "storage.sqlstore.GetTrashedPost"
+
+	var post models.OutputPost
+	err := s.stmts.getTrashedPost.QueryRowContext(ctx, id).
+		Scan(&post.ID, &post.Title, &post.Content, &post.Slug, &post.Section, &post.CreatedAt, &post.DeletedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows){
+			return models.OutputPost{}, storage.ErrPostNotFound
+		}
+		return models.OutputPost{}, fmt.Errorf("%s: scan row: %w", op, err)
+	}
+
+	if err = attachTags(ctx, s.stmts.tagsOfPosts, &post); err != nil{
+		return models.OutputPost{}, fmt.Errorf("%s: %w", op, err)
+	}
+	if err = attachAuthors(ctx, s.stmts.authorsOfPosts, &post); err != nil{
+		return models.OutputPost{}, fmt.Errorf("%s: %w", op, err)
+	}
+	if err = attachCommentCounts(ctx, s.stmts.commentCounts, &post); err != nil{
+		return models.OutputPost{}, fmt.Errorf("%s: %w", op, err)
+	}
+	if err = attachReactions(ctx, s.stmts.reactionCounts, &post); err != nil{
+		return models.OutputPost{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return post, nil
+}
+
+func (s *Storage) RestorePost(ctx context.Context, id int) (models.OutputPost, error){
+	op := "storage.sqlstore.RestorePost"
+
+	var post models.OutputPost
+	err := s.stmts.restorePost.QueryRowContext(ctx, time.Now().UTC(), id).
+		Scan(&post.ID, &post.Title, &post.Content, &post.Slug, &post.Section, &post.CreatedAt, &post.UpdatedAt, &post.Version)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows){
+			return models.OutputPost{}, storage.ErrPostNotFound
+		}
+		return models.OutputPost{}, fmt.Errorf("%s: scan row: %w", op, err)
+	}
+	if err = attachTags(ctx, s.stmts.tagsOfPosts, &post); err != nil{
+		return models.OutputPost{}, fmt.Errorf("%s: %w", op, err)
+	}
+	if err = attachAuthors(ctx, s.stmts.authorsOfPosts, &post); err != nil{
+		return models.OutputPost{}, fmt.Errorf("%s: %w", op, err)
+	}
+	if err = attachCommentCounts(ctx, s.stmts.commentCounts, &post); err != nil{
+		return models.OutputPost{}, fmt.Errorf("%s: %w", op, err)
+	}
+	if err = attachReactions(ctx, s.stmts.reactionCounts, &post); err != nil{
+		return models.OutputPost{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return post, nil
+}
+
+func (s *Storage) PurgePost(ctx context.Context, id int) error{
+	op := "storage.sqlstore.PurgePost"
+
+	res, err := s.stmts.purgePost.ExecContext(ctx, id)
+	if err != nil {
+		return fmt.Errorf("%s: failed purge: %w", op, err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: rows affected: %w", op, err)
+	}
+	if n == 0 {
+		return storage.ErrPostNotFound
+	}
+
+	return nil
+}
+
+// PurgeTrash permanently removes posts trashed before deletedBefore and
+// returns how many were removed.
+func (s *Storage) PurgeTrash(ctx context.Context, deletedBefore time.Time) (int, error){
+	op := "storage.sqlstore.PurgeTrash"
+
+	res, err := s.stmts.purgeTrash.ExecContext(ctx, deletedBefore.UTC())
+	if err != nil {
+		return 0, fmt.Errorf("%s: failed purge: %w", op, err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("%s: rows affected: %w", op, err)
+	}
+
+	return int(n), nil
+}
@@ -0,0 +1,235 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/RomanKovalev007/mai_news/internal/models"
+	"github.com/RomanKovalev007/mai_news/internal/storage"
+)
+
+// GetUsers returns every user, oldest first.
+func (s *Storage) GetUsers(ctx context.Context) ([]models.User, error){
+	op := "storage.sqlstore.GetUsers"
+
+	rows, err := s.stmts.getUsers.QueryContext(ctx)
+	if err != nil{
+		return []models.User{}, fmt.Errorf("%s: failed to get users: %w", op, err)
+	}
+	defer rows.Close()
+
+	var users []models.User
+
+	for rows.Next(){
+		user, err := scanUser(rows)
+		if err != nil {
+			return []models.User{}, fmt.Errorf("%s: scan row: %w", op, err)
+		}
+		users = append(users, user)
+	}
+
+	if err = rows.Err(); err != nil{
+		return []models.User{}, fmt.Errorf("%s: rows err: %w", op, err)
+	}
+
+	return users, nil
+}
+
+func (s *Storage) GetUser(ctx context.Context, id int) (models.User, error){
+	op := "storage.sqlstore.GetUser"
+
+	user, err := scanUser(s.stmts.getUser.QueryRowContext(ctx, id))
+	if err != nil{
+		if errors.Is(err, sql.ErrNoRows){
+			return models.User{}, storage.ErrUserNotFound
+		}
+		return models.User{}, fmt.Errorf("%s: scan row: %w", op, err)
+	}
+
+	return user, nil
+}
+
+// GetUserByEmail returns the user with the given email, which is expected
+// lowercased.
+func (s *Storage) GetUserByEmail(ctx context.Context, email string) (models.User, error){
+	op := "storage.sqlstore.GetUserByEmail"
+
+	user, err := scanUser(s.stmts.getUserByEmail.QueryRowContext(ctx, email))
+	if err != nil{
+		if errors.Is(err, sql.ErrNoRows){
+			return models.User{}, storage.ErrUserNotFound
+		}
+		return models.User{}, fmt.Errorf("%s: scan row: %w", op, err)
+	}
+
+	return user, nil
+}
+
+// SaveUser creates a user, a reader unless input sets the role. It fails
+// with storage.ErrUserExists if the email is in use.
+func (s *Storage) SaveUser(ctx context.Context, input models.InputUser) (models.User, error){
+	op := "storage.sqlstore.SaveUser"
+
+	now := time.Now().UTC()
+	res, err := s.stmts.saveUser.ExecContext(ctx, input.Name, input.Email, input.PasswordHash, input.Role, now)
+	if err != nil{
+		if isUniqueViolation(err){
+			return models.User{}, storage.ErrUserExists
+		}
+		return models.User{}, fmt.Errorf("%s: exec statement: %w", op, err)
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return models.User{}, fmt.Errorf("%s: get last insert id: %w", op, err)
+	}
+
+	user := models.User{
+		ID: int(id),
+		Name: input.Name,
+		Email: input.Email,
+		PasswordHash: input.PasswordHash,
+		Role: input.Role,
+		CreatedAt: now,
+	}
+	if user.Role == ""{
+		user.Role = models.RoleReader
+	}
+
+	return user, nil
+}
+
+// PatchUser updates the fields set in patch. A new name is a change of the
+// posts of the user, which show it.
+func (s *Storage) PatchUser(ctx context.Context, id int, patch models.UserPatch) (models.User, error){
+	op := "storage.sqlstore.PatchUser"
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil{
+		return models.User{}, fmt.Errorf("%s: begin: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	current, err := scanUser(tx.StmtContext(ctx, s.stmts.getUser).QueryRowContext(ctx, id))
+	if err != nil{
+		if errors.Is(err, sql.ErrNoRows){
+			return models.User{}, storage.ErrUserNotFound
+		}
+		return models.User{}, fmt.Errorf("%s: scan row: %w", op, err)
+	}
+
+	user, err := scanUser(tx.StmtContext(ctx, s.stmts.patchUser).QueryRowContext(ctx, patch.Name, patch.Email, patch.PasswordHash, patch.Role, id))
+	if err != nil{
+		if isUniqueViolation(err){
+			return models.User{}, storage.ErrUserExists
+		}
+		return models.User{}, fmt.Errorf("%s: scan row: %w", op, err)
+	}
+
+	if user.Name != current.Name{
+		if err = touchAuthored(ctx, tx, id); err != nil{
+			return models.User{}, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	if err = tx.Commit(); err != nil{
+		return models.User{}, fmt.Errorf("%s: commit: %w", op, err)
+	}
+
+	return user, nil
+}
+
+// DeleteUser deletes the user and their API keys. Their posts stay, without
+// an author.
+func (s *Storage) DeleteUser(ctx context.Context, id int) error{
+	op := "storage.sqlstore.DeleteUser"
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil{
+		return fmt.Errorf("%s: begin: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	if err = touchAuthored(ctx, tx, id); err != nil{
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	res, err := tx.StmtContext(ctx, s.stmts.deleteUser).ExecContext(ctx, id)
+	if err != nil {
+		return fmt.Errorf("%s: failed delete: %w", op, err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: rows affected: %w", op, err)
+	}
+	if n == 0 {
+		return storage.ErrUserNotFound
+	}
+
+	if err = tx.Commit(); err != nil{
+		return fmt.Errorf("%s: commit: %w", op, err)
+	}
+
+	return nil
+}
+
+func scanUser(row scanner) (models.User, error){
+	var user models.User
+	if err := row.Scan(&user.ID, &user.Name, &user.Email, &user.PasswordHash, &user.Role, &user.CreatedAt); err != nil{
+		return models.User{}, err
+	}
+	return user, nil
+}
+
+// touchAuthored records a change of the posts of the user, whose author is
+// about to change.
+func touchAuthored(ctx context.Context, tx *sql.Tx, userID int) error{
+	_, err := tx.ExecContext(ctx, `
+	UPDATE post SET updated_at = ?, version = version + 1
+	WHERE author_id = ?`, time.Now().UTC(), userID)
+	if err != nil{
+		return fmt.Errorf("touch authored posts: %w", err)
+	}
+	return nil
+}
+
+// attachAuthors fills in the authors of posts with one query of
+// authorsOfPosts, which may be bound to a transaction.
+func attachAuthors(ctx context.Context, authorsOfPosts *sql.Stmt, posts ...*models.OutputPost) error{
+	if len(posts) == 0{
+		return nil
+	}
+
+	byID := make(map[int]*models.OutputPost, len(posts))
+	ids := make([]int, 0, len(posts))
+	for _, post := range posts{
+		byID[post.ID] = post
+		ids = append(ids, post.ID)
+	}
+	idList, err := json.Marshal(ids)
+	if err != nil{
+		return fmt.Errorf("encode post ids: %w", err)
+	}
+
+	rows, err := authorsOfPosts.QueryContext(ctx, string(idList))
+	if err != nil{
+		return fmt.Errorf("get authors: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next(){
+		var id int
+		var author models.Author
+		if err := rows.Scan(&id, &author.ID, &author.Name); err != nil{
+			return fmt.Errorf("scan author: %w", err)
+		}
+		byID[id].Author = &author
+	}
+
+	return rows.Err()
+}
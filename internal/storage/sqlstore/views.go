@@ -0,0 +1,70 @@
+package sqlstore
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/RomanKovalev007/mai_news/internal/models"
+	"github.com/RomanKovalev007/mai_news/internal/storage"
+)
+
+// AddViews adds the counts to the per-day views of the posts in one
+// transaction. Counts of posts that no longer exist are dropped.
+func (s *Storage) AddViews(ctx context.Context, counts []models.ViewCount) error{
+	op := "storage.sqlstore.AddViews"
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil{
+		return fmt.Errorf("%s: begin: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	stmt := tx.StmtContext(ctx, s.stmts.addViews)
+	for _, count := range counts{
+		if _, err := stmt.ExecContext(ctx, count.PostID, count.Day, count.Views); err != nil{
+			return fmt.Errorf("%s: failed to save: %w", op, err)
+		}
+	}
+
+	if err = tx.Commit(); err != nil{
+		return fmt.Errorf("%s: commit: %w", op, err)
+	}
+
+	return nil
+}
+
+// GetPostStats returns the views of the post by day. It fails with
+// storage.ErrPostNotFound if the post does not exist or is in the trash.
+func (s *Storage) GetPostStats(ctx context.Context, postID int) (models.PostStats, error){
+	op := "storage.sqlstore.GetPostStats"
+
+	var exists bool
+	if err := s.stmts.postExists.QueryRowContext(ctx, postID).Scan(&exists); err != nil{
+		return models.PostStats{}, fmt.Errorf("%s: find post: %w", op, err)
+	}
+	if !exists{
+		return models.PostStats{}, storage.ErrPostNotFound
+	}
+
+	rows, err := s.stmts.getPostViews.QueryContext(ctx, postID)
+	if err != nil{
+		return models.PostStats{}, fmt.Errorf("%s: failed to get views: %w", op, err)
+	}
+	defer rows.Close()
+
+	stats := models.PostStats{PostID: postID, Days: []models.DayViews{}}
+	for rows.Next(){
+		var day models.DayViews
+		if err := rows.Scan(&day.Day, &day.Views); err != nil {
+			return models.PostStats{}, fmt.Errorf("%s: scan row: %w", op, err)
+		}
+		stats.Views += day.Views
+		stats.Days = append(stats.Days, day)
+	}
+
+	if err = rows.Err(); err != nil{
+		return models.PostStats{}, fmt.Errorf("%s: rows err: %w", op, err)
+	}
+
+	return stats, nil
+}
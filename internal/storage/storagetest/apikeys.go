@@ -0,0 +1,80 @@
+package storagetest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/RomanKovalev007/mai_news/internal/models"
+	"github.com/RomanKovalev007/mai_news/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testAPIKeys(t *testing.T, s Storage) {
+	ctx := context.Background()
+
+	owner, err := s.SaveUser(ctx, models.InputUser{Name: "Бот", Email: "bot@mai.ru", PasswordHash: "hash"})
+	require.NoError(t, err)
+
+	key, err := s.SaveAPIKey(ctx, models.InputAPIKey{
+		Name:   "telegram",
+		Prefix: "mai_aaaaaaaa",
+		Hash:   "hash-a",
+		Scopes: []string{models.ScopePostsRead, models.ScopePostsWrite},
+		UserID: owner.ID,
+	})
+	require.NoError(t, err)
+	assert.NotZero(t, key.ID)
+	assert.Equal(t, []string{models.ScopePostsRead, models.ScopePostsWrite}, key.Scopes)
+	assert.Equal(t, owner.ID, key.UserID)
+	assert.False(t, key.CreatedAt.IsZero())
+	assert.Nil(t, key.LastUsedAt)
+	_, err = s.SaveAPIKey(ctx, models.InputAPIKey{Name: "orphan", Prefix: "mai_bbbbbbbb", Hash: "hash-b", Scopes: []string{models.ScopeAdmin}, UserID: owner.ID + 100})
+	assert.ErrorIs(t, err, storage.ErrUserNotFound)
+
+	got, err := s.GetAPIKeyByHash(ctx, "hash-a")
+	require.NoError(t, err)
+	assert.Equal(t, key.ID, got.ID)
+	assert.Equal(t, "telegram", got.Name)
+	_, err = s.GetAPIKeyByHash(ctx, "hash-b")
+	assert.ErrorIs(t, err, storage.ErrAPIKeyNotFound)
+
+	usedAt := time.Now().UTC().Truncate(time.Second)
+	require.NoError(t, s.TouchAPIKey(ctx, key.ID, usedAt))
+	got, err = s.GetAPIKey(ctx, key.ID)
+	require.NoError(t, err)
+	require.NotNil(t, got.LastUsedAt)
+	assert.True(t, usedAt.Equal(*got.LastUsedAt))
+
+	rotated, err := s.RotateAPIKey(ctx, key.ID, "mai_cccccccc", "hash-c")
+	require.NoError(t, err)
+	assert.Equal(t, key.ID, rotated.ID)
+	assert.Equal(t, "mai_cccccccc", rotated.Prefix)
+	assert.Equal(t, key.Scopes, rotated.Scopes)
+	assert.Nil(t, rotated.LastUsedAt)
+	_, err = s.GetAPIKeyByHash(ctx, "hash-a")
+	assert.ErrorIs(t, err, storage.ErrAPIKeyNotFound)
+	_, err = s.RotateAPIKey(ctx, key.ID+100, "mai_dddddddd", "hash-d")
+	assert.ErrorIs(t, err, storage.ErrAPIKeyNotFound)
+
+	keys, err := s.GetAPIKeys(ctx)
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+	assert.Equal(t, "hash-c", keys[0].Hash)
+
+	require.NoError(t, s.DeleteAPIKey(ctx, key.ID))
+	assert.ErrorIs(t, s.DeleteAPIKey(ctx, key.ID), storage.ErrAPIKeyNotFound)
+	_, err = s.GetAPIKey(ctx, key.ID)
+	assert.ErrorIs(t, err, storage.ErrAPIKeyNotFound)
+
+	// the keys of a deleted user go with them
+	other, err := s.SaveAPIKey(ctx, models.InputAPIKey{Name: "import", Prefix: "mai_eeeeeeee", Hash: "hash-e", Scopes: []string{models.ScopePostsWrite}, UserID: owner.ID})
+	require.NoError(t, err)
+	require.NoError(t, s.DeleteUser(ctx, owner.ID))
+	_, err = s.GetAPIKey(ctx, other.ID)
+	assert.ErrorIs(t, err, storage.ErrAPIKeyNotFound)
+	keys, err = s.GetAPIKeys(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, keys)
+}
@@ -0,0 +1,125 @@
+package storagetest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/RomanKovalev007/mai_news/internal/models"
+	"github.com/RomanKovalev007/mai_news/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testComments(t *testing.T, s Storage) {
+	ctx := context.Background()
+
+	anna, err := s.SaveUser(ctx, models.InputUser{Name: "Анна", Email: "anna@mai.ru", PasswordHash: "hash"})
+	require.NoError(t, err)
+	post, err := s.SavePost(ctx, models.InputPost{Title: "Title", Content: "Content"})
+	require.NoError(t, err)
+
+	_, err = s.GetComments(ctx, post.ID+100)
+	assert.ErrorIs(t, err, storage.ErrPostNotFound)
+	_, err = s.SaveComment(ctx, models.InputComment{PostID: post.ID + 100, Content: "Hi", Status: models.CommentApproved})
+	assert.ErrorIs(t, err, storage.ErrPostNotFound)
+	_, err = s.SaveComment(ctx, models.InputComment{PostID: post.ID, AuthorID: anna.ID + 100, Content: "Hi", Status: models.CommentApproved})
+	assert.ErrorIs(t, err, storage.ErrUserNotFound)
+
+	first, err := s.SaveComment(ctx, models.InputComment{PostID: post.ID, AuthorID: anna.ID, Content: "First", Status: models.CommentApproved})
+	require.NoError(t, err)
+	assert.NotZero(t, first.ID)
+	assert.Equal(t, &models.Author{ID: anna.ID, Name: "Анна"}, first.Author)
+	assert.False(t, first.CreatedAt.IsZero())
+	pending, err := s.SaveComment(ctx, models.InputComment{PostID: post.ID, AuthorID: anna.ID, Content: "Pending", Status: models.CommentPending})
+	require.NoError(t, err)
+	reply, err := s.SaveComment(ctx, models.InputComment{PostID: post.ID, ParentID: first.ID, Content: "Reply", Status: models.CommentApproved})
+	require.NoError(t, err)
+	assert.Equal(t, first.ID, reply.ParentID)
+	assert.Nil(t, reply.Author)
+
+	// replies go to shown comments of the same post only
+	_, err = s.SaveComment(ctx, models.InputComment{PostID: post.ID, ParentID: pending.ID, Content: "Reply", Status: models.CommentApproved})
+	assert.ErrorIs(t, err, storage.ErrCommentNotFound)
+	other, err := s.SavePost(ctx, models.InputPost{Title: "Other", Content: "Content"})
+	require.NoError(t, err)
+	_, err = s.SaveComment(ctx, models.InputComment{PostID: other.ID, ParentID: first.ID, Content: "Reply", Status: models.CommentApproved})
+	assert.ErrorIs(t, err, storage.ErrCommentNotFound)
+
+	comments, err := s.GetComments(ctx, post.ID)
+	require.NoError(t, err)
+	require.Len(t, comments, 2)
+	assert.Equal(t, first.ID, comments[0].ID)
+	assert.Equal(t, reply.ID, comments[1].ID)
+	got, err := s.GetPost(ctx, post.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 2, got.CommentCount)
+	page, err := s.GetAllPosts(ctx, models.PostFilter{}, models.Page{Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, page.Posts, 2)
+	assert.Equal(t, 2, page.Posts[0].CommentCount+page.Posts[1].CommentCount)
+
+	queue, err := s.GetCommentQueue(ctx, nil)
+	require.NoError(t, err)
+	require.Len(t, queue, 1)
+	assert.Equal(t, pending.ID, queue[0].ID)
+	queue, err = s.GetCommentQueue(ctx, []string{models.DefaultSection})
+	require.NoError(t, err)
+	assert.Len(t, queue, 1)
+	queue, err = s.GetCommentQueue(ctx, []string{})
+	require.NoError(t, err)
+	assert.Empty(t, queue)
+
+	approved := models.CommentApproved
+	moderated, err := s.PatchComment(ctx, pending.ID, models.CommentPatch{Status: &approved})
+	require.NoError(t, err)
+	assert.Equal(t, models.CommentApproved, moderated.Status)
+	assert.Equal(t, pending.UpdatedAt, moderated.UpdatedAt)
+	content := "Edited"
+	edited, err := s.PatchComment(ctx, pending.ID, models.CommentPatch{Content: &content})
+	require.NoError(t, err)
+	assert.Equal(t, "Edited", edited.Content)
+	assert.Equal(t, models.CommentApproved, edited.Status)
+	_, err = s.PatchComment(ctx, pending.ID+100, models.CommentPatch{Content: &content})
+	assert.ErrorIs(t, err, storage.ErrCommentNotFound)
+	queue, err = s.GetCommentQueue(ctx, nil)
+	require.NoError(t, err)
+	assert.Empty(t, queue)
+
+	// replies to a rejected comment are hidden with it
+	rejected := models.CommentRejected
+	_, err = s.PatchComment(ctx, first.ID, models.CommentPatch{Status: &rejected})
+	require.NoError(t, err)
+	comments, err = s.GetComments(ctx, post.ID)
+	require.NoError(t, err)
+	require.Len(t, comments, 1)
+	assert.Equal(t, pending.ID, comments[0].ID)
+	got, err = s.GetPost(ctx, post.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, got.CommentCount)
+
+	// deleting a comment deletes the replies to it
+	require.NoError(t, s.DeleteComment(ctx, first.ID))
+	assert.ErrorIs(t, s.DeleteComment(ctx, first.ID), storage.ErrCommentNotFound)
+	_, err = s.GetComment(ctx, reply.ID)
+	assert.ErrorIs(t, err, storage.ErrCommentNotFound)
+
+	// comments of deleted users stay, without an author
+	require.NoError(t, s.DeleteUser(ctx, anna.ID))
+	orphan, err := s.GetComment(ctx, pending.ID)
+	require.NoError(t, err)
+	assert.Nil(t, orphan.Author)
+
+	// the comments of a post in the trash are not shown nor moderated, and
+	// go away with the post
+	_, err = s.SaveComment(ctx, models.InputComment{PostID: post.ID, Content: "Late", Status: models.CommentPending})
+	require.NoError(t, err)
+	require.NoError(t, s.DeletePost(ctx, post.ID, 0))
+	_, err = s.GetComments(ctx, post.ID)
+	assert.ErrorIs(t, err, storage.ErrPostNotFound)
+	queue, err = s.GetCommentQueue(ctx, nil)
+	require.NoError(t, err)
+	assert.Empty(t, queue)
+	require.NoError(t, s.PurgePost(ctx, post.ID))
+	_, err = s.GetComment(ctx, pending.ID)
+	assert.ErrorIs(t, err, storage.ErrCommentNotFound)
+}
@@ -0,0 +1,316 @@
+package storagetest
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/RomanKovalev007/mai_news/internal/lib/jsonpatch"
+	"github.com/RomanKovalev007/mai_news/internal/models"
+	"github.com/RomanKovalev007/mai_news/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testPostCRUD(t *testing.T, s Storage) {
+	ctx := context.Background()
+
+	created, err := s.SavePost(ctx, models.InputPost{Title: "Title", Content: "Content"})
+	require.NoError(t, err)
+	assert.NotZero(t, created.ID)
+
+	got, err := s.GetPost(ctx, created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Title", got.Title)
+	assert.Equal(t, "Content", got.Content)
+
+	patched, err := s.PatchPost(ctx, created.ID, 0, fullPatch("New title", "New content"))
+	require.NoError(t, err)
+	assert.Equal(t, "New title", patched.Title)
+
+	page, err := s.GetAllPosts(ctx, models.PostFilter{}, models.Page{Limit: 10})
+	require.NoError(t, err)
+	assert.Len(t, page.Posts, 1)
+
+	require.NoError(t, s.DeletePost(ctx, created.ID, 0))
+
+	_, err = s.GetPost(ctx, created.ID)
+	assert.ErrorIs(t, err, storage.ErrPostNotFound)
+}
+
+func testPostNotFound(t *testing.T, s Storage) {
+	ctx := context.Background()
+
+	_, err := s.GetPost(ctx, 42)
+	assert.ErrorIs(t, err, storage.ErrPostNotFound)
+
+	_, err = s.PatchPost(ctx, 42, 0, fullPatch("x", ""))
+	assert.ErrorIs(t, err, storage.ErrPostNotFound)
+
+	assert.ErrorIs(t, s.DeletePost(ctx, 42, 0), storage.ErrPostNotFound)
+}
+
+func testUniqueTitle(t *testing.T, s Storage) {
+	ctx := context.Background()
+
+	first, err := s.SavePost(ctx, models.InputPost{Title: "Same", Content: "a"})
+	require.NoError(t, err)
+	second, err := s.SavePost(ctx, models.InputPost{Title: "Other", Content: "b"})
+	require.NoError(t, err)
+
+	_, err = s.SavePost(ctx, models.InputPost{Title: "Same", Content: "c"})
+	assert.ErrorIs(t, err, storage.ErrPostExists)
+
+	_, err = s.PatchPost(ctx, second.ID, 0, fullPatch("Same", ""))
+	assert.ErrorIs(t, err, storage.ErrPostExists)
+
+	// keeping its own title is not a conflict
+	_, err = s.PatchPost(ctx, first.ID, 0, fullPatch("Same", "d"))
+	assert.NoError(t, err)
+}
+
+func testGetAllPostsPages(t *testing.T, s Storage) {
+	ctx := context.Background()
+
+	for i := 1; i <= 5; i++ {
+		_, err := s.SavePost(ctx, models.InputPost{Title: fmt.Sprintf("post %d", i)})
+		require.NoError(t, err)
+	}
+	trashed, err := s.SavePost(ctx, models.InputPost{Title: "trashed"})
+	require.NoError(t, err)
+	require.NoError(t, s.DeletePost(ctx, trashed.ID, 0))
+
+	newestFirst := models.PostFilter{SortBy: models.SortByCreatedAt, Desc: true}
+	var titles []string
+	page := models.Page{Limit: 2}
+	for pages := 1; ; pages++ {
+		result, err := s.GetAllPosts(ctx, newestFirst, page)
+		require.NoError(t, err)
+		for _, post := range result.Posts {
+			titles = append(titles, post.Title)
+		}
+		if result.Next == nil {
+			assert.Equal(t, 3, pages)
+			break
+		}
+		require.Len(t, result.Posts, 2)
+		page.After = result.Next
+	}
+	assert.Equal(t, []string{"post 5", "post 4", "post 3", "post 2", "post 1"}, titles)
+
+	// a full last page has no next cursor
+	result, err := s.GetAllPosts(ctx, models.PostFilter{}, models.Page{Limit: 5})
+	require.NoError(t, err)
+	assert.Len(t, result.Posts, 5)
+	assert.Nil(t, result.Next)
+}
+
+func testGetAllPostsFilter(t *testing.T, s Storage) {
+	ctx := context.Background()
+
+	for _, title := range []string{"Приём 2", "Приём 1"} {
+		_, err := s.SavePost(ctx, models.InputPost{Title: title})
+		require.NoError(t, err)
+	}
+	mid := time.Now()
+	_, err := s.SavePost(ctx, models.InputPost{Title: "Спорт"})
+	require.NoError(t, err)
+
+	titles := func(filter models.PostFilter, limit int) []string {
+		var titles []string
+		page := models.Page{Limit: limit}
+		for {
+			result, err := s.GetAllPosts(ctx, filter, page)
+			require.NoError(t, err)
+			for _, post := range result.Posts {
+				titles = append(titles, post.Title)
+			}
+			if result.Next == nil {
+				return titles
+			}
+			page.After = result.Next
+		}
+	}
+
+	assert.Equal(t, []string{"Приём 2", "Приём 1", "Спорт"}, titles(models.PostFilter{}, 10))
+	assert.Equal(t, []string{"Спорт"}, titles(models.PostFilter{From: mid}, 10))
+	assert.Equal(t, []string{"Приём 2", "Приём 1"}, titles(models.PostFilter{To: mid}, 10))
+	assert.Equal(t, []string{"Приём 1", "Приём 2"}, titles(models.PostFilter{TitlePrefix: "Приём", SortBy: models.SortByTitle}, 1))
+	assert.Empty(t, titles(models.PostFilter{TitlePrefix: "приём"}, 10))
+	assert.Equal(t, []string{"Спорт", "Приём 2", "Приём 1"}, titles(models.PostFilter{SortBy: models.SortByTitle, Desc: true}, 2))
+	assert.Equal(t, []string{"Спорт", "Приём 1", "Приём 2"}, titles(models.PostFilter{SortBy: models.SortByCreatedAt, Desc: true}, 2))
+}
+
+func testPostVersions(t *testing.T, s Storage) {
+	ctx := context.Background()
+
+	post, err := s.SavePost(ctx, models.InputPost{Title: "v1", Content: "first"})
+	require.NoError(t, err)
+	assert.Equal(t, 1, post.Version)
+
+	got, err := s.GetPost(ctx, post.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, got.Version)
+
+	patched, err := s.PatchPost(ctx, post.ID, 1, fullPatch("v2", "second"))
+	require.NoError(t, err)
+	assert.Equal(t, 2, patched.Version)
+
+	// a stale version changes nothing
+	_, err = s.PatchPost(ctx, post.ID, 1, fullPatch("lost", "update"))
+	assert.ErrorIs(t, err, storage.ErrVersionMismatch)
+	got, err = s.GetPost(ctx, post.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "v2", got.Title)
+	revisions, err := s.GetRevisions(ctx, post.ID)
+	require.NoError(t, err)
+	assert.Len(t, revisions, 1)
+
+	reverted, err := s.RevertPost(ctx, post.ID, 1, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 3, reverted.Version)
+
+	assert.ErrorIs(t, s.DeletePost(ctx, post.ID, 2), storage.ErrVersionMismatch)
+	require.NoError(t, s.DeletePost(ctx, post.ID, 3))
+
+	// restoring changes the post like any update
+	restored, err := s.RestorePost(ctx, post.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 5, restored.Version)
+
+	_, err = s.PatchPost(ctx, 42, 1, fullPatch("x", ""))
+	assert.ErrorIs(t, err, storage.ErrPostNotFound)
+	assert.ErrorIs(t, s.DeletePost(ctx, 42, 1), storage.ErrPostNotFound)
+}
+
+// fullPatch sets both fields of a post, like a PUT would.
+func fullPatch(title, content string) models.PostPatch {
+	return models.PostPatch{Title: &title, Content: &content}
+}
+
+func testPartialPatch(t *testing.T, s Storage) {
+	ctx := context.Background()
+
+	post, err := s.SavePost(ctx, models.InputPost{Title: "Title", Content: "Content"})
+	require.NoError(t, err)
+
+	title := "New title"
+	patched, err := s.PatchPost(ctx, post.ID, 0, models.PostPatch{Title: &title})
+	require.NoError(t, err)
+	assert.Equal(t, "New title", patched.Title)
+	assert.Equal(t, "Content", patched.Content)
+
+	content := "New content"
+	patched, err = s.PatchPost(ctx, post.ID, 0, models.PostPatch{Content: &content})
+	require.NoError(t, err)
+	assert.Equal(t, "New title", patched.Title)
+	assert.Equal(t, "New content", patched.Content)
+	assert.Equal(t, 3, patched.Version)
+
+	// an empty patch changes nothing, not even the version
+	patched, err = s.PatchPost(ctx, post.ID, 3, models.PostPatch{})
+	require.NoError(t, err)
+	assert.Equal(t, "New content", patched.Content)
+	assert.Equal(t, 3, patched.Version)
+	_, err = s.PatchPost(ctx, post.ID, 1, models.PostPatch{})
+	assert.ErrorIs(t, err, storage.ErrVersionMismatch)
+
+	revisions, err := s.GetRevisions(ctx, post.ID)
+	require.NoError(t, err)
+	require.Len(t, revisions, 2)
+	assert.Equal(t, "New title", revisions[0].Title)
+	assert.Equal(t, "Content", revisions[0].Content)
+
+	other, err := s.SavePost(ctx, models.InputPost{Title: "Other", Content: "x"})
+	require.NoError(t, err)
+	_, err = s.PatchPost(ctx, other.ID, 0, models.PostPatch{Title: &title})
+	assert.ErrorIs(t, err, storage.ErrPostExists)
+}
+
+func testApplyPatch(t *testing.T, s Storage) {
+	ctx := context.Background()
+
+	post, err := s.SavePost(ctx, models.InputPost{Title: "Title", Content: "Content"})
+	require.NoError(t, err)
+
+	patched, err := s.ApplyPatch(ctx, post.ID, 1, 0, []jsonpatch.Operation{
+		{Op: jsonpatch.Test, Field: "title", Value: "Title"},
+		{Op: jsonpatch.Replace, Field: "title", Value: "New title"},
+		{Op: jsonpatch.Test, Field: "title", Value: "New title"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "New title", patched.Title)
+	assert.Equal(t, "Content", patched.Content)
+	assert.Equal(t, 2, patched.Version)
+
+	// a failed test discards the operations before it
+	_, err = s.ApplyPatch(ctx, post.ID, 0, 0, []jsonpatch.Operation{
+		{Op: jsonpatch.Replace, Field: "content", Value: "lost"},
+		{Op: jsonpatch.Test, Field: "title", Value: "Title"},
+	})
+	assert.ErrorIs(t, err, jsonpatch.ErrTestFailed)
+	got, err := s.GetPost(ctx, post.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Content", got.Content)
+	assert.Equal(t, 2, got.Version)
+
+	// operations on tags and the slug see those of the post
+	_, err = s.ApplyPatch(ctx, post.ID, 0, 0, []jsonpatch.Operation{{Op: jsonpatch.Replace, Field: "tags", Tags: []string{"news", "sports"}}})
+	require.NoError(t, err)
+	patched, err = s.ApplyPatch(ctx, post.ID, 0, 0, []jsonpatch.Operation{
+		{Op: jsonpatch.Test, Field: "tag", Index: 1, Value: "sports"},
+		{Op: jsonpatch.Remove, Field: "tag", Index: 0},
+		{Op: jsonpatch.Replace, Field: "slug", Value: "patched"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"sports"}, patched.Tags)
+	assert.Equal(t, "patched", patched.Slug)
+
+	_, err = s.ApplyPatch(ctx, post.ID, 1, 0, nil)
+	assert.ErrorIs(t, err, storage.ErrVersionMismatch)
+	_, err = s.ApplyPatch(ctx, 42, 0, 0, nil)
+	assert.ErrorIs(t, err, storage.ErrPostNotFound)
+}
+
+func testUpdatedAt(t *testing.T, s Storage) {
+	ctx := context.Background()
+
+	lastModified := func() time.Time {
+		page, err := s.GetAllPosts(ctx, models.PostFilter{}, models.Page{Limit: 10})
+		require.NoError(t, err)
+		return page.LastModified
+	}
+	assert.True(t, lastModified().IsZero())
+
+	post, err := s.SavePost(ctx, models.InputPost{Title: "Title", Content: "Content"})
+	require.NoError(t, err)
+	assert.False(t, post.UpdatedAt.IsZero())
+	got, err := s.GetPost(ctx, post.ID)
+	require.NoError(t, err)
+	assert.WithinDuration(t, post.UpdatedAt, got.UpdatedAt, time.Millisecond)
+	assert.WithinDuration(t, post.UpdatedAt, lastModified(), time.Millisecond)
+
+	time.Sleep(2 * time.Millisecond)
+	patched, err := s.PatchPost(ctx, post.ID, 0, fullPatch("New title", "Content"))
+	require.NoError(t, err)
+	assert.True(t, patched.UpdatedAt.After(got.UpdatedAt))
+
+	// an empty patch is not a change
+	unchanged, err := s.PatchPost(ctx, post.ID, 0, models.PostPatch{})
+	require.NoError(t, err)
+	assert.True(t, unchanged.UpdatedAt.Equal(patched.UpdatedAt))
+
+	// trashing a post changes the list it disappears from
+	before := lastModified()
+	time.Sleep(2 * time.Millisecond)
+	require.NoError(t, s.DeletePost(ctx, post.ID, 0))
+	assert.True(t, lastModified().After(before))
+
+	before = lastModified()
+	time.Sleep(2 * time.Millisecond)
+	restored, err := s.RestorePost(ctx, post.ID)
+	require.NoError(t, err)
+	assert.True(t, restored.UpdatedAt.After(before))
+	assert.True(t, lastModified().After(before))
+}
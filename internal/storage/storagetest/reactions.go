@@ -0,0 +1,71 @@
+package storagetest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/RomanKovalev007/mai_news/internal/models"
+	"github.com/RomanKovalev007/mai_news/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testReactions(t *testing.T, s Storage) {
+	ctx := context.Background()
+
+	anna, err := s.SaveUser(ctx, models.InputUser{Name: "Анна", Email: "anna@mai.ru", PasswordHash: "hash"})
+	require.NoError(t, err)
+	post, err := s.SavePost(ctx, models.InputPost{Title: "Title", Content: "Content"})
+	require.NoError(t, err)
+	user := models.ReactorID{UserID: anna.ID}
+	client := models.ReactorID{Fingerprint: "abc"}
+
+	_, err = s.SetReaction(ctx, post.ID+100, client, models.ReactionLike)
+	assert.ErrorIs(t, err, storage.ErrPostNotFound)
+	_, err = s.SetReaction(ctx, post.ID, models.ReactorID{UserID: anna.ID + 100}, models.ReactionLike)
+	assert.ErrorIs(t, err, storage.ErrUserNotFound)
+
+	state, err := s.SetReaction(ctx, post.ID, user, models.ReactionLike)
+	require.NoError(t, err)
+	assert.Equal(t, models.PostReactions{PostID: post.ID, Kind: models.ReactionLike,
+		Reactions: map[models.ReactionKind]int{models.ReactionLike: 1}}, state)
+	state, err = s.SetReaction(ctx, post.ID, client, models.ReactionLike)
+	require.NoError(t, err)
+	assert.Equal(t, map[models.ReactionKind]int{models.ReactionLike: 2}, state.Reactions)
+
+	// the same reaction again changes nothing, another one replaces it
+	state, err = s.SetReaction(ctx, post.ID, client, models.ReactionLike)
+	require.NoError(t, err)
+	assert.Equal(t, map[models.ReactionKind]int{models.ReactionLike: 2}, state.Reactions)
+	state, err = s.SetReaction(ctx, post.ID, client, models.ReactionFire)
+	require.NoError(t, err)
+	assert.Equal(t, map[models.ReactionKind]int{models.ReactionLike: 1, models.ReactionFire: 1}, state.Reactions)
+
+	got, err := s.GetPost(ctx, post.ID)
+	require.NoError(t, err)
+	assert.Equal(t, map[models.ReactionKind]int{models.ReactionLike: 1, models.ReactionFire: 1}, got.Reactions)
+	assert.Equal(t, post.Version, got.Version)
+	page, err := s.GetAllPosts(ctx, models.PostFilter{}, models.Page{Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, page.Posts, 1)
+	assert.Equal(t, got.Reactions, page.Posts[0].Reactions)
+
+	err = s.DeleteReaction(ctx, post.ID, client, models.ReactionLike)
+	assert.ErrorIs(t, err, storage.ErrReactionNotFound)
+	require.NoError(t, s.DeleteReaction(ctx, post.ID, client, models.ReactionFire))
+	err = s.DeleteReaction(ctx, post.ID, client, models.ReactionFire)
+	assert.ErrorIs(t, err, storage.ErrReactionNotFound)
+
+	// reactions of deleted users go away with them
+	require.NoError(t, s.DeleteUser(ctx, anna.ID))
+	got, err = s.GetPost(ctx, post.ID)
+	require.NoError(t, err)
+	assert.Empty(t, got.Reactions)
+
+	_, err = s.SetReaction(ctx, post.ID, client, models.ReactionHeart)
+	require.NoError(t, err)
+	require.NoError(t, s.DeletePost(ctx, post.ID, 0))
+	_, err = s.SetReaction(ctx, post.ID, client, models.ReactionLike)
+	assert.ErrorIs(t, err, storage.ErrPostNotFound)
+	require.NoError(t, s.PurgePost(ctx, post.ID))
+}
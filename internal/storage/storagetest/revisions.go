@@ -0,0 +1,106 @@
+package storagetest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/RomanKovalev007/mai_news/internal/lib/jsonpatch"
+	"github.com/RomanKovalev007/mai_news/internal/models"
+	"github.com/RomanKovalev007/mai_news/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testRevisions(t *testing.T, s Storage) {
+	ctx := context.Background()
+
+	post, err := s.SavePost(ctx, models.InputPost{Title: "v1", Content: "first"})
+	require.NoError(t, err)
+
+	revisions, err := s.GetRevisions(ctx, post.ID)
+	require.NoError(t, err)
+	assert.Empty(t, revisions)
+
+	_, err = s.PatchPost(ctx, post.ID, 0, fullPatch("v2", "second"))
+	require.NoError(t, err)
+	_, err = s.PatchPost(ctx, post.ID, 0, fullPatch("v3", "third"))
+	require.NoError(t, err)
+	// an unchanged patch does not add a revision
+	_, err = s.PatchPost(ctx, post.ID, 0, fullPatch("v3", "third"))
+	require.NoError(t, err)
+
+	revisions, err = s.GetRevisions(ctx, post.ID)
+	require.NoError(t, err)
+	require.Len(t, revisions, 2)
+	assert.Equal(t, 2, revisions[0].Rev)
+	assert.Equal(t, "v2", revisions[0].Title)
+	assert.Equal(t, 1, revisions[1].Rev)
+	assert.Equal(t, "v1", revisions[1].Title)
+	assert.NotEmpty(t, revisions[1].RevisedAt)
+
+	revision, err := s.GetRevision(ctx, post.ID, 1)
+	require.NoError(t, err)
+	assert.Equal(t, "first", revision.Content)
+
+	_, err = s.GetRevision(ctx, post.ID, 3)
+	assert.ErrorIs(t, err, storage.ErrRevisionNotFound)
+
+	reverted, err := s.RevertPost(ctx, post.ID, 1, 0)
+	require.NoError(t, err)
+	assert.Equal(t, "v1", reverted.Title)
+	assert.Equal(t, "first", reverted.Content)
+
+	revision, err = s.GetRevision(ctx, post.ID, 3)
+	require.NoError(t, err)
+	assert.Equal(t, "v3", revision.Title)
+
+	_, err = s.RevertPost(ctx, post.ID, 10, 0)
+	assert.ErrorIs(t, err, storage.ErrRevisionNotFound)
+}
+
+func testRevisionsOfMissingPost(t *testing.T, s Storage) {
+	ctx := context.Background()
+
+	_, err := s.GetRevisions(ctx, 42)
+	assert.ErrorIs(t, err, storage.ErrPostNotFound)
+	_, err = s.GetRevision(ctx, 42, 1)
+	assert.ErrorIs(t, err, storage.ErrPostNotFound)
+	_, err = s.RevertPost(ctx, 42, 1, 0)
+	assert.ErrorIs(t, err, storage.ErrPostNotFound)
+}
+
+func testRevisionEditors(t *testing.T, s Storage) {
+	ctx := context.Background()
+
+	anna, err := s.SaveUser(ctx, models.InputUser{Name: "Анна", Email: "anna@mai.ru", PasswordHash: "hash"})
+	require.NoError(t, err)
+	post, err := s.SavePost(ctx, models.InputPost{Title: "v1", Content: "first"})
+	require.NoError(t, err)
+
+	patch := fullPatch("v2", "second")
+	patch.EditorID = anna.ID
+	_, err = s.PatchPost(ctx, post.ID, 0, patch)
+	require.NoError(t, err)
+	_, err = s.ApplyPatch(ctx, post.ID, 0, anna.ID, []jsonpatch.Operation{
+		{Op: jsonpatch.Replace, Field: "content", Value: "third"},
+	})
+	require.NoError(t, err)
+	// changes without a user or by an unknown one keep no editor
+	_, err = s.PatchPost(ctx, post.ID, 0, fullPatch("v4", "fourth"))
+	require.NoError(t, err)
+	_, err = s.RevertPost(ctx, post.ID, 1, 42)
+	require.NoError(t, err)
+
+	revisions, err := s.GetRevisions(ctx, post.ID)
+	require.NoError(t, err)
+	require.Len(t, revisions, 4)
+	assert.Nil(t, revisions[0].RevisedBy)
+	assert.Nil(t, revisions[1].RevisedBy)
+	assert.Equal(t, &models.Author{ID: anna.ID, Name: "Анна"}, revisions[2].RevisedBy)
+	assert.Equal(t, &models.Author{ID: anna.ID, Name: "Анна"}, revisions[3].RevisedBy)
+
+	require.NoError(t, s.DeleteUser(ctx, anna.ID))
+	revision, err := s.GetRevision(ctx, post.ID, 1)
+	require.NoError(t, err)
+	assert.Nil(t, revision.RevisedBy)
+}
@@ -0,0 +1,53 @@
+package storagetest
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/RomanKovalev007/mai_news/internal/models"
+	"github.com/RomanKovalev007/mai_news/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testSearchPosts(t *testing.T, s Storage) {
+	ctx := context.Background()
+
+	for _, p := range []models.InputPost{
+		{Title: "Приём документов", Content: "В МАИ начался приём документов на бакалавриат."},
+		{Title: "Стипендии", Content: "Повышенные стипендии назначат после приёма заявлений."},
+		{Title: "Спорт", Content: "Сборная МАИ победила в турнире по волейболу."},
+	} {
+		_, err := s.SavePost(ctx, p)
+		require.NoError(t, err)
+	}
+	trashed, err := s.SavePost(ctx, models.InputPost{Title: "Удалённый приём", Content: "Скрыт"})
+	require.NoError(t, err)
+	require.NoError(t, s.DeletePost(ctx, trashed.ID, 0))
+
+	// prefix matching, case folding and ё/е folding
+	results, err := s.SearchPosts(ctx, "ПРИЕМ", 10)
+	if errors.Is(err, storage.ErrSearchUnavailable) {
+		t.Skip(err)
+	}
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	// the title match ranks first
+	assert.Equal(t, "Приём документов", results[0].Title)
+	assert.Contains(t, results[0].TitleHighlight, "<mark>Приём</mark>")
+	assert.Contains(t, results[1].Snippet, "<mark>приёма</mark>")
+
+	results, err = s.SearchPosts(ctx, "маи волейб", 10)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "Спорт", results[0].Title)
+
+	results, err = s.SearchPosts(ctx, "приём", 1)
+	require.NoError(t, err)
+	assert.Len(t, results, 1)
+
+	results, err = s.SearchPosts(ctx, `"*:()`, 10)
+	require.NoError(t, err)
+	assert.Empty(t, results)
+}
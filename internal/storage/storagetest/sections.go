@@ -0,0 +1,109 @@
+package storagetest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/RomanKovalev007/mai_news/internal/models"
+	"github.com/RomanKovalev007/mai_news/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testSections(t *testing.T, s Storage) {
+	ctx := context.Background()
+
+	general, err := s.GetSection(ctx, models.DefaultSection)
+	require.NoError(t, err)
+	assert.Equal(t, models.DefaultSection, general.Slug)
+	assert.Empty(t, general.Editors)
+
+	it, err := s.SaveSection(ctx, models.InputSection{
+		Slug:        "it",
+		Title:       "Институт №8",
+		Description: "Компьютерные науки",
+		Editors:     []string{"b@mai.ru", "a@mai.ru", "b@mai.ru"},
+	})
+	require.NoError(t, err)
+	assert.NotZero(t, it.ID)
+	assert.Equal(t, []string{"a@mai.ru", "b@mai.ru"}, it.Editors)
+	assert.False(t, it.CreatedAt.IsZero())
+
+	_, err = s.SaveSection(ctx, models.InputSection{Slug: "it", Title: "Другой"})
+	assert.ErrorIs(t, err, storage.ErrSectionExists)
+	_, err = s.GetSection(ctx, "missing")
+	assert.ErrorIs(t, err, storage.ErrSectionNotFound)
+
+	got, err := s.GetSection(ctx, "it")
+	require.NoError(t, err)
+	assert.Equal(t, it.Editors, got.Editors)
+	assert.Equal(t, "Компьютерные науки", got.Description)
+
+	sections, err := s.GetSections(ctx)
+	require.NoError(t, err)
+	require.Len(t, sections, 2)
+	// ordered by title
+	assert.Equal(t, "it", sections[0].Slug)
+	assert.Equal(t, models.DefaultSection, sections[1].Slug)
+
+	title := "Институт №8 «Компьютерные науки»"
+	editors := []string{"c@mai.ru"}
+	patched, err := s.PatchSection(ctx, "it", models.SectionPatch{Title: &title, Editors: &editors})
+	require.NoError(t, err)
+	assert.Equal(t, title, patched.Title)
+	assert.Equal(t, "Компьютерные науки", patched.Description)
+	assert.Equal(t, []string{"c@mai.ru"}, patched.Editors)
+	description := ""
+	patched, err = s.PatchSection(ctx, "it", models.SectionPatch{Description: &description})
+	require.NoError(t, err)
+	assert.Empty(t, patched.Description)
+	assert.Equal(t, []string{"c@mai.ru"}, patched.Editors)
+	_, err = s.PatchSection(ctx, "missing", models.SectionPatch{Title: &title})
+	assert.ErrorIs(t, err, storage.ErrSectionNotFound)
+
+	// titles are unique per section
+	first, err := s.SavePost(ctx, models.InputPost{Title: "Расписание", Content: "Content"})
+	require.NoError(t, err)
+	assert.Equal(t, models.DefaultSection, first.Section)
+	second, err := s.SavePost(ctx, models.InputPost{Title: "Расписание", Content: "Content", Section: "it"})
+	require.NoError(t, err)
+	assert.Equal(t, "it", second.Section)
+	assert.NotEqual(t, first.Slug, second.Slug)
+	_, err = s.SavePost(ctx, models.InputPost{Title: "Расписание", Content: "Content", Section: "it"})
+	assert.ErrorIs(t, err, storage.ErrPostExists)
+	_, err = s.SavePost(ctx, models.InputPost{Title: "Other", Content: "Content", Section: "missing"})
+	assert.ErrorIs(t, err, storage.ErrSectionNotFound)
+
+	post, err := s.GetPost(ctx, second.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "it", post.Section)
+
+	list := func(section string) []int {
+		page, err := s.GetAllPosts(ctx, models.PostFilter{Section: section}, models.Page{Limit: 10})
+		require.NoError(t, err)
+		var ids []int
+		for _, post := range page.Posts {
+			ids = append(ids, post.ID)
+		}
+		return ids
+	}
+	assert.Equal(t, []int{second.ID}, list("it"))
+	assert.Equal(t, []int{first.ID}, list(models.DefaultSection))
+	assert.Equal(t, []int{first.ID, second.ID}, list(""))
+	assert.Empty(t, list("missing"))
+
+	// moving a post checks its title against the posts of the new section
+	it8 := "it"
+	_, err = s.PatchPost(ctx, first.ID, 0, models.PostPatch{Section: &it8})
+	assert.ErrorIs(t, err, storage.ErrPostExists)
+	missing := "missing"
+	_, err = s.PatchPost(ctx, first.ID, 0, models.PostPatch{Section: &missing})
+	assert.ErrorIs(t, err, storage.ErrSectionNotFound)
+	newTitle := "Расписание сессии"
+	moved, err := s.PatchPost(ctx, first.ID, 0, models.PostPatch{Title: &newTitle, Section: &it8})
+	require.NoError(t, err)
+	assert.Equal(t, "it", moved.Section)
+	assert.Equal(t, first.Version+1, moved.Version)
+	assert.Equal(t, []int{first.ID, second.ID}, list("it"))
+	assert.Empty(t, list(models.DefaultSection))
+}
@@ -0,0 +1,72 @@
+package storagetest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/RomanKovalev007/mai_news/internal/models"
+	"github.com/RomanKovalev007/mai_news/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testSlugs(t *testing.T, s Storage) {
+	ctx := context.Background()
+
+	first, err := s.SavePost(ctx, models.InputPost{Title: "Новости МАИ", Content: "Content"})
+	require.NoError(t, err)
+	assert.Equal(t, "novosti-mai", first.Slug)
+
+	// another title with the same transliteration gets a suffix
+	second, err := s.SavePost(ctx, models.InputPost{Title: "Новости маи!", Content: "Content"})
+	require.NoError(t, err)
+	assert.Equal(t, "novosti-mai-2", second.Slug)
+
+	_, err = s.SavePost(ctx, models.InputPost{Title: "Other", Content: "Content", Slug: "novosti-mai"})
+	assert.ErrorIs(t, err, storage.ErrSlugTaken)
+
+	requested, err := s.SavePost(ctx, models.InputPost{Title: "Other", Content: "Content", Slug: "custom"})
+	require.NoError(t, err)
+	assert.Equal(t, "custom", requested.Slug)
+
+	// a new title gives a new slug and keeps the old one pointing at the post
+	renamed, err := s.PatchPost(ctx, first.ID, 0, fullPatch("Расписание", "Content"))
+	require.NoError(t, err)
+	assert.Equal(t, "raspisanie", renamed.Slug)
+
+	got, err := s.GetPostBySlug(ctx, "raspisanie")
+	require.NoError(t, err)
+	assert.Equal(t, first.ID, got.ID)
+	got, err = s.GetPostBySlug(ctx, "novosti-mai")
+	require.NoError(t, err)
+	assert.Equal(t, first.ID, got.ID)
+	assert.Equal(t, "raspisanie", got.Slug)
+
+	// a former slug stays taken for other posts
+	former := "novosti-mai"
+	_, err = s.PatchPost(ctx, second.ID, 0, models.PostPatch{Slug: &former})
+	assert.ErrorIs(t, err, storage.ErrSlugTaken)
+
+	// but the post can take it back
+	reclaimed, err := s.PatchPost(ctx, first.ID, 0, models.PostPatch{Slug: &former})
+	require.NoError(t, err)
+	assert.Equal(t, "novosti-mai", reclaimed.Slug)
+	assert.Equal(t, renamed.Version+1, reclaimed.Version)
+	got, err = s.GetPostBySlug(ctx, "raspisanie")
+	require.NoError(t, err)
+	assert.Equal(t, "novosti-mai", got.Slug)
+
+	// content changes keep the slug
+	content := "Other content"
+	edited, err := s.PatchPost(ctx, first.ID, 0, models.PostPatch{Content: &content})
+	require.NoError(t, err)
+	assert.Equal(t, "novosti-mai", edited.Slug)
+
+	_, err = s.GetPostBySlug(ctx, "missing")
+	assert.ErrorIs(t, err, storage.ErrPostNotFound)
+
+	// trashed posts are not found by slug
+	require.NoError(t, s.DeletePost(ctx, first.ID, 0))
+	_, err = s.GetPostBySlug(ctx, "novosti-mai")
+	assert.ErrorIs(t, err, storage.ErrPostNotFound)
+}
@@ -0,0 +1,116 @@
+// Package storagetest holds the tests every storage backend has to pass.
+// A backend runs them against itself with Run, each on an empty storage.
+package storagetest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/RomanKovalev007/mai_news/internal/lib/jsonpatch"
+	"github.com/RomanKovalev007/mai_news/internal/models"
+)
+
+// Storage is what a storage backend implements.
+type Storage interface {
+	SavePost(ctx context.Context, inputPost models.InputPost) (models.OutputPost, error)
+	GetPost(ctx context.Context, id int) (models.OutputPost, error)
+	GetPostBySlug(ctx context.Context, slug string) (models.OutputPost, error)
+	GetAllPosts(ctx context.Context, filter models.PostFilter, page models.Page) (models.PostsPage, error)
+	PatchPost(ctx context.Context, id, version int, patch models.PostPatch) (models.OutputPost, error)
+	ApplyPatch(ctx context.Context, id, version, editorID int, ops []jsonpatch.Operation) (models.OutputPost, error)
+	DeletePost(ctx context.Context, id, version int) error
+	SearchPosts(ctx context.Context, query string, limit int) ([]models.SearchResult, error)
+
+	GetTrash(ctx context.Context) ([]models.OutputPost, error)
+	GetTrashedPost(ctx context.Context, id int) (models.OutputPost, error)
+	RestorePost(ctx context.Context, id int) (models.OutputPost, error)
+	PurgePost(ctx context.Context, id int) error
+	PurgeTrash(ctx context.Context, deletedBefore time.Time) (int, error)
+
+	GetRevisions(ctx context.Context, postID int) ([]models.Revision, error)
+	GetRevision(ctx context.Context, postID, rev int) (models.Revision, error)
+	RevertPost(ctx context.Context, postID, rev, editorID int) (models.OutputPost, error)
+
+	GetTags(ctx context.Context) ([]models.Tag, error)
+	RenameTag(ctx context.Context, name, newName string) (models.Tag, error)
+	MergeTags(ctx context.Context, from, into string) (models.Tag, error)
+
+	SaveSection(ctx context.Context, input models.InputSection) (models.Section, error)
+	GetSection(ctx context.Context, slug string) (models.Section, error)
+	GetSections(ctx context.Context) ([]models.Section, error)
+	PatchSection(ctx context.Context, slug string, patch models.SectionPatch) (models.Section, error)
+
+	SaveUser(ctx context.Context, input models.InputUser) (models.User, error)
+	GetUser(ctx context.Context, id int) (models.User, error)
+	GetUserByEmail(ctx context.Context, email string) (models.User, error)
+	GetUsers(ctx context.Context) ([]models.User, error)
+	PatchUser(ctx context.Context, id int, patch models.UserPatch) (models.User, error)
+	DeleteUser(ctx context.Context, id int) error
+
+	RevokeToken(ctx context.Context, id string, expiresAt time.Time) error
+	TokenRevoked(ctx context.Context, id string) (bool, error)
+
+	SaveAPIKey(ctx context.Context, input models.InputAPIKey) (models.APIKey, error)
+	GetAPIKey(ctx context.Context, id int) (models.APIKey, error)
+	GetAPIKeyByHash(ctx context.Context, hash string) (models.APIKey, error)
+	GetAPIKeys(ctx context.Context) ([]models.APIKey, error)
+	RotateAPIKey(ctx context.Context, id int, prefix, hash string) (models.APIKey, error)
+	TouchAPIKey(ctx context.Context, id int, usedAt time.Time) error
+	DeleteAPIKey(ctx context.Context, id int) error
+
+	SaveComment(ctx context.Context, input models.InputComment) (models.Comment, error)
+	GetComment(ctx context.Context, id int) (models.Comment, error)
+	GetComments(ctx context.Context, postID int) ([]models.Comment, error)
+	GetCommentQueue(ctx context.Context, sections []string) ([]models.Comment, error)
+	PatchComment(ctx context.Context, id int, patch models.CommentPatch) (models.Comment, error)
+	DeleteComment(ctx context.Context, id int) error
+
+	SetReaction(ctx context.Context, postID int, reactor models.ReactorID, kind models.ReactionKind) (models.PostReactions, error)
+	DeleteReaction(ctx context.Context, postID int, reactor models.ReactorID, kind models.ReactionKind) error
+
+	AddViews(ctx context.Context, counts []models.ViewCount) error
+	GetPostStats(ctx context.Context, postID int) (models.PostStats, error)
+}
+
+var tests = []struct {
+	name string
+	test func(t *testing.T, s Storage)
+}{
+	{"PostCRUD", testPostCRUD},
+	{"PostNotFound", testPostNotFound},
+	{"UniqueTitle", testUniqueTitle},
+	{"GetAllPostsPages", testGetAllPostsPages},
+	{"GetAllPostsFilter", testGetAllPostsFilter},
+	{"PostVersions", testPostVersions},
+	{"PartialPatch", testPartialPatch},
+	{"ApplyPatch", testApplyPatch},
+	{"UpdatedAt", testUpdatedAt},
+	{"Revisions", testRevisions},
+	{"RevisionsOfMissingPost", testRevisionsOfMissingPost},
+	{"RevisionEditors", testRevisionEditors},
+	{"SearchPosts", testSearchPosts},
+	{"Trash", testTrash},
+	{"PurgeTrash", testPurgeTrash},
+	{"GetTrashedPost", testGetTrashedPost},
+	{"Slugs", testSlugs},
+	{"Tags", testTags},
+	{"Sections", testSections},
+	{"Users", testUsers},
+	{"RevokedTokens", testRevokedTokens},
+	{"APIKeys", testAPIKeys},
+	{"UserRoles", testUserRoles},
+	{"Comments", testComments},
+	{"Reactions", testReactions},
+	{"PostViews", testPostViews},
+}
+
+// Run runs the tests against the storages newStorage returns, a new empty
+// one for each test.
+func Run(t *testing.T, newStorage func(t *testing.T) Storage) {
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.test(t, newStorage(t))
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package storagetest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/RomanKovalev007/mai_news/internal/models"
+	"github.com/RomanKovalev007/mai_news/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testTags(t *testing.T, s Storage) {
+	ctx := context.Background()
+
+	first, err := s.SavePost(ctx, models.InputPost{Title: "First", Tags: []string{"sports", "admissions", "sports"}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"admissions", "sports"}, first.Tags)
+	second, err := s.SavePost(ctx, models.InputPost{Title: "Second", Tags: []string{"sports"}})
+	require.NoError(t, err)
+	third, err := s.SavePost(ctx, models.InputPost{Title: "Third"})
+	require.NoError(t, err)
+	assert.Empty(t, third.Tags)
+
+	// a failed save leaves no tags behind
+	_, err = s.SavePost(ctx, models.InputPost{Title: "First", Tags: []string{"lost"}})
+	assert.ErrorIs(t, err, storage.ErrPostExists)
+
+	got, err := s.GetPost(ctx, first.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"admissions", "sports"}, got.Tags)
+
+	list := func(names []string, all bool) []int {
+		page, err := s.GetAllPosts(ctx, models.PostFilter{Tags: names, AllTags: all}, models.Page{Limit: 10})
+		require.NoError(t, err)
+		var ids []int
+		for _, post := range page.Posts {
+			ids = append(ids, post.ID)
+		}
+		return ids
+	}
+	assert.Equal(t, []int{first.ID, second.ID}, list([]string{"sports"}, false))
+	assert.Equal(t, []int{first.ID, second.ID}, list([]string{"sports", "admissions"}, false))
+	assert.Equal(t, []int{first.ID}, list([]string{"sports", "admissions"}, true))
+	assert.Empty(t, list([]string{"missing"}, false))
+	assert.Equal(t, []int{first.ID, second.ID, third.ID}, list(nil, true))
+
+	page, err := s.GetAllPosts(ctx, models.PostFilter{}, models.Page{Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, page.Posts, 3)
+	assert.Equal(t, []string{"admissions", "sports"}, page.Posts[0].Tags)
+	assert.Equal(t, []string{"sports"}, page.Posts[1].Tags)
+	assert.Empty(t, page.Posts[2].Tags)
+
+	all, err := s.GetTags(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []models.Tag{{Name: "sports", Count: 2}, {Name: "admissions", Count: 1}}, all)
+
+	// changing tags is a change of the post, setting the same ones is not
+	names := []string{"scholarships"}
+	patched, err := s.PatchPost(ctx, second.ID, 0, models.PostPatch{Tags: &names})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"scholarships"}, patched.Tags)
+	assert.Equal(t, second.Version+1, patched.Version)
+	unchanged, err := s.PatchPost(ctx, second.ID, 0, models.PostPatch{Tags: &names})
+	require.NoError(t, err)
+	assert.Equal(t, patched.Version, unchanged.Version)
+	title := "Second, renamed"
+	renamed, err := s.PatchPost(ctx, second.ID, 0, models.PostPatch{Title: &title})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"scholarships"}, renamed.Tags)
+
+	// a tag no post carries any more does not block a rename
+	old, empty := []string{"old"}, []string{}
+	_, err = s.PatchPost(ctx, third.ID, 0, models.PostPatch{Tags: &old})
+	require.NoError(t, err)
+	_, err = s.PatchPost(ctx, third.ID, 0, models.PostPatch{Tags: &empty})
+	require.NoError(t, err)
+
+	tag, err := s.RenameTag(ctx, "sports", "old")
+	require.NoError(t, err)
+	assert.Equal(t, models.Tag{Name: "old", Count: 1}, tag)
+	got, err = s.GetPost(ctx, first.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"admissions", "old"}, got.Tags)
+	assert.Greater(t, got.Version, first.Version)
+
+	_, err = s.RenameTag(ctx, "old", "admissions")
+	assert.ErrorIs(t, err, storage.ErrTagExists)
+	_, err = s.RenameTag(ctx, "sports", "sport")
+	assert.ErrorIs(t, err, storage.ErrTagNotFound)
+
+	before, err := s.GetPost(ctx, second.ID)
+	require.NoError(t, err)
+	tag, err = s.MergeTags(ctx, "scholarships", "admissions")
+	require.NoError(t, err)
+	assert.Equal(t, models.Tag{Name: "admissions", Count: 2}, tag)
+	got, err = s.GetPost(ctx, second.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"admissions"}, got.Tags)
+	assert.Greater(t, got.Version, before.Version)
+
+	_, err = s.MergeTags(ctx, "scholarships", "admissions")
+	assert.ErrorIs(t, err, storage.ErrTagNotFound)
+	_, err = s.MergeTags(ctx, "admissions", "missing")
+	assert.ErrorIs(t, err, storage.ErrTagNotFound)
+
+	// trashed posts are not counted
+	require.NoError(t, s.DeletePost(ctx, first.ID, 0))
+	all, err = s.GetTags(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []models.Tag{{Name: "admissions", Count: 1}, {Name: "old", Count: 0}}, all)
+
+	trash, err := s.GetTrash(ctx)
+	require.NoError(t, err)
+	require.Len(t, trash, 1)
+	assert.Equal(t, []string{"admissions", "old"}, trash[0].Tags)
+}
@@ -0,0 +1,95 @@
+package storagetest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/RomanKovalev007/mai_news/internal/models"
+	"github.com/RomanKovalev007/mai_news/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testTrash(t *testing.T, s Storage) {
+	ctx := context.Background()
+
+	post, err := s.SavePost(ctx, models.InputPost{Title: "Title", Content: "Content"})
+	require.NoError(t, err)
+	require.NoError(t, s.DeletePost(ctx, post.ID, 0))
+
+	_, err = s.GetPost(ctx, post.ID)
+	assert.ErrorIs(t, err, storage.ErrPostNotFound)
+	_, err = s.PatchPost(ctx, post.ID, 0, fullPatch("x", ""))
+	assert.ErrorIs(t, err, storage.ErrPostNotFound)
+	assert.ErrorIs(t, s.DeletePost(ctx, post.ID, 0), storage.ErrPostNotFound)
+
+	page, err := s.GetAllPosts(ctx, models.PostFilter{}, models.Page{Limit: 10})
+	require.NoError(t, err)
+	assert.Empty(t, page.Posts)
+
+	trash, err := s.GetTrash(ctx)
+	require.NoError(t, err)
+	require.Len(t, trash, 1)
+	assert.Equal(t, post.ID, trash[0].ID)
+	assert.NotEmpty(t, trash[0].DeletedAt)
+
+	restored, err := s.RestorePost(ctx, post.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Title", restored.Title)
+
+	_, err = s.RestorePost(ctx, post.ID)
+	assert.ErrorIs(t, err, storage.ErrPostNotFound)
+	assert.ErrorIs(t, s.PurgePost(ctx, post.ID), storage.ErrPostNotFound)
+
+	require.NoError(t, s.DeletePost(ctx, post.ID, 0))
+	require.NoError(t, s.PurgePost(ctx, post.ID))
+
+	trash, err = s.GetTrash(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []models.OutputPost{}, trash)
+}
+
+func testPurgeTrash(t *testing.T, s Storage) {
+	ctx := context.Background()
+
+	post, err := s.SavePost(ctx, models.InputPost{Title: "Title", Content: "Content"})
+	require.NoError(t, err)
+	require.NoError(t, s.DeletePost(ctx, post.ID, 0))
+
+	n, err := s.PurgeTrash(ctx, time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, 0, n)
+
+	n, err = s.PurgeTrash(ctx, time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	_, err = s.RestorePost(ctx, post.ID)
+	assert.ErrorIs(t, err, storage.ErrPostNotFound)
+}
+
+func testGetTrashedPost(t *testing.T, s Storage) {
+	ctx := context.Background()
+
+	author, err := s.SaveUser(ctx, models.InputUser{Name: "Анна", Email: "anna@mai.ru", PasswordHash: "hash"})
+	require.NoError(t, err)
+	post, err := s.SavePost(ctx, models.InputPost{Title: "Title", Content: "Content", Tags: []string{"news"}, AuthorID: author.ID})
+	require.NoError(t, err)
+
+	_, err = s.GetTrashedPost(ctx, post.ID)
+	assert.ErrorIs(t, err, storage.ErrPostNotFound)
+
+	require.NoError(t, s.DeletePost(ctx, post.ID, 0))
+	trashed, err := s.GetTrashedPost(ctx, post.ID)
+	require.NoError(t, err)
+	assert.Equal(t, post.ID, trashed.ID)
+	assert.Equal(t, models.DefaultSection, trashed.Section)
+	assert.Equal(t, []string{"news"}, trashed.Tags)
+	require.NotNil(t, trashed.Author)
+	assert.Equal(t, author.ID, trashed.Author.ID)
+	assert.False(t, trashed.DeletedAt.IsZero())
+
+	_, err = s.GetTrashedPost(ctx, post.ID+100)
+	assert.ErrorIs(t, err, storage.ErrPostNotFound)
+}
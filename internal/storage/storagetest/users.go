@@ -0,0 +1,141 @@
+package storagetest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/RomanKovalev007/mai_news/internal/models"
+	"github.com/RomanKovalev007/mai_news/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testUsers(t *testing.T, s Storage) {
+	ctx := context.Background()
+
+	anna, err := s.SaveUser(ctx, models.InputUser{Name: "Анна", Email: "anna@mai.ru", PasswordHash: "hash"})
+	require.NoError(t, err)
+	assert.NotZero(t, anna.ID)
+	assert.False(t, anna.CreatedAt.IsZero())
+	_, err = s.SaveUser(ctx, models.InputUser{Name: "Другая Анна", Email: "anna@mai.ru", PasswordHash: "hash"})
+	assert.ErrorIs(t, err, storage.ErrUserExists)
+	boris, err := s.SaveUser(ctx, models.InputUser{Name: "Борис", Email: "boris@mai.ru", PasswordHash: "hash"})
+	require.NoError(t, err)
+
+	got, err := s.GetUserByEmail(ctx, "anna@mai.ru")
+	require.NoError(t, err)
+	assert.Equal(t, anna.ID, got.ID)
+	assert.Equal(t, "hash", got.PasswordHash)
+	_, err = s.GetUserByEmail(ctx, "missing@mai.ru")
+	assert.ErrorIs(t, err, storage.ErrUserNotFound)
+	_, err = s.GetUser(ctx, 0)
+	assert.ErrorIs(t, err, storage.ErrUserNotFound)
+
+	users, err := s.GetUsers(ctx)
+	require.NoError(t, err)
+	require.Len(t, users, 2)
+	assert.Equal(t, anna.ID, users[0].ID)
+	assert.Equal(t, boris.ID, users[1].ID)
+
+	post, err := s.SavePost(ctx, models.InputPost{Title: "Title", Content: "Content", AuthorID: anna.ID})
+	require.NoError(t, err)
+	assert.Equal(t, &models.Author{ID: anna.ID, Name: "Анна"}, post.Author)
+	_, err = s.SavePost(ctx, models.InputPost{Title: "Other", Content: "Content", AuthorID: anna.ID + 100})
+	assert.ErrorIs(t, err, storage.ErrUserNotFound)
+	anonymous, err := s.SavePost(ctx, models.InputPost{Title: "Anonymous", Content: "Content"})
+	require.NoError(t, err)
+	assert.Nil(t, anonymous.Author)
+
+	page, err := s.GetAllPosts(ctx, models.PostFilter{AuthorID: anna.ID}, models.Page{Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, page.Posts, 1)
+	assert.Equal(t, post.ID, page.Posts[0].ID)
+	assert.Equal(t, "Анна", page.Posts[0].Author.Name)
+	page, err = s.GetAllPosts(ctx, models.PostFilter{AuthorID: boris.ID}, models.Page{Limit: 10})
+	require.NoError(t, err)
+	assert.Empty(t, page.Posts)
+
+	// a patch of the post keeps its author
+	content := "New content"
+	patched, err := s.PatchPost(ctx, post.ID, 0, models.PostPatch{Content: &content})
+	require.NoError(t, err)
+	assert.Equal(t, "Анна", patched.Author.Name)
+
+	// the post shows the new name of its author
+	name, email := "Анна Петрова", "petrova@mai.ru"
+	renamed, err := s.PatchUser(ctx, anna.ID, models.UserPatch{Name: &name, Email: &email})
+	require.NoError(t, err)
+	assert.Equal(t, name, renamed.Name)
+	assert.Equal(t, email, renamed.Email)
+	assert.Equal(t, "hash", renamed.PasswordHash)
+	updated, err := s.GetPost(ctx, post.ID)
+	require.NoError(t, err)
+	assert.Equal(t, name, updated.Author.Name)
+	assert.Greater(t, updated.Version, patched.Version)
+
+	taken := "boris@mai.ru"
+	_, err = s.PatchUser(ctx, anna.ID, models.UserPatch{Email: &taken})
+	assert.ErrorIs(t, err, storage.ErrUserExists)
+	_, err = s.PatchUser(ctx, anna.ID+100, models.UserPatch{Name: &name})
+	assert.ErrorIs(t, err, storage.ErrUserNotFound)
+
+	// the posts of a deleted user stay, without an author
+	require.NoError(t, s.DeleteUser(ctx, anna.ID))
+	assert.ErrorIs(t, s.DeleteUser(ctx, anna.ID), storage.ErrUserNotFound)
+	_, err = s.GetUser(ctx, anna.ID)
+	assert.ErrorIs(t, err, storage.ErrUserNotFound)
+	orphan, err := s.GetPost(ctx, post.ID)
+	require.NoError(t, err)
+	assert.Nil(t, orphan.Author)
+	assert.Greater(t, orphan.Version, updated.Version)
+}
+
+func testRevokedTokens(t *testing.T, s Storage) {
+	ctx := context.Background()
+
+	revoked, err := s.TokenRevoked(ctx, "a")
+	require.NoError(t, err)
+	assert.False(t, revoked)
+
+	require.NoError(t, s.RevokeToken(ctx, "a", time.Now().Add(time.Hour)))
+	revoked, err = s.TokenRevoked(ctx, "a")
+	require.NoError(t, err)
+	assert.True(t, revoked)
+	assert.ErrorIs(t, s.RevokeToken(ctx, "a", time.Now().Add(time.Hour)), storage.ErrTokenRevoked)
+
+	// revocations of expired tokens are dropped by later revocations
+	require.NoError(t, s.RevokeToken(ctx, "expired", time.Now().Add(-time.Minute)))
+	require.NoError(t, s.RevokeToken(ctx, "b", time.Now().Add(time.Hour)))
+	revoked, err = s.TokenRevoked(ctx, "expired")
+	require.NoError(t, err)
+	assert.False(t, revoked)
+	revoked, err = s.TokenRevoked(ctx, "a")
+	require.NoError(t, err)
+	assert.True(t, revoked)
+}
+
+func testUserRoles(t *testing.T, s Storage) {
+	ctx := context.Background()
+
+	reader, err := s.SaveUser(ctx, models.InputUser{Name: "Читатель", Email: "reader@mai.ru", PasswordHash: "hash"})
+	require.NoError(t, err)
+	assert.Equal(t, models.RoleReader, reader.Role)
+	editor, err := s.SaveUser(ctx, models.InputUser{Name: "Редактор", Email: "editor@mai.ru", PasswordHash: "hash", Role: models.RoleEditor})
+	require.NoError(t, err)
+	assert.Equal(t, models.RoleEditor, editor.Role)
+
+	role := models.RoleAuthor
+	patched, err := s.PatchUser(ctx, reader.ID, models.UserPatch{Role: &role})
+	require.NoError(t, err)
+	assert.Equal(t, models.RoleAuthor, patched.Role)
+	got, err := s.GetUserByEmail(ctx, "reader@mai.ru")
+	require.NoError(t, err)
+	assert.Equal(t, models.RoleAuthor, got.Role)
+
+	// a patch without a role keeps it
+	name := "Автор"
+	patched, err = s.PatchUser(ctx, reader.ID, models.UserPatch{Name: &name})
+	require.NoError(t, err)
+	assert.Equal(t, models.RoleAuthor, patched.Role)
+}
@@ -0,0 +1,45 @@
+package storagetest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/RomanKovalev007/mai_news/internal/models"
+	"github.com/RomanKovalev007/mai_news/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testPostViews(t *testing.T, s Storage) {
+	ctx := context.Background()
+
+	post, err := s.SavePost(ctx, models.InputPost{Title: "Title", Content: "Content"})
+	require.NoError(t, err)
+
+	stats, err := s.GetPostStats(ctx, post.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.PostStats{PostID: post.ID, Days: []models.DayViews{}}, stats)
+	_, err = s.GetPostStats(ctx, post.ID+100)
+	assert.ErrorIs(t, err, storage.ErrPostNotFound)
+
+	// views of unknown posts are dropped, and batches add up
+	require.NoError(t, s.AddViews(ctx, []models.ViewCount{
+		{PostID: post.ID, Day: "2025-09-02", Views: 3},
+		{PostID: post.ID + 100, Day: "2025-09-02", Views: 1},
+	}))
+	require.NoError(t, s.AddViews(ctx, []models.ViewCount{
+		{PostID: post.ID, Day: "2025-09-01", Views: 1},
+		{PostID: post.ID, Day: "2025-09-02", Views: 2},
+	}))
+	stats, err = s.GetPostStats(ctx, post.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.PostStats{PostID: post.ID, Views: 6, Days: []models.DayViews{
+		{Day: "2025-09-01", Views: 1},
+		{Day: "2025-09-02", Views: 5},
+	}}, stats)
+
+	require.NoError(t, s.DeletePost(ctx, post.ID, 0))
+	_, err = s.GetPostStats(ctx, post.ID)
+	assert.ErrorIs(t, err, storage.ErrPostNotFound)
+	require.NoError(t, s.PurgePost(ctx, post.ID))
+}